@@ -1,11 +1,16 @@
 package main
 
 import (
+	"context"
 	_ "knowledge-base/docs"
 	"knowledge-base/internal/app"
+	"knowledge-base/internal/config"
 	"knowledge-base/internal/database"
+	"knowledge-base/internal/logging"
 	"knowledge-base/internal/router"
+	"knowledge-base/internal/tracing"
 	"log"
+	"log/slog"
 	"net/http"
 
 	_ "github.com/lib/pq"
@@ -18,6 +23,9 @@ import (
 // @BasePath /
 func main() {
 
+	// Структурированные JSON логи. Стандартный пакет log тоже пишет через этот логгер.
+	slog.SetDefault(logging.New())
+
 	// Подключение к базе данных.
 	db := database.Connect()
 	defer db.Close()
@@ -27,8 +35,22 @@ func main() {
 		log.Fatal("Ошибка миграций:", err)
 	}
 
+	// Чтение настроек приложения.
+	cfg := config.Load()
+
+	// Настройка трассировки OpenTelemetry.
+	shutdownTracing, err := tracing.Setup(context.Background(), tracing.Options{
+		Exporter:     cfg.TracingExporter,
+		OTLPEndpoint: cfg.TracingOTLPEndpoint,
+		SampleRatio:  cfg.TracingSampleRatio,
+	})
+	if err != nil {
+		log.Fatal("Ошибка настройки трассировки:", err)
+	}
+	defer shutdownTracing(context.Background())
+
 	// Создание контейнера зависимостей.
-	container := app.NewContainer(db)
+	container := app.NewContainer(db, cfg)
 
 	// Настройка маршрутизатора.
 	router := router.Setup(container)
@@ -15,9 +15,120 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
+        "/admin/answers/feedback": {
+            "get": {
+                "description": "Answers with the worst helpful ratio among answers with at least min_feedback learner ratings, with their latest comments, so tutors can improve them",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "answer votes"
+                ],
+                "summary": "Answer helpfulness report",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Minimum number of ratings (1-10000, default 3)",
+                        "name": "min_feedback",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of answers (1-500, default 20)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.AnswerFeedbackStat"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid parameter",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Tutor or moderator token required",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/search/{report}": {
+            "get": {
+                "description": "Aggregated search log for the period: top-queries (most frequent), zero-results (queries that found nothing, most frequent first) or click-through (share of searches followed by opening a result)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "search analytics"
+                ],
+                "summary": "Search analytics report",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "top-queries, zero-results or click-through",
+                        "name": "report",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Period in days (1-365, default 30)",
+                        "name": "days",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of queries (1-500, default 20)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.SearchQueryStat"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid parameter",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Tutor or moderator token required",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Unknown report",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
         "/answer-versions/{id}": {
             "get": {
-                "description": "Returns all versions of a specific answer by answer ID",
+                "description": "Returns paginated versions of a specific answer by answer ID, supports cursor pagination. Learners see only published versions",
                 "produces": [
                     "application/json"
                 ],
@@ -32,6 +143,42 @@ const docTemplate = `{
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size (1-500, default 50)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of records to skip",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Opaque cursor from Link header (cannot be combined with offset)",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Sort field (version_number, created_at, id), prefix with - for descending",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Created after (RFC 3339 or YYYY-MM-DD)",
+                        "name": "created_after",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Created before (RFC 3339 or YYYY-MM-DD)",
+                        "name": "created_before",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -42,10 +189,26 @@ const docTemplate = `{
                             "items": {
                                 "$ref": "#/definitions/models.AnswerVersion"
                             }
+                        },
+                        "headers": {
+                            "Link": {
+                                "type": "string",
+                                "description": "Cursor links to next and prev pages"
+                            },
+                            "X-Total-Count": {
+                                "type": "integer",
+                                "description": "Total number of versions matching filters"
+                            }
                         }
                     },
                     "400": {
-                        "description": "Invalid answer ID",
+                        "description": "Invalid answer ID or pagination parameter",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Answer not found or not published",
                         "schema": {
                             "type": "string"
                         }
@@ -55,7 +218,7 @@ const docTemplate = `{
         },
         "/answers": {
             "get": {
-                "description": "Returns list of all answers",
+                "description": "Returns paginated list of answers with sorting and filters",
                 "produces": [
                     "application/json"
                 ],
@@ -63,6 +226,74 @@ const docTemplate = `{
                     "answers"
                 ],
                 "summary": "Get all answers",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Page size (1-500, default 50)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of records to skip",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Opaque cursor from Link header, sort by id, created_at or score (cannot be combined with offset)",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Sort field (id, created_at, question_id, tutor_id, score), prefix with - for descending",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Filter by tutor ID",
+                        "name": "tutor_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Filter by question ID",
+                        "name": "question_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Created after (RFC 3339 or YYYY-MM-DD)",
+                        "name": "created_after",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Created before (RFC 3339 or YYYY-MM-DD)",
+                        "name": "created_before",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Filter by edit flag",
+                        "name": "is_edit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Filter by accepted flag",
+                        "name": "is_accepted",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by status: draft, in_review, published or archived. Learners only see published",
+                        "name": "status",
+                        "in": "query"
+                    }
+                ],
                 "responses": {
                     "200": {
                         "description": "OK",
@@ -71,12 +302,28 @@ const docTemplate = `{
                             "items": {
                                 "$ref": "#/definitions/models.Answer"
                             }
+                        },
+                        "headers": {
+                            "Link": {
+                                "type": "string",
+                                "description": "Links to next and prev pages"
+                            },
+                            "X-Total-Count": {
+                                "type": "integer",
+                                "description": "Total number of records matching filters"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid pagination, sort or filter parameter",
+                        "schema": {
+                            "type": "string"
                         }
                     }
                 }
             },
             "post": {
-                "description": "Create a new answer with text, tutor_id, question_id. Create a new answer_version with answer_id, answer_text, tutor_id, answer_number",
+                "description": "Create a new answer with text, tutor_id, question_id. The first answer of a question becomes accepted, the next ones go last. Create a new answer_version with answer_id, answer_text, tutor_id, answer_number",
                 "consumes": [
                     "application/json"
                 ],
@@ -112,6 +359,116 @@ const docTemplate = `{
                             "type": "string"
                         }
                     },
+                    "401": {
+                        "description": "Tutor or moderator token required",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Question not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "413": {
+                        "description": "Request body too large",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "415": {
+                        "description": "Content-Type must be application/json",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/answers/export": {
+            "get": {
+                "description": "Streams all answers matching filters as a JSON array or NDJSON (Accept: application/x-ndjson) without pagination",
+                "produces": [
+                    "application/json",
+                    "application/x-ndjson"
+                ],
+                "tags": [
+                    "answers"
+                ],
+                "summary": "Export all answers",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Sort field (id, created_at, question_id, tutor_id, score), prefix with - for descending",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Filter by tutor ID",
+                        "name": "tutor_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Filter by question ID",
+                        "name": "question_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Created after (RFC 3339 or YYYY-MM-DD)",
+                        "name": "created_after",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Created before (RFC 3339 or YYYY-MM-DD)",
+                        "name": "created_before",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Filter by edit flag",
+                        "name": "is_edit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Filter by accepted flag",
+                        "name": "is_accepted",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by status: draft, in_review, published or archived. Learners only see published",
+                        "name": "status",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Answer"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid sort or filter parameter",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
@@ -162,7 +519,7 @@ const docTemplate = `{
                 }
             },
             "put": {
-                "description": "Update answer with text, tutor_id, question_id and edit flag. Create a new answer_version with answer_id, answer_text, tutor_id, answer_number",
+                "description": "Update answer with text, tutor_id, question_id and edit flag. An answer moved to another question is no longer accepted and goes last. Create a new answer_version with answer_id, answer_text, tutor_id, answer_number. An answer that is not a draft goes back to in_review until a moderator publishes it",
                 "consumes": [
                     "application/json"
                 ],
@@ -205,22 +562,43 @@ const docTemplate = `{
                             "type": "string"
                         }
                     },
+                    "401": {
+                        "description": "Tutor or moderator token required",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
                     "404": {
                         "description": "Answer not found",
                         "schema": {
                             "type": "string"
                         }
+                    },
+                    "413": {
+                        "description": "Request body too large",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "415": {
+                        "description": "Content-Type must be application/json",
+                        "schema": {
+                            "type": "string"
+                        }
                     }
                 }
             }
         },
-        "/answers/{id}/deleteBy/{tutor_id}": {
-            "delete": {
-                "description": "Delete answer by ID and mark all answer versions as deleted with tutor who performed deletion",
+        "/answers/{id}/comments": {
+            "get": {
+                "description": "Returns the comment threads of the answer: top-level comments in creation order with nested replies. Deleted comments stay in the thread with empty text",
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
-                    "answers"
+                    "comments"
                 ],
-                "summary": "Delete answer by ID with version tracking",
+                "summary": "Get comments of answer",
                 "parameters": [
                     {
                         "type": "integer",
@@ -228,149 +606,121 @@ const docTemplate = `{
                         "name": "id",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Tutor ID who deleted the answer",
-                        "name": "tutor_id",
-                        "in": "path",
-                        "required": true
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "No Content"
-                    },
-                    "400": {
-                        "description": "Invalid ID",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "type": "string"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Comment"
+                            }
                         }
                     },
-                    "404": {
-                        "description": "Answer not found",
+                    "400": {
+                        "description": "Invalid answer ID",
                         "schema": {
                             "type": "string"
                         }
-                    }
-                }
-            }
-        },
-        "/question-tags": {
-            "get": {
-                "description": "Returns list of all relations between questions and tags",
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "question-tags"
-                ],
-                "summary": "Get all question-tag relations",
-                "responses": {
-                    "200": {
-                        "description": "OK",
+                    },
+                    "404": {
+                        "description": "Answer not found",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.QuestionTag"
-                            }
+                            "type": "string"
                         }
                     }
                 }
-            }
-        },
-        "/question-tags/by-tag/{tag_id}": {
-            "get": {
-                "description": "Returns list of all relations between questions and tags by tag ID",
-                "produces": [
+            },
+            "post": {
+                "description": "Adds a comment or a reply (parent_id) to the answer. With tutor_id the author is the tutor, otherwise a learner identified by an anonymized client ID from a signed kb_client cookie issued by the server (or IP address and User-Agent). Tutors mentioned as @email or @Full Name (or @Full_Name) are recorded in mentions",
+                "consumes": [
                     "application/json"
                 ],
-                "tags": [
-                    "question-tags"
-                ],
-                "summary": "Get all question-tag relations by tag ID",
-                "parameters": [
-                    {
-                        "type": "integer",
-                        "description": "Tag ID",
-                        "name": "tag_id",
-                        "in": "path",
-                        "required": true
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.QuestionTag"
-                            }
-                        }
-                    }
-                }
-            }
-        },
-        "/question-tags/{question_id}/{tag_id}": {
-            "post": {
-                "description": "Associate a tag with a question using IDs from URL path",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "question-tags"
+                    "comments"
                 ],
-                "summary": "Add tag to question",
+                "summary": "Comment on answer",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Question ID",
-                        "name": "question_id",
+                        "description": "Answer ID",
+                        "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "type": "integer",
-                        "description": "Tag ID",
-                        "name": "tag_id",
-                        "in": "path",
-                        "required": true
+                        "description": "Comment text (up to 5000 characters), author and parent comment",
+                        "name": "comment",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CommentRequestBody"
+                        }
                     }
                 ],
                 "responses": {
                     "201": {
                         "description": "Created",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/models.Comment"
                         }
                     },
                     "400": {
-                        "description": "Bad request",
+                        "description": "Invalid request, unknown tutor or parent comment",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Answer not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "Parent comment is deleted",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "413": {
+                        "description": "Request body too large",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "415": {
+                        "description": "Content-Type must be application/json",
                         "schema": {
                             "type": "string"
                         }
                     }
                 }
-            },
+            }
+        },
+        "/answers/{id}/deleteBy/{tutor_id}": {
             "delete": {
-                "description": "Delete relation between question and tag by their IDs",
+                "description": "Delete answer by ID and mark all answer versions as deleted with tutor who performed deletion",
                 "tags": [
-                    "question-tags"
+                    "answers"
                 ],
-                "summary": "Delete question-tag relation",
+                "summary": "Delete answer by ID with version tracking",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Question ID",
-                        "name": "question_id",
+                        "description": "Answer ID",
+                        "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
                         "type": "integer",
-                        "description": "Tag ID",
-                        "name": "tag_id",
+                        "description": "Tutor ID who deleted the answer",
+                        "name": "tutor_id",
                         "in": "path",
                         "required": true
                     }
@@ -380,51 +730,19 @@ const docTemplate = `{
                         "description": "No Content"
                     },
                     "400": {
-                        "description": "Invalid question ID or tag ID",
+                        "description": "Invalid ID",
                         "schema": {
                             "type": "string"
                         }
                     },
-                    "404": {
-                        "description": "Relation not found",
+                    "401": {
+                        "description": "Tutor or moderator token required",
                         "schema": {
                             "type": "string"
                         }
-                    }
-                }
-            }
-        },
-        "/question-versions/{id}": {
-            "get": {
-                "description": "Returns all versions of a specific question by question ID",
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "question-versions"
-                ],
-                "summary": "Get question versions by question ID",
-                "parameters": [
-                    {
-                        "type": "integer",
-                        "description": "Question ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.QuestionVersion"
-                            }
-                        }
                     },
-                    "400": {
-                        "description": "Invalid question ID",
+                    "404": {
+                        "description": "Answer not found",
                         "schema": {
                             "type": "string"
                         }
@@ -432,30 +750,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/questions": {
-            "get": {
-                "description": "Returns list of all questions",
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "questions"
-                ],
-                "summary": "Get all questions",
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.Question"
-                            }
-                        }
-                    }
-                }
-            },
+        "/answers/{id}/feedback": {
             "post": {
-                "description": "Create a new question with text, tutor_id. Create a new question_version with question_id, question_text, tutor_id, version_number",
+                "description": "Learner feedback: was the answer helpful, with an optional comment. Learners are identified by an anonymized client ID from a signed kb_client cookie issued by the server (or IP address and User-Agent), one rating per answer; a repeated rating replaces the previous one. Ratings from one IP address are limited per hour (FEEDBACK_RATE_LIMIT)",
                 "consumes": [
                     "application/json"
                 ],
@@ -463,26 +760,38 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "questions"
+                    "answer votes"
                 ],
-                "summary": "Сreates a new question and records the version",
+                "summary": "Rate answer helpfulness",
                 "parameters": [
                     {
-                        "description": "Question data",
-                        "name": "question",
+                        "type": "integer",
+                        "description": "Answer ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Helpful flag and optional comment (up to 1000 characters)",
+                        "name": "feedback",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.QuestionsSwaggerRequestBody"
+                            "$ref": "#/definitions/models.AnswerFeedbackRequestBody"
                         }
                     }
                 ],
                 "responses": {
+                    "200": {
+                        "description": "Previous rating replaced",
+                        "schema": {
+                            "$ref": "#/definitions/models.Answer"
+                        }
+                    },
                     "201": {
-                        "description": "Question created",
+                        "description": "Rating recorded",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/models.Answer"
                         }
                     },
                     "400": {
@@ -491,57 +800,42 @@ const docTemplate = `{
                             "type": "string"
                         }
                     },
-                    "500": {
-                        "description": "Internal server error",
+                    "404": {
+                        "description": "Answer not found",
                         "schema": {
                             "type": "string"
                         }
-                    }
-                }
-            }
-        },
-        "/questions/{id}": {
-            "get": {
-                "description": "Returns question by specified ID",
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "questions"
-                ],
-                "summary": "Get question by ID",
-                "parameters": [
-                    {
-                        "type": "integer",
-                        "description": "Question ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "OK",
+                    },
+                    "413": {
+                        "description": "Request body too large",
                         "schema": {
-                            "$ref": "#/definitions/models.Question"
+                            "type": "string"
                         }
                     },
-                    "400": {
-                        "description": "Invalid ID",
+                    "415": {
+                        "description": "Content-Type must be application/json",
                         "schema": {
                             "type": "string"
                         }
                     },
-                    "404": {
-                        "description": "Question not found",
+                    "429": {
+                        "description": "Too many ratings from this address",
                         "schema": {
                             "type": "string"
+                        },
+                        "headers": {
+                            "Retry-After": {
+                                "type": "integer",
+                                "description": "Seconds until ratings are accepted again"
+                            }
                         }
                     }
                 }
-            },
+            }
+        },
+        "/answers/{id}/status": {
             "put": {
-                "description": "Update question with text, tutor_id and edit flag.Create a new question_version with question_id, question_text, tutor_id, version_number",
+                "description": "Moves the answer through the editorial workflow, with the same transitions as questions. The change is recorded as a new answer version",
                 "consumes": [
                     "application/json"
                 ],
@@ -549,43 +843,79 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "questions"
+                    "answers"
                 ],
-                "summary": "Update question and records the version",
+                "summary": "Change answer status",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Question ID",
+                        "description": "Answer ID",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Question data",
-                        "name": "question",
+                        "type": "string",
+                        "description": "Bearer token of tutor (TUTOR_TOKEN) or moderator (MODERATOR_TOKEN)",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "New status, tutor and review comment",
+                        "name": "status",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.QuestionsSwaggerRequestBody"
+                            "$ref": "#/definitions/models.StatusChangeRequestBody"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Question updated",
+                        "description": "OK",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/models.Answer"
                         }
                     },
                     "400": {
-                        "description": "Invalid request",
+                        "description": "Invalid ID, status, tutor or missing comment",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Tutor or moderator token required",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Role is not allowed to make this transition",
                         "schema": {
                             "type": "string"
                         }
                     },
                     "404": {
-                        "description": "Question not found",
+                        "description": "Answer not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "Transition is not allowed from the current status",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "413": {
+                        "description": "Request body too large",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "415": {
+                        "description": "Content-Type must be application/json",
                         "schema": {
                             "type": "string"
                         }
@@ -593,41 +923,65 @@ const docTemplate = `{
                 }
             }
         },
-        "/questions/{id}/deleteBy/{tutor_id}": {
-            "delete": {
-                "description": "Delete question by ID and mark versions as deleted",
+        "/answers/{id}/upvotes": {
+            "post": {
+                "description": "Tutor upvote for an answer of another tutor. The voting tutor is the one whose personal token (TUTOR_TOKENS) is in the Authorization header. A tutor can upvote an answer once and cannot upvote own answers",
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
-                    "questions"
+                    "answer votes"
                 ],
-                "summary": "Delete question",
+                "summary": "Upvote peer answer",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Question ID",
+                        "description": "Answer ID",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "type": "integer",
-                        "description": "Tutor ID who deleted the question",
-                        "name": "tutor_id",
-                        "in": "path",
+                        "type": "string",
+                        "description": "Bearer personal token of the voting tutor (TUTOR_TOKENS)",
+                        "name": "Authorization",
+                        "in": "header",
                         "required": true
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "No Content"
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.Answer"
+                        }
                     },
                     "400": {
-                        "description": "Invalid ID",
+                        "description": "Invalid ID, unknown tutor or own answer",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Tutor or moderator token required",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Not a personal tutor token",
                         "schema": {
                             "type": "string"
                         }
                     },
                     "404": {
-                        "description": "Question not found",
+                        "description": "Answer not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "Tutor already upvoted this answer",
                         "schema": {
                             "type": "string"
                         }
@@ -635,68 +989,70 @@ const docTemplate = `{
                 }
             }
         },
-        "/simple-search/{name}": {
-            "get": {
-                "description": "Search questions by exact tag name",
-                "produces": [
-                    "application/json"
-                ],
+        "/answers/{id}/upvotes/{tutor_id}": {
+            "delete": {
+                "description": "Removes the tutor upvote for the answer. A tutor with a personal token (TUTOR_TOKENS) removes only own upvote, a moderator removes any upvote",
                 "tags": [
-                    "search 🔍"
+                    "answer votes"
                 ],
-                "summary": "Search questions by tag name (exact match)",
+                "summary": "Remove upvote",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Tag name to search for (exact match)",
-                        "name": "name",
+                        "type": "integer",
+                        "description": "Answer ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Tutor ID",
+                        "name": "tutor_id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Bearer personal token of the tutor (TUTOR_TOKENS) or moderator token (MODERATOR_TOKEN)",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "204": {
+                        "description": "Upvote removed"
+                    },
+                    "400": {
+                        "description": "Invalid ID",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.Question"
-                            }
+                            "type": "string"
                         }
                     },
-                    "400": {
-                        "description": "Tag name parameter is required",
+                    "401": {
+                        "description": "Tutor or moderator token required",
                         "schema": {
                             "type": "string"
                         }
-                    }
-                }
-            }
-        },
-        "/tags": {
-            "get": {
-                "description": "Returns list of all tags",
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "tags"
-                ],
-                "summary": "Get all tags",
-                "responses": {
-                    "200": {
-                        "description": "OK",
+                    },
+                    "403": {
+                        "description": "Upvote of another tutor",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.Tag"
-                            }
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Answer or upvote not found",
+                        "schema": {
+                            "type": "string"
                         }
                     }
                 }
-            },
+            }
+        },
+        "/ask": {
             "post": {
-                "description": "Create a new tag with tag name and optional tutor_id",
+                "description": "Finds the existing question most similar to the input (trigram cosine similarity) and returns its answer with a confidence score and alternatives. If the confidence is below ASK_CONFIDENCE_THRESHOLD, no answer is returned and the query is recorded in the unanswered demand list",
                 "consumes": [
                     "application/json"
                 ],
@@ -704,36 +1060,47 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "tags"
+                    "ask"
                 ],
-                "summary": "Create new tag",
+                "summary": "Ask a free-text question",
                 "parameters": [
                     {
-                        "description": "Tag data",
-                        "name": "tag",
+                        "description": "Free-text question (up to 1000 characters)",
+                        "name": "question",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.TagSwaggerRequestBody"
+                            "$ref": "#/definitions/models.AskRequestBody"
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Tag created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/models.AskResult"
                         }
                     },
                     "400": {
-                        "description": "Invalid request",
+                        "description": "Empty or too long question",
                         "schema": {
                             "type": "string"
                         }
                     },
-                    "500": {
-                        "description": "Internal server error",
+                    "413": {
+                        "description": "Request body too large",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "415": {
+                        "description": "Content-Type must be application/json",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "501": {
+                        "description": "pg_trgm extension is not installed",
                         "schema": {
                             "type": "string"
                         }
@@ -741,21 +1108,21 @@ const docTemplate = `{
                 }
             }
         },
-        "/tags/name/{name}": {
+        "/comments/{id}": {
             "get": {
-                "description": "Returns tag by tags name",
+                "description": "Returns the comment without its replies",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "tags"
+                    "comments"
                 ],
-                "summary": "Get tag by name",
+                "summary": "Get comment by ID",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Tag Name",
-                        "name": "name",
+                        "type": "integer",
+                        "description": "Comment ID",
+                        "name": "id",
                         "in": "path",
                         "required": true
                     }
@@ -764,58 +1131,92 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.Tag"
+                            "$ref": "#/definitions/models.Comment"
                         }
                     },
                     "400": {
-                        "description": "Invalid name",
+                        "description": "Invalid ID",
                         "schema": {
                             "type": "string"
                         }
                     },
                     "404": {
-                        "description": "Tag not found",
+                        "description": "Comment not found",
                         "schema": {
                             "type": "string"
                         }
                     }
                 }
-            }
-        },
-        "/tags/{id}": {
-            "get": {
-                "description": "Returns tag by specified ID",
+            },
+            "put": {
+                "description": "Changes the comment text and its mentions and marks the comment as edited. A tutor comment is edited by its tutor (tutor_id), a learner comment without tutor_id from the same client",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "tags"
+                    "comments"
                 ],
-                "summary": "Get tag by ID",
+                "summary": "Edit comment",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Tag ID",
+                        "description": "Comment ID",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "New text (up to 5000 characters) and author",
+                        "name": "comment",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CommentUpdateRequestBody"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.Tag"
+                            "$ref": "#/definitions/models.Comment"
                         }
                     },
                     "400": {
-                        "description": "Invalid ID",
+                        "description": "Invalid request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Not the author of the comment",
                         "schema": {
                             "type": "string"
                         }
                     },
                     "404": {
-                        "description": "Tag not found",
+                        "description": "Comment not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "Comment is deleted",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "413": {
+                        "description": "Request body too large",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "415": {
+                        "description": "Content-Type must be application/json",
                         "schema": {
                             "type": "string"
                         }
@@ -823,15 +1224,15 @@ const docTemplate = `{
                 }
             },
             "delete": {
-                "description": "Delete tag by ID (cascades from questions_tags)",
+                "description": "Soft-deletes a learner comment from the same client that wrote it (kb_client cookie or IP address and User-Agent)",
                 "tags": [
-                    "tags"
+                    "comments"
                 ],
-                "summary": "Delete tag by ID",
+                "summary": "Delete own comment",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Tag ID",
+                        "description": "Comment ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -839,7 +1240,7 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "204": {
-                        "description": "No Content"
+                        "description": "Comment deleted"
                     },
                     "400": {
                         "description": "Invalid ID",
@@ -847,8 +1248,20 @@ const docTemplate = `{
                             "type": "string"
                         }
                     },
+                    "403": {
+                        "description": "Not the author of the comment",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
                     "404": {
-                        "description": "Tag not found",
+                        "description": "Comment not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "Comment is already deleted",
                         "schema": {
                             "type": "string"
                         }
@@ -856,61 +1269,206 @@ const docTemplate = `{
                 }
             }
         },
-        "/tutors": {
-            "get": {
-                "description": "Returns list of all tutors",
-                "produces": [
-                    "application/json"
-                ],
+        "/comments/{id}/deleteBy/{tutor_id}": {
+            "delete": {
+                "description": "Soft-deletes the comment and records the tutor who deleted it. The comment stays in the thread with empty text so that its replies are kept",
                 "tags": [
-                    "tutors"
+                    "comments"
+                ],
+                "summary": "Delete comment by tutor",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Comment ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Tutor ID",
+                        "name": "tutor_id",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "Get all tutors",
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "204": {
+                        "description": "Comment deleted"
+                    },
+                    "400": {
+                        "description": "Invalid ID",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.Tutor"
-                            }
+                            "type": "string"
                         }
-                    }
-                }
-            },
-            "post": {
-                "description": "Create a new tutor with full name and email",
-                "consumes": [
+                    },
+                    "401": {
+                        "description": "Tutor or moderator token required",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Comment not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "Comment is already deleted",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/question-tags": {
+            "get": {
+                "description": "Returns paginated list of relations between questions and tags with sorting and filters",
+                "produces": [
                     "application/json"
                 ],
+                "tags": [
+                    "question-tags"
+                ],
+                "summary": "Get all question-tag relations",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Page size (1-500, default 50)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of records to skip",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Sort field (question_id, tag_id), prefix with - for descending",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Filter by question ID",
+                        "name": "question_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Filter by tag ID",
+                        "name": "tag_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.QuestionTag"
+                            }
+                        },
+                        "headers": {
+                            "Link": {
+                                "type": "string",
+                                "description": "Links to next and prev pages"
+                            },
+                            "X-Total-Count": {
+                                "type": "integer",
+                                "description": "Total number of records matching filters"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid pagination, sort or filter parameter",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/question-tags/by-tag/{tag_id}": {
+            "get": {
+                "description": "Returns list of all relations between questions and tags by tag ID",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "tutors"
+                    "question-tags"
                 ],
-                "summary": "Create new tutor",
+                "summary": "Get all question-tag relations by tag ID",
                 "parameters": [
                     {
-                        "description": "Tutor data",
-                        "name": "tutor",
-                        "in": "body",
-                        "required": true,
+                        "type": "integer",
+                        "description": "Tag ID",
+                        "name": "tag_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.TutorSwaggerRequestBody"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.QuestionTag"
+                            }
                         }
                     }
+                }
+            }
+        },
+        "/question-tags/export": {
+            "get": {
+                "description": "Streams all question-tag relations matching filters as a JSON array or NDJSON (Accept: application/x-ndjson) without pagination",
+                "produces": [
+                    "application/json",
+                    "application/x-ndjson"
+                ],
+                "tags": [
+                    "question-tags"
+                ],
+                "summary": "Export all question-tag relations",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Sort field (question_id, tag_id), prefix with - for descending",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Filter by question ID",
+                        "name": "question_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Filter by tag ID",
+                        "name": "tag_id",
+                        "in": "query"
+                    }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Tutor created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.QuestionTag"
+                            }
                         }
                     },
                     "400": {
-                        "description": "Invalid request",
+                        "description": "Invalid sort or filter parameter",
                         "schema": {
                             "type": "string"
                         }
@@ -924,208 +1482,3562 @@ const docTemplate = `{
                 }
             }
         },
-        "/tutors/{id}": {
-            "get": {
-                "description": "Returns tutor by specified ID",
+        "/question-tags/{question_id}/{tag_id}": {
+            "post": {
+                "description": "Associate a tag with a question using IDs from URL path",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "tutors"
+                    "question-tags"
                 ],
-                "summary": "Get tutor by ID",
+                "summary": "Add tag to question",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Tutor ID",
-                        "name": "id",
+                        "description": "Question ID",
+                        "name": "question_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Tag ID",
+                        "name": "tag_id",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/models.Tutor"
+                            "type": "object",
+                            "additionalProperties": true
                         }
                     },
                     "400": {
-                        "description": "Invalid ID",
+                        "description": "Bad request",
                         "schema": {
                             "type": "string"
                         }
                     },
-                    "404": {
-                        "description": "Tutor not found",
+                    "401": {
+                        "description": "Tutor or moderator token required",
                         "schema": {
                             "type": "string"
                         }
                     }
                 }
             },
-            "put": {
-                "description": "Update tutor with full name and email",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
+            "delete": {
+                "description": "Delete relation between question and tag by their IDs",
                 "tags": [
-                    "tutors"
+                    "question-tags"
                 ],
-                "summary": "Update tutor",
+                "summary": "Delete question-tag relation",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Tutor ID",
-                        "name": "id",
+                        "description": "Question ID",
+                        "name": "question_id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Tutor data",
-                        "name": "tutor",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.TutorSwaggerRequestBody"
-                        }
+                        "type": "integer",
+                        "description": "Tag ID",
+                        "name": "tag_id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "Tutor updated",
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Invalid question ID or tag ID",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "type": "string"
                         }
                     },
-                    "400": {
-                        "description": "Invalid request",
+                    "401": {
+                        "description": "Tutor or moderator token required",
                         "schema": {
                             "type": "string"
                         }
                     },
                     "404": {
-                        "description": "Internal server error",
+                        "description": "Relation not found",
                         "schema": {
                             "type": "string"
                         }
                     }
                 }
-            },
-            "delete": {
-                "description": "Delete tutor by ID",
+            }
+        },
+        "/question-versions/{id}": {
+            "get": {
+                "description": "Returns paginated versions of a specific question by question ID, supports cursor pagination. Learners see only published versions",
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
-                    "tutors"
+                    "question-versions"
                 ],
-                "summary": "Delete tutor by ID",
+                "summary": "Get question versions by question ID",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Tutor ID",
+                        "description": "Question ID",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size (1-500, default 50)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of records to skip",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Opaque cursor from Link header (cannot be combined with offset)",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Sort field (version_number, created_at, id), prefix with - for descending",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Created after (RFC 3339 or YYYY-MM-DD)",
+                        "name": "created_after",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Created before (RFC 3339 or YYYY-MM-DD)",
+                        "name": "created_before",
+                        "in": "query"
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "No Content"
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.QuestionVersion"
+                            }
+                        },
+                        "headers": {
+                            "Link": {
+                                "type": "string",
+                                "description": "Cursor links to next and prev pages"
+                            },
+                            "X-Total-Count": {
+                                "type": "integer",
+                                "description": "Total number of versions matching filters"
+                            }
+                        }
                     },
                     "400": {
-                        "description": "Invalid ID",
+                        "description": "Invalid question ID or pagination parameter",
                         "schema": {
                             "type": "string"
                         }
                     },
                     "404": {
-                        "description": "Tutor not found",
+                        "description": "Question not found or not published",
                         "schema": {
                             "type": "string"
                         }
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "models.Answer": {
+        },
+        "/questions": {
+            "get": {
+                "description": "Returns paginated list of questions with sorting and filters",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "questions"
+                ],
+                "summary": "Get all questions",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Page size (1-500, default 50)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of records to skip",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Opaque cursor from Link header, sort by id or created_at (cannot be combined with offset)",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Sort field (id, created_at, question_text, tutor_id), prefix with - for descending",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Filter by tutor ID",
+                        "name": "tutor_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Created after (RFC 3339 or YYYY-MM-DD)",
+                        "name": "created_after",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Created before (RFC 3339 or YYYY-MM-DD)",
+                        "name": "created_before",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Filter by edit flag",
+                        "name": "is_edit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by status: draft, in_review, published or archived. Learners only see published",
+                        "name": "status",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Question"
+                            }
+                        },
+                        "headers": {
+                            "Link": {
+                                "type": "string",
+                                "description": "Links to next and prev pages"
+                            },
+                            "X-Total-Count": {
+                                "type": "integer",
+                                "description": "Total number of records matching filters"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid pagination, sort or filter parameter",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Create a new question with text, tutor_id. Create a new question_version with question_id, question_text, tutor_id, version_number. The response contains tag suggestions for the new question. If existing questions are similar above the duplicate threshold, returns 409 with the list of duplicates unless force=true; with force=true the question is created and the duplicates are returned in possible_duplicates",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "questions"
+                ],
+                "summary": "Сreates a new question and records the version",
+                "parameters": [
+                    {
+                        "description": "Question data",
+                        "name": "question",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.QuestionsSwaggerRequestBody"
+                        }
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Create the question even if likely duplicates exist",
+                        "name": "force",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Attach suggested tags above the auto-apply threshold (default from TAG_SUGGEST_AUTO_APPLY)",
+                        "name": "auto_tag",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Question created, with suggested_tags and applied_tags",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Tutor or moderator token required",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "Likely duplicates found",
+                        "schema": {
+                            "$ref": "#/definitions/models.DuplicateQuestionsResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "Request body too large",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "415": {
+                        "description": "Content-Type must be application/json",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/questions/export": {
+            "get": {
+                "description": "Streams all questions matching filters as a JSON array or NDJSON (Accept: application/x-ndjson) without pagination",
+                "produces": [
+                    "application/json",
+                    "application/x-ndjson"
+                ],
+                "tags": [
+                    "questions"
+                ],
+                "summary": "Export all questions",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Sort field (id, created_at, question_text, tutor_id), prefix with - for descending",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Filter by tutor ID",
+                        "name": "tutor_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Created after (RFC 3339 or YYYY-MM-DD)",
+                        "name": "created_after",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Created before (RFC 3339 or YYYY-MM-DD)",
+                        "name": "created_before",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Filter by edit flag",
+                        "name": "is_edit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by status: draft, in_review, published or archived. Learners only see published",
+                        "name": "status",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Question"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid sort or filter parameter",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/questions/{id}": {
+            "get": {
+                "description": "Returns question by specified ID. With expand the answer (accepted or first), all answers, tags, author, versions and stats are embedded in a fixed number of queries",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "questions"
+                ],
+                "summary": "Get question by ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Question ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated expansions: answer, answers, tags, author, versions, stats",
+                        "name": "expand",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.QuestionDetail"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID or unknown expansion",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Question not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Update question with text, tutor_id and edit flag.Create a new question_version with question_id, question_text, tutor_id, version_number. A question that is not a draft goes back to in_review until a moderator publishes it",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "questions"
+                ],
+                "summary": "Update question and records the version",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Question ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Question data",
+                        "name": "question",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.QuestionsSwaggerRequestBody"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Question updated",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Tutor or moderator token required",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Question not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "413": {
+                        "description": "Request body too large",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "415": {
+                        "description": "Content-Type must be application/json",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/questions/{id}/accepted-answer": {
+            "put": {
+                "description": "Marks the answer as the accepted answer of the question. The previously accepted answer is no longer accepted",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "answers"
+                ],
+                "summary": "Set accepted answer of question",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Question ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Answer of this question",
+                        "name": "answer",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.AcceptedAnswerRequestBody"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Answer"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Tutor or moderator token required",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Question or its answer not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "413": {
+                        "description": "Request body too large",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "415": {
+                        "description": "Content-Type must be application/json",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "The accepted answer of the question is no longer accepted. The answer itself is kept",
+                "tags": [
+                    "answers"
+                ],
+                "summary": "Remove accepted answer of question",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Question ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Accepted answer removed"
+                    },
+                    "400": {
+                        "description": "Invalid question ID",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Tutor or moderator token required",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Question has no accepted answer",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/questions/{id}/answer": {
+            "get": {
+                "description": "Returns the accepted answer to the question with specified ID, or its first answer if none is accepted",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "answers"
+                ],
+                "summary": "Get answer of question",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Question ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Answer"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid question ID",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Answer not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/questions/{id}/answers": {
+            "get": {
+                "description": "Returns all answers to the question with specified ID: the accepted answer first, the rest in their order. With sort=score the best rated answers come first",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "answers"
+                ],
+                "summary": "Get all answers of question",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Question ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "position (default) or score",
+                        "name": "sort",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Answer"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid question ID or sort",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Question not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/questions/{id}/answers/order": {
+            "put": {
+                "description": "Sets the order of the question answers. answer_ids must list every answer of the question exactly once. The accepted answer is still listed first",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "answers"
+                ],
+                "summary": "Reorder answers of question",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Question ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "All answer IDs of the question in the new order",
+                        "name": "order",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.AnswerOrderRequestBody"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Answer"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request or incomplete answer list",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Tutor or moderator token required",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Question not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "413": {
+                        "description": "Request body too large",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "415": {
+                        "description": "Content-Type must be application/json",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/questions/{id}/comments": {
+            "get": {
+                "description": "Returns the comment threads of the question: top-level comments in creation order with nested replies. Deleted comments stay in the thread with empty text",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "comments"
+                ],
+                "summary": "Get comments of question",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Question ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Comment"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid question ID",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Question not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Adds a comment or a reply (parent_id) to the question. With tutor_id the author is the tutor, otherwise a learner identified by an anonymized client ID from a signed kb_client cookie issued by the server (or IP address and User-Agent). Tutors mentioned as @email or @Full Name (or @Full_Name) are recorded in mentions",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "comments"
+                ],
+                "summary": "Comment on question",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Question ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Comment text (up to 5000 characters), author and parent comment",
+                        "name": "comment",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CommentRequestBody"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.Comment"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request, unknown tutor or parent comment",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Question not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "Parent comment is deleted",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "413": {
+                        "description": "Request body too large",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "415": {
+                        "description": "Content-Type must be application/json",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/questions/{id}/deleteBy/{tutor_id}": {
+            "delete": {
+                "description": "Delete question by ID and mark versions as deleted",
+                "tags": [
+                    "questions"
+                ],
+                "summary": "Delete question",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Question ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Tutor ID who deleted the question",
+                        "name": "tutor_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Invalid ID",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Tutor or moderator token required",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Question not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/questions/{id}/similar": {
+            "get": {
+                "description": "Returns questions similar to the given one by trigram cosine similarity, most similar first",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "questions"
+                ],
+                "summary": "Get similar questions",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Question ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum number of questions (1-50, default from SIMILAR_LIMIT)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.SimilarQuestion"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID or limit",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Question not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "501": {
+                        "description": "pg_trgm extension is not installed",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/questions/{id}/status": {
+            "put": {
+                "description": "Moves the question through the editorial workflow: draft -\u003e in_review (tutor), in_review -\u003e published or back to draft with a comment (moderator), published \u003c-\u003e archived (moderator), archived -\u003e draft (tutor). The change is recorded as a new question version",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "questions"
+                ],
+                "summary": "Change question status",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Question ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Bearer token of tutor (TUTOR_TOKEN) or moderator (MODERATOR_TOKEN)",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "New status, tutor and review comment",
+                        "name": "status",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.StatusChangeRequestBody"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Question"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID, status, tutor or missing comment",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Tutor or moderator token required",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Role is not allowed to make this transition",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Question not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "Transition is not allowed from the current status",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "413": {
+                        "description": "Request body too large",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "415": {
+                        "description": "Content-Type must be application/json",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/questions/{id}/suggested-tags": {
+            "get": {
+                "description": "Suggests tags the question does not have yet: tag names and synonyms found in the question and answer text, and tags that often co-occur with its tags. Each suggestion has a confidence from 0 to 1",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "questions"
+                ],
+                "summary": "Get suggested tags",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Question ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.TagSuggestion"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Question not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/questions/{id}/tags": {
+            "get": {
+                "description": "Returns all tags attached to the question with specified ID",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "question-tags"
+                ],
+                "summary": "Get tags of question",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Question ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Tag"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid question ID",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Question not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Atomically replaces the whole tag set of the question. An empty tag_ids list removes all tags",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "question-tags"
+                ],
+                "summary": "Replace tags of question",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Question ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "New tag set",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.QuestionTagsRequestBody"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Tag"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid question ID, JSON or unknown tag",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Tutor or moderator token required",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Question not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "413": {
+                        "description": "Request body too large",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "415": {
+                        "description": "Content-Type must be application/json",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/search": {
+            "get": {
+                "description": "Searches question and answer texts (Russian and English word forms), ranked by relevance with highlighted snippets. Words typed in Latin transliteration or in the wrong keyboard layout also match Russian text: \"kak nastroit docker\" finds \"Как настроить Docker\"",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "search 🔍"
+                ],
+                "summary": "Full-text search",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Search query: words, quoted phrase, -excluded, or",
+                        "name": "q",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Question must have all these tags (any layout or alphabet, synonyms)",
+                        "name": "tag",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Only questions of this tutor",
+                        "name": "tutor_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size (1-500, default 50)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of records to skip",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.SearchResult"
+                            }
+                        },
+                        "headers": {
+                            "Link": {
+                                "type": "string",
+                                "description": "Links to next and prev pages"
+                            },
+                            "X-Total-Count": {
+                                "type": "integer",
+                                "description": "Total number of found questions"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Empty query or invalid parameter",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/search/clicks": {
+            "post": {
+                "description": "Records that the client opened a search result. search_id is the X-Search-ID header of the search response",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "search analytics"
+                ],
+                "summary": "Report a search result click",
+                "parameters": [
+                    {
+                        "description": "Search ID, opened question and its position",
+                        "name": "click",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.SearchClickRequestBody"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Click recorded"
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "413": {
+                        "description": "Request body too large",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "415": {
+                        "description": "Content-Type must be application/json",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/search/engine": {
+            "get": {
+                "description": "Finds questions whose text or answers contain the query words using the search backend selected by SEARCH_BACKEND: postgres (full-text search) or index (embedded inverted index with BM25). Supports words, quoted phrases and -excluded words; synonyms are expanded, transliterated and wrong-layout words also match Russian text",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "search 🔍"
+                ],
+                "summary": "Search with the configured engine",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Search query: words, quoted phrase, -excluded",
+                        "name": "q",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size (1-500, default 50)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of records to skip",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.EngineSearchResult"
+                        },
+                        "headers": {
+                            "Link": {
+                                "type": "string",
+                                "description": "Links to next and prev pages"
+                            },
+                            "X-Search-Backend": {
+                                "type": "string",
+                                "description": "Search backend that served the request"
+                            },
+                            "X-Total-Count": {
+                                "type": "integer",
+                                "description": "Total number of found questions"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Empty query or invalid parameter",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/search/fuzzy": {
+            "get": {
+                "description": "Finds questions whose tags or question text are similar to the query (pg_trgm), so \"postgre\" finds \"postgresql\". When nothing is found, did_you_mean contains similar tag names",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "search 🔍"
+                ],
+                "summary": "Typo-tolerant fuzzy search",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Search query",
+                        "name": "q",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size (1-500, default 50)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of records to skip",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.FuzzySearchResult"
+                        },
+                        "headers": {
+                            "Link": {
+                                "type": "string",
+                                "description": "Links to next and prev pages"
+                            },
+                            "X-Total-Count": {
+                                "type": "integer",
+                                "description": "Total number of found questions"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Empty query or invalid parameter",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "501": {
+                        "description": "pg_trgm extension is not installed",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/search/query": {
+            "get": {
+                "description": "Searches questions with one query string: words, \"exact phrase\", -excluded, tag:go, author:ivanov, created:\u003e2025-01-01 (also \u003e=, \u003c, \u003c=, exact date or 2025-01-01..2025-02-01), is:answered, is:unanswered, is:edited. Any term can be negated with minus",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "search 🔍"
+                ],
+                "summary": "Search with query language",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Query, e.g. tag:go author:ivanov -docker created:\u003e2025-01-01 is:unanswered",
+                        "name": "q",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size (1-500, default 50)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of records to skip",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.SearchResult"
+                            }
+                        },
+                        "headers": {
+                            "Link": {
+                                "type": "string",
+                                "description": "Links to next and prev pages"
+                            },
+                            "X-Total-Count": {
+                                "type": "integer",
+                                "description": "Total number of found questions"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Query parse error with position",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/search/tags": {
+            "get": {
+                "description": "Finds questions by tag lists (all, any, none) or by expression like ` + "`" + `go AND (postgres OR mysql) AND NOT orm` + "`" + `. Tags match in any keyboard layout or alphabet and by synonyms. Returns tag facet counts for the whole result set",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "search 🔍"
+                ],
+                "summary": "Boolean search by tags",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Comma-separated tags, question must have all of them",
+                        "name": "all",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated tags, question must have at least one of them",
+                        "name": "any",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated tags, question must have none of them",
+                        "name": "none",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Tag expression with AND, OR, NOT and parentheses (cannot be combined with lists)",
+                        "name": "expr",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size (1-500, default 50)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of records to skip",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.TagSearchResult"
+                        },
+                        "headers": {
+                            "Link": {
+                                "type": "string",
+                                "description": "Links to next and prev pages"
+                            },
+                            "X-Total-Count": {
+                                "type": "integer",
+                                "description": "Total number of found questions"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid tag lists or expression",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/simple-search/{name}": {
+            "get": {
+                "description": "Search questions by tag name in any alphabet or keyboard layout: \"докер\", \"docker\" and \"вщслук\" find tag docker. When no question has the tag (e.g. a typo like \"dokcer\"), X-Did-You-Mean lists similar tag names and the response contains questions with the first of them",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "search 🔍"
+                ],
+                "summary": "Search questions by tag name",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Tag name to search for",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Question"
+                            }
+                        },
+                        "headers": {
+                            "X-Did-You-Mean": {
+                                "type": "string",
+                                "description": "Comma-separated URL-encoded similar tag names, only when nothing matched the name"
+                            },
+                            "X-Total-Count": {
+                                "type": "integer",
+                                "description": "Number of found questions"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Tag name parameter is required",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/synonyms": {
+            "get": {
+                "description": "Returns all groups of equivalent terms used in search and tag lookup",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "synonyms"
+                ],
+                "summary": "Get all synonym groups",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.SynonymGroup"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Creates a group of equivalent terms, e.g. [\"kubernetes\", \"k8s\"]. Changes apply to search immediately",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "synonyms"
+                ],
+                "summary": "Create synonym group",
+                "parameters": [
+                    {
+                        "description": "Terms (2-20, up to 50 characters each)",
+                        "name": "group",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.SynonymGroupRequestBody"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.SynonymGroup"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid terms",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Tutor or moderator token required",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "Term already belongs to another group",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "413": {
+                        "description": "Request body too large",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "415": {
+                        "description": "Content-Type must be application/json",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/synonyms/{id}": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "synonyms"
+                ],
+                "summary": "Get synonym group by ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Synonym group ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SynonymGroup"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Synonym group not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Replaces all terms of the group. Changes apply to search immediately",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "synonyms"
+                ],
+                "summary": "Replace synonym group terms",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Synonym group ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Terms (2-20, up to 50 characters each)",
+                        "name": "group",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.SynonymGroupRequestBody"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SynonymGroup"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID or terms",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Tutor or moderator token required",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Synonym group not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "Term already belongs to another group",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "413": {
+                        "description": "Request body too large",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "415": {
+                        "description": "Content-Type must be application/json",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "tags": [
+                    "synonyms"
+                ],
+                "summary": "Delete synonym group",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Synonym group ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Invalid ID",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Tutor or moderator token required",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Synonym group not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/tags": {
+            "get": {
+                "description": "Returns paginated list of tags with sorting and filters",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tags"
+                ],
+                "summary": "Get all tags",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Page size (1-500, default 50)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of records to skip",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Sort field (id, tag, tutor_id), prefix with - for descending",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Filter by tutor ID",
+                        "name": "tutor_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Tag"
+                            }
+                        },
+                        "headers": {
+                            "Link": {
+                                "type": "string",
+                                "description": "Links to next and prev pages"
+                            },
+                            "X-Total-Count": {
+                                "type": "integer",
+                                "description": "Total number of records matching filters"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid pagination, sort or filter parameter",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Create a new tag with tag name and optional tutor_id",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tags"
+                ],
+                "summary": "Create new tag",
+                "parameters": [
+                    {
+                        "description": "Tag data",
+                        "name": "tag",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.TagSwaggerRequestBody"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Tag created",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Tutor or moderator token required",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "413": {
+                        "description": "Request body too large",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "415": {
+                        "description": "Content-Type must be application/json",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/tags/export": {
+            "get": {
+                "description": "Streams all tags matching filters as a JSON array or NDJSON (Accept: application/x-ndjson) without pagination",
+                "produces": [
+                    "application/json",
+                    "application/x-ndjson"
+                ],
+                "tags": [
+                    "tags"
+                ],
+                "summary": "Export all tags",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Sort field (id, tag, tutor_id), prefix with - for descending",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Filter by tutor ID",
+                        "name": "tutor_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Tag"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid sort or filter parameter",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/tags/name/{name}": {
+            "get": {
+                "description": "Returns tag by tags name. Name is matched in any alphabet or keyboard layout: \"голанг\" and \"ujkfyu\" find tag golang",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tags"
+                ],
+                "summary": "Get tag by name",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Tag Name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Tag"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid name",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Tag not found, with similar tag names if any",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/tags/{id}": {
+            "get": {
+                "description": "Returns tag by specified ID",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tags"
+                ],
+                "summary": "Get tag by ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Tag ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Tag"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Tag not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Delete tag by ID (cascades from questions_tags)",
+                "tags": [
+                    "tags"
+                ],
+                "summary": "Delete tag by ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Tag ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Invalid ID",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Tutor or moderator token required",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Tag not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/tags/{id}/questions": {
+            "get": {
+                "description": "Returns paginated list of questions the tag with specified ID is attached to",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "questions"
+                ],
+                "summary": "Get questions with tag",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Tag ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size (1-500, default 50)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of records to skip",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Opaque cursor from Link header, sort by id or created_at (cannot be combined with offset)",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Sort field (id, created_at, question_text, tutor_id), prefix with - for descending",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Filter by tutor ID",
+                        "name": "tutor_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Created after (RFC 3339 or YYYY-MM-DD)",
+                        "name": "created_after",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Created before (RFC 3339 or YYYY-MM-DD)",
+                        "name": "created_before",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Filter by edit flag",
+                        "name": "is_edit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by status: draft, in_review, published or archived. Learners only see published",
+                        "name": "status",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Question"
+                            }
+                        },
+                        "headers": {
+                            "Link": {
+                                "type": "string",
+                                "description": "Links to next and prev pages"
+                            },
+                            "X-Total-Count": {
+                                "type": "integer",
+                                "description": "Total number of records matching filters"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid tag ID, pagination, sort or filter parameter",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/tutors": {
+            "get": {
+                "description": "Returns paginated list of tutors with sorting",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tutors"
+                ],
+                "summary": "Get all tutors",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Page size (1-500, default 50)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of records to skip",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Sort field (id, full_name, email), prefix with - for descending",
+                        "name": "sort",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Tutor"
+                            }
+                        },
+                        "headers": {
+                            "Link": {
+                                "type": "string",
+                                "description": "Links to next and prev pages"
+                            },
+                            "X-Total-Count": {
+                                "type": "integer",
+                                "description": "Total number of records matching filters"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid pagination, sort or filter parameter",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Create a new tutor with full name and email",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tutors"
+                ],
+                "summary": "Create new tutor",
+                "parameters": [
+                    {
+                        "description": "Tutor data",
+                        "name": "tutor",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.TutorSwaggerRequestBody"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Tutor created",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Tutor or moderator token required",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "413": {
+                        "description": "Request body too large",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "415": {
+                        "description": "Content-Type must be application/json",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/tutors/export": {
+            "get": {
+                "description": "Streams all tutors matching filters as a JSON array or NDJSON (Accept: application/x-ndjson) without pagination",
+                "produces": [
+                    "application/json",
+                    "application/x-ndjson"
+                ],
+                "tags": [
+                    "tutors"
+                ],
+                "summary": "Export all tutors",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Sort field (id, full_name, email), prefix with - for descending",
+                        "name": "sort",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Tutor"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid sort or filter parameter",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/tutors/{id}": {
+            "get": {
+                "description": "Returns tutor by specified ID",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tutors"
+                ],
+                "summary": "Get tutor by ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Tutor ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Tutor"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Tutor not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Update tutor with full name and email",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tutors"
+                ],
+                "summary": "Update tutor",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Tutor ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Tutor data",
+                        "name": "tutor",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.TutorSwaggerRequestBody"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Tutor updated",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Tutor or moderator token required",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "413": {
+                        "description": "Request body too large",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "415": {
+                        "description": "Content-Type must be application/json",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Delete tutor by ID",
+                "tags": [
+                    "tutors"
+                ],
+                "summary": "Delete tutor by ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Tutor ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Invalid ID",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Tutor or moderator token required",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Tutor not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/tutors/{id}/answers": {
+            "get": {
+                "description": "Returns paginated list of answers written by the tutor with specified ID",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "answers"
+                ],
+                "summary": "Get answers of tutor",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Tutor ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size (1-500, default 50)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of records to skip",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Opaque cursor from Link header, sort by id, created_at or score (cannot be combined with offset)",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Sort field (id, created_at, question_id, tutor_id, score), prefix with - for descending",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Filter by question ID",
+                        "name": "question_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Created after (RFC 3339 or YYYY-MM-DD)",
+                        "name": "created_after",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Created before (RFC 3339 or YYYY-MM-DD)",
+                        "name": "created_before",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Filter by edit flag",
+                        "name": "is_edit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Filter by accepted flag",
+                        "name": "is_accepted",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by status: draft, in_review, published or archived. Learners only see published",
+                        "name": "status",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Answer"
+                            }
+                        },
+                        "headers": {
+                            "Link": {
+                                "type": "string",
+                                "description": "Links to next and prev pages"
+                            },
+                            "X-Total-Count": {
+                                "type": "integer",
+                                "description": "Total number of records matching filters"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid tutor ID, pagination, sort or filter parameter",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/tutors/{id}/mentions": {
+            "get": {
+                "description": "Returns comments where the tutor is mentioned, newest first. Deleted comments are not listed. X-Total-Count and Link headers describe the pages",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "comments"
+                ],
+                "summary": "Get comments mentioning tutor",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Tutor ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size (1-500, default 50)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of records to skip",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Comment"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID or page",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/tutors/{id}/questions": {
+            "get": {
+                "description": "Returns paginated list of questions written by the tutor with specified ID",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "questions"
+                ],
+                "summary": "Get questions of tutor",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Tutor ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size (1-500, default 50)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of records to skip",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Opaque cursor from Link header, sort by id or created_at (cannot be combined with offset)",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Sort field (id, created_at, question_text, tutor_id), prefix with - for descending",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Created after (RFC 3339 or YYYY-MM-DD)",
+                        "name": "created_after",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Created before (RFC 3339 or YYYY-MM-DD)",
+                        "name": "created_before",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Filter by edit flag",
+                        "name": "is_edit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by status: draft, in_review, published or archived. Learners only see published",
+                        "name": "status",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Question"
+                            }
+                        },
+                        "headers": {
+                            "Link": {
+                                "type": "string",
+                                "description": "Links to next and prev pages"
+                            },
+                            "X-Total-Count": {
+                                "type": "integer",
+                                "description": "Total number of records matching filters"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid tutor ID, pagination, sort or filter parameter",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/unanswered": {
+            "get": {
+                "description": "Returns free-text questions that got no confident answer, most frequently asked first",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ask"
+                ],
+                "summary": "Get unanswered demand",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "open (default), resolved or dismissed",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size (1-500, default 50)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of records to skip",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.UnansweredDemand"
+                            }
+                        },
+                        "headers": {
+                            "Link": {
+                                "type": "string",
+                                "description": "Links to next and prev pages"
+                            },
+                            "X-Total-Count": {
+                                "type": "integer",
+                                "description": "Total number of records"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid status or parameter",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Tutor or moderator token required",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/unanswered/{id}/dismiss": {
+            "post": {
+                "description": "Marks an open unanswered query as dismissed",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ask"
+                ],
+                "summary": "Dismiss unanswered demand",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Unanswered demand ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.UnansweredDemand"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Tutor or moderator token required",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Unanswered demand not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "Already resolved or dismissed",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/unanswered/{id}/question": {
+            "post": {
+                "description": "Creates a new question (with its first version) from an open unanswered query and marks the query as resolved. The question text defaults to the query text",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ask"
+                ],
+                "summary": "Create a question from unanswered demand",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Unanswered demand ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Question text and author",
+                        "name": "question",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.DemandQuestionRequestBody"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.UnansweredDemand"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID or request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Tutor or moderator token required",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Unanswered demand not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "Already resolved or dismissed, or question with this text exists",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "models.AcceptedAnswerRequestBody": {
+            "type": "object",
+            "properties": {
+                "answer_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.Answer": {
+            "type": "object",
+            "properties": {
+                "answer_text": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "helpful_count": {
+                    "description": "Оценки учащихся \"полезен / не полезен\" и голоса тьюторов.",
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "is_accepted": {
+                    "description": "Принятый ответ вопроса. У вопроса не больше одного принятого ответа.",
+                    "type": "boolean"
+                },
+                "is_edit": {
+                    "type": "boolean"
+                },
+                "not_helpful_count": {
+                    "type": "integer"
+                },
+                "position": {
+                    "description": "Порядок ответа среди ответов вопроса (принятый ответ всегда первый).",
+                    "type": "integer"
+                },
+                "question_id": {
+                    "type": "integer"
+                },
+                "score": {
+                    "description": "Рейтинг ответа: голоса тьюторов + полезен - не полезен.",
+                    "type": "integer"
+                },
+                "status": {
+                    "description": "Статус: draft, in_review, published или archived. Публично видны только опубликованные ответы опубликованных вопросов.",
+                    "type": "string"
+                },
+                "tutor_id": {
+                    "type": "integer"
+                },
+                "upvotes": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.AnswerFeedbackRequestBody": {
+            "type": "object",
+            "properties": {
+                "comment": {
+                    "description": "Необязательный комментарий, до 1000 символов.",
+                    "type": "string"
+                },
+                "helpful": {
+                    "description": "Был ли ответ полезен. Обязательное поле.",
+                    "type": "boolean"
+                }
+            }
+        },
+        "models.AnswerFeedbackStat": {
+            "type": "object",
+            "properties": {
+                "answer": {
+                    "$ref": "#/definitions/models.Answer"
+                },
+                "feedback": {
+                    "description": "Количество оценок учащихся.",
+                    "type": "integer"
+                },
+                "helpful_ratio": {
+                    "description": "Доля оценок \"полезен\" от 0 до 1.",
+                    "type": "number"
+                },
+                "recent_comments": {
+                    "description": "Последние непустые комментарии, новые сначала.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "models.AnswerOrderRequestBody": {
+            "type": "object",
+            "properties": {
+                "answer_ids": {
+                    "description": "ID всех ответов вопроса в нужном порядке.",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                }
+            }
+        },
+        "models.AnswerVersion": {
+            "type": "object",
+            "properties": {
+                "answer_id": {
+                    "type": "integer"
+                },
+                "answer_text": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "delete_by_tutor": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "is_delete": {
+                    "type": "boolean"
+                },
+                "question_id": {
+                    "type": "integer"
+                },
+                "status": {
+                    "description": "Статус ответа в этой версии и комментарий к смене статуса (например, причина отклонения).",
+                    "type": "string"
+                },
+                "status_comment": {
+                    "type": "string"
+                },
+                "tutor_id": {
+                    "type": "integer"
+                },
+                "version_number": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.AnswersSwaggerRequestBody": {
+            "type": "object",
+            "properties": {
+                "answer_text": {
+                    "type": "string"
+                },
+                "question_id": {
+                    "type": "integer"
+                },
+                "tutor_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.AskMatch": {
+            "type": "object",
+            "properties": {
+                "answer": {
+                    "$ref": "#/definitions/models.Answer"
+                },
+                "confidence": {
+                    "type": "number"
+                },
+                "question": {
+                    "$ref": "#/definitions/models.Question"
+                }
+            }
+        },
+        "models.AskRequestBody": {
+            "type": "object",
+            "properties": {
+                "question": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.AskResult": {
+            "type": "object",
+            "properties": {
+                "alternatives": {
+                    "description": "Другие подходящие вопросы, самые похожие сначала.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.AskMatch"
+                    }
+                },
+                "answered": {
+                    "description": "Найден ли вопрос с уверенностью не ниже порога.",
+                    "type": "boolean"
+                },
+                "best": {
+                    "description": "Лучший вопрос и ответ на него, если answered.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.AskMatch"
+                        }
+                    ]
+                },
+                "confidence": {
+                    "description": "Уверенность лучшего совпадения от 0 до 1.",
+                    "type": "number"
+                },
+                "demand_id": {
+                    "description": "ID записи в списке вопросов без ответа, если answered = false.",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.Comment": {
+            "type": "object",
+            "properties": {
+                "answer_id": {
+                    "type": "integer"
+                },
+                "author_name": {
+                    "type": "string"
+                },
+                "author_type": {
+                    "description": "Автор: tutor или learner. Для тьютора заполнен tutor_id, author_name - имя тьютора или подпись учащегося.",
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "delete_by_tutor": {
+                    "type": "integer"
+                },
+                "deleted_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "is_deleted": {
+                    "description": "Мягкое удаление: комментарий остается в ветке, чтобы не потерять ответы на него.",
+                    "type": "boolean"
+                },
+                "is_edit": {
+                    "type": "boolean"
+                },
+                "mentions": {
+                    "description": "Тьюторы, упомянутые через @имя или @email.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Tutor"
+                    }
+                },
+                "parent_id": {
+                    "description": "Комментарий, на который это ответ. nil - начало ветки.",
+                    "type": "integer"
+                },
+                "question_id": {
+                    "description": "Вопрос или ответ, к которому относится комментарий (заполнено одно из полей).",
+                    "type": "integer"
+                },
+                "replies": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Comment"
+                    }
+                },
+                "text": {
+                    "description": "Текст. У удаленного комментария пустой.",
+                    "type": "string"
+                },
+                "tutor_id": {
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.CommentRequestBody": {
             "type": "object",
             "properties": {
-                "answer_text": {
-                    "type": "string"
-                },
-                "created_at": {
+                "learner_name": {
+                    "description": "Подпись учащегося, по умолчанию \"Учащийся\".",
                     "type": "string"
                 },
-                "id": {
+                "parent_id": {
+                    "description": "Комментарий, на который отвечают.",
                     "type": "integer"
                 },
-                "is_edit": {
-                    "type": "boolean"
+                "text": {
+                    "type": "string"
                 },
-                "question_id": {
+                "tutor_id": {
+                    "description": "Автор-тьютор. Без tutor_id автор - учащийся.",
                     "type": "integer"
+                }
+            }
+        },
+        "models.CommentUpdateRequestBody": {
+            "type": "object",
+            "properties": {
+                "text": {
+                    "type": "string"
                 },
                 "tutor_id": {
+                    "description": "Автор-тьютор. Комментарий учащегося изменяется без tutor_id с того же клиента.",
                     "type": "integer"
                 }
             }
         },
-        "models.AnswerVersion": {
+        "models.DemandQuestionRequestBody": {
             "type": "object",
             "properties": {
-                "answer_id": {
+                "question_text": {
+                    "type": "string"
+                },
+                "tutor_id": {
                     "type": "integer"
+                }
+            }
+        },
+        "models.DuplicateQuestionsResponse": {
+            "type": "object",
+            "properties": {
+                "duplicates": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.SimilarQuestion"
+                    }
                 },
-                "answer_text": {
+                "message": {
                     "type": "string"
+                }
+            }
+        },
+        "models.EngineMatch": {
+            "type": "object",
+            "properties": {
+                "question": {
+                    "$ref": "#/definitions/models.Question"
                 },
-                "created_at": {
+                "score": {
+                    "description": "Релевантность: ts_rank_cd для postgres, BM25 для index. Больше - лучше.",
+                    "type": "number"
+                }
+            }
+        },
+        "models.EngineSearchResult": {
+            "type": "object",
+            "properties": {
+                "backend": {
                     "type": "string"
                 },
-                "delete_by_tutor": {
-                    "type": "integer"
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.EngineMatch"
+                    }
                 },
-                "id": {
+                "total": {
                     "type": "integer"
+                }
+            }
+        },
+        "models.FuzzyMatch": {
+            "type": "object",
+            "properties": {
+                "matched_tag": {
+                    "description": "Самый похожий тег вопроса, если вопрос найден по тегу.",
+                    "type": "string"
                 },
-                "is_delete": {
-                    "type": "boolean"
+                "question": {
+                    "$ref": "#/definitions/models.Question"
                 },
-                "question_id": {
-                    "type": "integer"
+                "score": {
+                    "description": "Сходство с запросом от 0 до 1 (pg_trgm), больше - лучше.",
+                    "type": "number"
+                }
+            }
+        },
+        "models.FuzzySearchResult": {
+            "type": "object",
+            "properties": {
+                "did_you_mean": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
                 },
-                "tutor_id": {
-                    "type": "integer"
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.FuzzyMatch"
+                    }
                 },
-                "version_number": {
+                "total": {
                     "type": "integer"
                 }
             }
         },
-        "models.AnswersSwaggerRequestBody": {
+        "models.Question": {
             "type": "object",
             "properties": {
-                "answer_text": {
+                "created_at": {
                     "type": "string"
                 },
-                "question_id": {
+                "id": {
                     "type": "integer"
                 },
+                "is_edit": {
+                    "type": "boolean"
+                },
+                "question_text": {
+                    "type": "string"
+                },
+                "status": {
+                    "description": "Статус: draft, in_review, published или archived. Публично видны только опубликованные вопросы.",
+                    "type": "string"
+                },
                 "tutor_id": {
                     "type": "integer"
                 }
             }
         },
-        "models.Question": {
+        "models.QuestionDetail": {
             "type": "object",
             "properties": {
+                "answer": {
+                    "$ref": "#/definitions/models.Answer"
+                },
+                "answers": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Answer"
+                    }
+                },
+                "author": {
+                    "$ref": "#/definitions/models.Tutor"
+                },
                 "created_at": {
                     "type": "string"
                 },
@@ -1138,14 +5050,55 @@ const docTemplate = `{
                 "question_text": {
                     "type": "string"
                 },
+                "stats": {
+                    "$ref": "#/definitions/models.QuestionStats"
+                },
+                "status": {
+                    "description": "Статус: draft, in_review, published или archived. Публично видны только опубликованные вопросы.",
+                    "type": "string"
+                },
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Tag"
+                    }
+                },
                 "tutor_id": {
                     "type": "integer"
+                },
+                "versions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.QuestionVersion"
+                    }
+                }
+            }
+        },
+        "models.QuestionStats": {
+            "type": "object",
+            "properties": {
+                "answer_version_count": {
+                    "type": "integer"
+                },
+                "tag_count": {
+                    "type": "integer"
+                },
+                "version_count": {
+                    "type": "integer"
                 }
             }
         },
         "models.QuestionTag": {
             "type": "object",
             "properties": {
+                "added_by": {
+                    "description": "Тьютор, добавивший тег.",
+                    "type": "integer"
+                },
+                "is_auto": {
+                    "description": "Тег добавлен автоматически по подсказке.",
+                    "type": "boolean"
+                },
                 "question_id": {
                     "type": "integer"
                 },
@@ -1154,6 +5107,17 @@ const docTemplate = `{
                 }
             }
         },
+        "models.QuestionTagsRequestBody": {
+            "type": "object",
+            "properties": {
+                "tag_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                }
+            }
+        },
         "models.QuestionVersion": {
             "type": "object",
             "properties": {
@@ -1175,6 +5139,13 @@ const docTemplate = `{
                 "question_text": {
                     "type": "string"
                 },
+                "status": {
+                    "description": "Статус вопроса в этой версии и комментарий к смене статуса (например, причина отклонения).",
+                    "type": "string"
+                },
+                "status_comment": {
+                    "type": "string"
+                },
                 "tutor_id": {
                     "type": "integer"
                 },
@@ -1194,6 +5165,139 @@ const docTemplate = `{
                 }
             }
         },
+        "models.SearchClickRequestBody": {
+            "type": "object",
+            "properties": {
+                "position": {
+                    "description": "Место результата в выдаче, начиная с 1.",
+                    "type": "integer"
+                },
+                "question_id": {
+                    "type": "integer"
+                },
+                "search_id": {
+                    "description": "ID поиска из заголовка X-Search-ID ответа.",
+                    "type": "string"
+                }
+            }
+        },
+        "models.SearchQueryStat": {
+            "type": "object",
+            "properties": {
+                "avg_click_position": {
+                    "description": "Среднее место первого открытого результата.",
+                    "type": "number"
+                },
+                "avg_latency_ms": {
+                    "type": "number"
+                },
+                "avg_results": {
+                    "type": "number"
+                },
+                "click_through_rate": {
+                    "type": "number"
+                },
+                "clicks": {
+                    "description": "Сколько поисков закончились переходом к результату и их доля.",
+                    "type": "integer"
+                },
+                "clients": {
+                    "type": "integer"
+                },
+                "last_searched_at": {
+                    "type": "string"
+                },
+                "query": {
+                    "description": "Запрос в нижнем регистре без лишних пробелов.",
+                    "type": "string"
+                },
+                "searches": {
+                    "description": "Сколько раз искали и сколько разных клиентов.",
+                    "type": "integer"
+                },
+                "zero_results": {
+                    "description": "Сколько поисков ничего не нашли.",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.SearchResult": {
+            "type": "object",
+            "properties": {
+                "answer_highlight": {
+                    "type": "string"
+                },
+                "answer_id": {
+                    "description": "ID ответа, если совпадение найдено в ответе.",
+                    "type": "integer"
+                },
+                "question": {
+                    "$ref": "#/definitions/models.Question"
+                },
+                "question_highlight": {
+                    "description": "Фрагменты текста, найденные слова обрамлены тегами \u003cmark\u003e\u003c/mark\u003e.",
+                    "type": "string"
+                },
+                "rank": {
+                    "description": "Релевантность (ts_rank_cd), больше - лучше.",
+                    "type": "number"
+                }
+            }
+        },
+        "models.SimilarQuestion": {
+            "type": "object",
+            "properties": {
+                "question": {
+                    "$ref": "#/definitions/models.Question"
+                },
+                "score": {
+                    "description": "Косинусное сходство триграмм от 0 до 1, больше - похожее.",
+                    "type": "number"
+                }
+            }
+        },
+        "models.StatusChangeRequestBody": {
+            "type": "object",
+            "properties": {
+                "comment": {
+                    "description": "Комментарий проверяющего. Обязателен при отклонении (in_review -\u003e draft).",
+                    "type": "string"
+                },
+                "status": {
+                    "description": "Новый статус: in_review, published, draft или archived.",
+                    "type": "string"
+                },
+                "tutor_id": {
+                    "description": "Тьютор, который меняет статус. Записывается в версию.",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.SynonymGroup": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "integer"
+                },
+                "terms": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "models.SynonymGroupRequestBody": {
+            "type": "object",
+            "properties": {
+                "terms": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
         "models.Tag": {
             "type": "object",
             "properties": {
@@ -1208,6 +5312,62 @@ const docTemplate = `{
                 }
             }
         },
+        "models.TagFacet": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "tag": {
+                    "type": "string"
+                },
+                "tag_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.TagSearchResult": {
+            "type": "object",
+            "properties": {
+                "facets": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.TagFacet"
+                    }
+                },
+                "questions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Question"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.TagSuggestion": {
+            "type": "object",
+            "properties": {
+                "confidence": {
+                    "description": "Уверенность от 0 до 1.",
+                    "type": "number"
+                },
+                "sources": {
+                    "description": "Откуда подсказка: question_text, answer_text (имя тега или синоним есть в тексте), co_occurrence (тег часто стоит рядом с тегами вопроса).",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "tag": {
+                    "type": "string"
+                },
+                "tag_id": {
+                    "type": "integer"
+                }
+            }
+        },
         "models.TagSwaggerRequestBody": {
             "type": "object",
             "properties": {
@@ -1243,6 +5403,38 @@ const docTemplate = `{
                     "type": "string"
                 }
             }
+        },
+        "models.UnansweredDemand": {
+            "type": "object",
+            "properties": {
+                "ask_count": {
+                    "description": "Сколько раз задавали такой вопрос.",
+                    "type": "integer"
+                },
+                "best_confidence": {
+                    "description": "Лучшая уверенность среди найденных вопросов.",
+                    "type": "number"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "last_asked_at": {
+                    "type": "string"
+                },
+                "query_text": {
+                    "type": "string"
+                },
+                "question_id": {
+                    "type": "integer"
+                },
+                "status": {
+                    "description": "open, resolved (создан вопрос question_id) или dismissed.",
+                    "type": "string"
+                }
+            }
         }
     }
 }`
@@ -1,9 +1,15 @@
 package app
 
 import (
+	"context"
 	"database/sql"
+	"knowledge-base/internal/config"
+	"knowledge-base/internal/database"
 	"knowledge-base/internal/handler"
+	"knowledge-base/internal/metrics"
+	"knowledge-base/internal/middleware"
 	"knowledge-base/internal/service"
+	"log"
 )
 
 // Services содержит все сервисы.
@@ -16,6 +22,11 @@ type services struct {
 	AnswerVersion   *service.AnswerVersionService
 	QuestionTag     *service.QuestionTagService
 	SimpleSearch    *service.SimpleSearchService
+	Search          *service.SearchService
+	Synonym         *service.SynonymService
+	Ask             *service.AskService
+	SearchLog       *service.SearchLogService
+	Comment         *service.CommentService
 }
 
 // Handlers содержит все хэндлеры.
@@ -28,10 +39,95 @@ type Handlers struct {
 	AnswerVersion   *handler.AnswerVersionHandler
 	QuestionTag     *handler.QuestionTagHandler
 	SimpleSearch    *handler.SimpleSearchHandler
+	Search          *handler.SearchHandler
+	Synonym         *handler.SynonymHandler
+	Ask             *handler.AskHandler
+	SearchLog       *handler.SearchLogHandler
+	Comment         *handler.CommentHandler
 }
 
 // Создает и инициализирует все зависимости.
-func NewContainer(db *sql.DB) *Handlers {
+func NewContainer(db *sql.DB, cfg config.Config) *Handlers {
+
+	// Настройка разбора JSON тела запросов.
+	handler.SetDecodeOptions(handler.DecodeOptions{
+		MaxBytes:           cfg.MaxBodyBytes,
+		AllowUnknownFields: cfg.LenientJSON,
+	})
+
+	// Ограничение времени на запросы к БД.
+	service.SetQueryTimeout(cfg.QueryTimeout)
+	service.SetExportTimeout(cfg.ExportTimeout)
+
+	// Пороги нечеткого поиска.
+	service.SetFuzzySettings(service.FuzzySettings{
+		TagThreshold:     cfg.FuzzyTagThreshold,
+		TextThreshold:    cfg.FuzzyTextThreshold,
+		SuggestThreshold: cfg.FuzzySuggestThreshold,
+		SuggestLimit:     cfg.FuzzySuggestLimit,
+	})
+
+	// Пороги поиска похожих вопросов и дубликатов.
+	service.SetSimilarSettings(service.SimilarSettings{
+		DuplicateThreshold: cfg.SimilarDuplicateThreshold,
+		RelatedThreshold:   cfg.SimilarRelatedThreshold,
+		Limit:              cfg.SimilarLimit,
+	})
+
+	// Подсказки тегов и их автоматическое добавление.
+	service.SetTagSuggestSettings(service.TagSuggestSettings{
+		MinConfidence:      cfg.TagSuggestMinConfidence,
+		Limit:              cfg.TagSuggestLimit,
+		AutoApply:          cfg.TagSuggestAutoApply,
+		AutoApplyThreshold: cfg.TagSuggestAutoApplyThreshold,
+	})
+
+	// Порог уверенности ответов на свободные вопросы.
+	service.SetAskSettings(service.AskSettings{
+		ConfidenceThreshold: cfg.AskConfidenceThreshold,
+		Alternatives:        cfg.AskAlternatives,
+	})
+
+	// Журнал поисков и обезличивание клиентов (журнал поиска, оценки ответов).
+	handler.SetSearchLogOptions(handler.SearchLogOptions{
+		Enabled: cfg.SearchLogEnabled,
+	})
+	handler.SetClientIDSalt(cfg.ClientIDSalt)
+	handler.SetFeedbackRateLimit(cfg.FeedbackRateLimit)
+
+	// Токены ролей редакционного процесса.
+	middleware.SetRoleTokens(middleware.RoleTokens{
+		Tutor:     cfg.TutorToken,
+		Moderator: cfg.ModeratorToken,
+		Tutors:    cfg.TutorTokens,
+	})
+
+	// Без расширения pg_trgm нечеткий поиск, похожие вопросы и /ask отвечают 501, подсказки тегов считаются в Go.
+	trigram, err := database.TrigramAvailable(db)
+	if err != nil {
+		log.Fatal("Ошибка проверки расширения pg_trgm:", err)
+	}
+	service.SetTrigramEnabled(trigram)
+
+	// Движок поиска: полнотекстовый поиск Postgres или встроенный индекс, который строится при запуске.
+	switch cfg.SearchBackend {
+	case service.SearchBackendPostgres:
+		service.SetSearchEngine(service.NewPostgresSearchEngine(db))
+	case service.SearchBackendIndex:
+		engine, err := service.NewIndexSearchEngine(context.Background(), db, service.IndexSearchOptions{
+			Path:         cfg.SearchIndexPath,
+			SaveInterval: cfg.SearchIndexSaveInterval,
+		})
+		if err != nil {
+			log.Fatal("Ошибка построения поискового индекса:", err)
+		}
+		service.SetSearchEngine(engine)
+	default:
+		log.Fatalf("Неизвестный движок поиска SEARCH_BACKEND=%q: ожидается postgres или index", cfg.SearchBackend)
+	}
+
+	// Метрики пула соединений и доменные счетчики.
+	metrics.RegisterDB(db)
 
 	// Инициализация всех сервисов.
 	services := services{
@@ -43,6 +139,11 @@ func NewContainer(db *sql.DB) *Handlers {
 		AnswerVersion:   service.NewAnswerVersionService(db),
 		QuestionTag:     service.NewQuestionTagService(db),
 		SimpleSearch:    service.NewSimpleSearchService(db),
+		Search:          service.NewSearchService(db),
+		Synonym:         service.NewSynonymService(db),
+		Ask:             service.NewAskService(db),
+		SearchLog:       service.NewSearchLogService(db),
+		Comment:         service.NewCommentService(db),
 	}
 
 	// Инициализация всех хэндлеров с соответствующими сервисами.
@@ -55,6 +156,11 @@ func NewContainer(db *sql.DB) *Handlers {
 		AnswerVersion:   handler.NewAnswerVersionHandler(services.AnswerVersion),
 		QuestionTag:     handler.NewQuestionTagHandler(services.QuestionTag),
 		SimpleSearch:    handler.NewSimpleSearchHandler(services.SimpleSearch),
+		Search:          handler.NewSearchHandler(services.Search),
+		Synonym:         handler.NewSynonymHandler(services.Synonym),
+		Ask:             handler.NewAskHandler(services.Ask),
+		SearchLog:       handler.NewSearchLogHandler(services.SearchLog),
+		Comment:         handler.NewCommentHandler(services.Comment),
 	}
 
 	return handlers
@@ -0,0 +1,185 @@
+package config
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Config содержит настройки приложения, которые читаются из переменных окружения.
+type Config struct {
+	// Максимальный размер тела запроса в байтах.
+	MaxBodyBytes int64
+
+	// Разрешать ли неизвестные поля в JSON теле запроса.
+	LenientJSON bool
+
+	// Ограничение времени на один вызов сервиса (запросы к БД).
+	QueryTimeout time.Duration
+
+	// Ограничение времени на потоковую выгрузку всей таблицы.
+	ExportTimeout time.Duration
+
+	// Пороги нечеткого поиска (pg_trgm) и количество подсказок.
+	FuzzyTagThreshold     float64
+	FuzzyTextThreshold    float64
+	FuzzySuggestThreshold float64
+	FuzzySuggestLimit     int
+
+	// Пороги сходства для дубликатов и похожих вопросов и количество похожих вопросов.
+	SimilarDuplicateThreshold float64
+	SimilarRelatedThreshold   float64
+	SimilarLimit              int
+
+	// Подсказки тегов: минимальная уверенность, количество и автоматическое добавление при создании вопроса.
+	TagSuggestMinConfidence      float64
+	TagSuggestLimit              int
+	TagSuggestAutoApply          bool
+	TagSuggestAutoApplyThreshold float64
+
+	// Уверенность, с которой POST /ask возвращает ответ, и количество альтернатив.
+	AskConfidenceThreshold float64
+	AskAlternatives        int
+
+	// Запись поисков в журнал.
+	SearchLogEnabled bool
+
+	// Соль для обезличивания клиентов в журнале поиска и оценках ответов и для подписи cookie клиента.
+	ClientIDSalt string
+
+	// Оценок ответов в час с одного IP адреса.
+	FeedbackRateLimit int
+
+	// Токены ролей тьютора и модератора для редакционного процесса. Пустой токен отключает роль.
+	TutorToken     string
+	ModeratorToken string
+
+	// Личные токены тьюторов: токен -> ID тьютора. Голоса за ответы отдаются только по личному токену.
+	TutorTokens map[string]int
+
+	// Движок поиска: postgres (полнотекстовый поиск БД) или index (встроенный обратный индекс).
+	SearchBackend string
+
+	// Файл встроенного индекса и как часто в него записываются изменения.
+	SearchIndexPath         string
+	SearchIndexSaveInterval time.Duration
+
+	// Экспорт трейсов: none, otlp или stdout.
+	TracingExporter string
+
+	// Адрес OTLP/HTTP коллектора трейсов.
+	TracingOTLPEndpoint string
+
+	// Доля запросов, которые попадают в трейсы.
+	TracingSampleRatio float64
+}
+
+// Load читает настройки из окружения. Если переменная не задана, берется значение по умолчанию.
+func Load() Config {
+	return Config{
+		MaxBodyBytes: getInt64("MAX_BODY_BYTES", 1<<20),
+		LenientJSON:  getBool("JSON_LENIENT", false),
+		QueryTimeout: getDuration("DB_QUERY_TIMEOUT", 5*time.Second),
+
+		ExportTimeout: getDuration("DB_EXPORT_TIMEOUT", 10*time.Minute),
+
+		FuzzyTagThreshold:     getFloat("FUZZY_TAG_THRESHOLD", 0.3),
+		FuzzyTextThreshold:    getFloat("FUZZY_TEXT_THRESHOLD", 0.6),
+		FuzzySuggestThreshold: getFloat("FUZZY_SUGGEST_THRESHOLD", 0.2),
+		FuzzySuggestLimit:     int(getInt64("FUZZY_SUGGEST_LIMIT", 5)),
+
+		SimilarDuplicateThreshold: getFloat("SIMILAR_DUPLICATE_THRESHOLD", 0.8),
+		SimilarRelatedThreshold:   getFloat("SIMILAR_RELATED_THRESHOLD", 0.3),
+		SimilarLimit:              int(getInt64("SIMILAR_LIMIT", 10)),
+
+		TagSuggestMinConfidence:      getFloat("TAG_SUGGEST_MIN_CONFIDENCE", 0.2),
+		TagSuggestLimit:              int(getInt64("TAG_SUGGEST_LIMIT", 5)),
+		TagSuggestAutoApply:          getBool("TAG_SUGGEST_AUTO_APPLY", false),
+		TagSuggestAutoApplyThreshold: getFloat("TAG_SUGGEST_AUTO_THRESHOLD", 0.8),
+
+		AskConfidenceThreshold: getFloat("ASK_CONFIDENCE_THRESHOLD", 0.5),
+		AskAlternatives:        int(getInt64("ASK_ALTERNATIVES", 3)),
+
+		SearchLogEnabled: getBool("SEARCH_LOG_ENABLED", true),
+		ClientIDSalt:     getString("CLIENT_ID_SALT", ""),
+
+		FeedbackRateLimit: int(getInt64("FEEDBACK_RATE_LIMIT", 20)),
+
+		TutorToken:     getString("TUTOR_TOKEN", ""),
+		ModeratorToken: getString("MODERATOR_TOKEN", ""),
+		TutorTokens:    getTokenMap("TUTOR_TOKENS"),
+
+		SearchBackend:           strings.ToLower(getString("SEARCH_BACKEND", "postgres")),
+		SearchIndexPath:         getString("SEARCH_INDEX_PATH", "data/search.idx"),
+		SearchIndexSaveInterval: getDuration("SEARCH_INDEX_SAVE_INTERVAL", 30*time.Second),
+
+		TracingExporter:     getString("TRACING_EXPORTER", "none"),
+		TracingOTLPEndpoint: getString("TRACING_OTLP_ENDPOINT", "http://localhost:4318"),
+		TracingSampleRatio:  getFloat("TRACING_SAMPLE_RATIO", 1),
+	}
+}
+
+// Получение строки из переменной окружения.
+func getString(key string, fallback string) string {
+	value := strings.TrimSpace(os.Getenv(key))
+	if value == "" {
+		return fallback
+	}
+	return value
+}
+
+// Получение числа из переменной окружения.
+func getInt64(key string, fallback int64) int64 {
+	value, err := strconv.ParseInt(strings.TrimSpace(os.Getenv(key)), 10, 64)
+	if err != nil || value <= 0 {
+		return fallback
+	}
+	return value
+}
+
+// Получение флага из переменной окружения.
+func getBool(key string, fallback bool) bool {
+	value, err := strconv.ParseBool(strings.TrimSpace(os.Getenv(key)))
+	if err != nil {
+		return fallback
+	}
+	return value
+}
+
+// Получение длительности из переменной окружения (например "5s" или "250ms").
+func getDuration(key string, fallback time.Duration) time.Duration {
+	value, err := time.ParseDuration(strings.TrimSpace(os.Getenv(key)))
+	if err != nil || value <= 0 {
+		return fallback
+	}
+	return value
+}
+
+// Получение личных токенов из переменной окружения вида "1:token1,2:token2".
+// Записи без ID, с неверным ID или пустым токеном пропускаются.
+func getTokenMap(key string) map[string]int {
+	tokens := map[string]int{}
+	for _, entry := range strings.Split(os.Getenv(key), ",") {
+		idStr, token, ok := strings.Cut(strings.TrimSpace(entry), ":")
+		if !ok {
+			continue
+		}
+		id, err := strconv.Atoi(strings.TrimSpace(idStr))
+		token = strings.TrimSpace(token)
+		if err != nil || id <= 0 || token == "" {
+			continue
+		}
+		tokens[token] = id
+	}
+	return tokens
+}
+
+// Получение дробного числа из переменной окружения.
+func getFloat(key string, fallback float64) float64 {
+	value, err := strconv.ParseFloat(strings.TrimSpace(os.Getenv(key)), 64)
+	if err != nil || value < 0 {
+		return fallback
+	}
+	return value
+}
@@ -0,0 +1,87 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"os"
+)
+
+// Дополнительные миграции, которые выполняются после создания и наполнения таблиц.
+// Каждая миграция должна быть повторяемой (if not exists), так как выполняется при каждом запуске.
+var migrations = []string{
+	"003_pagination_indexes.sql",
+	"004_full_text_search.sql",
+	"005_fuzzy_search.sql",
+	"006_translit_search.sql",
+	"007_synonyms.sql",
+	"008_similar_questions.sql",
+	"009_tag_suggestions.sql",
+	"010_ask.sql",
+	"011_search_analytics.sql",
+	"012_multiple_answers.sql",
+	"013_answer_votes.sql",
+	"014_comments.sql",
+	"015_editorial_workflow.sql",
+}
+
+// Миграции, которым нужно расширение pg_trgm. Если создать расширение нельзя (нет прав),
+// они пропускаются, а нечеткий поиск, похожие вопросы и /ask отключаются (см. TrigramAvailable).
+var trigramMigrations = map[string]bool{
+	"005_fuzzy_search.sql":      true,
+	"008_similar_questions.sql": true,
+}
+
+func ApplyMigrations(db *sql.DB) error {
+	trigram := true
+	for _, name := range migrations {
+		if trigramMigrations[name] && !trigram {
+			log.Printf("⚠️ Миграция %s пропущена: нет расширения pg_trgm", name)
+			continue
+		}
+
+		err := applyMigration(db, name)
+		if err != nil && trigramMigrations[name] {
+			// Ошибка из-за того, что расширение не создалось, не мешает остальным миграциям.
+			available, checkErr := TrigramAvailable(db)
+			if checkErr == nil && !available {
+				log.Printf("⚠️ Миграция %s пропущена, нечеткий поиск, похожие вопросы и /ask отключены: %v", name, err)
+				trigram = false
+				continue
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// TrigramAvailable проверяет, установлено ли расширение pg_trgm.
+func TrigramAvailable(db *sql.DB) (bool, error) {
+	var available bool
+	err := db.QueryRow(`select exists (select 1 from pg_extension where extname = 'pg_trgm')`).Scan(&available)
+	return available, err
+}
+
+// Выполнение одного файла миграции.
+func applyMigration(db *sql.DB, name string) error {
+	// Читаем SQL из файла миграций.
+	sqlBytes, err := os.ReadFile("./migrations/" + name)
+	if err != nil {
+		// Пробуем путь для локальной разработки.
+		sqlBytes, err = os.ReadFile("../../migrations/" + name)
+		if err != nil {
+			return fmt.Errorf("ошибка чтения файла миграции %s: %v", name, err)
+		}
+	}
+
+	// Выполняем SQL.
+	_, err = db.Exec(string(sqlBytes))
+	if err != nil {
+		return fmt.Errorf("ошибка выполнения миграции %s: %v", name, err)
+	}
+
+	log.Printf("✅ Миграция %s выполнена", name)
+	return nil
+}
@@ -2,7 +2,7 @@ package database
 
 import "database/sql"
 
-// Создание координатора, кторый сразу вызовет 2 пакета запросов на создание таблиц и их заполнение.
+// Создание координатора, кторый сразу вызовет 2 пакета запросов на создание таблиц и их заполнение, а затем дополнительные миграции.
 func RunMigrations(db *sql.DB) error {
 	if err := CreateTables(db); err != nil {
 		return err
@@ -12,5 +12,9 @@ func RunMigrations(db *sql.DB) error {
 		return err
 	}
 
+	if err := ApplyMigrations(db); err != nil {
+		return err
+	}
+
 	return nil
 }
@@ -1,7 +1,9 @@
 package handler
 
 import (
+	"database/sql"
 	"encoding/json"
+	"errors"
 	"knowledge-base/internal/models"
 	"knowledge-base/internal/service"
 	"net/http"
@@ -21,23 +23,47 @@ func NewAnswerHandler(answerService *service.AnswerService) *AnswerHandler {
 }
 
 // @Summary Get all answers
-// @Description Returns list of all answers
+// @Description Returns paginated list of answers with sorting and filters
 // @Tags answers
 // @Produce json
+// @Param limit query int false "Page size (1-500, default 50)"
+// @Param offset query int false "Number of records to skip"
+// @Param cursor query string false "Opaque cursor from Link header, sort by id, created_at or score (cannot be combined with offset)"
+// @Param sort query string false "Sort field (id, created_at, question_id, tutor_id, score), prefix with - for descending"
+// @Param tutor_id query int false "Filter by tutor ID"
+// @Param question_id query int false "Filter by question ID"
+// @Param created_after query string false "Created after (RFC 3339 or YYYY-MM-DD)"
+// @Param created_before query string false "Created before (RFC 3339 or YYYY-MM-DD)"
+// @Param is_edit query bool false "Filter by edit flag"
+// @Param is_accepted query bool false "Filter by accepted flag"
+// @Param status query string false "Filter by status: draft, in_review, published or archived. Learners only see published"
 // @Success 200 {array} models.Answer
+// @Header 200 {integer} X-Total-Count "Total number of records matching filters"
+// @Header 200 {string} Link "Links to next and prev pages"
+// @Failure 400 {string} string "Invalid pagination, sort or filter parameter"
 // @Router /answers [get]
 func (answerHandler *AnswerHandler) GetAllAnswers(w http.ResponseWriter, r *http.Request) {
 
+	// Разбор параметров пагинации, сортировки и фильтров.
+	params, err := parseListParams(r, service.AnswerListSpec)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	// Вызов сервиса.
-	answers, err := answerHandler.answerService.GetAll()
+	answers, page, err := answerHandler.answerService.GetAll(r.Context(), params)
 	if err != nil {
-		http.Error(w, "Ошибка получения ответов: "+err.Error(), http.StatusInternalServerError)
+		serviceError(w, err, "Ошибка получения ответов: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
 
 	// Устанавливаем заголовок JSON.
 	w.Header().Set("Content-Type", "application/json")
 
+	// Заголовки с общим количеством и ссылками на соседние страницы.
+	writeListHeaders(w, r, params, page)
+
 	// Кодируем результат в JSON фомат и возвращаем.
 	err = json.NewEncoder(w).Encode(answers)
 	if err != nil {
@@ -46,6 +72,46 @@ func (answerHandler *AnswerHandler) GetAllAnswers(w http.ResponseWriter, r *http
 	}
 }
 
+// @Summary Export all answers
+// @Description Streams all answers matching filters as a JSON array or NDJSON (Accept: application/x-ndjson) without pagination
+// @Tags answers
+// @Produce json
+// @Produce application/x-ndjson
+// @Param sort query string false "Sort field (id, created_at, question_id, tutor_id, score), prefix with - for descending"
+// @Param tutor_id query int false "Filter by tutor ID"
+// @Param question_id query int false "Filter by question ID"
+// @Param created_after query string false "Created after (RFC 3339 or YYYY-MM-DD)"
+// @Param created_before query string false "Created before (RFC 3339 or YYYY-MM-DD)"
+// @Param is_edit query bool false "Filter by edit flag"
+// @Param is_accepted query bool false "Filter by accepted flag"
+// @Param status query string false "Filter by status: draft, in_review, published or archived. Learners only see published"
+// @Success 200 {array} models.Answer
+// @Failure 400 {string} string "Invalid sort or filter parameter"
+// @Failure 500 {string} string "Internal server error"
+// @Router /answers/export [get]
+func (answerHandler *AnswerHandler) ExportAnswers(w http.ResponseWriter, r *http.Request) {
+
+	// Разбор параметров сортировки и фильтров.
+	params, err := parseExportParams(r, service.AnswerListSpec)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Вызов сервиса: каждая запись сразу пишется в ответ.
+	stream := newStreamWriter(w, r)
+	err = answerHandler.answerService.Export(r.Context(), params, func(answer models.Answer) error {
+		return stream.Write(answer)
+	})
+	if err != nil {
+		stream.Fail(r, err, "Ошибка выгрузки ответов: ")
+		return
+	}
+
+	// Завершение массива и отправка остатка буфера.
+	stream.Close()
+}
+
 // @Summary Get answer by ID
 // @Description Returns answer by specified ID
 // @Tags answers
@@ -69,9 +135,9 @@ func (answerHandler *AnswerHandler) GetAnswerByID(w http.ResponseWriter, r *http
 	}
 
 	// Вызов сервиса.
-	answer, err := answerHandler.answerService.GetByID(id)
+	answer, err := answerHandler.answerService.GetByID(r.Context(), id)
 	if err != nil {
-		http.Error(w, "Ответ не найден", http.StatusNotFound)
+		serviceError(w, err, "Ответ не найден", http.StatusNotFound)
 		return
 	}
 
@@ -93,6 +159,7 @@ func (answerHandler *AnswerHandler) GetAnswerByID(w http.ResponseWriter, r *http
 // @Param tutor_id path int true "Tutor ID who deleted the answer"
 // @Success 204
 // @Failure 400 {string} string "Invalid ID"
+// @Failure 401 {string} string "Tutor or moderator token required"
 // @Failure 404 {string} string "Answer not found"
 // @Router /answers/{id}/deleteBy/{tutor_id} [delete]
 func (answerHandler *AnswerHandler) DeleteAnswerByID(w http.ResponseWriter, r *http.Request) {
@@ -118,9 +185,9 @@ func (answerHandler *AnswerHandler) DeleteAnswerByID(w http.ResponseWriter, r *h
 
 	//Возврат кода операции.
 	//  Успешный ответ - 204 No connect для удаления.
-	err = answerHandler.answerService.DeleteByID(id, deleteByTutor)
+	err = answerHandler.answerService.DeleteByID(r.Context(), id, deleteByTutor)
 	if err != nil {
-		http.Error(w, "Вопрос не найден", http.StatusNotFound)
+		serviceError(w, err, "Вопрос не найден", http.StatusNotFound)
 		return
 	}
 
@@ -130,13 +197,17 @@ func (answerHandler *AnswerHandler) DeleteAnswerByID(w http.ResponseWriter, r *h
 }
 
 // @Summary Create new answer and records the version
-// @Description Create a new answer with text, tutor_id, question_id. Create a new answer_version with answer_id, answer_text, tutor_id, answer_number
+// @Description Create a new answer with text, tutor_id, question_id. The first answer of a question becomes accepted, the next ones go last. Create a new answer_version with answer_id, answer_text, tutor_id, answer_number
 // @Tags answers
 // @Accept json
 // @Produce json
 // @Param answer body models.AnswersSwaggerRequestBody true "Answer data"
 // @Success 201 {object} map[string]interface{} "Answer created"
 // @Failure 400 {string} string "Invalid request"
+// @Failure 401 {string} string "Tutor or moderator token required"
+// @Failure 413 {string} string "Request body too large"
+// @Failure 404 {string} string "Question not found"
+// @Failure 415 {string} string "Content-Type must be application/json"
 // @Failure 500 {string} string "Internal server error"
 // @Router /answers [post]
 func (answerHandler *AnswerHandler) PostAnswerString(w http.ResponseWriter, r *http.Request) {
@@ -144,9 +215,9 @@ func (answerHandler *AnswerHandler) PostAnswerString(w http.ResponseWriter, r *h
 	var answer models.Answer
 
 	//Преобразование JSON данных в формат структуры models.Answer.
-	err := json.NewDecoder(r.Body).Decode(&answer)
+	err := decodeJSONBody(w, r, &answer)
 	if err != nil {
-		http.Error(w, "Invalid JSON: "+err.Error(), http.StatusBadRequest)
+		http.Error(w, "Invalid JSON: "+err.Error(), bodyErrorStatus(err))
 		return
 	}
 
@@ -157,9 +228,13 @@ func (answerHandler *AnswerHandler) PostAnswerString(w http.ResponseWriter, r *h
 	}
 
 	// Вызов сервиса.
-	id, err := answerHandler.answerService.PostString(answer.AnswersText, answer.TutorID, answer.QuestionID)
+	id, err := answerHandler.answerService.PostString(r.Context(), answer.AnswersText, answer.TutorID, answer.QuestionID)
+	if errors.Is(err, sql.ErrNoRows) {
+		http.Error(w, "Вопрос не найден", http.StatusNotFound)
+		return
+	}
 	if err != nil {
-		http.Error(w, "Failed to create answer or answer_version: "+err.Error(), http.StatusInternalServerError)
+		serviceError(w, err, "Failed to create answer or answer_version: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
 
@@ -177,7 +252,7 @@ func (answerHandler *AnswerHandler) PostAnswerString(w http.ResponseWriter, r *h
 }
 
 // @Summary Update answer and records the version
-// @Description Update answer with text, tutor_id, question_id and edit flag. Create a new answer_version with answer_id, answer_text, tutor_id, answer_number
+// @Description Update answer with text, tutor_id, question_id and edit flag. An answer moved to another question is no longer accepted and goes last. Create a new answer_version with answer_id, answer_text, tutor_id, answer_number. An answer that is not a draft goes back to in_review until a moderator publishes it
 // @Tags answers
 // @Accept json
 // @Produce json
@@ -185,6 +260,9 @@ func (answerHandler *AnswerHandler) PostAnswerString(w http.ResponseWriter, r *h
 // @Param answer body models.AnswersSwaggerRequestBody true "Answer data"
 // @Success 200 {object} map[string]interface{} "Answer updated"
 // @Failure 400 {string} string "Invalid request"
+// @Failure 401 {string} string "Tutor or moderator token required"
+// @Failure 413 {string} string "Request body too large"
+// @Failure 415 {string} string "Content-Type must be application/json"
 // @Failure 404 {string} string "Answer not found"
 // @Router /answers/{id} [put]
 func (answerHandler *AnswerHandler) PutAnswerString(w http.ResponseWriter, r *http.Request) {
@@ -203,9 +281,9 @@ func (answerHandler *AnswerHandler) PutAnswerString(w http.ResponseWriter, r *ht
 	var answer models.Answer
 
 	//Преобразование JSON данных в формат структуры models.Answer.
-	err = json.NewDecoder(r.Body).Decode(&answer)
+	err = decodeJSONBody(w, r, &answer)
 	if err != nil {
-		http.Error(w, "Invalid JSON: "+err.Error(), http.StatusBadRequest)
+		http.Error(w, "Invalid JSON: "+err.Error(), bodyErrorStatus(err))
 		return
 	}
 
@@ -216,9 +294,9 @@ func (answerHandler *AnswerHandler) PutAnswerString(w http.ResponseWriter, r *ht
 	}
 
 	// Вызов сервиса.
-	updatedAnswer, err := answerHandler.answerService.PutString(answer.AnswersText, answer.TutorID, answer.QuestionID, id)
+	updatedAnswer, err := answerHandler.answerService.PutString(r.Context(), answer.AnswersText, answer.TutorID, answer.QuestionID, id)
 	if err != nil {
-		http.Error(w, "Ответ не найден", http.StatusNotFound)
+		serviceError(w, err, "Ответ не найден", http.StatusNotFound)
 		return
 	}
 
@@ -235,5 +313,112 @@ func (answerHandler *AnswerHandler) PutAnswerString(w http.ResponseWriter, r *ht
 		"question_id": updatedAnswer.QuestionID,
 		"created_at":  updatedAnswer.CreatedAt,
 		"is_edit":     updatedAnswer.IsEdit,
+		"is_accepted": updatedAnswer.IsAccepted,
+		"position":    updatedAnswer.Position,
+		"status":      updatedAnswer.Status,
 	})
 }
+
+// @Summary Get answer of question
+// @Description Returns the accepted answer to the question with specified ID, or its first answer if none is accepted
+// @Tags answers
+// @Produce json
+// @Param id path int true "Question ID"
+// @Success 200 {object} models.Answer
+// @Failure 400 {string} string "Invalid question ID"
+// @Failure 404 {string} string "Answer not found"
+// @Router /questions/{id}/answer [get]
+func (answerHandler *AnswerHandler) GetAnswerByQuestionID(w http.ResponseWriter, r *http.Request) {
+
+	//Разбиение пути handler на части.
+	vars := mux.Vars(r)
+	idStr := vars["id"]
+
+	//Преобразование строк в число.
+	questionID, err := strconv.Atoi(idStr)
+	if err != nil {
+		http.Error(w, "Неверный ID вопроса", http.StatusBadRequest)
+		return
+	}
+
+	// Вызов сервиса.
+	answer, err := answerHandler.answerService.GetByQuestionID(r.Context(), questionID)
+	if err != nil {
+		serviceError(w, err, "Ответ на вопрос не найден", http.StatusNotFound)
+		return
+	}
+
+	// Устанавливаем заголовок JSON.
+	w.Header().Set("Content-Type", "application/json")
+
+	// Кодируем результат в JSON фомат и возвращаем.
+	err = json.NewEncoder(w).Encode(answer)
+	if err != nil {
+		http.Error(w, "Ошибка кодирования JSON: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// @Summary Get answers of tutor
+// @Description Returns paginated list of answers written by the tutor with specified ID
+// @Tags answers
+// @Produce json
+// @Param id path int true "Tutor ID"
+// @Param limit query int false "Page size (1-500, default 50)"
+// @Param offset query int false "Number of records to skip"
+// @Param cursor query string false "Opaque cursor from Link header, sort by id, created_at or score (cannot be combined with offset)"
+// @Param sort query string false "Sort field (id, created_at, question_id, tutor_id, score), prefix with - for descending"
+// @Param question_id query int false "Filter by question ID"
+// @Param created_after query string false "Created after (RFC 3339 or YYYY-MM-DD)"
+// @Param created_before query string false "Created before (RFC 3339 or YYYY-MM-DD)"
+// @Param is_edit query bool false "Filter by edit flag"
+// @Param is_accepted query bool false "Filter by accepted flag"
+// @Param status query string false "Filter by status: draft, in_review, published or archived. Learners only see published"
+// @Success 200 {array} models.Answer
+// @Header 200 {integer} X-Total-Count "Total number of records matching filters"
+// @Header 200 {string} Link "Links to next and prev pages"
+// @Failure 400 {string} string "Invalid tutor ID, pagination, sort or filter parameter"
+// @Router /tutors/{id}/answers [get]
+func (answerHandler *AnswerHandler) GetAnswersByTutorID(w http.ResponseWriter, r *http.Request) {
+
+	//Разбиение пути handler на части.
+	vars := mux.Vars(r)
+	idStr := vars["id"]
+
+	//Преобразование строк в число.
+	tutorID, err := strconv.Atoi(idStr)
+	if err != nil {
+		http.Error(w, "Неверный ID тьютора", http.StatusBadRequest)
+		return
+	}
+
+	// Разбор параметров пагинации, сортировки и фильтров.
+	params, err := parseListParams(r, service.AnswerListSpec)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Тьютор из пути задает фильтр списка.
+	params.Filters["tutor_id"] = tutorID
+
+	// Вызов сервиса.
+	answers, page, err := answerHandler.answerService.GetAll(r.Context(), params)
+	if err != nil {
+		serviceError(w, err, "Ошибка получения ответов: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Устанавливаем заголовок JSON.
+	w.Header().Set("Content-Type", "application/json")
+
+	// Заголовки с общим количеством и ссылками на соседние страницы.
+	writeListHeaders(w, r, params, page)
+
+	// Кодируем результат в JSON фомат и возвращаем.
+	err = json.NewEncoder(w).Encode(answers)
+	if err != nil {
+		http.Error(w, "Ошибка кодирования JSON: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
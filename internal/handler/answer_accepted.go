@@ -0,0 +1,217 @@
+package handler
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"knowledge-base/internal/models"
+	"knowledge-base/internal/service"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// @Summary Get all answers of question
+// @Description Returns all answers to the question with specified ID: the accepted answer first, the rest in their order. With sort=score the best rated answers come first
+// @Tags answers
+// @Produce json
+// @Param id path int true "Question ID"
+// @Param sort query string false "position (default) or score"
+// @Success 200 {array} models.Answer
+// @Failure 400 {string} string "Invalid question ID or sort"
+// @Failure 404 {string} string "Question not found"
+// @Router /questions/{id}/answers [get]
+func (answerHandler *AnswerHandler) GetAnswersByQuestionID(w http.ResponseWriter, r *http.Request) {
+
+	//Разбиение пути handler на части.
+	vars := mux.Vars(r)
+	idStr := vars["id"]
+
+	//Преобразование строк в число.
+	questionID, err := strconv.Atoi(idStr)
+	if err != nil {
+		http.Error(w, "Неверный ID вопроса", http.StatusBadRequest)
+		return
+	}
+
+	// Порядок ответов.
+	var byScore bool
+	switch r.URL.Query().Get("sort") {
+	case "", "position":
+	case "score":
+		byScore = true
+	default:
+		http.Error(w, "sort должен быть position или score", http.StatusBadRequest)
+		return
+	}
+
+	// Вызов сервиса.
+	answers, err := answerHandler.answerService.GetAllByQuestionID(r.Context(), questionID, byScore)
+	if err != nil {
+		answerOrderError(w, err, "Ошибка получения ответов: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Устанавливаем заголовок JSON.
+	w.Header().Set("Content-Type", "application/json")
+
+	// Кодируем результат в JSON фомат и возвращаем.
+	err = json.NewEncoder(w).Encode(answers)
+	if err != nil {
+		http.Error(w, "Ошибка кодирования JSON: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// @Summary Set accepted answer of question
+// @Description Marks the answer as the accepted answer of the question. The previously accepted answer is no longer accepted
+// @Tags answers
+// @Accept json
+// @Produce json
+// @Param id path int true "Question ID"
+// @Param answer body models.AcceptedAnswerRequestBody true "Answer of this question"
+// @Success 200 {object} models.Answer
+// @Failure 400 {string} string "Invalid request"
+// @Failure 401 {string} string "Tutor or moderator token required"
+// @Failure 404 {string} string "Question or its answer not found"
+// @Failure 413 {string} string "Request body too large"
+// @Failure 415 {string} string "Content-Type must be application/json"
+// @Router /questions/{id}/accepted-answer [put]
+func (answerHandler *AnswerHandler) PutAcceptedAnswer(w http.ResponseWriter, r *http.Request) {
+
+	//Разбиение пути handler на части.
+	vars := mux.Vars(r)
+	idStr := vars["id"]
+
+	//Преобразование строк в число.
+	questionID, err := strconv.Atoi(idStr)
+	if err != nil {
+		http.Error(w, "Неверный ID вопроса", http.StatusBadRequest)
+		return
+	}
+
+	var body models.AcceptedAnswerRequestBody
+
+	//Преобразование JSON данных в формат структуры models.AcceptedAnswerRequestBody.
+	err = decodeJSONBody(w, r, &body)
+	if err != nil {
+		http.Error(w, "Invalid JSON: "+err.Error(), bodyErrorStatus(err))
+		return
+	}
+
+	// Вызов сервиса.
+	answer, err := answerHandler.answerService.Accept(r.Context(), questionID, body.AnswerID)
+	if err != nil {
+		answerOrderError(w, err, "Ошибка выбора принятого ответа: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Устанавливаем заголовок JSON.
+	w.Header().Set("Content-Type", "application/json")
+
+	// Кодируем результат в JSON фомат и возвращаем.
+	err = json.NewEncoder(w).Encode(answer)
+	if err != nil {
+		http.Error(w, "Ошибка кодирования JSON: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// @Summary Remove accepted answer of question
+// @Description The accepted answer of the question is no longer accepted. The answer itself is kept
+// @Tags answers
+// @Param id path int true "Question ID"
+// @Success 204 "Accepted answer removed"
+// @Failure 400 {string} string "Invalid question ID"
+// @Failure 401 {string} string "Tutor or moderator token required"
+// @Failure 404 {string} string "Question has no accepted answer"
+// @Router /questions/{id}/accepted-answer [delete]
+func (answerHandler *AnswerHandler) DeleteAcceptedAnswer(w http.ResponseWriter, r *http.Request) {
+
+	//Разбиение пути handler на части.
+	vars := mux.Vars(r)
+	idStr := vars["id"]
+
+	//Преобразование строк в число.
+	questionID, err := strconv.Atoi(idStr)
+	if err != nil {
+		http.Error(w, "Неверный ID вопроса", http.StatusBadRequest)
+		return
+	}
+
+	// Вызов сервиса.
+	err = answerHandler.answerService.Unaccept(r.Context(), questionID)
+	if err != nil {
+		answerOrderError(w, err, "Ошибка снятия принятого ответа: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// @Summary Reorder answers of question
+// @Description Sets the order of the question answers. answer_ids must list every answer of the question exactly once. The accepted answer is still listed first
+// @Tags answers
+// @Accept json
+// @Produce json
+// @Param id path int true "Question ID"
+// @Param order body models.AnswerOrderRequestBody true "All answer IDs of the question in the new order"
+// @Success 200 {array} models.Answer
+// @Failure 400 {string} string "Invalid request or incomplete answer list"
+// @Failure 401 {string} string "Tutor or moderator token required"
+// @Failure 404 {string} string "Question not found"
+// @Failure 413 {string} string "Request body too large"
+// @Failure 415 {string} string "Content-Type must be application/json"
+// @Router /questions/{id}/answers/order [put]
+func (answerHandler *AnswerHandler) PutAnswerOrder(w http.ResponseWriter, r *http.Request) {
+
+	//Разбиение пути handler на части.
+	vars := mux.Vars(r)
+	idStr := vars["id"]
+
+	//Преобразование строк в число.
+	questionID, err := strconv.Atoi(idStr)
+	if err != nil {
+		http.Error(w, "Неверный ID вопроса", http.StatusBadRequest)
+		return
+	}
+
+	var body models.AnswerOrderRequestBody
+
+	//Преобразование JSON данных в формат структуры models.AnswerOrderRequestBody.
+	err = decodeJSONBody(w, r, &body)
+	if err != nil {
+		http.Error(w, "Invalid JSON: "+err.Error(), bodyErrorStatus(err))
+		return
+	}
+
+	// Вызов сервиса.
+	answers, err := answerHandler.answerService.Reorder(r.Context(), questionID, body.AnswerIDs)
+	if err != nil {
+		answerOrderError(w, err, "Ошибка изменения порядка ответов: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Устанавливаем заголовок JSON.
+	w.Header().Set("Content-Type", "application/json")
+
+	// Кодируем результат в JSON фомат и возвращаем.
+	err = json.NewEncoder(w).Encode(answers)
+	if err != nil {
+		http.Error(w, "Ошибка кодирования JSON: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// Ответ на ошибку принятого ответа и порядка ответов: 400 для неверного порядка, 404 если вопроса или ответа нет.
+func answerOrderError(w http.ResponseWriter, err error, msg string, status int) {
+	switch {
+	case errors.Is(err, service.ErrInvalidAnswerOrder):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, sql.ErrNoRows):
+		http.Error(w, "Вопрос или ответ не найден", http.StatusNotFound)
+	default:
+		serviceError(w, err, msg, status)
+	}
+}
@@ -1,7 +1,9 @@
 package handler
 
 import (
+	"database/sql"
 	"encoding/json"
+	"errors"
 	"knowledge-base/internal/service"
 	"net/http"
 	"strconv"
@@ -20,12 +22,21 @@ func NewAnswerVersionHandler(answerVersionService *service.AnswerVersionService)
 }
 
 // @Summary Get answer versions by answer ID
-// @Description Returns all versions of a specific answer by answer ID
+// @Description Returns paginated versions of a specific answer by answer ID, supports cursor pagination. Learners see only published versions
 // @Tags answer-versions
 // @Produce json
 // @Param id path int true "Answer ID"
+// @Param limit query int false "Page size (1-500, default 50)"
+// @Param offset query int false "Number of records to skip"
+// @Param cursor query string false "Opaque cursor from Link header (cannot be combined with offset)"
+// @Param sort query string false "Sort field (version_number, created_at, id), prefix with - for descending"
+// @Param created_after query string false "Created after (RFC 3339 or YYYY-MM-DD)"
+// @Param created_before query string false "Created before (RFC 3339 or YYYY-MM-DD)"
 // @Success 200 {array} models.AnswerVersion
-// @Failure 400 {string} string "Invalid answer ID"
+// @Header 200 {integer} X-Total-Count "Total number of versions matching filters"
+// @Header 200 {string} Link "Cursor links to next and prev pages"
+// @Failure 400 {string} string "Invalid answer ID or pagination parameter"
+// @Failure 404 {string} string "Answer not found or not published"
 // @Router /answer-versions/{id} [get]
 func (handler *AnswerVersionHandler) GetAllAnswerVersionsByID(w http.ResponseWriter, r *http.Request) {
 	// Извлечение ID из параметров пути
@@ -39,16 +50,30 @@ func (handler *AnswerVersionHandler) GetAllAnswerVersionsByID(w http.ResponseWri
 		return
 	}
 
+	// Разбор параметров пагинации, сортировки и фильтров
+	params, err := parseListParams(r, service.AnswerVersionListSpec)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	// Вызов сервиса для получения версий ответа
-	answerVersions, err := handler.answerVersionService.GetAllByID(id)
+	answerVersions, page, err := handler.answerVersionService.GetAllByID(r.Context(), id, params)
 	if err != nil {
-		http.Error(w, "Ошибка получения версий ответа: "+err.Error(), http.StatusInternalServerError)
+		if errors.Is(err, sql.ErrNoRows) {
+			http.Error(w, "Ответ не найден", http.StatusNotFound)
+			return
+		}
+		serviceError(w, err, "Ошибка получения версий ответа: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
 
 	// Устанавливаем заголовок JSON
 	w.Header().Set("Content-Type", "application/json")
 
+	// Заголовки с общим количеством и ссылками на соседние страницы
+	writeListHeaders(w, r, params, page)
+
 	// Кодируем результат в JSON формат
 	json.NewEncoder(w).Encode(answerVersions)
 }
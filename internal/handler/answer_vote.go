@@ -0,0 +1,240 @@
+package handler
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"knowledge-base/internal/models"
+	"knowledge-base/internal/service"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// @Summary Rate answer helpfulness
+// @Description Learner feedback: was the answer helpful, with an optional comment. Learners are identified by an anonymized client ID from a signed kb_client cookie issued by the server (or IP address and User-Agent), one rating per answer; a repeated rating replaces the previous one. Ratings from one IP address are limited per hour (FEEDBACK_RATE_LIMIT)
+// @Tags answer votes
+// @Accept json
+// @Produce json
+// @Param id path int true "Answer ID"
+// @Param feedback body models.AnswerFeedbackRequestBody true "Helpful flag and optional comment (up to 1000 characters)"
+// @Success 201 {object} models.Answer "Rating recorded"
+// @Success 200 {object} models.Answer "Previous rating replaced"
+// @Failure 400 {string} string "Invalid request"
+// @Failure 404 {string} string "Answer not found"
+// @Failure 413 {string} string "Request body too large"
+// @Failure 415 {string} string "Content-Type must be application/json"
+// @Failure 429 {string} string "Too many ratings from this address"
+// @Header 429 {integer} Retry-After "Seconds until ratings are accepted again"
+// @Router /answers/{id}/feedback [post]
+func (answerHandler *AnswerHandler) PostAnswerFeedback(w http.ResponseWriter, r *http.Request) {
+
+	//Разбиение пути handler на части.
+	vars := mux.Vars(r)
+	idStr := vars["id"]
+
+	//Преобразование строк в число.
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		http.Error(w, "Неверный ID", http.StatusBadRequest)
+		return
+	}
+
+	var body models.AnswerFeedbackRequestBody
+
+	//Преобразование JSON данных в формат структуры models.AnswerFeedbackRequestBody.
+	err = decodeJSONBody(w, r, &body)
+	if err != nil {
+		http.Error(w, "Invalid JSON: "+err.Error(), bodyErrorStatus(err))
+		return
+	}
+
+	// Валидация.
+	if body.Helpful == nil {
+		http.Error(w, "helpful is required", http.StatusBadRequest)
+		return
+	}
+
+	// Ограничение оценок с одного адреса, адрес учитывается только в виде хэша.
+	allowed, retryAfter := feedbackLimiter.allow(hashClientID(clientHost(r)), time.Now())
+	if !allowed {
+		w.Header().Set("Retry-After", strconv.Itoa(int(retryAfter.Seconds())+1))
+		http.Error(w, "Слишком много оценок с этого адреса, попробуйте позже", http.StatusTooManyRequests)
+		return
+	}
+
+	// Вызов сервиса.
+	answer, created, err := answerHandler.answerService.RateAnswer(r.Context(), id, issueClientID(w, r), *body.Helpful, body.Comment)
+	if err != nil {
+		answerVoteError(w, err, "Ошибка записи оценки: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Устанавливаем заголовок JSON.
+	w.Header().Set("Content-Type", "application/json")
+
+	//Возврат кода операции.
+	if created {
+		w.WriteHeader(http.StatusCreated)
+	}
+
+	// Кодируем результат в JSON фомат.
+	json.NewEncoder(w).Encode(answer)
+}
+
+// @Summary Upvote peer answer
+// @Description Tutor upvote for an answer of another tutor. The voting tutor is the one whose personal token (TUTOR_TOKENS) is in the Authorization header. A tutor can upvote an answer once and cannot upvote own answers
+// @Tags answer votes
+// @Produce json
+// @Param id path int true "Answer ID"
+// @Param Authorization header string true "Bearer personal token of the voting tutor (TUTOR_TOKENS)"
+// @Success 201 {object} models.Answer
+// @Failure 400 {string} string "Invalid ID, unknown tutor or own answer"
+// @Failure 401 {string} string "Tutor or moderator token required"
+// @Failure 403 {string} string "Not a personal tutor token"
+// @Failure 404 {string} string "Answer not found"
+// @Failure 409 {string} string "Tutor already upvoted this answer"
+// @Router /answers/{id}/upvotes [post]
+func (answerHandler *AnswerHandler) PostAnswerUpvote(w http.ResponseWriter, r *http.Request) {
+
+	//Разбиение пути handler на части.
+	vars := mux.Vars(r)
+	idStr := vars["id"]
+
+	//Преобразование строк в число.
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		http.Error(w, "Неверный ID", http.StatusBadRequest)
+		return
+	}
+
+	// Вызов сервиса. Голосующего тьютора сервис берет из личного токена.
+	answer, err := answerHandler.answerService.Upvote(r.Context(), id)
+	if err != nil {
+		answerVoteError(w, err, "Ошибка записи голоса: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Устанавливаем заголовок JSON.
+	w.Header().Set("Content-Type", "application/json")
+
+	//Возврат кода операции.
+	w.WriteHeader(http.StatusCreated)
+
+	// Кодируем результат в JSON фомат.
+	json.NewEncoder(w).Encode(answer)
+}
+
+// @Summary Remove upvote
+// @Description Removes the tutor upvote for the answer. A tutor with a personal token (TUTOR_TOKENS) removes only own upvote, a moderator removes any upvote
+// @Tags answer votes
+// @Param id path int true "Answer ID"
+// @Param tutor_id path int true "Tutor ID"
+// @Param Authorization header string true "Bearer personal token of the tutor (TUTOR_TOKENS) or moderator token (MODERATOR_TOKEN)"
+// @Success 204 "Upvote removed"
+// @Failure 400 {string} string "Invalid ID"
+// @Failure 401 {string} string "Tutor or moderator token required"
+// @Failure 403 {string} string "Upvote of another tutor"
+// @Failure 404 {string} string "Answer or upvote not found"
+// @Router /answers/{id}/upvotes/{tutor_id} [delete]
+func (answerHandler *AnswerHandler) DeleteAnswerUpvote(w http.ResponseWriter, r *http.Request) {
+
+	//Разбиение пути handler на части.
+	vars := mux.Vars(r)
+	idStr := vars["id"]
+	tutorIDStr := vars["tutor_id"]
+
+	//Преобразование строк в число.
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		http.Error(w, "Неверный ID", http.StatusBadRequest)
+		return
+	}
+	tutorID, err := strconv.Atoi(tutorIDStr)
+	if err != nil {
+		http.Error(w, "Неверный ID тьютора", http.StatusBadRequest)
+		return
+	}
+
+	// Вызов сервиса.
+	err = answerHandler.answerService.RemoveUpvote(r.Context(), id, tutorID)
+	if err != nil {
+		answerVoteError(w, err, "Ошибка удаления голоса: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Количество оценок, с которого ответ попадает в отчет по полезности, по умолчанию и максимальное.
+const (
+	defaultFeedbackMinVotes = 3
+	maxFeedbackMinVotes     = 10000
+)
+
+// Количество ответов в отчете по полезности по умолчанию и максимальное.
+const (
+	defaultFeedbackReportLimit = 20
+	maxFeedbackReportLimit     = 500
+)
+
+// @Summary Answer helpfulness report
+// @Description Answers with the worst helpful ratio among answers with at least min_feedback learner ratings, with their latest comments, so tutors can improve them
+// @Tags answer votes
+// @Produce json
+// @Param min_feedback query int false "Minimum number of ratings (1-10000, default 3)"
+// @Param limit query int false "Number of answers (1-500, default 20)"
+// @Success 200 {array} models.AnswerFeedbackStat
+// @Failure 400 {string} string "Invalid parameter"
+// @Failure 401 {string} string "Tutor or moderator token required"
+// @Router /admin/answers/feedback [get]
+func (answerHandler *AnswerHandler) GetFeedbackReport(w http.ResponseWriter, r *http.Request) {
+
+	// Порог оценок и количество ответов.
+	minFeedback, err := boundedIntParam(r, "min_feedback", defaultFeedbackMinVotes, maxFeedbackMinVotes)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	limit, err := boundedIntParam(r, "limit", defaultFeedbackReportLimit, maxFeedbackReportLimit)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Вызов сервиса.
+	stats, err := answerHandler.answerService.FeedbackReport(r.Context(), minFeedback, limit)
+	if err != nil {
+		serviceError(w, err, "Ошибка построения отчета: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Устанавливаем заголовок JSON.
+	w.Header().Set("Content-Type", "application/json")
+
+	// Кодируем результат в JSON фомат и возвращаем.
+	err = json.NewEncoder(w).Encode(stats)
+	if err != nil {
+		http.Error(w, "Ошибка кодирования JSON: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// Ответ на ошибку оценок: 400 для неверной оценки или своего ответа, 403 для голоса не своим токеном,
+// 409 для повторного голоса, 404 если ответа нет.
+func answerVoteError(w http.ResponseWriter, err error, msg string, status int) {
+	switch {
+	case errors.Is(err, service.ErrInvalidFeedback), errors.Is(err, service.ErrOwnAnswerVote):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, service.ErrVoteForbidden):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case errors.Is(err, service.ErrAlreadyVoted):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, sql.ErrNoRows):
+		http.Error(w, "Ответ или голос не найден", http.StatusNotFound)
+	default:
+		serviceError(w, err, msg, status)
+	}
+}
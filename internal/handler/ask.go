@@ -0,0 +1,226 @@
+package handler
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"knowledge-base/internal/models"
+	"knowledge-base/internal/service"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// Структура для работы со всеми ф-ями handler/ask.go.
+type AskHandler struct {
+	askService *service.AskService
+}
+
+// Функция для создания объекта типа AskHandler.
+func NewAskHandler(askService *service.AskService) *AskHandler {
+	return &AskHandler{askService: askService}
+}
+
+// @Summary Ask a free-text question
+// @Description Finds the existing question most similar to the input (trigram cosine similarity) and returns its answer with a confidence score and alternatives. If the confidence is below ASK_CONFIDENCE_THRESHOLD, no answer is returned and the query is recorded in the unanswered demand list
+// @Tags ask
+// @Accept json
+// @Produce json
+// @Param question body models.AskRequestBody true "Free-text question (up to 1000 characters)"
+// @Success 200 {object} models.AskResult
+// @Failure 400 {string} string "Empty or too long question"
+// @Failure 413 {string} string "Request body too large"
+// @Failure 415 {string} string "Content-Type must be application/json"
+// @Failure 501 {string} string "pg_trgm extension is not installed"
+// @Router /ask [post]
+func (askHandler *AskHandler) Ask(w http.ResponseWriter, r *http.Request) {
+
+	var body models.AskRequestBody
+
+	//Преобразование JSON данных в формат структуры models.AskRequestBody.
+	err := decodeJSONBody(w, r, &body)
+	if err != nil {
+		http.Error(w, "Invalid JSON: "+err.Error(), bodyErrorStatus(err))
+		return
+	}
+
+	// Вызов сервиса.
+	result, err := askHandler.askService.Ask(r.Context(), body.Question)
+	if err != nil {
+		askError(w, err, "Ошибка поиска ответа: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Устанавливаем заголовок JSON.
+	w.Header().Set("Content-Type", "application/json")
+
+	// Кодируем результат в JSON формат и возвращаем.
+	err = json.NewEncoder(w).Encode(result)
+	if err != nil {
+		http.Error(w, "Ошибка кодирования JSON: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// @Summary Get unanswered demand
+// @Description Returns free-text questions that got no confident answer, most frequently asked first
+// @Tags ask
+// @Produce json
+// @Param status query string false "open (default), resolved or dismissed"
+// @Param limit query int false "Page size (1-500, default 50)"
+// @Param offset query int false "Number of records to skip"
+// @Success 200 {array} models.UnansweredDemand
+// @Failure 401 {string} string "Tutor or moderator token required"
+// @Header 200 {integer} X-Total-Count "Total number of records"
+// @Header 200 {string} Link "Links to next and prev pages"
+// @Failure 400 {string} string "Invalid status or parameter"
+// @Router /unanswered [get]
+func (askHandler *AskHandler) GetUnanswered(w http.ResponseWriter, r *http.Request) {
+
+	// Проверка состояния.
+	status := r.URL.Query().Get("status")
+	switch status {
+	case "":
+		status = service.DemandOpen
+	case service.DemandOpen, service.DemandResolved, service.DemandDismissed:
+	default:
+		http.Error(w, "status должен быть open, resolved или dismissed", http.StatusBadRequest)
+		return
+	}
+
+	// Разбор страницы.
+	page, err := parseListParams(r, service.ListSpec{})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Вызов сервиса.
+	demand, total, err := askHandler.askService.GetDemand(r.Context(), status, page.Limit, page.Offset)
+	if err != nil {
+		serviceError(w, err, "Ошибка получения вопросов без ответа: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Устанавливаем заголовок JSON.
+	w.Header().Set("Content-Type", "application/json")
+
+	// Заголовки с общим количеством и ссылками на соседние страницы.
+	writeListHeaders(w, r, page, service.PageInfo{Total: total})
+
+	// Кодируем результат в JSON формат и возвращаем.
+	err = json.NewEncoder(w).Encode(demand)
+	if err != nil {
+		http.Error(w, "Ошибка кодирования JSON: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// @Summary Create a question from unanswered demand
+// @Description Creates a new question (with its first version) from an open unanswered query and marks the query as resolved. The question text defaults to the query text
+// @Tags ask
+// @Accept json
+// @Produce json
+// @Param id path int true "Unanswered demand ID"
+// @Param question body models.DemandQuestionRequestBody true "Question text and author"
+// @Success 201 {object} models.UnansweredDemand
+// @Failure 400 {string} string "Invalid ID or request"
+// @Failure 401 {string} string "Tutor or moderator token required"
+// @Failure 404 {string} string "Unanswered demand not found"
+// @Failure 409 {string} string "Already resolved or dismissed, or question with this text exists"
+// @Router /unanswered/{id}/question [post]
+func (askHandler *AskHandler) CreateQuestionFromUnanswered(w http.ResponseWriter, r *http.Request) {
+
+	//Разбиение пути handler на части.
+	vars := mux.Vars(r)
+	idStr := vars["id"]
+
+	//Преобразование строки в число.
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		http.Error(w, "Неверный ID", http.StatusBadRequest)
+		return
+	}
+
+	var body models.DemandQuestionRequestBody
+
+	//Преобразование JSON данных в формат структуры models.DemandQuestionRequestBody.
+	err = decodeJSONBody(w, r, &body)
+	if err != nil {
+		http.Error(w, "Invalid JSON: "+err.Error(), bodyErrorStatus(err))
+		return
+	}
+
+	// Вызов сервиса.
+	demand, err := askHandler.askService.CreateQuestion(r.Context(), id, body.QuestionText, body.TutorID)
+	if err != nil {
+		askError(w, err, "Ошибка создания вопроса: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Устанавливаем заголовок JSON.
+	w.Header().Set("Content-Type", "application/json")
+
+	//Возврат кода операции.
+	w.WriteHeader(http.StatusCreated)
+
+	// Кодируем результат в JSON формат.
+	json.NewEncoder(w).Encode(demand)
+}
+
+// @Summary Dismiss unanswered demand
+// @Description Marks an open unanswered query as dismissed
+// @Tags ask
+// @Produce json
+// @Param id path int true "Unanswered demand ID"
+// @Success 200 {object} models.UnansweredDemand
+// @Failure 400 {string} string "Invalid ID"
+// @Failure 401 {string} string "Tutor or moderator token required"
+// @Failure 404 {string} string "Unanswered demand not found"
+// @Failure 409 {string} string "Already resolved or dismissed"
+// @Router /unanswered/{id}/dismiss [post]
+func (askHandler *AskHandler) DismissUnanswered(w http.ResponseWriter, r *http.Request) {
+
+	//Разбиение пути handler на части.
+	vars := mux.Vars(r)
+	idStr := vars["id"]
+
+	//Преобразование строки в число.
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		http.Error(w, "Неверный ID", http.StatusBadRequest)
+		return
+	}
+
+	// Вызов сервиса.
+	demand, err := askHandler.askService.Dismiss(r.Context(), id)
+	if err != nil {
+		askError(w, err, "Ошибка отклонения запроса: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Устанавливаем заголовок JSON.
+	w.Header().Set("Content-Type", "application/json")
+
+	// Кодируем результат в JSON формат и возвращаем.
+	err = json.NewEncoder(w).Encode(demand)
+	if err != nil {
+		http.Error(w, "Ошибка кодирования JSON: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// Ответ на ошибку сервиса свободных вопросов: 400 для неверного вопроса, 404 и 409 для запросов без ответа.
+func askError(w http.ResponseWriter, err error, msg string, status int) {
+	switch {
+	case errors.Is(err, service.ErrInvalidAsk):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, service.ErrDemandClosed), errors.Is(err, service.ErrQuestionExists):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, sql.ErrNoRows):
+		http.Error(w, "Запрос не найден", http.StatusNotFound)
+	default:
+		serviceError(w, err, msg, status)
+	}
+}
@@ -0,0 +1,103 @@
+package handler
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Cookie с ID клиента, который выдает сервер. ID подписан солью, поэтому клиент не может выбрать его сам.
+const (
+	clientCookieName   = "kb_client"
+	clientCookieMaxAge = 365 * 24 * time.Hour
+)
+
+// Соль для обезличивания клиентов и подписи cookie. Без настройки генерируется при запуске.
+var clientIDSalt = randomHex()
+
+// SetClientIDSalt задает соль для обезличивания клиентов. Пустая соль заменяется случайной.
+func SetClientIDSalt(salt string) {
+	if salt == "" {
+		salt = randomHex()
+	}
+	clientIDSalt = salt
+}
+
+// Случайные 16 байт в hex: ID поиска и соль по умолчанию.
+func randomHex() string {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(buf)
+}
+
+// Подпись ID клиента для cookie.
+func clientSignature(id string) string {
+	mac := hmac.New(sha256.New, []byte(clientIDSalt))
+	mac.Write([]byte(id))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// ID из cookie запроса, если подпись верна.
+func cookieClientID(r *http.Request) (string, bool) {
+	cookie, err := r.Cookie(clientCookieName)
+	if err != nil {
+		return "", false
+	}
+
+	id, signature, ok := strings.Cut(cookie.Value, ".")
+	if !ok || id == "" || !hmac.Equal([]byte(signature), []byte(clientSignature(id))) {
+		return "", false
+	}
+	return id, true
+}
+
+// Адрес клиента без порта.
+func clientHost(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return host
+}
+
+// Хэш с солью, сам источник не сохраняется.
+func hashClientID(source string) string {
+	sum := sha256.Sum256([]byte(clientIDSalt + "|" + source))
+	return hex.EncodeToString(sum[:16])
+}
+
+// Обезличенный ID клиента: хэш с солью от ID из подписанной cookie, а без нее от адреса и User-Agent.
+// Заголовкам клиента не доверяем: иначе каждый запрос с новым значением был бы новым клиентом.
+// Используется в журнале поиска, для защиты от повторных оценок ответов и для правки своих комментариев.
+func clientID(r *http.Request) string {
+	if id, ok := cookieClientID(r); ok {
+		return hashClientID("cookie|" + id)
+	}
+	return hashClientID(clientHost(r) + "|" + r.UserAgent())
+}
+
+// ID клиента для записи от его имени (оценка, комментарий). Клиенту без cookie выдается новая,
+// и уже этот запрос записывается под ее ID, чтобы следующие запросы клиента совпали с ним.
+func issueClientID(w http.ResponseWriter, r *http.Request) string {
+	if _, ok := cookieClientID(r); ok {
+		return clientID(r)
+	}
+
+	id := randomHex()
+	http.SetCookie(w, &http.Cookie{
+		Name:     clientCookieName,
+		Value:    id + "." + clientSignature(id),
+		Path:     "/",
+		MaxAge:   int(clientCookieMaxAge / time.Second),
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+	return hashClientID("cookie|" + id)
+}
@@ -0,0 +1,62 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClientID(t *testing.T) {
+	SetClientIDSalt("test-salt")
+
+	issued := httptest.NewRecorder()
+	issuedID := issueClientID(issued, httptest.NewRequest(http.MethodPost, "/answers/1/feedback", nil))
+	cookies := issued.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].Name != clientCookieName || !cookies[0].HttpOnly {
+		t.Fatalf("issueClientID cookies = %v, want one HttpOnly %s cookie", cookies, clientCookieName)
+	}
+	cookie := cookies[0]
+
+	request := func(remoteAddr string, userAgent string, clientHeader string, cookie *http.Cookie) *http.Request {
+		r := httptest.NewRequest(http.MethodPost, "/answers/1/feedback", nil)
+		r.RemoteAddr = remoteAddr
+		r.Header.Set("User-Agent", userAgent)
+		if clientHeader != "" {
+			r.Header.Set("X-Client-ID", clientHeader)
+		}
+		if cookie != nil {
+			r.AddCookie(cookie)
+		}
+		return r
+	}
+	byAddress := clientID(request("10.0.0.1:5000", "curl", "", nil))
+
+	tests := []struct {
+		name string
+		r    *http.Request
+		want string
+	}{
+		{name: "выданная cookie", r: request("10.0.0.2:6000", "firefox", "", cookie), want: issuedID},
+		{name: "другой порт того же адреса", r: request("10.0.0.1:6000", "curl", "", nil), want: byAddress},
+		{name: "заголовок X-Client-ID не учитывается", r: request("10.0.0.1:5000", "curl", "forged", nil), want: byAddress},
+		{name: "подделанный ID в cookie", r: request("10.0.0.1:5000", "curl", "", &http.Cookie{Name: clientCookieName, Value: "0123." + cookie.Value[len(cookie.Value)-64:]}), want: byAddress},
+		{name: "cookie без подписи", r: request("10.0.0.1:5000", "curl", "", &http.Cookie{Name: clientCookieName, Value: "0123"}), want: byAddress},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := clientID(tt.r); got != tt.want {
+				t.Errorf("clientID() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	// Клиент с действующей cookie не получает новую.
+	repeated := httptest.NewRecorder()
+	if got := issueClientID(repeated, request("10.0.0.3:7000", "chrome", "", cookie)); got != issuedID {
+		t.Errorf("issueClientID() with cookie = %q, want %q", got, issuedID)
+	}
+	if cookies := repeated.Result().Cookies(); len(cookies) != 0 {
+		t.Errorf("issueClientID() with cookie set %v, want no new cookie", cookies)
+	}
+}
@@ -0,0 +1,399 @@
+package handler
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"knowledge-base/internal/models"
+	"knowledge-base/internal/service"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// Структура для работы со всеми ф-ями handler/comment.go.
+type CommentHandler struct {
+	commentService *service.CommentService
+}
+
+// Функция для создания объекта типа CommentHandler.
+func NewCommentHandler(commentService *service.CommentService) *CommentHandler {
+	return &CommentHandler{commentService: commentService}
+}
+
+// @Summary Get comments of question
+// @Description Returns the comment threads of the question: top-level comments in creation order with nested replies. Deleted comments stay in the thread with empty text
+// @Tags comments
+// @Produce json
+// @Param id path int true "Question ID"
+// @Success 200 {array} models.Comment
+// @Failure 400 {string} string "Invalid question ID"
+// @Failure 404 {string} string "Question not found"
+// @Router /questions/{id}/comments [get]
+func (commentHandler *CommentHandler) GetQuestionComments(w http.ResponseWriter, r *http.Request) {
+	commentHandler.getThread(w, r, service.CommentOnQuestion)
+}
+
+// @Summary Get comments of answer
+// @Description Returns the comment threads of the answer: top-level comments in creation order with nested replies. Deleted comments stay in the thread with empty text
+// @Tags comments
+// @Produce json
+// @Param id path int true "Answer ID"
+// @Success 200 {array} models.Comment
+// @Failure 400 {string} string "Invalid answer ID"
+// @Failure 404 {string} string "Answer not found"
+// @Router /answers/{id}/comments [get]
+func (commentHandler *CommentHandler) GetAnswerComments(w http.ResponseWriter, r *http.Request) {
+	commentHandler.getThread(w, r, service.CommentOnAnswer)
+}
+
+// @Summary Comment on question
+// @Description Adds a comment or a reply (parent_id) to the question. With tutor_id the author is the tutor, otherwise a learner identified by an anonymized client ID from a signed kb_client cookie issued by the server (or IP address and User-Agent). Tutors mentioned as @email or @Full Name (or @Full_Name) are recorded in mentions
+// @Tags comments
+// @Accept json
+// @Produce json
+// @Param id path int true "Question ID"
+// @Param comment body models.CommentRequestBody true "Comment text (up to 5000 characters), author and parent comment"
+// @Success 201 {object} models.Comment
+// @Failure 400 {string} string "Invalid request, unknown tutor or parent comment"
+// @Failure 404 {string} string "Question not found"
+// @Failure 409 {string} string "Parent comment is deleted"
+// @Failure 413 {string} string "Request body too large"
+// @Failure 415 {string} string "Content-Type must be application/json"
+// @Router /questions/{id}/comments [post]
+func (commentHandler *CommentHandler) PostQuestionComment(w http.ResponseWriter, r *http.Request) {
+	commentHandler.postComment(w, r, service.CommentOnQuestion)
+}
+
+// @Summary Comment on answer
+// @Description Adds a comment or a reply (parent_id) to the answer. With tutor_id the author is the tutor, otherwise a learner identified by an anonymized client ID from a signed kb_client cookie issued by the server (or IP address and User-Agent). Tutors mentioned as @email or @Full Name (or @Full_Name) are recorded in mentions
+// @Tags comments
+// @Accept json
+// @Produce json
+// @Param id path int true "Answer ID"
+// @Param comment body models.CommentRequestBody true "Comment text (up to 5000 characters), author and parent comment"
+// @Success 201 {object} models.Comment
+// @Failure 400 {string} string "Invalid request, unknown tutor or parent comment"
+// @Failure 404 {string} string "Answer not found"
+// @Failure 409 {string} string "Parent comment is deleted"
+// @Failure 413 {string} string "Request body too large"
+// @Failure 415 {string} string "Content-Type must be application/json"
+// @Router /answers/{id}/comments [post]
+func (commentHandler *CommentHandler) PostAnswerComment(w http.ResponseWriter, r *http.Request) {
+	commentHandler.postComment(w, r, service.CommentOnAnswer)
+}
+
+// @Summary Get comment by ID
+// @Description Returns the comment without its replies
+// @Tags comments
+// @Produce json
+// @Param id path int true "Comment ID"
+// @Success 200 {object} models.Comment
+// @Failure 400 {string} string "Invalid ID"
+// @Failure 404 {string} string "Comment not found"
+// @Router /comments/{id} [get]
+func (commentHandler *CommentHandler) GetCommentByID(w http.ResponseWriter, r *http.Request) {
+
+	//Разбиение пути handler на части.
+	vars := mux.Vars(r)
+	idStr := vars["id"]
+
+	//Преобразование строк в число.
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		http.Error(w, "Неверный ID", http.StatusBadRequest)
+		return
+	}
+
+	// Вызов сервиса.
+	comment, err := commentHandler.commentService.GetByID(r.Context(), id)
+	if err != nil {
+		commentError(w, err, "Ошибка получения комментария: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Устанавливаем заголовок JSON.
+	w.Header().Set("Content-Type", "application/json")
+
+	// Кодируем результат в JSON фомат и возвращаем.
+	err = json.NewEncoder(w).Encode(comment)
+	if err != nil {
+		http.Error(w, "Ошибка кодирования JSON: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// @Summary Edit comment
+// @Description Changes the comment text and its mentions and marks the comment as edited. A tutor comment is edited by its tutor (tutor_id), a learner comment without tutor_id from the same client
+// @Tags comments
+// @Accept json
+// @Produce json
+// @Param id path int true "Comment ID"
+// @Param comment body models.CommentUpdateRequestBody true "New text (up to 5000 characters) and author"
+// @Success 200 {object} models.Comment
+// @Failure 400 {string} string "Invalid request"
+// @Failure 403 {string} string "Not the author of the comment"
+// @Failure 404 {string} string "Comment not found"
+// @Failure 409 {string} string "Comment is deleted"
+// @Failure 413 {string} string "Request body too large"
+// @Failure 415 {string} string "Content-Type must be application/json"
+// @Router /comments/{id} [put]
+func (commentHandler *CommentHandler) PutComment(w http.ResponseWriter, r *http.Request) {
+
+	//Разбиение пути handler на части.
+	vars := mux.Vars(r)
+	idStr := vars["id"]
+
+	//Преобразование строк в число.
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		http.Error(w, "Неверный ID", http.StatusBadRequest)
+		return
+	}
+
+	var body models.CommentUpdateRequestBody
+
+	//Преобразование JSON данных в формат структуры models.CommentUpdateRequestBody.
+	err = decodeJSONBody(w, r, &body)
+	if err != nil {
+		http.Error(w, "Invalid JSON: "+err.Error(), bodyErrorStatus(err))
+		return
+	}
+
+	// Вызов сервиса.
+	comment, err := commentHandler.commentService.Update(r.Context(), id, body.Text, body.TutorID, clientID(r))
+	if err != nil {
+		commentError(w, err, "Ошибка изменения комментария: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Устанавливаем заголовок JSON.
+	w.Header().Set("Content-Type", "application/json")
+
+	// Кодируем результат в JSON фомат и возвращаем.
+	err = json.NewEncoder(w).Encode(comment)
+	if err != nil {
+		http.Error(w, "Ошибка кодирования JSON: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// @Summary Delete comment by tutor
+// @Description Soft-deletes the comment and records the tutor who deleted it. The comment stays in the thread with empty text so that its replies are kept
+// @Tags comments
+// @Param id path int true "Comment ID"
+// @Param tutor_id path int true "Tutor ID"
+// @Success 204 "Comment deleted"
+// @Failure 400 {string} string "Invalid ID"
+// @Failure 401 {string} string "Tutor or moderator token required"
+// @Failure 404 {string} string "Comment not found"
+// @Failure 409 {string} string "Comment is already deleted"
+// @Router /comments/{id}/deleteBy/{tutor_id} [delete]
+func (commentHandler *CommentHandler) DeleteCommentByTutor(w http.ResponseWriter, r *http.Request) {
+
+	//Разбиение пути handler на части.
+	vars := mux.Vars(r)
+	idStr := vars["id"]
+	tutorIDStr := vars["tutor_id"]
+
+	//Преобразование строк в число.
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		http.Error(w, "Неверный ID", http.StatusBadRequest)
+		return
+	}
+	tutorID, err := strconv.Atoi(tutorIDStr)
+	if err != nil {
+		http.Error(w, "Неверный ID тьютора", http.StatusBadRequest)
+		return
+	}
+
+	// Вызов сервиса.
+	err = commentHandler.commentService.DeleteByTutor(r.Context(), id, tutorID)
+	if err != nil {
+		commentError(w, err, "Ошибка удаления комментария: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// @Summary Delete own comment
+// @Description Soft-deletes a learner comment from the same client that wrote it (kb_client cookie or IP address and User-Agent)
+// @Tags comments
+// @Param id path int true "Comment ID"
+// @Success 204 "Comment deleted"
+// @Failure 400 {string} string "Invalid ID"
+// @Failure 403 {string} string "Not the author of the comment"
+// @Failure 404 {string} string "Comment not found"
+// @Failure 409 {string} string "Comment is already deleted"
+// @Router /comments/{id} [delete]
+func (commentHandler *CommentHandler) DeleteOwnComment(w http.ResponseWriter, r *http.Request) {
+
+	//Разбиение пути handler на части.
+	vars := mux.Vars(r)
+	idStr := vars["id"]
+
+	//Преобразование строк в число.
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		http.Error(w, "Неверный ID", http.StatusBadRequest)
+		return
+	}
+
+	// Вызов сервиса.
+	err = commentHandler.commentService.DeleteOwn(r.Context(), id, clientID(r))
+	if err != nil {
+		commentError(w, err, "Ошибка удаления комментария: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// @Summary Get comments mentioning tutor
+// @Description Returns comments where the tutor is mentioned, newest first. Deleted comments are not listed. X-Total-Count and Link headers describe the pages
+// @Tags comments
+// @Produce json
+// @Param id path int true "Tutor ID"
+// @Param limit query int false "Page size (1-500, default 50)"
+// @Param offset query int false "Number of records to skip"
+// @Success 200 {array} models.Comment
+// @Failure 400 {string} string "Invalid ID or page"
+// @Router /tutors/{id}/mentions [get]
+func (commentHandler *CommentHandler) GetTutorMentions(w http.ResponseWriter, r *http.Request) {
+
+	//Разбиение пути handler на части.
+	vars := mux.Vars(r)
+	idStr := vars["id"]
+
+	//Преобразование строк в число.
+	tutorID, err := strconv.Atoi(idStr)
+	if err != nil {
+		http.Error(w, "Неверный ID тьютора", http.StatusBadRequest)
+		return
+	}
+
+	// Разбор страницы.
+	page, err := parseListParams(r, service.ListSpec{})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Вызов сервиса.
+	comments, total, err := commentHandler.commentService.GetMentions(r.Context(), tutorID, page.Limit, page.Offset)
+	if err != nil {
+		serviceError(w, err, "Ошибка получения упоминаний: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Устанавливаем заголовок JSON.
+	w.Header().Set("Content-Type", "application/json")
+
+	// Заголовки с общим количеством и ссылками на соседние страницы.
+	writeListHeaders(w, r, page, service.PageInfo{Total: total})
+
+	// Кодируем результат в JSON формат и возвращаем.
+	err = json.NewEncoder(w).Encode(comments)
+	if err != nil {
+		http.Error(w, "Ошибка кодирования JSON: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// Комментарии вопроса или ответа деревом.
+func (commentHandler *CommentHandler) getThread(w http.ResponseWriter, r *http.Request, kind string) {
+
+	//Разбиение пути handler на части.
+	vars := mux.Vars(r)
+	idStr := vars["id"]
+
+	//Преобразование строк в число.
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		http.Error(w, "Неверный ID", http.StatusBadRequest)
+		return
+	}
+
+	// Вызов сервиса.
+	comments, err := commentHandler.commentService.GetThread(r.Context(), service.CommentTarget{Kind: kind, ID: id})
+	if err != nil {
+		commentError(w, err, "Ошибка получения комментариев: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Устанавливаем заголовок JSON.
+	w.Header().Set("Content-Type", "application/json")
+
+	// Кодируем результат в JSON фомат и возвращаем.
+	err = json.NewEncoder(w).Encode(comments)
+	if err != nil {
+		http.Error(w, "Ошибка кодирования JSON: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// Новый комментарий к вопросу или ответу.
+func (commentHandler *CommentHandler) postComment(w http.ResponseWriter, r *http.Request, kind string) {
+
+	//Разбиение пути handler на части.
+	vars := mux.Vars(r)
+	idStr := vars["id"]
+
+	//Преобразование строк в число.
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		http.Error(w, "Неверный ID", http.StatusBadRequest)
+		return
+	}
+
+	var body models.CommentRequestBody
+
+	//Преобразование JSON данных в формат структуры models.CommentRequestBody.
+	err = decodeJSONBody(w, r, &body)
+	if err != nil {
+		http.Error(w, "Invalid JSON: "+err.Error(), bodyErrorStatus(err))
+		return
+	}
+
+	// Вызов сервиса.
+	comment, err := commentHandler.commentService.Create(r.Context(), service.CommentTarget{Kind: kind, ID: id}, service.CommentInput{
+		Text:        body.Text,
+		TutorID:     body.TutorID,
+		LearnerName: body.LearnerName,
+		ClientID:    issueClientID(w, r),
+		ParentID:    body.ParentID,
+	})
+	if err != nil {
+		commentError(w, err, "Ошибка добавления комментария: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Устанавливаем заголовок JSON.
+	w.Header().Set("Content-Type", "application/json")
+
+	//Возврат кода операции.
+	w.WriteHeader(http.StatusCreated)
+
+	// Кодируем результат в JSON фомат.
+	json.NewEncoder(w).Encode(comment)
+}
+
+// Ответ на ошибку комментариев: 400 для неверного комментария, 403 если изменяет не автор,
+// 409 для удаленного комментария, 404 если комментария, вопроса или ответа нет.
+func commentError(w http.ResponseWriter, err error, msg string, status int) {
+	switch {
+	case errors.Is(err, service.ErrInvalidComment):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, service.ErrCommentForbidden):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case errors.Is(err, service.ErrCommentDeleted):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, sql.ErrNoRows):
+		http.Error(w, "Комментарий не найден", http.StatusNotFound)
+	default:
+		serviceError(w, err, msg, status)
+	}
+}
@@ -0,0 +1,110 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// Настройки разбора JSON тела запроса, общие для всех хэндлеров.
+type DecodeOptions struct {
+	// Максимальный размер тела запроса в байтах.
+	MaxBytes int64
+
+	// Разрешать ли неизвестные поля (мягкий режим).
+	AllowUnknownFields bool
+}
+
+// Настройки по умолчанию: 1 МБ и строгая проверка полей.
+var decodeOptions = DecodeOptions{MaxBytes: 1 << 20}
+
+// SetDecodeOptions задает настройки разбора тела запроса для всех хэндлеров.
+func SetDecodeOptions(options DecodeOptions) {
+	if options.MaxBytes <= 0 {
+		options.MaxBytes = decodeOptions.MaxBytes
+	}
+	decodeOptions = options
+}
+
+// Ошибка разбора тела запроса с HTTP статусом, который надо вернуть клиенту.
+type bodyError struct {
+	status int
+	msg    string
+}
+
+func (e *bodyError) Error() string {
+	return e.msg
+}
+
+// Возвращает HTTP статус для ошибки разбора тела запроса.
+func bodyErrorStatus(err error) int {
+	var bodyErr *bodyError
+	if errors.As(err, &bodyErr) {
+		return bodyErr.status
+	}
+	return http.StatusBadRequest
+}
+
+// Разбирает JSON тело запроса в dst.
+// Проверяет Content-Type, размер тела, неизвестные поля и то, что в теле ровно один JSON объект.
+func decodeJSONBody(w http.ResponseWriter, r *http.Request, dst interface{}) error {
+
+	// Проверка заголовка Content-Type.
+	contentType := r.Header.Get("Content-Type")
+	if contentType == "" {
+		return &bodyError{status: http.StatusUnsupportedMediaType, msg: "Content-Type header must be application/json"}
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil || mediaType != "application/json" {
+		return &bodyError{status: http.StatusUnsupportedMediaType, msg: "Content-Type header must be application/json"}
+	}
+
+	// Ограничение размера тела, чтобы не читать в память слишком большие запросы.
+	r.Body = http.MaxBytesReader(w, r.Body, decodeOptions.MaxBytes)
+
+	decoder := json.NewDecoder(r.Body)
+	if !decodeOptions.AllowUnknownFields {
+		decoder.DisallowUnknownFields()
+	}
+
+	err = decoder.Decode(dst)
+	if err != nil {
+		var syntaxErr *json.SyntaxError
+		var typeErr *json.UnmarshalTypeError
+		var maxBytesErr *http.MaxBytesError
+
+		switch {
+		case errors.As(err, &maxBytesErr):
+			return &bodyError{status: http.StatusRequestEntityTooLarge, msg: fmt.Sprintf("request body must not be larger than %d bytes", maxBytesErr.Limit)}
+		case errors.As(err, &syntaxErr):
+			return &bodyError{status: http.StatusBadRequest, msg: fmt.Sprintf("malformed JSON at position %d", syntaxErr.Offset)}
+		case errors.Is(err, io.ErrUnexpectedEOF):
+			return &bodyError{status: http.StatusBadRequest, msg: "malformed JSON"}
+		case errors.As(err, &typeErr):
+			return &bodyError{status: http.StatusBadRequest, msg: fmt.Sprintf("invalid value for field %q", typeErr.Field)}
+		case strings.HasPrefix(err.Error(), "json: unknown field "):
+			field := strings.TrimPrefix(err.Error(), "json: unknown field ")
+			return &bodyError{status: http.StatusBadRequest, msg: "unknown field " + field}
+		case errors.Is(err, io.EOF):
+			return &bodyError{status: http.StatusBadRequest, msg: "request body must not be empty"}
+		default:
+			return &bodyError{status: http.StatusBadRequest, msg: err.Error()}
+		}
+	}
+
+	// Проверка, что после объекта в теле ничего нет.
+	err = decoder.Decode(&struct{}{})
+	if err != io.EOF {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			return &bodyError{status: http.StatusRequestEntityTooLarge, msg: fmt.Sprintf("request body must not be larger than %d bytes", maxBytesErr.Limit)}
+		}
+		return &bodyError{status: http.StatusBadRequest, msg: "request body must contain a single JSON object"}
+	}
+
+	return nil
+}
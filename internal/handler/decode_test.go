@@ -0,0 +1,73 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestDecodeJSONBody(t *testing.T) {
+	type body struct {
+		Text  string `json:"text"`
+		Count int    `json:"count"`
+	}
+
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		options     DecodeOptions
+
+		// 0 - тело разобрано без ошибки.
+		status int
+	}{
+		{name: "объект", contentType: "application/json", body: `{"text": "go", "count": 2}`},
+		{name: "Content-Type с кодировкой", contentType: "application/json; charset=utf-8", body: `{"text": "go"}`},
+		{name: "без Content-Type", contentType: "", body: `{"text": "go"}`, status: http.StatusUnsupportedMediaType},
+		{name: "другой Content-Type", contentType: "text/plain", body: `{"text": "go"}`, status: http.StatusUnsupportedMediaType},
+		{name: "неверный Content-Type", contentType: "application/json; =", body: `{"text": "go"}`, status: http.StatusUnsupportedMediaType},
+		{name: "слишком большое тело", contentType: "application/json", body: `{"text": "` + strings.Repeat("a", 100) + `"}`, options: DecodeOptions{MaxBytes: 32}, status: http.StatusRequestEntityTooLarge},
+		{name: "данные после объекта сверх лимита", contentType: "application/json", body: `{"text": "go"}` + strings.Repeat(" ", 40) + `{}`, options: DecodeOptions{MaxBytes: 32}, status: http.StatusRequestEntityTooLarge},
+		{name: "неизвестное поле", contentType: "application/json", body: `{"text": "go", "extra": 1}`, status: http.StatusBadRequest},
+		{name: "неизвестное поле в мягком режиме", contentType: "application/json", body: `{"text": "go", "extra": 1}`, options: DecodeOptions{AllowUnknownFields: true}},
+		{name: "второй объект", contentType: "application/json", body: `{"text": "go"}{"text": "sql"}`, status: http.StatusBadRequest},
+		{name: "мусор после объекта", contentType: "application/json", body: `{"text": "go"} x`, status: http.StatusBadRequest},
+		{name: "пустое тело", contentType: "application/json", body: ``, status: http.StatusBadRequest},
+		{name: "синтаксическая ошибка", contentType: "application/json", body: `{"text": }`, status: http.StatusBadRequest},
+		{name: "обрезанный JSON", contentType: "application/json", body: `{"text": "go"`, status: http.StatusBadRequest},
+		{name: "неверный тип поля", contentType: "application/json", body: `{"count": "два"}`, status: http.StatusBadRequest},
+	}
+
+	defer SetDecodeOptions(decodeOptions)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decodeOptions = DecodeOptions{MaxBytes: 1 << 20}
+			SetDecodeOptions(tt.options)
+
+			r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body))
+			if tt.contentType != "" {
+				r.Header.Set("Content-Type", tt.contentType)
+			}
+
+			var dst body
+			err := decodeJSONBody(httptest.NewRecorder(), r, &dst)
+			if tt.status == 0 {
+				if err != nil {
+					t.Fatalf("decodeJSONBody() error = %v", err)
+				}
+				if dst.Text != "go" {
+					t.Errorf("decodeJSONBody() text = %q, want %q", dst.Text, "go")
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("decodeJSONBody() = nil, want status %d", tt.status)
+			}
+			if status := bodyErrorStatus(err); status != tt.status {
+				t.Errorf("bodyErrorStatus(%v) = %d, want %d", err, status, tt.status)
+			}
+		})
+	}
+}
@@ -0,0 +1,26 @@
+package handler
+
+import (
+	"errors"
+	"knowledge-base/internal/service"
+	"net/http"
+)
+
+// Нестандартный статус "клиент закрыл соединение" (используется nginx).
+const statusClientClosedRequest = 499
+
+// Записывает ответ на ошибку сервиса.
+// Для прерванных запросов к БД возвращается отдельный статус, для функций, которым нужно расширение pg_trgm, - 501,
+// для остальных ошибок - переданные сообщение и статус.
+func serviceError(w http.ResponseWriter, err error, msg string, status int) {
+	switch {
+	case errors.Is(err, service.ErrTrigramUnavailable):
+		http.Error(w, err.Error(), http.StatusNotImplemented)
+	case errors.Is(err, service.ErrTimeout):
+		http.Error(w, "Превышено время ожидания ответа БД", http.StatusGatewayTimeout)
+	case errors.Is(err, service.ErrCanceled):
+		http.Error(w, "Запрос отменен", statusClientClosedRequest)
+	default:
+		http.Error(w, msg, status)
+	}
+}
@@ -0,0 +1,177 @@
+package handler
+
+import (
+	"fmt"
+	"knowledge-base/internal/service"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Размер страницы списка по умолчанию и максимальный.
+const (
+	defaultListLimit = 50
+	maxListLimit     = 500
+)
+
+// Разбирает query параметры списка: limit, offset, cursor, sort и фильтры из spec.
+// Сортировка по убыванию задается минусом перед именем поля: sort=-created_at.
+// Курсор задает и сортировку, поэтому sort вместе с ним должен совпадать с сортировкой курсора.
+func parseListParams(r *http.Request, spec service.ListSpec) (service.ListParams, error) {
+	query := r.URL.Query()
+
+	params := service.ListParams{
+		Limit:   defaultListLimit,
+		Sort:    spec.DefaultSort,
+		Filters: map[string]interface{}{},
+	}
+
+	// Размер страницы.
+	if value := query.Get("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > maxListLimit {
+			return service.ListParams{}, fmt.Errorf("limit должен быть числом от 1 до %d", maxListLimit)
+		}
+		params.Limit = limit
+	}
+
+	// Смещение от начала списка.
+	if value := query.Get("offset"); value != "" {
+		offset, err := strconv.Atoi(value)
+		if err != nil || offset < 0 {
+			return service.ListParams{}, fmt.Errorf("offset должен быть неотрицательным числом")
+		}
+		params.Offset = offset
+	}
+
+	// Поле и направление сортировки.
+	if value := query.Get("sort"); value != "" {
+		field := strings.TrimPrefix(value, "-")
+		if _, ok := spec.Sort[field]; !ok {
+			return service.ListParams{}, fmt.Errorf("сортировка по полю %q не поддерживается, допустимые поля: %s", field, strings.Join(sortFields(spec), ", "))
+		}
+		params.Sort = field
+		params.Desc = strings.HasPrefix(value, "-")
+	}
+
+	// Курсор страницы из ссылок Link предыдущего ответа.
+	if value := query.Get("cursor"); value != "" {
+		if spec.CursorTypes == nil {
+			return service.ListParams{}, fmt.Errorf("курсорная пагинация для этого списка не поддерживается")
+		}
+		if query.Get("offset") != "" {
+			return service.ListParams{}, fmt.Errorf("cursor и offset нельзя использовать вместе")
+		}
+
+		cursor, err := service.DecodeCursor(value, spec)
+		if err != nil {
+			return service.ListParams{}, err
+		}
+
+		if query.Get("sort") != "" && (cursor.Sort != params.Sort || cursor.Desc != params.Desc) {
+			return service.ListParams{}, fmt.Errorf("sort не совпадает с сортировкой курсора")
+		}
+		params.Sort = cursor.Sort
+		params.Desc = cursor.Desc
+		params.Cursor = &cursor
+	}
+
+	// Фильтры.
+	for name, filter := range spec.Filters {
+		value := query.Get(name)
+		if value == "" {
+			continue
+		}
+
+		parsed, err := parseFilterValue(filter.Kind, value)
+		if err != nil {
+			return service.ListParams{}, fmt.Errorf("неверное значение фильтра %s: %v", name, err)
+		}
+		params.Filters[name] = parsed
+	}
+
+	return params, nil
+}
+
+// Преобразование значения фильтра из строки в тип колонки.
+func parseFilterValue(kind service.FilterKind, value string) (interface{}, error) {
+	switch kind {
+	case service.FilterInt:
+		return strconv.Atoi(value)
+	case service.FilterBool:
+		return strconv.ParseBool(value)
+	case service.FilterStatus:
+		if !service.ValidStatus(value) {
+			return nil, fmt.Errorf("статус должен быть draft, in_review, published или archived")
+		}
+		return value, nil
+	case service.FilterTime:
+		// Принимается полная дата со временем (RFC 3339) или только дата.
+		if parsed, err := time.Parse(time.RFC3339, value); err == nil {
+			return parsed, nil
+		}
+		return time.Parse(time.DateOnly, value)
+	}
+	return nil, fmt.Errorf("неизвестный тип фильтра")
+}
+
+// Поля сортировки списка в алфавитном порядке для сообщения об ошибке.
+func sortFields(spec service.ListSpec) []string {
+	fields := make([]string, 0, len(spec.Sort))
+	for field := range spec.Sort {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// Записывает заголовки пагинации: X-Total-Count и Link со ссылками на соседние страницы.
+// Если сервис вернул курсоры, ссылки строятся по ним, иначе по offset.
+func writeListHeaders(w http.ResponseWriter, r *http.Request, params service.ListParams, page service.PageInfo) {
+	w.Header().Set("X-Total-Count", strconv.Itoa(page.Total))
+
+	var links []string
+
+	// Следующая страница есть, если после текущей остались записи.
+	if page.NextCursor != "" {
+		links = append(links, fmt.Sprintf(`<%s>; rel="next"`, cursorURL(r.URL, params.Limit, page.NextCursor)))
+	} else if params.Cursor == nil && params.Offset+params.Limit < page.Total {
+		links = append(links, fmt.Sprintf(`<%s>; rel="next"`, pageURL(r.URL, params.Limit, params.Offset+params.Limit)))
+	}
+
+	// Предыдущая страница есть, если текущая начинается не с начала списка.
+	if page.PrevCursor != "" {
+		links = append(links, fmt.Sprintf(`<%s>; rel="prev"`, cursorURL(r.URL, params.Limit, page.PrevCursor)))
+	} else if params.Cursor == nil && params.Offset > 0 {
+		prevOffset := params.Offset - params.Limit
+		if prevOffset < 0 {
+			prevOffset = 0
+		}
+		links = append(links, fmt.Sprintf(`<%s>; rel="prev"`, pageURL(r.URL, params.Limit, prevOffset)))
+	}
+
+	if len(links) > 0 {
+		w.Header().Set("Link", strings.Join(links, ", "))
+	}
+}
+
+// Ссылка на страницу списка с теми же фильтрами и сортировкой.
+func pageURL(current *url.URL, limit int, offset int) string {
+	query := current.Query()
+	query.Del("cursor")
+	query.Set("limit", strconv.Itoa(limit))
+	query.Set("offset", strconv.Itoa(offset))
+	return current.Path + "?" + query.Encode()
+}
+
+// Ссылка на страницу списка по курсору с теми же фильтрами и сортировкой.
+func cursorURL(current *url.URL, limit int, cursor string) string {
+	query := current.Query()
+	query.Del("offset")
+	query.Set("limit", strconv.Itoa(limit))
+	query.Set("cursor", cursor)
+	return current.Path + "?" + query.Encode()
+}
@@ -0,0 +1,136 @@
+package handler
+
+import (
+	"knowledge-base/internal/service"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseListParams(t *testing.T) {
+	cursor := service.EncodeCursor(service.Cursor{Sort: "created_at", Desc: true, Key: "2025-01-02 10:20:30", ID: 7})
+
+	tests := []struct {
+		name  string
+		query string
+		spec  service.ListSpec
+		want  service.ListParams
+
+		// Фрагмент ошибки, пустой - ошибки нет.
+		err string
+	}{
+		{
+			name: "по умолчанию",
+			spec: service.QuestionListSpec,
+			want: service.ListParams{Limit: defaultListLimit, Sort: "id", Filters: map[string]interface{}{}},
+		},
+		{
+			name:  "страница и сортировка по убыванию",
+			query: "limit=10&offset=20&sort=-created_at",
+			spec:  service.QuestionListSpec,
+			want:  service.ListParams{Limit: 10, Offset: 20, Sort: "created_at", Desc: true, Filters: map[string]interface{}{}},
+		},
+		{
+			name:  "фильтры по типам",
+			query: "tutor_id=3&is_edit=true&status=published&created_after=2025-01-01&created_before=2025-02-01T10:00:00Z&unknown=1",
+			spec:  service.QuestionListSpec,
+			want: service.ListParams{Limit: defaultListLimit, Sort: "id", Filters: map[string]interface{}{
+				"tutor_id":       3,
+				"is_edit":        true,
+				"status":         "published",
+				"created_after":  time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+				"created_before": time.Date(2025, 2, 1, 10, 0, 0, 0, time.UTC),
+			}},
+		},
+		{
+			name:  "курсор задает сортировку",
+			query: "cursor=" + cursor,
+			spec:  service.QuestionListSpec,
+			want: service.ListParams{Limit: defaultListLimit, Sort: "created_at", Desc: true, Filters: map[string]interface{}{},
+				Cursor: &service.Cursor{Sort: "created_at", Desc: true, Key: "2025-01-02 10:20:30", ID: 7}},
+		},
+		{name: "limit не число", query: "limit=ten", spec: service.QuestionListSpec, err: "limit должен быть числом от 1 до 500"},
+		{name: "limit ноль", query: "limit=0", spec: service.QuestionListSpec, err: "limit должен быть числом"},
+		{name: "limit больше максимума", query: "limit=501", spec: service.QuestionListSpec, err: "limit должен быть числом"},
+		{name: "отрицательный offset", query: "offset=-1", spec: service.QuestionListSpec, err: "offset должен быть неотрицательным числом"},
+		{name: "неизвестное поле сортировки", query: "sort=password", spec: service.QuestionListSpec, err: `сортировка по полю "password" не поддерживается, допустимые поля: created_at, id, question_text, tutor_id`},
+		{name: "неверное число в фильтре", query: "tutor_id=abc", spec: service.QuestionListSpec, err: "неверное значение фильтра tutor_id"},
+		{name: "неверная дата в фильтре", query: "created_after=01.01.2025", spec: service.QuestionListSpec, err: "неверное значение фильтра created_after"},
+		{name: "неверный статус", query: "status=deleted", spec: service.QuestionListSpec, err: "неверное значение фильтра status"},
+		{name: "курсор вместе с offset", query: "offset=10&cursor=" + cursor, spec: service.QuestionListSpec, err: "cursor и offset нельзя использовать вместе"},
+		{name: "sort не совпадает с курсором", query: "sort=id&cursor=" + cursor, spec: service.QuestionListSpec, err: "sort не совпадает с сортировкой курсора"},
+		{name: "подделанный курсор", query: "cursor=abc", spec: service.QuestionListSpec, err: service.ErrInvalidCursor.Error()},
+		{name: "курсор для списка без курсоров", query: "cursor=" + cursor, spec: service.TagListSpec, err: "курсорная пагинация для этого списка не поддерживается"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/questions?"+tt.query, nil)
+
+			got, err := parseListParams(r, tt.spec)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("parseListParams(%q) error = %v, want %q", tt.query, err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseListParams(%q) error = %v", tt.query, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseListParams(%q) =\n%+v\nwant\n%+v", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteListHeaders(t *testing.T) {
+	tests := []struct {
+		name   string
+		params service.ListParams
+		page   service.PageInfo
+		link   string
+	}{
+		{
+			name:   "первая страница",
+			params: service.ListParams{Limit: 10},
+			page:   service.PageInfo{Total: 25},
+			link:   `</questions?limit=10&offset=10&sort=-id>; rel="next"`,
+		},
+		{
+			name:   "последняя страница",
+			params: service.ListParams{Limit: 10, Offset: 20},
+			page:   service.PageInfo{Total: 25},
+			link:   `</questions?limit=10&offset=10&sort=-id>; rel="prev"`,
+		},
+		{
+			name:   "курсоры",
+			params: service.ListParams{Limit: 10, Cursor: &service.Cursor{}},
+			page:   service.PageInfo{Total: 25, NextCursor: "n", PrevCursor: "p"},
+			link:   `</questions?cursor=n&limit=10&sort=-id>; rel="next", </questions?cursor=p&limit=10&sort=-id>; rel="prev"`,
+		},
+		{
+			name:   "одна страница",
+			params: service.ListParams{Limit: 10},
+			page:   service.PageInfo{Total: 5},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			writeListHeaders(w, httptest.NewRequest(http.MethodGet, "/questions?sort=-id&offset=5", nil), tt.params, tt.page)
+
+			if got := w.Header().Get("Link"); got != tt.link {
+				t.Errorf("Link = %q, want %q", got, tt.link)
+			}
+			if got, want := w.Header().Get("X-Total-Count"), strconv.Itoa(tt.page.Total); got != want {
+				t.Errorf("X-Total-Count = %q, want %q", got, want)
+			}
+		})
+	}
+}
@@ -2,10 +2,13 @@ package handler
 
 import (
 	"encoding/json"
+	"fmt"
+	"knowledge-base/internal/logging"
 	"knowledge-base/internal/models"
 	"knowledge-base/internal/service"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/gorilla/mux"
 )
@@ -21,23 +24,45 @@ func NewQuestionHandler(questionService *service.QuestionService) *QuestionHandl
 }
 
 // @Summary Get all questions
-// @Description Returns list of all questions
+// @Description Returns paginated list of questions with sorting and filters
 // @Tags questions
 // @Produce json
+// @Param limit query int false "Page size (1-500, default 50)"
+// @Param offset query int false "Number of records to skip"
+// @Param cursor query string false "Opaque cursor from Link header, sort by id or created_at (cannot be combined with offset)"
+// @Param sort query string false "Sort field (id, created_at, question_text, tutor_id), prefix with - for descending"
+// @Param tutor_id query int false "Filter by tutor ID"
+// @Param created_after query string false "Created after (RFC 3339 or YYYY-MM-DD)"
+// @Param created_before query string false "Created before (RFC 3339 or YYYY-MM-DD)"
+// @Param is_edit query bool false "Filter by edit flag"
+// @Param status query string false "Filter by status: draft, in_review, published or archived. Learners only see published"
 // @Success 200 {array} models.Question
+// @Header 200 {integer} X-Total-Count "Total number of records matching filters"
+// @Header 200 {string} Link "Links to next and prev pages"
+// @Failure 400 {string} string "Invalid pagination, sort or filter parameter"
 // @Router /questions [get]
 func (questionHandler *QuestionHandler) GetAllQuestions(w http.ResponseWriter, r *http.Request) {
 
+	// Разбор параметров пагинации, сортировки и фильтров.
+	params, err := parseListParams(r, service.QuestionListSpec)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	// Вызов сервиса.
-	questions, err := questionHandler.questionService.GetAll()
+	questions, page, err := questionHandler.questionService.GetAll(r.Context(), params)
 	if err != nil {
-		http.Error(w, "Ошибка получения вопросов: "+err.Error(), http.StatusInternalServerError)
+		serviceError(w, err, "Ошибка получения вопросов: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
 
 	// Устанавливаем заголовок JSON.
 	w.Header().Set("Content-Type", "application/json")
 
+	// Заголовки с общим количеством и ссылками на соседние страницы.
+	writeListHeaders(w, r, params, page)
+
 	// Кодируем результат в JSON фомат и возвращаем.
 	err = json.NewEncoder(w).Encode(questions)
 	if err != nil {
@@ -46,13 +71,52 @@ func (questionHandler *QuestionHandler) GetAllQuestions(w http.ResponseWriter, r
 	}
 }
 
+// @Summary Export all questions
+// @Description Streams all questions matching filters as a JSON array or NDJSON (Accept: application/x-ndjson) without pagination
+// @Tags questions
+// @Produce json
+// @Produce application/x-ndjson
+// @Param sort query string false "Sort field (id, created_at, question_text, tutor_id), prefix with - for descending"
+// @Param tutor_id query int false "Filter by tutor ID"
+// @Param created_after query string false "Created after (RFC 3339 or YYYY-MM-DD)"
+// @Param created_before query string false "Created before (RFC 3339 or YYYY-MM-DD)"
+// @Param is_edit query bool false "Filter by edit flag"
+// @Param status query string false "Filter by status: draft, in_review, published or archived. Learners only see published"
+// @Success 200 {array} models.Question
+// @Failure 400 {string} string "Invalid sort or filter parameter"
+// @Failure 500 {string} string "Internal server error"
+// @Router /questions/export [get]
+func (questionHandler *QuestionHandler) ExportQuestions(w http.ResponseWriter, r *http.Request) {
+
+	// Разбор параметров сортировки и фильтров.
+	params, err := parseExportParams(r, service.QuestionListSpec)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Вызов сервиса: каждая запись сразу пишется в ответ.
+	stream := newStreamWriter(w, r)
+	err = questionHandler.questionService.Export(r.Context(), params, func(question models.Question) error {
+		return stream.Write(question)
+	})
+	if err != nil {
+		stream.Fail(r, err, "Ошибка выгрузки вопросов: ")
+		return
+	}
+
+	// Завершение массива и отправка остатка буфера.
+	stream.Close()
+}
+
 // @Summary Get question by ID
-// @Description Returns question by specified ID
+// @Description Returns question by specified ID. With expand the answer (accepted or first), all answers, tags, author, versions and stats are embedded in a fixed number of queries
 // @Tags questions
 // @Produce json
 // @Param id path int true "Question ID"
-// @Success 200 {object} models.Question
-// @Failure 400 {string} string "Invalid ID"
+// @Param expand query string false "Comma-separated expansions: answer, answers, tags, author, versions, stats"
+// @Success 200 {object} models.QuestionDetail
+// @Failure 400 {string} string "Invalid ID or unknown expansion"
 // @Failure 404 {string} string "Question not found"
 // @Router /questions/{id} [get]
 func (questionHandler *QuestionHandler) GetQuestionByID(w http.ResponseWriter, r *http.Request) {
@@ -68,10 +132,16 @@ func (questionHandler *QuestionHandler) GetQuestionByID(w http.ResponseWriter, r
 		return
 	}
 
+	// Вопрос со связанными данными, если они запрошены.
+	if r.URL.Query().Has("expand") {
+		questionHandler.getQuestionDetail(w, r, id)
+		return
+	}
+
 	// Вызов сервиса.
-	question, err := questionHandler.questionService.GetByID(id)
+	question, err := questionHandler.questionService.GetByID(r.Context(), id)
 	if err != nil {
-		http.Error(w, "Вопрос не найден", http.StatusNotFound)
+		serviceError(w, err, "Вопрос не найден", http.StatusNotFound)
 		return
 	}
 
@@ -86,6 +156,62 @@ func (questionHandler *QuestionHandler) GetQuestionByID(w http.ResponseWriter, r
 	}
 }
 
+// Ответ с вопросом и расширениями из query параметра expand.
+func (questionHandler *QuestionHandler) getQuestionDetail(w http.ResponseWriter, r *http.Request, id int) {
+
+	// Разбор списка расширений.
+	expand, err := parseQuestionExpand(r.URL.Query().Get("expand"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Вызов сервиса.
+	detail, err := questionHandler.questionService.GetDetail(r.Context(), id, expand)
+	if err != nil {
+		serviceError(w, err, "Вопрос не найден", http.StatusNotFound)
+		return
+	}
+
+	// Устанавливаем заголовок JSON.
+	w.Header().Set("Content-Type", "application/json")
+
+	// Кодируем результат в JSON фомат и возвращаем.
+	err = json.NewEncoder(w).Encode(detail)
+	if err != nil {
+		http.Error(w, "Ошибка кодирования JSON: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// Разбирает список расширений через запятую. Неизвестные имена отклоняются.
+func parseQuestionExpand(value string) (service.QuestionExpand, error) {
+	var expand service.QuestionExpand
+	fields := map[string]*bool{
+		"answer":   &expand.Answer,
+		"answers":  &expand.Answers,
+		"tags":     &expand.Tags,
+		"author":   &expand.Author,
+		"versions": &expand.Versions,
+		"stats":    &expand.Stats,
+	}
+
+	for _, name := range strings.Split(value, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+
+		field, ok := fields[name]
+		if !ok {
+			return service.QuestionExpand{}, fmt.Errorf("расширение %q не поддерживается, допустимые: answer, answers, tags, author, versions, stats", name)
+		}
+		*field = true
+	}
+
+	return expand, nil
+}
+
 // @Summary Delete question
 // @Description Delete question by ID and mark versions as deleted
 // @Tags questions
@@ -93,6 +219,7 @@ func (questionHandler *QuestionHandler) GetQuestionByID(w http.ResponseWriter, r
 // @Param tutor_id path int true "Tutor ID who deleted the question"
 // @Success 204
 // @Failure 400 {string} string "Invalid ID"
+// @Failure 401 {string} string "Tutor or moderator token required"
 // @Failure 404 {string} string "Question not found"
 // @Router /questions/{id}/deleteBy/{tutor_id} [delete]
 func (questionHandler *QuestionHandler) DeleteQuestionByID(w http.ResponseWriter, r *http.Request) {
@@ -118,9 +245,9 @@ func (questionHandler *QuestionHandler) DeleteQuestionByID(w http.ResponseWriter
 
 	//Возврат кода операции.
 	//  Успешный ответ - 204 No connect для удаления.
-	err = questionHandler.questionService.DeleteByID(id, deleteByTutor)
+	err = questionHandler.questionService.DeleteByID(r.Context(), id, deleteByTutor)
 	if err != nil {
-		http.Error(w, "Вопрос не найден", http.StatusNotFound)
+		serviceError(w, err, "Вопрос не найден", http.StatusNotFound)
 		return
 	}
 
@@ -128,13 +255,19 @@ func (questionHandler *QuestionHandler) DeleteQuestionByID(w http.ResponseWriter
 }
 
 // @Summary Сreates a new question and records the version
-// @Description Create a new question with text, tutor_id. Create a new question_version with question_id, question_text, tutor_id, version_number
+// @Description Create a new question with text, tutor_id. Create a new question_version with question_id, question_text, tutor_id, version_number. The response contains tag suggestions for the new question. If existing questions are similar above the duplicate threshold, returns 409 with the list of duplicates unless force=true; with force=true the question is created and the duplicates are returned in possible_duplicates
 // @Tags questions
 // @Accept json
 // @Produce json
 // @Param question body models.QuestionsSwaggerRequestBody true "Question data"
-// @Success 201 {object} map[string]interface{} "Question created"
+// @Param force query bool false "Create the question even if likely duplicates exist"
+// @Param auto_tag query bool false "Attach suggested tags above the auto-apply threshold (default from TAG_SUGGEST_AUTO_APPLY)"
+// @Success 201 {object} map[string]interface{} "Question created, with suggested_tags and applied_tags"
 // @Failure 400 {string} string "Invalid request"
+// @Failure 401 {string} string "Tutor or moderator token required"
+// @Failure 409 {object} models.DuplicateQuestionsResponse "Likely duplicates found"
+// @Failure 413 {string} string "Request body too large"
+// @Failure 415 {string} string "Content-Type must be application/json"
 // @Failure 500 {string} string "Internal server error"
 // @Router /questions [post]
 func (questionHandler *QuestionHandler) PostQuestionString(w http.ResponseWriter, r *http.Request) {
@@ -142,9 +275,9 @@ func (questionHandler *QuestionHandler) PostQuestionString(w http.ResponseWriter
 	var question models.Question
 
 	//Преобразование JSON данных в формат структуры models.Question.
-	err := json.NewDecoder(r.Body).Decode(&question)
+	err := decodeJSONBody(w, r, &question)
 	if err != nil {
-		http.Error(w, "Invalid JSON: "+err.Error(), http.StatusBadRequest)
+		http.Error(w, "Invalid JSON: "+err.Error(), bodyErrorStatus(err))
 		return
 	}
 
@@ -154,13 +287,75 @@ func (questionHandler *QuestionHandler) PostQuestionString(w http.ResponseWriter
 		return
 	}
 
+	// Разрешение создать вопрос, даже если он похож на существующие.
+	force := false
+	if value := r.URL.Query().Get("force"); value != "" {
+		force, err = strconv.ParseBool(value)
+		if err != nil {
+			http.Error(w, "Неверное значение force", http.StatusBadRequest)
+			return
+		}
+	}
+
+	// Автоматическое добавление подсказанных тегов: из запроса или из настройки.
+	autoTag := service.AutoApplyTagsEnabled()
+	if value := r.URL.Query().Get("auto_tag"); value != "" {
+		autoTag, err = strconv.ParseBool(value)
+		if err != nil {
+			http.Error(w, "Неверное значение auto_tag", http.StatusBadRequest)
+			return
+		}
+	}
+
+	// Поиск вероятных дубликатов.
+	duplicates, err := questionHandler.questionService.FindDuplicates(r.Context(), question.QuestionText)
+	if err != nil {
+		serviceError(w, err, "Ошибка поиска похожих вопросов: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Вопрос не создается, в ответе похожие вопросы.
+	if len(duplicates) > 0 && !force {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(models.DuplicateQuestionsResponse{
+			Message:    "Похожие вопросы уже есть, чтобы все равно создать вопрос, повторите запрос с force=true",
+			Duplicates: duplicates,
+		})
+		return
+	}
+
 	// Вызов сервиса.
-	id, err := questionHandler.questionService.PostString(question.QuestionText, question.TutorID)
+	id, err := questionHandler.questionService.PostString(r.Context(), question.QuestionText, question.TutorID)
 	if err != nil {
-		http.Error(w, "Failed to create question or question_version: "+err.Error(), http.StatusInternalServerError)
+		serviceError(w, err, "Failed to create question or question_version: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
 
+	response := map[string]interface{}{
+		"id":      id,
+		"message": "Question created successfully",
+	}
+	if len(duplicates) > 0 {
+		response["possible_duplicates"] = duplicates
+	}
+
+	// Подсказки тегов. Вопрос уже создан, поэтому ошибка подсказок только записывается в лог.
+	suggestions, err := questionHandler.questionService.SuggestNewQuestionTags(r.Context(), id)
+	if err == nil {
+		response["suggested_tags"] = suggestions
+		if autoTag {
+			applied, err := questionHandler.questionService.ApplySuggestedTags(r.Context(), id, suggestions)
+			if err == nil {
+				response["applied_tags"] = applied
+			} else {
+				logging.FromContext(r.Context()).Error("auto tagging failed", "question_id", id, "error", err.Error())
+			}
+		}
+	} else {
+		logging.FromContext(r.Context()).Error("tag suggestions failed", "question_id", id, "error", err.Error())
+	}
+
 	// Устанавливаем заголовок JSON.
 	w.Header().Set("Content-Type", "application/json")
 
@@ -168,14 +363,106 @@ func (questionHandler *QuestionHandler) PostQuestionString(w http.ResponseWriter
 	w.WriteHeader(http.StatusCreated)
 
 	// Кодируем результат в JSON фомат.
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"id":      id,
-		"message": "Question created successfully",
-	})
+	json.NewEncoder(w).Encode(response)
+}
+
+// @Summary Get similar questions
+// @Description Returns questions similar to the given one by trigram cosine similarity, most similar first
+// @Tags questions
+// @Produce json
+// @Param id path int true "Question ID"
+// @Param limit query int false "Maximum number of questions (1-50, default from SIMILAR_LIMIT)"
+// @Success 200 {array} models.SimilarQuestion
+// @Failure 400 {string} string "Invalid ID or limit"
+// @Failure 404 {string} string "Question not found"
+// @Failure 501 {string} string "pg_trgm extension is not installed"
+// @Router /questions/{id}/similar [get]
+func (questionHandler *QuestionHandler) GetSimilarQuestions(w http.ResponseWriter, r *http.Request) {
+
+	//Разбиение пути handler на части
+	vars := mux.Vars(r)
+	idStr := vars["id"]
+
+	//Преобразование строк в число.
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		http.Error(w, "Неверный ID", http.StatusBadRequest)
+		return
+	}
+
+	// Количество похожих вопросов.
+	limit := 0
+	if value := r.URL.Query().Get("limit"); value != "" {
+		limit, err = strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > maxSimilarLimit {
+			http.Error(w, fmt.Sprintf("limit должен быть от 1 до %d", maxSimilarLimit), http.StatusBadRequest)
+			return
+		}
+	}
+
+	// Вызов сервиса.
+	similar, err := questionHandler.questionService.GetSimilar(r.Context(), id, limit)
+	if err != nil {
+		serviceError(w, err, "Вопрос не найден", http.StatusNotFound)
+		return
+	}
+
+	// Устанавливаем заголовок JSON.
+	w.Header().Set("Content-Type", "application/json")
+
+	// Кодируем результат в JSON фомат и возвращаем.
+	err = json.NewEncoder(w).Encode(similar)
+	if err != nil {
+		http.Error(w, "Ошибка кодирования JSON: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// @Summary Get suggested tags
+// @Description Suggests tags the question does not have yet: tag names and synonyms found in the question and answer text, and tags that often co-occur with its tags. Each suggestion has a confidence from 0 to 1
+// @Tags questions
+// @Produce json
+// @Param id path int true "Question ID"
+// @Success 200 {array} models.TagSuggestion
+// @Failure 400 {string} string "Invalid ID"
+// @Failure 404 {string} string "Question not found"
+// @Router /questions/{id}/suggested-tags [get]
+func (questionHandler *QuestionHandler) GetSuggestedTags(w http.ResponseWriter, r *http.Request) {
+
+	//Разбиение пути handler на части
+	vars := mux.Vars(r)
+	idStr := vars["id"]
+
+	//Преобразование строк в число.
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		http.Error(w, "Неверный ID", http.StatusBadRequest)
+		return
+	}
+
+	// Вызов сервиса.
+	suggestions, err := questionHandler.questionService.SuggestTags(r.Context(), id)
+	if err != nil {
+		serviceError(w, err, "Вопрос не найден", http.StatusNotFound)
+		return
+	}
+
+	// Устанавливаем заголовок JSON.
+	w.Header().Set("Content-Type", "application/json")
+
+	// Кодируем результат в JSON фомат и возвращаем.
+	err = json.NewEncoder(w).Encode(suggestions)
+	if err != nil {
+		http.Error(w, "Ошибка кодирования JSON: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
 }
 
+// Максимальное количество похожих вопросов в одном ответе.
+const maxSimilarLimit = 50
+
 // @Summary Update question and records the version
-// @Description Update question with text, tutor_id and edit flag.Create a new question_version with question_id, question_text, tutor_id, version_number
+// @Description Update question with text, tutor_id and edit flag.Create a new question_version with question_id, question_text, tutor_id, version_number. A question that is not a draft goes back to in_review until a moderator publishes it
 // @Tags questions
 // @Accept json
 // @Produce json
@@ -183,6 +470,9 @@ func (questionHandler *QuestionHandler) PostQuestionString(w http.ResponseWriter
 // @Param question body models.QuestionsSwaggerRequestBody true "Question data"
 // @Success 200 {object} map[string]interface{} "Question updated"
 // @Failure 400 {string} string "Invalid request"
+// @Failure 401 {string} string "Tutor or moderator token required"
+// @Failure 413 {string} string "Request body too large"
+// @Failure 415 {string} string "Content-Type must be application/json"
 // @Failure 404 {string} string "Question not found"
 // @Router /questions/{id} [put]
 func (questionHandler *QuestionHandler) PutQuestionString(w http.ResponseWriter, r *http.Request) {
@@ -201,9 +491,9 @@ func (questionHandler *QuestionHandler) PutQuestionString(w http.ResponseWriter,
 	var question models.Question
 
 	//Преобразование JSON данных в формат структуры models.Question.
-	err = json.NewDecoder(r.Body).Decode(&question)
+	err = decodeJSONBody(w, r, &question)
 	if err != nil {
-		http.Error(w, "Invalid JSON: "+err.Error(), http.StatusBadRequest)
+		http.Error(w, "Invalid JSON: "+err.Error(), bodyErrorStatus(err))
 		return
 	}
 
@@ -214,9 +504,9 @@ func (questionHandler *QuestionHandler) PutQuestionString(w http.ResponseWriter,
 	}
 
 	// Вызов сервиса.
-	updatedQuestion, err := questionHandler.questionService.PutString(question.QuestionText, question.TutorID, id)
+	updatedQuestion, err := questionHandler.questionService.PutString(r.Context(), question.QuestionText, question.TutorID, id)
 	if err != nil {
-		http.Error(w, "Вопрос не найден", http.StatusNotFound)
+		serviceError(w, err, "Вопрос не найден", http.StatusNotFound)
 		return
 	}
 
@@ -232,5 +522,123 @@ func (questionHandler *QuestionHandler) PutQuestionString(w http.ResponseWriter,
 		"tutor_id":      updatedQuestion.TutorID,
 		"created_at":    updatedQuestion.CreatedAt,
 		"is_edit":       updatedQuestion.IsEdit,
+		"status":        updatedQuestion.Status,
 	})
 }
+
+// @Summary Get questions of tutor
+// @Description Returns paginated list of questions written by the tutor with specified ID
+// @Tags questions
+// @Produce json
+// @Param id path int true "Tutor ID"
+// @Param limit query int false "Page size (1-500, default 50)"
+// @Param offset query int false "Number of records to skip"
+// @Param cursor query string false "Opaque cursor from Link header, sort by id or created_at (cannot be combined with offset)"
+// @Param sort query string false "Sort field (id, created_at, question_text, tutor_id), prefix with - for descending"
+// @Param created_after query string false "Created after (RFC 3339 or YYYY-MM-DD)"
+// @Param created_before query string false "Created before (RFC 3339 or YYYY-MM-DD)"
+// @Param is_edit query bool false "Filter by edit flag"
+// @Param status query string false "Filter by status: draft, in_review, published or archived. Learners only see published"
+// @Success 200 {array} models.Question
+// @Header 200 {integer} X-Total-Count "Total number of records matching filters"
+// @Header 200 {string} Link "Links to next and prev pages"
+// @Failure 400 {string} string "Invalid tutor ID, pagination, sort or filter parameter"
+// @Router /tutors/{id}/questions [get]
+func (questionHandler *QuestionHandler) GetQuestionsByTutorID(w http.ResponseWriter, r *http.Request) {
+
+	//Разбиение пути handler на части.
+	vars := mux.Vars(r)
+	idStr := vars["id"]
+
+	//Преобразование строк в число.
+	tutorID, err := strconv.Atoi(idStr)
+	if err != nil {
+		http.Error(w, "Неверный ID тьютора", http.StatusBadRequest)
+		return
+	}
+
+	// Разбор параметров пагинации, сортировки и фильтров.
+	params, err := parseListParams(r, service.QuestionListSpec)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Тьютор из пути задает фильтр списка.
+	params.Filters["tutor_id"] = tutorID
+
+	// Вызов сервиса.
+	questions, page, err := questionHandler.questionService.GetAll(r.Context(), params)
+	if err != nil {
+		serviceError(w, err, "Ошибка получения вопросов: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	questionHandler.writeQuestionList(w, r, params, questions, page)
+}
+
+// @Summary Get questions with tag
+// @Description Returns paginated list of questions the tag with specified ID is attached to
+// @Tags questions
+// @Produce json
+// @Param id path int true "Tag ID"
+// @Param limit query int false "Page size (1-500, default 50)"
+// @Param offset query int false "Number of records to skip"
+// @Param cursor query string false "Opaque cursor from Link header, sort by id or created_at (cannot be combined with offset)"
+// @Param sort query string false "Sort field (id, created_at, question_text, tutor_id), prefix with - for descending"
+// @Param tutor_id query int false "Filter by tutor ID"
+// @Param created_after query string false "Created after (RFC 3339 or YYYY-MM-DD)"
+// @Param created_before query string false "Created before (RFC 3339 or YYYY-MM-DD)"
+// @Param is_edit query bool false "Filter by edit flag"
+// @Param status query string false "Filter by status: draft, in_review, published or archived. Learners only see published"
+// @Success 200 {array} models.Question
+// @Header 200 {integer} X-Total-Count "Total number of records matching filters"
+// @Header 200 {string} Link "Links to next and prev pages"
+// @Failure 400 {string} string "Invalid tag ID, pagination, sort or filter parameter"
+// @Router /tags/{id}/questions [get]
+func (questionHandler *QuestionHandler) GetQuestionsByTagID(w http.ResponseWriter, r *http.Request) {
+
+	//Разбиение пути handler на части.
+	vars := mux.Vars(r)
+	idStr := vars["id"]
+
+	//Преобразование строк в число.
+	tagID, err := strconv.Atoi(idStr)
+	if err != nil {
+		http.Error(w, "Неверный ID тега", http.StatusBadRequest)
+		return
+	}
+
+	// Разбор параметров пагинации, сортировки и фильтров.
+	params, err := parseListParams(r, service.QuestionListSpec)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Вызов сервиса.
+	questions, page, err := questionHandler.questionService.GetAllByTagID(r.Context(), tagID, params)
+	if err != nil {
+		serviceError(w, err, "Ошибка получения вопросов: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	questionHandler.writeQuestionList(w, r, params, questions, page)
+}
+
+// Ответ со страницей вопросов и заголовками пагинации.
+func (questionHandler *QuestionHandler) writeQuestionList(w http.ResponseWriter, r *http.Request, params service.ListParams, questions []models.Question, page service.PageInfo) {
+
+	// Устанавливаем заголовок JSON.
+	w.Header().Set("Content-Type", "application/json")
+
+	// Заголовки с общим количеством и ссылками на соседние страницы.
+	writeListHeaders(w, r, params, page)
+
+	// Кодируем результат в JSON фомат и возвращаем.
+	err := json.NewEncoder(w).Encode(questions)
+	if err != nil {
+		http.Error(w, "Ошибка кодирования JSON: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
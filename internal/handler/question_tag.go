@@ -2,7 +2,9 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"knowledge-base/internal/models"
 	"knowledge-base/internal/service"
 	"net/http"
 	"strconv"
@@ -28,6 +30,7 @@ func NewQuestionTagHandler(questionTagService *service.QuestionTagService) *Ques
 // @Param tag_id path int true "Tag ID"
 // @Success 201 {object} map[string]interface{}
 // @Failure 400 {string} string "Bad request"
+// @Failure 401 {string} string "Tutor or moderator token required"
 // @Router /question-tags/{question_id}/{tag_id} [post]
 func (questionTagHandler *QuestionTagHandler) AddTagToQuestion(w http.ResponseWriter, r *http.Request) {
 
@@ -51,9 +54,9 @@ func (questionTagHandler *QuestionTagHandler) AddTagToQuestion(w http.ResponseWr
 	}
 
 	// Вызов сервиса.
-	err = questionTagHandler.questionTagService.AddToQuestion(questionID, tagID)
+	err = questionTagHandler.questionTagService.AddToQuestion(r.Context(), questionID, tagID)
 	if err != nil {
-		http.Error(w, "Ошибка добавления тега: "+err.Error(), http.StatusBadRequest)
+		serviceError(w, err, "Ошибка добавления тега: "+err.Error(), http.StatusBadRequest)
 		return
 	}
 
@@ -72,23 +75,41 @@ func (questionTagHandler *QuestionTagHandler) AddTagToQuestion(w http.ResponseWr
 }
 
 // @Summary Get all question-tag relations
-// @Description Returns list of all relations between questions and tags
+// @Description Returns paginated list of relations between questions and tags with sorting and filters
 // @Tags question-tags
 // @Produce json
+// @Param limit query int false "Page size (1-500, default 50)"
+// @Param offset query int false "Number of records to skip"
+// @Param sort query string false "Sort field (question_id, tag_id), prefix with - for descending"
+// @Param question_id query int false "Filter by question ID"
+// @Param tag_id query int false "Filter by tag ID"
 // @Success 200 {array} models.QuestionTag
+// @Header 200 {integer} X-Total-Count "Total number of records matching filters"
+// @Header 200 {string} Link "Links to next and prev pages"
+// @Failure 400 {string} string "Invalid pagination, sort or filter parameter"
 // @Router /question-tags [get]
 func (questionTagHandler *QuestionTagHandler) GetAllQuestionTagRelations(w http.ResponseWriter, r *http.Request) {
 
+	// Разбор параметров пагинации, сортировки и фильтров.
+	params, err := parseListParams(r, service.QuestionTagListSpec)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	// Вызов сервиса для получения всех связей.
-	relations, err := questionTagHandler.questionTagService.GetAllRelations()
+	relations, total, err := questionTagHandler.questionTagService.GetAllRelations(r.Context(), params)
 	if err != nil {
-		http.Error(w, "Ошибка получения связей вопрос-тег: "+err.Error(), http.StatusInternalServerError)
+		serviceError(w, err, "Ошибка получения связей вопрос-тег: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
 
 	// Устанавливаем заголовок JSON.
 	w.Header().Set("Content-Type", "application/json")
 
+	// Заголовки с общим количеством и ссылками на соседние страницы.
+	writeListHeaders(w, r, params, service.PageInfo{Total: total})
+
 	// Кодируем результат в JSON формат и возвращаем.
 	err = json.NewEncoder(w).Encode(relations)
 	if err != nil {
@@ -97,6 +118,41 @@ func (questionTagHandler *QuestionTagHandler) GetAllQuestionTagRelations(w http.
 	}
 }
 
+// @Summary Export all question-tag relations
+// @Description Streams all question-tag relations matching filters as a JSON array or NDJSON (Accept: application/x-ndjson) without pagination
+// @Tags question-tags
+// @Produce json
+// @Produce application/x-ndjson
+// @Param sort query string false "Sort field (question_id, tag_id), prefix with - for descending"
+// @Param question_id query int false "Filter by question ID"
+// @Param tag_id query int false "Filter by tag ID"
+// @Success 200 {array} models.QuestionTag
+// @Failure 400 {string} string "Invalid sort or filter parameter"
+// @Failure 500 {string} string "Internal server error"
+// @Router /question-tags/export [get]
+func (questionTagHandler *QuestionTagHandler) ExportQuestionTagRelations(w http.ResponseWriter, r *http.Request) {
+
+	// Разбор параметров сортировки и фильтров.
+	params, err := parseExportParams(r, service.QuestionTagListSpec)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Вызов сервиса: каждая запись сразу пишется в ответ.
+	stream := newStreamWriter(w, r)
+	err = questionTagHandler.questionTagService.ExportRelations(r.Context(), params, func(relation models.QuestionTag) error {
+		return stream.Write(relation)
+	})
+	if err != nil {
+		stream.Fail(r, err, "Ошибка выгрузки связей вопросов и тегов: ")
+		return
+	}
+
+	// Завершение массива и отправка остатка буфера.
+	stream.Close()
+}
+
 // @Summary Get all question-tag relations by tag ID
 // @Description Returns list of all relations between questions and tags by tag ID
 // @Tags question-tags
@@ -118,9 +174,9 @@ func (questionTagHandler *QuestionTagHandler) GetAllQuestionTagRelationsByTagID(
 	}
 
 	// Вызов сервиса для получения всех связей.
-	relations, err := questionTagHandler.questionTagService.GetAllRelationsByTagID(tagID)
+	relations, err := questionTagHandler.questionTagService.GetAllRelationsByTagID(r.Context(), tagID)
 	if err != nil {
-		http.Error(w, "Ошибка получения связей вопрос-тег: "+err.Error(), http.StatusInternalServerError)
+		serviceError(w, err, "Ошибка получения связей вопрос-тег: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
 
@@ -142,6 +198,7 @@ func (questionTagHandler *QuestionTagHandler) GetAllQuestionTagRelationsByTagID(
 // @Param tag_id path int true "Tag ID"
 // @Success 204 "No Content"
 // @Failure 400 {string} string "Invalid question ID or tag ID"
+// @Failure 401 {string} string "Tutor or moderator token required"
 // @Failure 404 {string} string "Relation not found"
 // @Router /question-tags/{question_id}/{tag_id} [delete]
 func (questionTagHandler *QuestionTagHandler) DeleteQuestionTagRelationByID(w http.ResponseWriter, r *http.Request) {
@@ -167,11 +224,107 @@ func (questionTagHandler *QuestionTagHandler) DeleteQuestionTagRelationByID(w ht
 
 	//Возврат кода операции.
 	//  Успешный ответ - 204 No connect для удаления.
-	err = questionTagHandler.questionTagService.DeleteRelationByID(questionID, tagID)
+	err = questionTagHandler.questionTagService.DeleteRelationByID(r.Context(), questionID, tagID)
 	if err != nil {
-		http.Error(w, "Связь не найдена", http.StatusNotFound)
+		serviceError(w, err, "Связь не найдена", http.StatusNotFound)
 		return
 	}
 
 	w.WriteHeader(http.StatusNoContent)
 }
+
+// @Summary Get tags of question
+// @Description Returns all tags attached to the question with specified ID
+// @Tags question-tags
+// @Produce json
+// @Param id path int true "Question ID"
+// @Success 200 {array} models.Tag
+// @Failure 400 {string} string "Invalid question ID"
+// @Failure 404 {string} string "Question not found"
+// @Router /questions/{id}/tags [get]
+func (questionTagHandler *QuestionTagHandler) GetQuestionTags(w http.ResponseWriter, r *http.Request) {
+
+	// Разбиение пути handler на части.
+	vars := mux.Vars(r)
+	idStr := vars["id"]
+
+	// Преобразование строк в число.
+	questionID, err := strconv.Atoi(idStr)
+	if err != nil {
+		http.Error(w, "Неверный ID вопроса", http.StatusBadRequest)
+		return
+	}
+
+	// Вызов сервиса.
+	tags, err := questionTagHandler.questionTagService.GetTagsByQuestionID(r.Context(), questionID)
+	if err != nil {
+		serviceError(w, err, "Вопрос не найден", http.StatusNotFound)
+		return
+	}
+
+	// Устанавливаем заголовок JSON.
+	w.Header().Set("Content-Type", "application/json")
+
+	// Кодируем результат в JSON формат.
+	json.NewEncoder(w).Encode(tags)
+}
+
+// @Summary Replace tags of question
+// @Description Atomically replaces the whole tag set of the question. An empty tag_ids list removes all tags
+// @Tags question-tags
+// @Accept json
+// @Produce json
+// @Param id path int true "Question ID"
+// @Param body body models.QuestionTagsRequestBody true "New tag set"
+// @Success 200 {array} models.Tag
+// @Failure 400 {string} string "Invalid question ID, JSON or unknown tag"
+// @Failure 401 {string} string "Tutor or moderator token required"
+// @Failure 404 {string} string "Question not found"
+// @Failure 413 {string} string "Request body too large"
+// @Failure 415 {string} string "Content-Type must be application/json"
+// @Router /questions/{id}/tags [put]
+func (questionTagHandler *QuestionTagHandler) PutQuestionTags(w http.ResponseWriter, r *http.Request) {
+
+	// Разбиение пути handler на части.
+	vars := mux.Vars(r)
+	idStr := vars["id"]
+
+	// Преобразование строк в число.
+	questionID, err := strconv.Atoi(idStr)
+	if err != nil {
+		http.Error(w, "Неверный ID вопроса", http.StatusBadRequest)
+		return
+	}
+
+	var body models.QuestionTagsRequestBody
+
+	// Преобразование JSON данных в формат структуры models.QuestionTagsRequestBody.
+	err = decodeJSONBody(w, r, &body)
+	if err != nil {
+		http.Error(w, "Invalid JSON: "+err.Error(), bodyErrorStatus(err))
+		return
+	}
+
+	// Валидация: список обязателен, чтобы случайно пустое тело не удалило все теги.
+	if body.TagIDs == nil {
+		http.Error(w, "tag_ids is required", http.StatusBadRequest)
+		return
+	}
+
+	// Вызов сервиса.
+	tags, err := questionTagHandler.questionTagService.ReplaceTags(r.Context(), questionID, body.TagIDs)
+	if errors.Is(err, service.ErrTagNotFound) {
+		http.Error(w, "Один или несколько тегов не найдены", http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		serviceError(w, err, "Вопрос не найден", http.StatusNotFound)
+		return
+	}
+
+	// Устанавливаем заголовок JSON.
+	w.Header().Set("Content-Type", "application/json")
+
+	// Кодируем результат в JSON формат.
+	json.NewEncoder(w).Encode(tags)
+}
@@ -1,7 +1,9 @@
 package handler
 
 import (
+	"database/sql"
 	"encoding/json"
+	"errors"
 	"knowledge-base/internal/service"
 	"net/http"
 	"strconv"
@@ -20,12 +22,21 @@ func NewQuestionVersionHandler(questionVersionService *service.QuestionVersionSe
 }
 
 // @Summary Get question versions by question ID
-// @Description Returns all versions of a specific question by question ID
+// @Description Returns paginated versions of a specific question by question ID, supports cursor pagination. Learners see only published versions
 // @Tags question-versions
 // @Produce json
 // @Param id path int true "Question ID"
+// @Param limit query int false "Page size (1-500, default 50)"
+// @Param offset query int false "Number of records to skip"
+// @Param cursor query string false "Opaque cursor from Link header (cannot be combined with offset)"
+// @Param sort query string false "Sort field (version_number, created_at, id), prefix with - for descending"
+// @Param created_after query string false "Created after (RFC 3339 or YYYY-MM-DD)"
+// @Param created_before query string false "Created before (RFC 3339 or YYYY-MM-DD)"
 // @Success 200 {array} models.QuestionVersion
-// @Failure 400 {string} string "Invalid question ID"
+// @Header 200 {integer} X-Total-Count "Total number of versions matching filters"
+// @Header 200 {string} Link "Cursor links to next and prev pages"
+// @Failure 400 {string} string "Invalid question ID or pagination parameter"
+// @Failure 404 {string} string "Question not found or not published"
 // @Router /question-versions/{id} [get]
 func (handler *QuestionVersionHandler) GetAllQuestionVersionsByID(w http.ResponseWriter, r *http.Request) {
 
@@ -40,16 +51,30 @@ func (handler *QuestionVersionHandler) GetAllQuestionVersionsByID(w http.Respons
 		return
 	}
 
+	// Разбор параметров пагинации, сортировки и фильтров.
+	params, err := parseListParams(r, service.QuestionVersionListSpec)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	// Вызов сервиса.
-	questionVersions, err := handler.questionVersionService.GetAllByID(id)
+	questionVersions, page, err := handler.questionVersionService.GetAllByID(r.Context(), id, params)
 	if err != nil {
-		http.Error(w, "Ошибка получения версий вопроса: "+err.Error(), http.StatusInternalServerError)
+		if errors.Is(err, sql.ErrNoRows) {
+			http.Error(w, "Вопрос не найден", http.StatusNotFound)
+			return
+		}
+		serviceError(w, err, "Ошибка получения версий вопроса: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
 
 	// Устанавливаем заголовок JSON.
 	w.Header().Set("Content-Type", "application/json")
 
+	// Заголовки с общим количеством и ссылками на соседние страницы.
+	writeListHeaders(w, r, params, page)
+
 	// Кодируем результат в JSON фомат.
 	json.NewEncoder(w).Encode(questionVersions)
 }
@@ -0,0 +1,64 @@
+package handler
+
+import (
+	"sync"
+	"time"
+)
+
+// Ограничение частоты запросов по ключу в окне времени. Счетчики хранятся в памяти процесса.
+type rateLimiter struct {
+	mu     sync.Mutex
+	limit  int
+	window time.Duration
+
+	windows map[string]*rateWindow
+
+	// Время последней очистки закончившихся окон.
+	swept time.Time
+}
+
+// Окно одного ключа: начало и количество запросов в нем.
+type rateWindow struct {
+	start time.Time
+	count int
+}
+
+func newRateLimiter(limit int, window time.Duration) *rateLimiter {
+	return &rateLimiter{limit: limit, window: window, windows: make(map[string]*rateWindow)}
+}
+
+// Учет запроса по ключу. Если лимит исчерпан, возвращается false и время до начала нового окна.
+func (limiter *rateLimiter) allow(key string, now time.Time) (bool, time.Duration) {
+	limiter.mu.Lock()
+	defer limiter.mu.Unlock()
+
+	// Закончившиеся окна удаляются раз в окно, чтобы карта не росла.
+	if now.Sub(limiter.swept) >= limiter.window {
+		for k, w := range limiter.windows {
+			if now.Sub(w.start) >= limiter.window {
+				delete(limiter.windows, k)
+			}
+		}
+		limiter.swept = now
+	}
+
+	w, ok := limiter.windows[key]
+	if !ok || now.Sub(w.start) >= limiter.window {
+		w = &rateWindow{start: now}
+		limiter.windows[key] = w
+	}
+	if w.count >= limiter.limit {
+		return false, w.start.Add(limiter.window).Sub(now)
+	}
+	w.count++
+	return true, 0
+}
+
+// Оценок ответов в час с одного адреса. Новую cookie можно получить, просто удалив старую,
+// поэтому число оценок ограничено и по адресу.
+var feedbackLimiter = newRateLimiter(20, time.Hour)
+
+// SetFeedbackRateLimit задает количество оценок ответов в час с одного IP адреса.
+func SetFeedbackRateLimit(limit int) {
+	feedbackLimiter = newRateLimiter(limit, time.Hour)
+}
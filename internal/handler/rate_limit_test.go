@@ -0,0 +1,34 @@
+package handler
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRateLimiter(t *testing.T) {
+	start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	limiter := newRateLimiter(2, time.Hour)
+
+	tests := []struct {
+		name       string
+		key        string
+		at         time.Duration
+		allowed    bool
+		retryAfter time.Duration
+	}{
+		{name: "первый запрос", key: "a", at: 0, allowed: true},
+		{name: "второй запрос", key: "a", at: time.Minute, allowed: true},
+		{name: "лимит исчерпан", key: "a", at: 10 * time.Minute, allowed: false, retryAfter: 50 * time.Minute},
+		{name: "другой ключ", key: "b", at: 10 * time.Minute, allowed: true},
+		{name: "новое окно", key: "a", at: time.Hour, allowed: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			allowed, retryAfter := limiter.allow(tt.key, start.Add(tt.at))
+			if allowed != tt.allowed || retryAfter != tt.retryAfter {
+				t.Errorf("allow(%q) = %v, %v, want %v, %v", tt.key, allowed, retryAfter, tt.allowed, tt.retryAfter)
+			}
+		})
+	}
+}
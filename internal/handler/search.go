@@ -0,0 +1,333 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"knowledge-base/internal/service"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Структура для работы со всеми ф-ями handler/search.go.
+type SearchHandler struct {
+	searchService *service.SearchService
+}
+
+// Функция для создания объекта типа SearchHandler.
+func NewSearchHandler(searchService *service.SearchService) *SearchHandler {
+	return &SearchHandler{searchService: searchService}
+}
+
+// @Summary Full-text search
+// @Description Searches question and answer texts (Russian and English word forms), ranked by relevance with highlighted snippets. Words typed in Latin transliteration or in the wrong keyboard layout also match Russian text: "kak nastroit docker" finds "Как настроить Docker"
+// @Tags search 🔍
+// @Produce json
+// @Param q query string true "Search query: words, quoted phrase, -excluded, or"
+// @Param tag query []string false "Question must have all these tags (any layout or alphabet, synonyms)" collectionFormat(multi)
+// @Param tutor_id query int false "Only questions of this tutor"
+// @Param limit query int false "Page size (1-500, default 50)"
+// @Param offset query int false "Number of records to skip"
+// @Success 200 {array} models.SearchResult
+// @Header 200 {integer} X-Total-Count "Total number of found questions"
+// @Header 200 {string} Link "Links to next and prev pages"
+// @Failure 400 {string} string "Empty query or invalid parameter"
+// @Router /search [get]
+func (searchHandler *SearchHandler) Search(w http.ResponseWriter, r *http.Request) {
+
+	// Разбор параметров поиска.
+	params, page, err := parseSearchParams(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Вызов сервиса.
+	results, total, err := searchHandler.searchService.Search(r.Context(), params)
+	if err != nil {
+		serviceError(w, err, "Ошибка поиска: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Устанавливаем заголовок JSON.
+	w.Header().Set("Content-Type", "application/json")
+
+	// Заголовки с общим количеством и ссылками на соседние страницы.
+	writeListHeaders(w, r, page, service.PageInfo{Total: total})
+
+	// Кодируем результат в JSON формат и возвращаем.
+	err = json.NewEncoder(w).Encode(results)
+	if err != nil {
+		http.Error(w, "Ошибка кодирования JSON: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// Разбирает параметры поиска. Страница разбирается так же, как у списков, и возвращается отдельно для заголовков.
+func parseSearchParams(r *http.Request) (service.SearchParams, service.ListParams, error) {
+	query := r.URL.Query()
+
+	params := service.SearchParams{Query: strings.TrimSpace(query.Get("q"))}
+	if params.Query == "" {
+		return service.SearchParams{}, service.ListParams{}, fmt.Errorf("параметр q обязателен")
+	}
+
+	// Теги: пустые значения пропускаются.
+	for _, tag := range query["tag"] {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			params.Tags = append(params.Tags, tag)
+		}
+	}
+
+	// Тьютор.
+	if value := query.Get("tutor_id"); value != "" {
+		tutorID, err := strconv.Atoi(value)
+		if err != nil {
+			return service.SearchParams{}, service.ListParams{}, fmt.Errorf("tutor_id должен быть числом")
+		}
+		params.TutorID = &tutorID
+	}
+
+	// Страница: у поиска нет сортировки и фильтров из ListSpec, только limit и offset.
+	page, err := parseListParams(r, service.ListSpec{})
+	if err != nil {
+		return service.SearchParams{}, service.ListParams{}, err
+	}
+	params.Limit = page.Limit
+	params.Offset = page.Offset
+
+	return params, page, nil
+}
+
+// @Summary Search with query language
+// @Description Searches questions with one query string: words, "exact phrase", -excluded, tag:go, author:ivanov, created:>2025-01-01 (also >=, <, <=, exact date or 2025-01-01..2025-02-01), is:answered, is:unanswered, is:edited. Any term can be negated with minus
+// @Tags search 🔍
+// @Produce json
+// @Param q query string true "Query, e.g. tag:go author:ivanov -docker created:>2025-01-01 is:unanswered"
+// @Param limit query int false "Page size (1-500, default 50)"
+// @Param offset query int false "Number of records to skip"
+// @Success 200 {array} models.SearchResult
+// @Header 200 {integer} X-Total-Count "Total number of found questions"
+// @Header 200 {string} Link "Links to next and prev pages"
+// @Failure 400 {string} string "Query parse error with position"
+// @Router /search/query [get]
+func (searchHandler *SearchHandler) SearchByQuery(w http.ResponseWriter, r *http.Request) {
+
+	// Разбор запроса.
+	searchQuery, err := service.ParseSearchQuery(r.URL.Query().Get("q"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Разбор страницы.
+	page, err := parseListParams(r, service.ListSpec{})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Вызов сервиса.
+	results, total, err := searchHandler.searchService.SearchByQuery(r.Context(), searchQuery, page.Limit, page.Offset)
+	if err != nil {
+		serviceError(w, err, "Ошибка поиска: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Устанавливаем заголовок JSON.
+	w.Header().Set("Content-Type", "application/json")
+
+	// Заголовки с общим количеством и ссылками на соседние страницы.
+	writeListHeaders(w, r, page, service.PageInfo{Total: total})
+
+	// Кодируем результат в JSON формат и возвращаем.
+	err = json.NewEncoder(w).Encode(results)
+	if err != nil {
+		http.Error(w, "Ошибка кодирования JSON: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// @Summary Typo-tolerant fuzzy search
+// @Description Finds questions whose tags or question text are similar to the query (pg_trgm), so "postgre" finds "postgresql". When nothing is found, did_you_mean contains similar tag names
+// @Tags search 🔍
+// @Produce json
+// @Param q query string true "Search query"
+// @Param limit query int false "Page size (1-500, default 50)"
+// @Param offset query int false "Number of records to skip"
+// @Success 200 {object} models.FuzzySearchResult
+// @Header 200 {integer} X-Total-Count "Total number of found questions"
+// @Header 200 {string} Link "Links to next and prev pages"
+// @Failure 400 {string} string "Empty query or invalid parameter"
+// @Failure 501 {string} string "pg_trgm extension is not installed"
+// @Router /search/fuzzy [get]
+func (searchHandler *SearchHandler) FuzzySearch(w http.ResponseWriter, r *http.Request) {
+
+	// Проверка, что запрос не пустой.
+	term := strings.TrimSpace(r.URL.Query().Get("q"))
+	if term == "" {
+		http.Error(w, "параметр q обязателен", http.StatusBadRequest)
+		return
+	}
+
+	// Разбор страницы.
+	page, err := parseListParams(r, service.ListSpec{})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Вызов сервиса.
+	result, err := searchHandler.searchService.FuzzySearch(r.Context(), term, page.Limit, page.Offset)
+	if err != nil {
+		serviceError(w, err, "Ошибка поиска: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Устанавливаем заголовок JSON.
+	w.Header().Set("Content-Type", "application/json")
+
+	// Заголовки с общим количеством и ссылками на соседние страницы.
+	writeListHeaders(w, r, page, service.PageInfo{Total: result.Total})
+
+	// Кодируем результат в JSON формат и возвращаем.
+	err = json.NewEncoder(w).Encode(result)
+	if err != nil {
+		http.Error(w, "Ошибка кодирования JSON: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// @Summary Search with the configured engine
+// @Description Finds questions whose text or answers contain the query words using the search backend selected by SEARCH_BACKEND: postgres (full-text search) or index (embedded inverted index with BM25). Supports words, quoted phrases and -excluded words; synonyms are expanded, transliterated and wrong-layout words also match Russian text
+// @Tags search 🔍
+// @Produce json
+// @Param q query string true "Search query: words, quoted phrase, -excluded"
+// @Param limit query int false "Page size (1-500, default 50)"
+// @Param offset query int false "Number of records to skip"
+// @Success 200 {object} models.EngineSearchResult
+// @Header 200 {integer} X-Total-Count "Total number of found questions"
+// @Header 200 {string} Link "Links to next and prev pages"
+// @Header 200 {string} X-Search-Backend "Search backend that served the request"
+// @Failure 400 {string} string "Empty query or invalid parameter"
+// @Router /search/engine [get]
+func (searchHandler *SearchHandler) EngineSearch(w http.ResponseWriter, r *http.Request) {
+
+	// Проверка, что запрос не пустой.
+	query := strings.TrimSpace(r.URL.Query().Get("q"))
+	if query == "" {
+		http.Error(w, "параметр q обязателен", http.StatusBadRequest)
+		return
+	}
+
+	// Разбор страницы.
+	page, err := parseListParams(r, service.ListSpec{})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Вызов сервиса.
+	result, err := searchHandler.searchService.EngineSearch(r.Context(), query, page.Limit, page.Offset)
+	if err != nil {
+		serviceError(w, err, "Ошибка поиска: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Устанавливаем заголовок JSON.
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Search-Backend", result.Backend)
+
+	// Заголовки с общим количеством и ссылками на соседние страницы.
+	writeListHeaders(w, r, page, service.PageInfo{Total: result.Total})
+
+	// Кодируем результат в JSON формат и возвращаем.
+	err = json.NewEncoder(w).Encode(result)
+	if err != nil {
+		http.Error(w, "Ошибка кодирования JSON: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// @Summary Boolean search by tags
+// @Description Finds questions by tag lists (all, any, none) or by expression like `go AND (postgres OR mysql) AND NOT orm`. Tags match in any keyboard layout or alphabet and by synonyms. Returns tag facet counts for the whole result set
+// @Tags search 🔍
+// @Produce json
+// @Param all query string false "Comma-separated tags, question must have all of them"
+// @Param any query string false "Comma-separated tags, question must have at least one of them"
+// @Param none query string false "Comma-separated tags, question must have none of them"
+// @Param expr query string false "Tag expression with AND, OR, NOT and parentheses (cannot be combined with lists)"
+// @Param limit query int false "Page size (1-500, default 50)"
+// @Param offset query int false "Number of records to skip"
+// @Success 200 {object} models.TagSearchResult
+// @Header 200 {integer} X-Total-Count "Total number of found questions"
+// @Header 200 {string} Link "Links to next and prev pages"
+// @Failure 400 {string} string "Invalid tag lists or expression"
+// @Router /search/tags [get]
+func (searchHandler *SearchHandler) SearchByTags(w http.ResponseWriter, r *http.Request) {
+
+	// Разбор условия по тегам.
+	tagQuery, err := parseTagQuery(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Разбор страницы.
+	page, err := parseListParams(r, service.ListSpec{})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Вызов сервиса.
+	result, err := searchHandler.searchService.SearchByTags(r.Context(), tagQuery, page.Limit, page.Offset)
+	if err != nil {
+		serviceError(w, err, "Ошибка поиска: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Устанавливаем заголовок JSON.
+	w.Header().Set("Content-Type", "application/json")
+
+	// Заголовки с общим количеством и ссылками на соседние страницы.
+	writeListHeaders(w, r, page, service.PageInfo{Total: result.Total})
+
+	// Кодируем результат в JSON формат и возвращаем.
+	err = json.NewEncoder(w).Encode(result)
+	if err != nil {
+		http.Error(w, "Ошибка кодирования JSON: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// Условие по тегам из выражения expr или из списков all, any и none.
+func parseTagQuery(r *http.Request) (service.TagQuery, error) {
+	query := r.URL.Query()
+
+	all := tagList(query["all"])
+	anyOf := tagList(query["any"])
+	none := tagList(query["none"])
+
+	if expr := strings.TrimSpace(query.Get("expr")); expr != "" {
+		if len(all)+len(anyOf)+len(none) > 0 {
+			return nil, fmt.Errorf("expr нельзя использовать вместе с all, any и none")
+		}
+		return service.ParseTagQuery(expr)
+	}
+
+	return service.TagQueryFromLists(all, anyOf, none)
+}
+
+// Теги из параметров: значения через запятую, параметр можно повторять.
+func tagList(values []string) []string {
+	var tags []string
+	for _, value := range values {
+		for _, tag := range strings.Split(value, ",") {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				tags = append(tags, tag)
+			}
+		}
+	}
+	return tags
+}
@@ -0,0 +1,248 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"knowledge-base/internal/logging"
+	"knowledge-base/internal/models"
+	"knowledge-base/internal/service"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// Заголовок с ID поиска, по которому клиент сообщает о переходе к результату.
+const searchIDHeader = "X-Search-ID"
+
+// Параметры страницы и сортировки, которые не относятся к тексту запроса.
+var searchPageParams = []string{"limit", "offset", "cursor", "sort"}
+
+// Настройки журнала поиска.
+type SearchLogOptions struct {
+	// Записывать ли поиски в журнал.
+	Enabled bool
+}
+
+// Текущие настройки журнала поиска.
+var searchLogOptions = SearchLogOptions{Enabled: true}
+
+// SetSearchLogOptions задает настройки журнала поиска.
+func SetSearchLogOptions(options SearchLogOptions) {
+	searchLogOptions = options
+}
+
+// Структура для работы со всеми ф-ями handler/search_log.go.
+type SearchLogHandler struct {
+	searchLogService *service.SearchLogService
+}
+
+// Функция для создания объекта типа SearchLogHandler.
+func NewSearchLogHandler(searchLogService *service.SearchLogService) *SearchLogHandler {
+	return &SearchLogHandler{searchLogService: searchLogService}
+}
+
+// Обертка над http.ResponseWriter, которая запоминает статус ответа.
+type searchStatusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (rec *searchStatusRecorder) WriteHeader(status int) {
+	if rec.status == 0 {
+		rec.status = status
+	}
+	rec.ResponseWriter.WriteHeader(status)
+}
+
+func (rec *searchStatusRecorder) Write(b []byte) (int, error) {
+	if rec.status == 0 {
+		rec.status = http.StatusOK
+	}
+	return rec.ResponseWriter.Write(b)
+}
+
+// Track записывает в журнал каждый успешный поиск через next: запрос, количество результатов из X-Total-Count,
+// время выполнения и обезличенный ID клиента. Запись не задерживает ответ.
+// ID поиска возвращается в заголовке X-Search-ID.
+func (searchLogHandler *SearchLogHandler) Track(endpoint string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !searchLogOptions.Enabled {
+			next(w, r)
+			return
+		}
+
+		searchID := randomHex()
+		w.Header().Set(searchIDHeader, searchID)
+
+		start := time.Now()
+		rec := &searchStatusRecorder{ResponseWriter: w}
+		next(rec, r)
+		latency := time.Since(start)
+
+		if rec.status != http.StatusOK {
+			return
+		}
+
+		entry := service.SearchLogEntry{
+			SearchID: searchID,
+			Endpoint: endpoint,
+			Query:    searchQueryText(r),
+			Latency:  latency,
+			ClientID: clientID(r),
+		}
+		if total, err := strconv.Atoi(w.Header().Get("X-Total-Count")); err == nil {
+			entry.ResultCount = &total
+		}
+
+		// Запись после ответа: клиент уже мог отключиться, поэтому контекст без отмены.
+		ctx := context.WithoutCancel(r.Context())
+		go func() {
+			if err := searchLogHandler.searchLogService.Record(ctx, entry); err != nil {
+				logging.FromContext(ctx).Error("search log write failed", "search_id", searchID, "error", err.Error())
+			}
+		}()
+	}
+}
+
+// Текст поиска: параметр q, имя из пути (/simple-search/{name}) или остальные параметры (/search/tags).
+func searchQueryText(r *http.Request) string {
+	query := r.URL.Query()
+	if q := query.Get("q"); q != "" {
+		return q
+	}
+	if name := mux.Vars(r)["name"]; name != "" {
+		return name
+	}
+
+	rest := url.Values{}
+	for key, values := range query {
+		rest[key] = values
+	}
+	for _, key := range searchPageParams {
+		rest.Del(key)
+	}
+	text, err := url.QueryUnescape(rest.Encode())
+	if err != nil {
+		return rest.Encode()
+	}
+	return text
+}
+
+// @Summary Report a search result click
+// @Description Records that the client opened a search result. search_id is the X-Search-ID header of the search response
+// @Tags search analytics
+// @Accept json
+// @Param click body models.SearchClickRequestBody true "Search ID, opened question and its position"
+// @Success 204 "Click recorded"
+// @Failure 400 {string} string "Invalid request"
+// @Failure 413 {string} string "Request body too large"
+// @Failure 415 {string} string "Content-Type must be application/json"
+// @Router /search/clicks [post]
+func (searchLogHandler *SearchLogHandler) PostSearchClick(w http.ResponseWriter, r *http.Request) {
+
+	var click models.SearchClickRequestBody
+
+	//Преобразование JSON данных в формат структуры models.SearchClickRequestBody.
+	err := decodeJSONBody(w, r, &click)
+	if err != nil {
+		http.Error(w, "Invalid JSON: "+err.Error(), bodyErrorStatus(err))
+		return
+	}
+
+	// Вызов сервиса.
+	err = searchLogHandler.searchLogService.RecordClick(r.Context(), strings.TrimSpace(click.SearchID), click.QuestionID, click.Position, clientID(r))
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidSearchClick) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		serviceError(w, err, "Ошибка записи перехода: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Период отчета по умолчанию и максимальный, в днях.
+const (
+	defaultSearchReportDays = 30
+	maxSearchReportDays     = 365
+)
+
+// Количество запросов в отчете по умолчанию и максимальное.
+const (
+	defaultSearchReportLimit = 20
+	maxSearchReportLimit     = 500
+)
+
+// @Summary Search analytics report
+// @Description Aggregated search log for the period: top-queries (most frequent), zero-results (queries that found nothing, most frequent first) or click-through (share of searches followed by opening a result)
+// @Tags search analytics
+// @Produce json
+// @Param report path string true "top-queries, zero-results or click-through"
+// @Param days query int false "Period in days (1-365, default 30)"
+// @Param limit query int false "Number of queries (1-500, default 20)"
+// @Success 200 {array} models.SearchQueryStat
+// @Failure 400 {string} string "Invalid parameter"
+// @Failure 401 {string} string "Tutor or moderator token required"
+// @Failure 404 {string} string "Unknown report"
+// @Router /admin/search/{report} [get]
+func (searchLogHandler *SearchLogHandler) GetSearchReport(w http.ResponseWriter, r *http.Request) {
+
+	//Разбиение пути handler на части.
+	report := mux.Vars(r)["report"]
+	switch report {
+	case service.SearchReportTopQueries, service.SearchReportZeroResults, service.SearchReportClickThrough:
+	default:
+		http.Error(w, "Отчет не найден", http.StatusNotFound)
+		return
+	}
+
+	// Период и количество запросов.
+	days, err := boundedIntParam(r, "days", defaultSearchReportDays, maxSearchReportDays)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	limit, err := boundedIntParam(r, "limit", defaultSearchReportLimit, maxSearchReportLimit)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Вызов сервиса.
+	stats, err := searchLogHandler.searchLogService.Report(r.Context(), report, time.Now().AddDate(0, 0, -days), limit)
+	if err != nil {
+		serviceError(w, err, "Ошибка построения отчета: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Устанавливаем заголовок JSON.
+	w.Header().Set("Content-Type", "application/json")
+
+	// Кодируем результат в JSON формат и возвращаем.
+	err = json.NewEncoder(w).Encode(stats)
+	if err != nil {
+		http.Error(w, "Ошибка кодирования JSON: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// Целый параметр запроса от 1 до max, fallback - если параметр не передан.
+func boundedIntParam(r *http.Request, name string, fallback int, max int) (int, error) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return fallback, nil
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil || parsed < 1 || parsed > max {
+		return 0, fmt.Errorf("%s должен быть от 1 до %d", name, max)
+	}
+	return parsed, nil
+}
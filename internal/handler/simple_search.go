@@ -4,6 +4,9 @@ import (
 	"encoding/json"
 	"knowledge-base/internal/service"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
 
 	"github.com/gorilla/mux"
 )
@@ -18,12 +21,14 @@ func NewSimpleSearchHandler(simpleSearchService *service.SimpleSearchService) *S
 	return &SimpleSearchHandler{simpleSearchService: simpleSearchService}
 }
 
-// @Summary Search questions by tag name (exact match)
-// @Description Search questions by exact tag name
+// @Summary Search questions by tag name
+// @Description Search questions by tag name in any alphabet or keyboard layout: "докер", "docker" and "вщслук" find tag docker. When no question has the tag (e.g. a typo like "dokcer"), X-Did-You-Mean lists similar tag names and the response contains questions with the first of them
 // @Tags search 🔍
 // @Produce json
-// @Param name path string true "Tag name to search for (exact match)"
+// @Param name path string true "Tag name to search for"
 // @Success 200 {array} models.Question
+// @Header 200 {integer} X-Total-Count "Number of found questions"
+// @Header 200 {string} X-Did-You-Mean "Comma-separated URL-encoded similar tag names, only when nothing matched the name"
 // @Failure 400 {string} string "Tag name parameter is required"
 // @Router /simple-search/{name} [get]
 func (simpleSearchHandler *SimpleSearchHandler) SearchHandler(w http.ResponseWriter, r *http.Request) {
@@ -39,14 +44,25 @@ func (simpleSearchHandler *SimpleSearchHandler) SearchHandler(w http.ResponseWri
 	}
 
 	// Вызов сервиса.
-	questions, err := simpleSearchHandler.simpleSearchService.SearchLogic(name)
+	questions, suggestions, err := simpleSearchHandler.simpleSearchService.SearchLogic(r.Context(), name)
 	if err != nil {
-		http.Error(w, "Ошибка поиска: "+err.Error(), http.StatusInternalServerError)
+		serviceError(w, err, "Ошибка поиска: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
 
 	// Устанавливаем заголовок JSON.
 	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Total-Count", strconv.Itoa(len(questions)))
+
+	// Похожие имена тегов, если по самому имени ничего не найдено. Имена кодируются, чтобы в заголовке были только
+	// ASCII символы, и их можно было подставить в /simple-search/{name}.
+	if len(suggestions) > 0 {
+		escaped := make([]string, 0, len(suggestions))
+		for _, suggestion := range suggestions {
+			escaped = append(escaped, strings.ReplaceAll(url.PathEscape(suggestion), ",", "%2C"))
+		}
+		w.Header().Set("X-Did-You-Mean", strings.Join(escaped, ","))
+	}
 
 	// Кодируем результат в JSON формат и возвращаем.
 	err = json.NewEncoder(w).Encode(questions)
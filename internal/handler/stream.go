@@ -0,0 +1,132 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"knowledge-base/internal/logging"
+	"knowledge-base/internal/service"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// Тип содержимого для построчного JSON (одна запись на строку).
+const ndjsonContentType = "application/x-ndjson"
+
+// Через сколько записей сбрасывать буфер ответа клиенту.
+const streamFlushEvery = 100
+
+// Потоковая запись списка в ответ: JSON массив или NDJSON, если клиент передал Accept: application/x-ndjson.
+// Статус и заголовки отправляются при первой записи, поэтому до нее еще можно ответить ошибкой.
+type streamWriter struct {
+	w       http.ResponseWriter
+	encoder *json.Encoder
+	ndjson  bool
+	started bool
+	count   int
+}
+
+// Создание потоковой записи с форматом по заголовку Accept.
+func newStreamWriter(w http.ResponseWriter, r *http.Request) *streamWriter {
+	return &streamWriter{w: w, encoder: json.NewEncoder(w), ndjson: acceptsNDJSON(r)}
+}
+
+// Проверка, просит ли клиент NDJSON.
+func acceptsNDJSON(r *http.Request) bool {
+	for _, part := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err == nil && mediaType == ndjsonContentType {
+			return true
+		}
+	}
+	return false
+}
+
+// Отправка статуса, заголовков и начала массива.
+func (stream *streamWriter) start() error {
+	stream.started = true
+
+	// Устанавливаем заголовок формата ответа.
+	if stream.ndjson {
+		stream.w.Header().Set("Content-Type", ndjsonContentType)
+	} else {
+		stream.w.Header().Set("Content-Type", "application/json")
+	}
+	stream.w.WriteHeader(http.StatusOK)
+
+	if stream.ndjson {
+		return nil
+	}
+	_, err := stream.w.Write([]byte("["))
+	return err
+}
+
+// Write кодирует одну запись и периодически сбрасывает буфер клиенту.
+func (stream *streamWriter) Write(item interface{}) error {
+	if !stream.started {
+		if err := stream.start(); err != nil {
+			return err
+		}
+	} else if !stream.ndjson {
+		if _, err := stream.w.Write([]byte(",")); err != nil {
+			return err
+		}
+	}
+
+	// Encode сам добавляет перевод строки после записи.
+	if err := stream.encoder.Encode(item); err != nil {
+		return err
+	}
+
+	stream.count++
+	if stream.count%streamFlushEvery == 0 {
+		stream.flush()
+	}
+	return nil
+}
+
+// Close завершает массив (для пустого списка отправляет "[]") и сбрасывает буфер.
+func (stream *streamWriter) Close() error {
+	if !stream.started {
+		if err := stream.start(); err != nil {
+			return err
+		}
+	}
+
+	if !stream.ndjson {
+		if _, err := stream.w.Write([]byte("]\n")); err != nil {
+			return err
+		}
+	}
+
+	stream.flush()
+	return nil
+}
+
+// Сброс буфера, если ResponseWriter это поддерживает.
+func (stream *streamWriter) flush() {
+	http.NewResponseController(stream.w).Flush()
+}
+
+// Ошибка выгрузки. Если данные еще не отправлялись, клиент получает обычный ответ с ошибкой.
+// Иначе статус уже отправлен: ошибка пишется в лог, а ответ обрывается без закрывающей скобки массива,
+// чтобы клиент не принял неполный список за целый.
+func (stream *streamWriter) Fail(r *http.Request, err error, msg string) {
+	if !stream.started {
+		serviceError(stream.w, err, msg+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	logging.FromContext(r.Context()).Error("export interrupted", "rows", stream.count, "error", err.Error())
+}
+
+// Разбор параметров выгрузки: сортировка и фильтры как у списка, без страницы.
+func parseExportParams(r *http.Request, spec service.ListSpec) (service.ListParams, error) {
+	query := r.URL.Query()
+	for _, name := range []string{"limit", "offset", "cursor"} {
+		if query.Get(name) != "" {
+			return service.ListParams{}, fmt.Errorf("выгрузка возвращает все записи, параметр %s не поддерживается", name)
+		}
+	}
+	return parseListParams(r, spec)
+}
@@ -0,0 +1,231 @@
+package handler
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"knowledge-base/internal/models"
+	"knowledge-base/internal/service"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// Структура для работы со всеми ф-ями handler/synonym.go.
+type SynonymHandler struct {
+	synonymService *service.SynonymService
+}
+
+// Функция для создания объекта типа SynonymHandler.
+func NewSynonymHandler(synonymService *service.SynonymService) *SynonymHandler {
+	return &SynonymHandler{synonymService: synonymService}
+}
+
+// @Summary Get all synonym groups
+// @Description Returns all groups of equivalent terms used in search and tag lookup
+// @Tags synonyms
+// @Produce json
+// @Success 200 {array} models.SynonymGroup
+// @Router /synonyms [get]
+func (synonymHandler *SynonymHandler) GetAllSynonymGroups(w http.ResponseWriter, r *http.Request) {
+
+	// Вызов сервиса.
+	groups, err := synonymHandler.synonymService.GetAll(r.Context())
+	if err != nil {
+		serviceError(w, err, "Ошибка получения синонимов: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Устанавливаем заголовок JSON.
+	w.Header().Set("Content-Type", "application/json")
+
+	// Кодируем результат в JSON формат и возвращаем.
+	err = json.NewEncoder(w).Encode(groups)
+	if err != nil {
+		http.Error(w, "Ошибка кодирования JSON: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// @Summary Get synonym group by ID
+// @Tags synonyms
+// @Produce json
+// @Param id path int true "Synonym group ID"
+// @Success 200 {object} models.SynonymGroup
+// @Failure 400 {string} string "Invalid ID"
+// @Failure 404 {string} string "Synonym group not found"
+// @Router /synonyms/{id} [get]
+func (synonymHandler *SynonymHandler) GetSynonymGroupByID(w http.ResponseWriter, r *http.Request) {
+
+	//Разбиение пути handler на части.
+	vars := mux.Vars(r)
+	idStr := vars["id"]
+
+	//Преобразование строки в число.
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		http.Error(w, "Неверный ID", http.StatusBadRequest)
+		return
+	}
+
+	// Вызов сервиса.
+	group, err := synonymHandler.synonymService.GetByID(r.Context(), id)
+	if err != nil {
+		serviceError(w, err, "Группа синонимов не найдена", http.StatusNotFound)
+		return
+	}
+
+	// Устанавливаем заголовок JSON.
+	w.Header().Set("Content-Type", "application/json")
+
+	// Кодируем результат в JSON формат и возвращаем.
+	err = json.NewEncoder(w).Encode(group)
+	if err != nil {
+		http.Error(w, "Ошибка кодирования JSON: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// @Summary Create synonym group
+// @Description Creates a group of equivalent terms, e.g. ["kubernetes", "k8s"]. Changes apply to search immediately
+// @Tags synonyms
+// @Accept json
+// @Produce json
+// @Param group body models.SynonymGroupRequestBody true "Terms (2-20, up to 50 characters each)"
+// @Success 201 {object} models.SynonymGroup
+// @Failure 400 {string} string "Invalid terms"
+// @Failure 401 {string} string "Tutor or moderator token required"
+// @Failure 409 {string} string "Term already belongs to another group"
+// @Failure 413 {string} string "Request body too large"
+// @Failure 415 {string} string "Content-Type must be application/json"
+// @Router /synonyms [post]
+func (synonymHandler *SynonymHandler) PostSynonymGroup(w http.ResponseWriter, r *http.Request) {
+
+	var body models.SynonymGroupRequestBody
+
+	//Преобразование JSON данных в формат структуры models.SynonymGroupRequestBody.
+	err := decodeJSONBody(w, r, &body)
+	if err != nil {
+		http.Error(w, "Invalid JSON: "+err.Error(), bodyErrorStatus(err))
+		return
+	}
+
+	// Вызов сервиса.
+	group, err := synonymHandler.synonymService.Create(r.Context(), body.Terms)
+	if err != nil {
+		synonymError(w, err, "Ошибка создания группы синонимов: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Устанавливаем заголовок JSON.
+	w.Header().Set("Content-Type", "application/json")
+
+	//Возврат кода операции.
+	w.WriteHeader(http.StatusCreated)
+
+	// Кодируем результат в JSON формат.
+	json.NewEncoder(w).Encode(group)
+}
+
+// @Summary Replace synonym group terms
+// @Description Replaces all terms of the group. Changes apply to search immediately
+// @Tags synonyms
+// @Accept json
+// @Produce json
+// @Param id path int true "Synonym group ID"
+// @Param group body models.SynonymGroupRequestBody true "Terms (2-20, up to 50 characters each)"
+// @Success 200 {object} models.SynonymGroup
+// @Failure 400 {string} string "Invalid ID or terms"
+// @Failure 401 {string} string "Tutor or moderator token required"
+// @Failure 404 {string} string "Synonym group not found"
+// @Failure 409 {string} string "Term already belongs to another group"
+// @Failure 413 {string} string "Request body too large"
+// @Failure 415 {string} string "Content-Type must be application/json"
+// @Router /synonyms/{id} [put]
+func (synonymHandler *SynonymHandler) PutSynonymGroup(w http.ResponseWriter, r *http.Request) {
+
+	//Разбиение пути handler на части.
+	vars := mux.Vars(r)
+	idStr := vars["id"]
+
+	//Преобразование строки в число.
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		http.Error(w, "Неверный ID", http.StatusBadRequest)
+		return
+	}
+
+	var body models.SynonymGroupRequestBody
+
+	//Преобразование JSON данных в формат структуры models.SynonymGroupRequestBody.
+	err = decodeJSONBody(w, r, &body)
+	if err != nil {
+		http.Error(w, "Invalid JSON: "+err.Error(), bodyErrorStatus(err))
+		return
+	}
+
+	// Вызов сервиса.
+	group, err := synonymHandler.synonymService.Replace(r.Context(), id, body.Terms)
+	if err != nil {
+		synonymError(w, err, "Группа синонимов не найдена", http.StatusNotFound)
+		return
+	}
+
+	// Устанавливаем заголовок JSON.
+	w.Header().Set("Content-Type", "application/json")
+
+	// Кодируем результат в JSON формат и возвращаем.
+	err = json.NewEncoder(w).Encode(group)
+	if err != nil {
+		http.Error(w, "Ошибка кодирования JSON: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// @Summary Delete synonym group
+// @Tags synonyms
+// @Param id path int true "Synonym group ID"
+// @Success 204
+// @Failure 400 {string} string "Invalid ID"
+// @Failure 401 {string} string "Tutor or moderator token required"
+// @Failure 404 {string} string "Synonym group not found"
+// @Router /synonyms/{id} [delete]
+func (synonymHandler *SynonymHandler) DeleteSynonymGroupByID(w http.ResponseWriter, r *http.Request) {
+
+	//Разбиение пути handler на части.
+	vars := mux.Vars(r)
+	idStr := vars["id"]
+
+	//Преобразование строки в число.
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		http.Error(w, "Неверный ID", http.StatusBadRequest)
+		return
+	}
+
+	// Вызов сервиса.
+	err = synonymHandler.synonymService.DeleteByID(r.Context(), id)
+	if err != nil {
+		serviceError(w, err, "Группа синонимов не найдена", http.StatusNotFound)
+		return
+	}
+
+	//Возврат кода операции.
+	//  Успешный ответ - 204 No connect для удаления.
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Ответ на ошибку изменения группы синонимов: неверные термины - 400, занятый термин - 409.
+func synonymError(w http.ResponseWriter, err error, msg string, status int) {
+	switch {
+	case errors.Is(err, service.ErrInvalidSynonyms):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, service.ErrSynonymExists):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, sql.ErrNoRows):
+		http.Error(w, "Группа синонимов не найдена", http.StatusNotFound)
+	default:
+		serviceError(w, err, msg, status)
+	}
+}
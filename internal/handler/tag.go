@@ -1,11 +1,14 @@
 package handler
 
 import (
+	"database/sql"
 	"encoding/json"
+	"errors"
 	"knowledge-base/internal/models"
 	"knowledge-base/internal/service"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/gorilla/mux"
 )
@@ -21,23 +24,40 @@ func NewTagHandler(tagService *service.TagService) *TagHandler {
 }
 
 // @Summary Get all tags
-// @Description Returns list of all tags
+// @Description Returns paginated list of tags with sorting and filters
 // @Tags tags
 // @Produce json
+// @Param limit query int false "Page size (1-500, default 50)"
+// @Param offset query int false "Number of records to skip"
+// @Param sort query string false "Sort field (id, tag, tutor_id), prefix with - for descending"
+// @Param tutor_id query int false "Filter by tutor ID"
 // @Success 200 {array} models.Tag
+// @Header 200 {integer} X-Total-Count "Total number of records matching filters"
+// @Header 200 {string} Link "Links to next and prev pages"
+// @Failure 400 {string} string "Invalid pagination, sort or filter parameter"
 // @Router /tags [get]
 func (tagHandler *TagHandler) GetAllTags(w http.ResponseWriter, r *http.Request) {
 
+	// Разбор параметров пагинации, сортировки и фильтров.
+	params, err := parseListParams(r, service.TagListSpec)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	// Вызов сервиса.
-	tags, err := tagHandler.tagService.GetAll()
+	tags, total, err := tagHandler.tagService.GetAll(r.Context(), params)
 	if err != nil {
-		http.Error(w, "Ошибка получения тегов: "+err.Error(), http.StatusInternalServerError)
+		serviceError(w, err, "Ошибка получения тегов: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
 
 	// Устанавливаем заголовок JSON.
 	w.Header().Set("Content-Type", "application/json")
 
+	// Заголовки с общим количеством и ссылками на соседние страницы.
+	writeListHeaders(w, r, params, service.PageInfo{Total: total})
+
 	// Кодируем результат в JSON фомат и возвращаем.
 	err = json.NewEncoder(w).Encode(tags)
 	if err != nil {
@@ -46,6 +66,40 @@ func (tagHandler *TagHandler) GetAllTags(w http.ResponseWriter, r *http.Request)
 	}
 }
 
+// @Summary Export all tags
+// @Description Streams all tags matching filters as a JSON array or NDJSON (Accept: application/x-ndjson) without pagination
+// @Tags tags
+// @Produce json
+// @Produce application/x-ndjson
+// @Param sort query string false "Sort field (id, tag, tutor_id), prefix with - for descending"
+// @Param tutor_id query int false "Filter by tutor ID"
+// @Success 200 {array} models.Tag
+// @Failure 400 {string} string "Invalid sort or filter parameter"
+// @Failure 500 {string} string "Internal server error"
+// @Router /tags/export [get]
+func (tagHandler *TagHandler) ExportTags(w http.ResponseWriter, r *http.Request) {
+
+	// Разбор параметров сортировки и фильтров.
+	params, err := parseExportParams(r, service.TagListSpec)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Вызов сервиса: каждая запись сразу пишется в ответ.
+	stream := newStreamWriter(w, r)
+	err = tagHandler.tagService.Export(r.Context(), params, func(tag models.Tag) error {
+		return stream.Write(tag)
+	})
+	if err != nil {
+		stream.Fail(r, err, "Ошибка выгрузки тегов: ")
+		return
+	}
+
+	// Завершение массива и отправка остатка буфера.
+	stream.Close()
+}
+
 // @Summary Get tag by ID
 // @Description Returns tag by specified ID
 // @Tags tags
@@ -69,9 +123,9 @@ func (tagHandler *TagHandler) GetTagByID(w http.ResponseWriter, r *http.Request)
 	}
 
 	// Вызов сервиса.
-	tag, err := tagHandler.tagService.GetByID(id)
+	tag, err := tagHandler.tagService.GetByID(r.Context(), id)
 	if err != nil {
-		http.Error(w, "Тег не найден", http.StatusNotFound)
+		serviceError(w, err, "Тег не найден", http.StatusNotFound)
 		return
 	}
 
@@ -87,13 +141,13 @@ func (tagHandler *TagHandler) GetTagByID(w http.ResponseWriter, r *http.Request)
 }
 
 // @Summary Get tag by name
-// @Description Returns tag by tags name
+// @Description Returns tag by tags name. Name is matched in any alphabet or keyboard layout: "голанг" and "ujkfyu" find tag golang
 // @Tags tags
 // @Produce json
 // @Param name path string true "Tag Name"
 // @Success 200 {object} models.Tag
 // @Failure 400 {string} string "Invalid name""
-// @Failure 404 {string} string "Tag not found"
+// @Failure 404 {string} string "Tag not found, with similar tag names if any"
 // @Router /tags/name/{name} [get]
 func (tagHandler *TagHandler) GetTagByName(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
@@ -111,9 +165,13 @@ func (tagHandler *TagHandler) GetTagByName(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	tag, err := tagHandler.tagService.GetByName(name)
+	tag, err := tagHandler.tagService.GetByName(r.Context(), name)
+	if errors.Is(err, sql.ErrNoRows) {
+		tagNotFound(w, r, tagHandler.tagService, name)
+		return
+	}
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		serviceError(w, err, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
@@ -131,6 +189,7 @@ func (tagHandler *TagHandler) GetTagByName(w http.ResponseWriter, r *http.Reques
 // @Param id path int true "Tag ID"
 // @Success 204
 // @Failure 400 {string} string "Invalid ID"
+// @Failure 401 {string} string "Tutor or moderator token required"
 // @Failure 404 {string} string "Tag not found"
 // @Router /tags/{id} [delete]
 func (tagHandler *TagHandler) DeleteTagByID(w http.ResponseWriter, r *http.Request) {
@@ -147,9 +206,9 @@ func (tagHandler *TagHandler) DeleteTagByID(w http.ResponseWriter, r *http.Reque
 	}
 
 	// Вызов сервиса.
-	err = tagHandler.tagService.DeleteByID(id)
+	err = tagHandler.tagService.DeleteByID(r.Context(), id)
 	if err != nil {
-		http.Error(w, "Тег не найден", http.StatusNotFound)
+		serviceError(w, err, "Тег не найден", http.StatusNotFound)
 		return
 	}
 
@@ -166,6 +225,9 @@ func (tagHandler *TagHandler) DeleteTagByID(w http.ResponseWriter, r *http.Reque
 // @Param tag body models.TagSwaggerRequestBody true "Tag data"
 // @Success 201 {object} map[string]interface{} "Tag created"
 // @Failure 400 {string} string "Invalid request"
+// @Failure 401 {string} string "Tutor or moderator token required"
+// @Failure 413 {string} string "Request body too large"
+// @Failure 415 {string} string "Content-Type must be application/json"
 // @Failure 500 {string} string "Internal server error"
 // @Router /tags [post]
 func (tagHandler *TagHandler) PostTagString(w http.ResponseWriter, r *http.Request) {
@@ -173,9 +235,9 @@ func (tagHandler *TagHandler) PostTagString(w http.ResponseWriter, r *http.Reque
 	var tag models.TagSwaggerRequestBody
 
 	//Преобразование JSON данных в формат структуры models.TagSwaggerRequestBody.
-	err := json.NewDecoder(r.Body).Decode(&tag)
+	err := decodeJSONBody(w, r, &tag)
 	if err != nil {
-		http.Error(w, "Invalid JSON: "+err.Error(), http.StatusBadRequest)
+		http.Error(w, "Invalid JSON: "+err.Error(), bodyErrorStatus(err))
 		return
 	}
 
@@ -186,9 +248,9 @@ func (tagHandler *TagHandler) PostTagString(w http.ResponseWriter, r *http.Reque
 	}
 
 	// Вызов сервиса.
-	id, err := tagHandler.tagService.PostString(tag.Tag, tag.TutorID)
+	id, err := tagHandler.tagService.PostString(r.Context(), tag.Tag, tag.TutorID)
 	if err != nil {
-		http.Error(w, "Failed to create tag: "+err.Error(), http.StatusInternalServerError)
+		serviceError(w, err, "Failed to create tag: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
 
@@ -204,3 +266,15 @@ func (tagHandler *TagHandler) PostTagString(w http.ResponseWriter, r *http.Reque
 		"message": "Tag created successfully",
 	})
 }
+
+// Ответ 404 для тега по имени с подсказками похожих имен, если они есть.
+func tagNotFound(w http.ResponseWriter, r *http.Request, tagService *service.TagService, name string) {
+	msg := "Тег не найден"
+
+	suggestions, err := tagService.Suggest(r.Context(), name)
+	if err == nil && len(suggestions) > 0 {
+		msg += ". Возможно, вы имели в виду: " + strings.Join(suggestions, ", ")
+	}
+
+	http.Error(w, msg, http.StatusNotFound)
+}
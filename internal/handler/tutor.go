@@ -21,23 +21,39 @@ func NewTutorhandler(tutorService *service.TutorService) *TutorHandler {
 }
 
 // @Summary Get all tutors
-// @Description Returns list of all tutors
+// @Description Returns paginated list of tutors with sorting
 // @Tags tutors
 // @Produce json
+// @Param limit query int false "Page size (1-500, default 50)"
+// @Param offset query int false "Number of records to skip"
+// @Param sort query string false "Sort field (id, full_name, email), prefix with - for descending"
 // @Success 200 {array} models.Tutor
+// @Header 200 {integer} X-Total-Count "Total number of records matching filters"
+// @Header 200 {string} Link "Links to next and prev pages"
+// @Failure 400 {string} string "Invalid pagination, sort or filter parameter"
 // @Router /tutors [get]
 func (tutorHandler *TutorHandler) GetAllTutors(w http.ResponseWriter, r *http.Request) {
 
+	// Разбор параметров пагинации, сортировки и фильтров.
+	params, err := parseListParams(r, service.TutorListSpec)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	// Вызов сервиса.
-	tutors, err := tutorHandler.tutorService.GetAll()
+	tutors, total, err := tutorHandler.tutorService.GetAll(r.Context(), params)
 	if err != nil {
-		http.Error(w, "Ошибка получения тьюторов: "+err.Error(), http.StatusInternalServerError)
+		serviceError(w, err, "Ошибка получения тьюторов: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
 
 	// Устанавливаем заголовок JSON.
 	w.Header().Set("Content-Type", "application/json")
 
+	// Заголовки с общим количеством и ссылками на соседние страницы.
+	writeListHeaders(w, r, params, service.PageInfo{Total: total})
+
 	// Кодируем результат в JSON фомат и возвращаем.
 	err = json.NewEncoder(w).Encode(tutors)
 	if err != nil {
@@ -46,6 +62,39 @@ func (tutorHandler *TutorHandler) GetAllTutors(w http.ResponseWriter, r *http.Re
 	}
 }
 
+// @Summary Export all tutors
+// @Description Streams all tutors matching filters as a JSON array or NDJSON (Accept: application/x-ndjson) without pagination
+// @Tags tutors
+// @Produce json
+// @Produce application/x-ndjson
+// @Param sort query string false "Sort field (id, full_name, email), prefix with - for descending"
+// @Success 200 {array} models.Tutor
+// @Failure 400 {string} string "Invalid sort or filter parameter"
+// @Failure 500 {string} string "Internal server error"
+// @Router /tutors/export [get]
+func (tutorHandler *TutorHandler) ExportTutors(w http.ResponseWriter, r *http.Request) {
+
+	// Разбор параметров сортировки и фильтров.
+	params, err := parseExportParams(r, service.TutorListSpec)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Вызов сервиса: каждая запись сразу пишется в ответ.
+	stream := newStreamWriter(w, r)
+	err = tutorHandler.tutorService.Export(r.Context(), params, func(tutor models.Tutor) error {
+		return stream.Write(tutor)
+	})
+	if err != nil {
+		stream.Fail(r, err, "Ошибка выгрузки тьюторов: ")
+		return
+	}
+
+	// Завершение массива и отправка остатка буфера.
+	stream.Close()
+}
+
 // @Summary Get tutor by ID
 // @Description Returns tutor by specified ID
 // @Tags tutors
@@ -69,9 +118,9 @@ func (tutorHandler *TutorHandler) GetTutorByID(w http.ResponseWriter, r *http.Re
 	}
 
 	// Вызов сервиса.
-	tutor, err := tutorHandler.tutorService.GetByID(id)
+	tutor, err := tutorHandler.tutorService.GetByID(r.Context(), id)
 	if err != nil {
-		http.Error(w, "Тьютор не найден", http.StatusNotFound)
+		serviceError(w, err, "Тьютор не найден", http.StatusNotFound)
 		return
 	}
 
@@ -92,6 +141,7 @@ func (tutorHandler *TutorHandler) GetTutorByID(w http.ResponseWriter, r *http.Re
 // @Param id path int true "Tutor ID"
 // @Success 204
 // @Failure 400 {string} string "Invalid ID"
+// @Failure 401 {string} string "Tutor or moderator token required"
 // @Failure 404 {string} string "Tutor not found"
 // @Router /tutors/{id} [delete]
 func (tutorHandler *TutorHandler) DeleteTutorByID(w http.ResponseWriter, r *http.Request) {
@@ -108,9 +158,9 @@ func (tutorHandler *TutorHandler) DeleteTutorByID(w http.ResponseWriter, r *http
 	}
 
 	// Вызов сервиса.
-	err = tutorHandler.tutorService.DeleteByID(id)
+	err = tutorHandler.tutorService.DeleteByID(r.Context(), id)
 	if err != nil {
-		http.Error(w, "Тьютор не найден", http.StatusNotFound)
+		serviceError(w, err, "Тьютор не найден", http.StatusNotFound)
 		return
 	}
 
@@ -127,6 +177,9 @@ func (tutorHandler *TutorHandler) DeleteTutorByID(w http.ResponseWriter, r *http
 // @Param tutor body models.TutorSwaggerRequestBody true "Tutor data"
 // @Success 201 {object} map[string]interface{} "Tutor created"
 // @Failure 400 {string} string "Invalid request"
+// @Failure 401 {string} string "Tutor or moderator token required"
+// @Failure 413 {string} string "Request body too large"
+// @Failure 415 {string} string "Content-Type must be application/json"
 // @Failure 500 {string} string "Internal server error"
 // @Router /tutors [post]
 func (tutorHandler *TutorHandler) PostTutorString(w http.ResponseWriter, r *http.Request) {
@@ -134,9 +187,9 @@ func (tutorHandler *TutorHandler) PostTutorString(w http.ResponseWriter, r *http
 	var tutor models.Tutor
 
 	//Преобразование JSON данных в формат структуры models.Tutor.
-	err := json.NewDecoder(r.Body).Decode(&tutor)
+	err := decodeJSONBody(w, r, &tutor)
 	if err != nil {
-		http.Error(w, "Invalid JSON: "+err.Error(), http.StatusBadRequest)
+		http.Error(w, "Invalid JSON: "+err.Error(), bodyErrorStatus(err))
 		return
 	}
 
@@ -147,9 +200,9 @@ func (tutorHandler *TutorHandler) PostTutorString(w http.ResponseWriter, r *http
 	}
 
 	// Вызов сервиса.
-	id, err := tutorHandler.tutorService.PostString(tutor.FullName, tutor.Email)
+	id, err := tutorHandler.tutorService.PostString(r.Context(), tutor.FullName, tutor.Email)
 	if err != nil {
-		http.Error(w, "Failed to create tutor: "+err.Error(), http.StatusInternalServerError)
+		serviceError(w, err, "Failed to create tutor: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
 
@@ -175,6 +228,9 @@ func (tutorHandler *TutorHandler) PostTutorString(w http.ResponseWriter, r *http
 // @Param tutor body models.TutorSwaggerRequestBody true "Tutor data"
 // @Success 200 {object} map[string]string "Tutor updated"
 // @Failure 400 {string} string "Invalid request"
+// @Failure 401 {string} string "Tutor or moderator token required"
+// @Failure 413 {string} string "Request body too large"
+// @Failure 415 {string} string "Content-Type must be application/json"
 // @Failure 404 {string} string "Internal server error"
 // @Router /tutors/{id} [put]
 func (tutorHandler *TutorHandler) PutTutorString(w http.ResponseWriter, r *http.Request) {
@@ -193,9 +249,9 @@ func (tutorHandler *TutorHandler) PutTutorString(w http.ResponseWriter, r *http.
 	var tutor models.TutorSwaggerRequestBody
 
 	//Преобразование JSON данных в формат структуры models.Tutor.
-	err = json.NewDecoder(r.Body).Decode(&tutor)
+	err = decodeJSONBody(w, r, &tutor)
 	if err != nil {
-		http.Error(w, "Invalid JSON: "+err.Error(), http.StatusBadRequest)
+		http.Error(w, "Invalid JSON: "+err.Error(), bodyErrorStatus(err))
 		return
 	}
 
@@ -206,9 +262,9 @@ func (tutorHandler *TutorHandler) PutTutorString(w http.ResponseWriter, r *http.
 	}
 
 	// Вызов сервиса.
-	updatedTutor, err := tutorHandler.tutorService.PutString(tutor.FullName, tutor.Email, id)
+	updatedTutor, err := tutorHandler.tutorService.PutString(r.Context(), tutor.FullName, tutor.Email, id)
 	if err != nil {
-		http.Error(w, "Тьютор не найден", http.StatusNotFound)
+		serviceError(w, err, "Тьютор не найден", http.StatusNotFound)
 		return
 	}
 
@@ -0,0 +1,143 @@
+package handler
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"knowledge-base/internal/models"
+	"knowledge-base/internal/service"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// @Summary Change question status
+// @Description Moves the question through the editorial workflow: draft -> in_review (tutor), in_review -> published or back to draft with a comment (moderator), published <-> archived (moderator), archived -> draft (tutor). The change is recorded as a new question version
+// @Tags questions
+// @Accept json
+// @Produce json
+// @Param id path int true "Question ID"
+// @Param Authorization header string true "Bearer token of tutor (TUTOR_TOKEN) or moderator (MODERATOR_TOKEN)"
+// @Param status body models.StatusChangeRequestBody true "New status, tutor and review comment"
+// @Success 200 {object} models.Question
+// @Failure 400 {string} string "Invalid ID, status, tutor or missing comment"
+// @Failure 401 {string} string "Tutor or moderator token required"
+// @Failure 403 {string} string "Role is not allowed to make this transition"
+// @Failure 404 {string} string "Question not found"
+// @Failure 409 {string} string "Transition is not allowed from the current status"
+// @Failure 413 {string} string "Request body too large"
+// @Failure 415 {string} string "Content-Type must be application/json"
+// @Router /questions/{id}/status [put]
+func (questionHandler *QuestionHandler) PutQuestionStatus(w http.ResponseWriter, r *http.Request) {
+
+	//Разбиение пути handler на части.
+	vars := mux.Vars(r)
+	idStr := vars["id"]
+
+	//Преобразование строк в число.
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		http.Error(w, "Неверный ID", http.StatusBadRequest)
+		return
+	}
+
+	var body models.StatusChangeRequestBody
+
+	//Преобразование JSON данных в формат структуры models.StatusChangeRequestBody.
+	err = decodeJSONBody(w, r, &body)
+	if err != nil {
+		http.Error(w, "Invalid JSON: "+err.Error(), bodyErrorStatus(err))
+		return
+	}
+
+	// Вызов сервиса.
+	question, err := questionHandler.questionService.ChangeStatus(r.Context(), id, body.Status, body.TutorID, body.Comment)
+	if err != nil {
+		workflowError(w, err, "Ошибка смены статуса вопроса: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Устанавливаем заголовок JSON.
+	w.Header().Set("Content-Type", "application/json")
+
+	// Кодируем результат в JSON фомат и возвращаем.
+	err = json.NewEncoder(w).Encode(question)
+	if err != nil {
+		http.Error(w, "Ошибка кодирования JSON: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// @Summary Change answer status
+// @Description Moves the answer through the editorial workflow, with the same transitions as questions. The change is recorded as a new answer version
+// @Tags answers
+// @Accept json
+// @Produce json
+// @Param id path int true "Answer ID"
+// @Param Authorization header string true "Bearer token of tutor (TUTOR_TOKEN) or moderator (MODERATOR_TOKEN)"
+// @Param status body models.StatusChangeRequestBody true "New status, tutor and review comment"
+// @Success 200 {object} models.Answer
+// @Failure 400 {string} string "Invalid ID, status, tutor or missing comment"
+// @Failure 401 {string} string "Tutor or moderator token required"
+// @Failure 403 {string} string "Role is not allowed to make this transition"
+// @Failure 404 {string} string "Answer not found"
+// @Failure 409 {string} string "Transition is not allowed from the current status"
+// @Failure 413 {string} string "Request body too large"
+// @Failure 415 {string} string "Content-Type must be application/json"
+// @Router /answers/{id}/status [put]
+func (answerHandler *AnswerHandler) PutAnswerStatus(w http.ResponseWriter, r *http.Request) {
+
+	//Разбиение пути handler на части.
+	vars := mux.Vars(r)
+	idStr := vars["id"]
+
+	//Преобразование строк в число.
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		http.Error(w, "Неверный ID", http.StatusBadRequest)
+		return
+	}
+
+	var body models.StatusChangeRequestBody
+
+	//Преобразование JSON данных в формат структуры models.StatusChangeRequestBody.
+	err = decodeJSONBody(w, r, &body)
+	if err != nil {
+		http.Error(w, "Invalid JSON: "+err.Error(), bodyErrorStatus(err))
+		return
+	}
+
+	// Вызов сервиса.
+	answer, err := answerHandler.answerService.ChangeStatus(r.Context(), id, body.Status, body.TutorID, body.Comment)
+	if err != nil {
+		workflowError(w, err, "Ошибка смены статуса ответа: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Устанавливаем заголовок JSON.
+	w.Header().Set("Content-Type", "application/json")
+
+	// Кодируем результат в JSON фомат и возвращаем.
+	err = json.NewEncoder(w).Encode(answer)
+	if err != nil {
+		http.Error(w, "Ошибка кодирования JSON: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// Ответ на ошибку смены статуса: 400 для неверного статуса, 403 для перехода не по роли, 409 для недопустимого перехода.
+func workflowError(w http.ResponseWriter, err error, msg string, status int) {
+	switch {
+	case errors.Is(err, service.ErrInvalidStatus):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, service.ErrStatusForbidden):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case errors.Is(err, service.ErrStatusTransition):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, sql.ErrNoRows):
+		http.Error(w, "Запись не найдена", http.StatusNotFound)
+	default:
+		serviceError(w, err, msg, status)
+	}
+}
@@ -0,0 +1,76 @@
+package metrics
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Время на запрос доменных счетчиков при одном опросе /metrics.
+const domainScrapeTimeout = 3 * time.Second
+
+// Коллектор доменных метрик. Значения считаются запросом к БД при каждом опросе /metrics,
+// поэтому они всегда совпадают с данными в таблицах и переживают перезапуск приложения.
+type domainCollector struct {
+	db *sql.DB
+
+	questions  *prometheus.Desc
+	unanswered *prometheus.Desc
+	tags       *prometheus.Desc
+	versions   *prometheus.Desc
+	deletions  *prometheus.Desc
+}
+
+func newDomainCollector(db *sql.DB) *domainCollector {
+	return &domainCollector{
+		db:         db,
+		questions:  prometheus.NewDesc("kb_questions", "Total number of questions.", nil, nil),
+		unanswered: prometheus.NewDesc("kb_questions_unanswered", "Number of questions without an answer.", nil, nil),
+		tags:       prometheus.NewDesc("kb_tags", "Total number of tags.", nil, nil),
+		versions:   prometheus.NewDesc("kb_versions_written", "Number of versions written by entity.", []string{"entity"}, nil),
+		deletions:  prometheus.NewDesc("kb_deletions", "Number of deleted entities recorded in version history.", []string{"entity"}, nil),
+	}
+}
+
+func (c *domainCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.questions
+	ch <- c.unanswered
+	ch <- c.tags
+	ch <- c.versions
+	ch <- c.deletions
+}
+
+func (c *domainCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), domainScrapeTimeout)
+	defer cancel()
+
+	// Все счетчики одним запросом.
+	var query string = `select
+		(select count(*) from questions),
+		(select count(*) from questions q where not exists (select 1 from answers a where a.question_id = q.id)),
+		(select count(*) from tags),
+		(select count(*) from question_versions),
+		(select count(*) from answer_versions),
+		(select count(distinct question_id) from question_versions where is_delete),
+		(select count(distinct answer_id) from answer_versions where is_delete)`
+
+	var questions, unanswered, tags, questionVersions, answerVersions, questionDeletions, answerDeletions float64
+	err := c.db.QueryRowContext(ctx, query).Scan(&questions, &unanswered, &tags,
+		&questionVersions, &answerVersions, &questionDeletions, &answerDeletions)
+	if err != nil {
+		slog.Error("failed to collect domain metrics", "error", err.Error())
+		ch <- prometheus.NewInvalidMetric(c.questions, err)
+		return
+	}
+
+	ch <- prometheus.MustNewConstMetric(c.questions, prometheus.GaugeValue, questions)
+	ch <- prometheus.MustNewConstMetric(c.unanswered, prometheus.GaugeValue, unanswered)
+	ch <- prometheus.MustNewConstMetric(c.tags, prometheus.GaugeValue, tags)
+	ch <- prometheus.MustNewConstMetric(c.versions, prometheus.GaugeValue, questionVersions, "question")
+	ch <- prometheus.MustNewConstMetric(c.versions, prometheus.GaugeValue, answerVersions, "answer")
+	ch <- prometheus.MustNewConstMetric(c.deletions, prometheus.GaugeValue, questionDeletions, "question")
+	ch <- prometheus.MustNewConstMetric(c.deletions, prometheus.GaugeValue, answerDeletions, "answer")
+}
@@ -0,0 +1,117 @@
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Отдельный реестр, чтобы в /metrics были только метрики приложения, Go рантайма и процесса.
+var registry = prometheus.NewRegistry()
+
+var (
+	// Количество HTTP запросов по маршруту, методу и статусу.
+	httpRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_requests_total",
+		Help: "Number of HTTP requests by route template, method and status code.",
+	}, []string{"route", "method", "status"})
+
+	// Время обработки HTTP запросов по маршруту и методу.
+	httpDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "http_request_duration_seconds",
+		Help:    "HTTP request latency by route template and method.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"route", "method"})
+
+	// Время выполнения вызовов сервисов, которые работают с БД.
+	queryDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "db_query_duration_seconds",
+		Help:    "Duration of database calls by service method.",
+		Buckets: []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5},
+	}, []string{"method"})
+)
+
+func init() {
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		httpRequests,
+		httpDuration,
+		queryDuration,
+	)
+}
+
+// RegisterDB добавляет метрики пула соединений и доменные счетчики, которые читаются из БД.
+func RegisterDB(db *sql.DB) {
+	registry.MustRegister(
+		collectors.NewDBStatsCollector(db, "knowledge_db"),
+		newDomainCollector(db),
+	)
+}
+
+// Handler отдает метрики в текстовом формате Prometheus.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+}
+
+// ObserveQuery записывает время выполнения вызова сервиса.
+func ObserveQuery(method string, duration time.Duration) {
+	queryDuration.WithLabelValues(method).Observe(duration.Seconds())
+}
+
+// Обертка над http.ResponseWriter, которая запоминает статус ответа.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (rec *statusRecorder) WriteHeader(status int) {
+	rec.status = status
+	rec.ResponseWriter.WriteHeader(status)
+}
+
+// Flush пробрасывает сброс буфера, если его поддерживает исходный ResponseWriter.
+func (rec *statusRecorder) Flush() {
+	if flusher, ok := rec.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Unwrap нужен http.ResponseController для доступа к исходному ResponseWriter.
+func (rec *statusRecorder) Unwrap() http.ResponseWriter {
+	return rec.ResponseWriter
+}
+
+// Middleware считает запросы и время их обработки.
+// В метку route пишется шаблон маршрута mux, а не реальный путь, чтобы число серий не росло с каждым ID.
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+
+		next.ServeHTTP(rec, r)
+
+		route := routeTemplate(r)
+		httpRequests.WithLabelValues(route, r.Method, strconv.Itoa(rec.status)).Inc()
+		httpDuration.WithLabelValues(route, r.Method).Observe(time.Since(start).Seconds())
+	})
+}
+
+// Шаблон маршрута mux или "unmatched", если запрос не попал ни в один маршрут.
+func routeTemplate(r *http.Request) string {
+	route := mux.CurrentRoute(r)
+	if route == nil {
+		return "unmatched"
+	}
+	template, err := route.GetPathTemplate()
+	if err != nil {
+		return "unmatched"
+	}
+	return template
+}
@@ -0,0 +1,61 @@
+package middleware
+
+import (
+	"encoding/json"
+	"fmt"
+	"knowledge-base/internal/logging"
+	"net/http"
+	"runtime/debug"
+)
+
+// Ответ об ошибке в формате application/problem+json (RFC 9457).
+type problem struct {
+	Type      string `json:"type"`
+	Title     string `json:"title"`
+	Status    int    `json:"status"`
+	Detail    string `json:"detail,omitempty"`
+	Instance  string `json:"instance,omitempty"`
+	RequestID string `json:"request_id,omitempty"`
+}
+
+// Recover перехватывает панику в хэндлере, пишет ее в лог со стеком и возвращает клиенту 500.
+func Recover(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			recovered := recover()
+			if recovered == nil {
+				return
+			}
+
+			// Паника для прерывания ответа должна обрабатываться сервером как обычно.
+			if recovered == http.ErrAbortHandler {
+				panic(recovered)
+			}
+
+			logging.FromContext(r.Context()).Error("panic recovered",
+				"panic", fmt.Sprint(recovered),
+				"method", r.Method,
+				"path", r.URL.Path,
+				"stack", string(debug.Stack()),
+			)
+
+			writeProblem(w, r, http.StatusInternalServerError, "Внутренняя ошибка сервера")
+		}()
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Запись ответа об ошибке в формате application/problem+json.
+func writeProblem(w http.ResponseWriter, r *http.Request, status int, detail string) {
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(problem{
+		Type:      "about:blank",
+		Title:     http.StatusText(status),
+		Status:    status,
+		Detail:    detail,
+		Instance:  r.URL.Path,
+		RequestID: logging.RequestID(r.Context()),
+	})
+}
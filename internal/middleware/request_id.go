@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"knowledge-base/internal/logging"
+	"net/http"
+)
+
+// Заголовок, в котором передается ID запроса.
+const RequestIDHeader = "X-Request-ID"
+
+// Максимальная длина ID запроса, принятого от клиента.
+const maxRequestIDLength = 128
+
+// RequestID берет ID запроса из заголовка X-Request-ID или генерирует новый.
+// ID возвращается клиенту в том же заголовке и кладется в контекст запроса.
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID := r.Header.Get(RequestIDHeader)
+		if !validRequestID(requestID) {
+			requestID = newRequestID()
+		}
+
+		w.Header().Set(RequestIDHeader, requestID)
+		next.ServeHTTP(w, r.WithContext(logging.WithRequestID(r.Context(), requestID)))
+	})
+}
+
+// Проверка ID от клиента: непустой, ограниченной длины и только из печатных ASCII символов.
+func validRequestID(requestID string) bool {
+	if requestID == "" || len(requestID) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(requestID); i++ {
+		if requestID[i] < 0x21 || requestID[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+// Генерация случайного ID из 16 байт в hex.
+func newRequestID() string {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "unknown"
+	}
+	return hex.EncodeToString(buf)
+}
@@ -0,0 +1,96 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"knowledge-base/internal/service"
+	"net/http"
+	"strings"
+)
+
+// Секретные токены ролей, которые выдаются тьюторам и модераторам. Пустой токен отключает роль.
+// Tutors - личные токены тьюторов (токен -> ID тьютора): кроме роли они определяют, какой тьютор вызывает.
+type RoleTokens struct {
+	Tutor     string
+	Moderator string
+	Tutors    map[string]int
+}
+
+// Токены ролей из настройки. Без настройки привилегированные роли недоступны.
+var roleTokens RoleTokens
+
+// SetRoleTokens задает токены ролей.
+func SetRoleTokens(tokens RoleTokens) {
+	roleTokens = tokens
+}
+
+// Role определяет роль вызывающего по токену из заголовка Authorization: Bearer <token>.
+// Роль берется только из токена, который знает сервер, заголовкам клиента о роли не доверяем.
+// Без заголовка вызывающий считается учащимся и видит только опубликованные вопросы и ответы. Неизвестный токен - 401.
+func Role(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header := r.Header.Get("Authorization")
+		if header == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		role, tutorID := tokenRole(header)
+		if role == "" {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="knowledge-base"`)
+			http.Error(w, "Неверный токен", http.StatusUnauthorized)
+			return
+		}
+
+		ctx := service.WithRole(r.Context(), role)
+		if tutorID != 0 {
+			ctx = service.WithTutorID(ctx, tutorID)
+		}
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// Роль по заголовку Authorization или пустая строка, если токен не подошел.
+// Для личного токена тьютора возвращается и ID тьютора, для общих токенов он равен 0.
+func tokenRole(header string) (string, int) {
+	token, ok := strings.CutPrefix(header, "Bearer ")
+	token = strings.TrimSpace(token)
+	if !ok || token == "" {
+		return "", 0
+	}
+
+	// Сравнение за постоянное время, чтобы токен нельзя было подобрать по времени ответа.
+	switch {
+	case roleTokens.Moderator != "" && subtle.ConstantTimeCompare([]byte(token), []byte(roleTokens.Moderator)) == 1:
+		return service.RoleModerator, 0
+	case roleTokens.Tutor != "" && subtle.ConstantTimeCompare([]byte(token), []byte(roleTokens.Tutor)) == 1:
+		return service.RoleTutor, 0
+	}
+
+	// Личные токены сравниваются все, чтобы время ответа не зависело от того, какой из них подошел.
+	tutorID := 0
+	for personal, id := range roleTokens.Tutors {
+		if personal != "" && subtle.ConstantTimeCompare([]byte(token), []byte(personal)) == 1 {
+			tutorID = id
+		}
+	}
+	if tutorID != 0 {
+		return service.RoleTutor, tutorID
+	}
+	return "", 0
+}
+
+// RequireStaff пропускает к маршруту только тьюторов и модераторов.
+// Без токена вызывающий - учащийся и получает 401, с токеном другой роли - 403.
+func RequireStaff(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch service.RoleFromContext(r.Context()) {
+		case service.RoleTutor, service.RoleModerator:
+			next.ServeHTTP(w, r)
+		case service.RoleLearner:
+			w.Header().Set("WWW-Authenticate", `Bearer realm="knowledge-base"`)
+			http.Error(w, "Нужен токен тьютора или модератора", http.StatusUnauthorized)
+		default:
+			http.Error(w, "Недостаточно прав", http.StatusForbidden)
+		}
+	})
+}
@@ -9,6 +9,23 @@ type Answer struct {
 	QuestionID  int       `db:"question_id" json:"question_id"`
 	CreatedAt   time.Time `db:"created_at" json:"created_at"`
 	IsEdit      bool      `db:"is_edit" json:"is_edit"`
+
+	// Принятый ответ вопроса. У вопроса не больше одного принятого ответа.
+	IsAccepted bool `db:"is_accepted" json:"is_accepted"`
+
+	// Порядок ответа среди ответов вопроса (принятый ответ всегда первый).
+	Position int `db:"position" json:"position"`
+
+	// Оценки учащихся "полезен / не полезен" и голоса тьюторов.
+	HelpfulCount    int `db:"helpful_count" json:"helpful_count"`
+	NotHelpfulCount int `db:"not_helpful_count" json:"not_helpful_count"`
+	Upvotes         int `db:"upvote_count" json:"upvotes"`
+
+	// Рейтинг ответа: голоса тьюторов + полезен - не полезен.
+	Score int `db:"score" json:"score"`
+
+	// Статус: draft, in_review, published или archived. Публично видны только опубликованные ответы опубликованных вопросов.
+	Status string `db:"status" json:"status"`
 }
 
 // Модель для swagger записи POST и PUT
@@ -17,3 +34,14 @@ type AnswersSwaggerRequestBody struct {
 	TutorID     *int   `json:"tutor_id,omitempty"`
 	QuestionID  int    `json:"question_id"`
 }
+
+// Модель для swagger выбора принятого ответа.
+type AcceptedAnswerRequestBody struct {
+	AnswerID int `json:"answer_id"`
+}
+
+// Модель для swagger порядка ответов вопроса.
+type AnswerOrderRequestBody struct {
+	// ID всех ответов вопроса в нужном порядке.
+	AnswerIDs []int `json:"answer_ids"`
+}
@@ -0,0 +1,24 @@
+package models
+
+// Модель для swagger оценки ответа учащимся.
+type AnswerFeedbackRequestBody struct {
+	// Был ли ответ полезен. Обязательное поле.
+	Helpful *bool `json:"helpful"`
+
+	// Необязательный комментарий, до 1000 символов.
+	Comment string `json:"comment,omitempty"`
+}
+
+// Ответ в отчете по полезности: доля оценок "полезен" и последние комментарии.
+type AnswerFeedbackStat struct {
+	Answer Answer `json:"answer"`
+
+	// Количество оценок учащихся.
+	Feedback int `json:"feedback"`
+
+	// Доля оценок "полезен" от 0 до 1.
+	HelpfulRatio float64 `json:"helpful_ratio"`
+
+	// Последние непустые комментарии, новые сначала.
+	RecentComments []string `json:"recent_comments"`
+}
@@ -0,0 +1,58 @@
+package models
+
+import "time"
+
+// Модель для swagger POST /ask: свободный вопрос.
+type AskRequestBody struct {
+	Question string `json:"question"`
+}
+
+// Ответ на свободный вопрос: лучший найденный ответ и альтернативы.
+type AskResult struct {
+	// Найден ли вопрос с уверенностью не ниже порога.
+	Answered bool `json:"answered"`
+
+	// Уверенность лучшего совпадения от 0 до 1.
+	Confidence float64 `json:"confidence"`
+
+	// Лучший вопрос и ответ на него, если answered.
+	Best *AskMatch `json:"best,omitempty"`
+
+	// Другие подходящие вопросы, самые похожие сначала.
+	Alternatives []AskMatch `json:"alternatives"`
+
+	// ID записи в списке вопросов без ответа, если answered = false.
+	DemandID *int `json:"demand_id,omitempty"`
+}
+
+// Вопрос с ответом и его сходство со свободным вопросом.
+type AskMatch struct {
+	Question   Question `json:"question"`
+	Answer     Answer   `json:"answer"`
+	Confidence float64  `json:"confidence"`
+}
+
+// Запрос, на который не нашлось ответа.
+type UnansweredDemand struct {
+	ID        int    `json:"id"`
+	QueryText string `json:"query_text"`
+
+	// Сколько раз задавали такой вопрос.
+	AskCount int `json:"ask_count"`
+
+	// Лучшая уверенность среди найденных вопросов.
+	BestConfidence float64 `json:"best_confidence"`
+
+	// open, resolved (создан вопрос question_id) или dismissed.
+	Status     string `json:"status"`
+	QuestionID *int   `json:"question_id"`
+
+	CreatedAt   time.Time `json:"created_at"`
+	LastAskedAt time.Time `json:"last_asked_at"`
+}
+
+// Модель для swagger POST /unanswered/{id}/question: текст нового вопроса (по умолчанию текст запроса) и автор.
+type DemandQuestionRequestBody struct {
+	QuestionText string `json:"question_text,omitempty"`
+	TutorID      *int   `json:"tutor_id"`
+}
@@ -0,0 +1,59 @@
+package models
+
+import "time"
+
+// Комментарий к вопросу или ответу. Ответы на комментарий вложены в Replies.
+type Comment struct {
+	ID int `json:"id"`
+
+	// Вопрос или ответ, к которому относится комментарий (заполнено одно из полей).
+	QuestionID *int `json:"question_id,omitempty"`
+	AnswerID   *int `json:"answer_id,omitempty"`
+
+	// Комментарий, на который это ответ. nil - начало ветки.
+	ParentID *int `json:"parent_id"`
+
+	// Автор: tutor или learner. Для тьютора заполнен tutor_id, author_name - имя тьютора или подпись учащегося.
+	AuthorType string `json:"author_type"`
+	TutorID    *int   `json:"tutor_id"`
+	AuthorName string `json:"author_name"`
+
+	// Текст. У удаленного комментария пустой.
+	Text string `json:"text"`
+
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+	IsEdit    bool       `json:"is_edit"`
+
+	// Мягкое удаление: комментарий остается в ветке, чтобы не потерять ответы на него.
+	IsDeleted     bool       `json:"is_deleted"`
+	DeletedAt     *time.Time `json:"deleted_at,omitempty"`
+	DeleteByTutor *int       `json:"delete_by_tutor,omitempty"`
+
+	// Тьюторы, упомянутые через @имя или @email.
+	Mentions []Tutor `json:"mentions"`
+
+	Replies []Comment `json:"replies,omitempty"`
+}
+
+// Модель для swagger нового комментария.
+type CommentRequestBody struct {
+	Text string `json:"text"`
+
+	// Автор-тьютор. Без tutor_id автор - учащийся.
+	TutorID *int `json:"tutor_id,omitempty"`
+
+	// Подпись учащегося, по умолчанию "Учащийся".
+	LearnerName string `json:"learner_name,omitempty"`
+
+	// Комментарий, на который отвечают.
+	ParentID *int `json:"parent_id,omitempty"`
+}
+
+// Модель для swagger изменения комментария.
+type CommentUpdateRequestBody struct {
+	Text string `json:"text"`
+
+	// Автор-тьютор. Комментарий учащегося изменяется без tutor_id с того же клиента.
+	TutorID *int `json:"tutor_id,omitempty"`
+}
@@ -3,4 +3,27 @@ package models
 type QuestionTag struct {
 	QuestionID int `db:"question_id" json:"question_id"`
 	TagID      int `db:"tag_id" json:"tag_id"`
+
+	// Тьютор, добавивший тег.
+	AddedBy *int `db:"added_by" json:"added_by"`
+
+	// Тег добавлен автоматически по подсказке.
+	IsAuto bool `db:"is_auto" json:"is_auto"`
+}
+
+// Модель для swagger PUT /questions/{id}/tags: новый набор тегов вопроса.
+type QuestionTagsRequestBody struct {
+	TagIDs []int `json:"tag_ids"`
+}
+
+// Подсказка тега для вопроса.
+type TagSuggestion struct {
+	TagID int    `json:"tag_id"`
+	Tag   string `json:"tag"`
+
+	// Уверенность от 0 до 1.
+	Confidence float64 `json:"confidence"`
+
+	// Откуда подсказка: question_text, answer_text (имя тега или синоним есть в тексте), co_occurrence (тег часто стоит рядом с тегами вопроса).
+	Sources []string `json:"sources"`
 }
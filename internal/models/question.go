@@ -8,6 +8,9 @@ type Question struct {
 	TutorID      *int      `db:"tutor_id" json:"tutor_id"`
 	CreatedAt    time.Time `db:"created_at" json:"created_at"`
 	IsEdit       bool      `db:"is_edit" json:"is_edit"`
+
+	// Статус: draft, in_review, published или archived. Публично видны только опубликованные вопросы.
+	Status string `db:"status" json:"status"`
 }
 
 // Модель для swagger записи POST и PUT
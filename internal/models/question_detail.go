@@ -0,0 +1,20 @@
+package models
+
+// Вопрос вместе со связанными данными для GET /questions/{id}?expand=...
+// Поля, которые не запрошены в expand, в ответ не попадают.
+type QuestionDetail struct {
+	Question
+	Answer   *Answer           `json:"answer,omitempty"`
+	Answers  []Answer          `json:"answers,omitempty"`
+	Tags     []Tag             `json:"tags,omitempty"`
+	Author   *Tutor            `json:"author,omitempty"`
+	Versions []QuestionVersion `json:"versions,omitempty"`
+	Stats    *QuestionStats    `json:"stats,omitempty"`
+}
+
+// Счетчики по вопросу.
+type QuestionStats struct {
+	VersionCount       int `json:"version_count"`
+	AnswerVersionCount int `json:"answer_version_count"`
+	TagCount           int `json:"tag_count"`
+}
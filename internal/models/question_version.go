@@ -11,4 +11,8 @@ type QuestionVersion struct {
 	VersionNumber int       `db:"version_number" json:"version_number"`
 	IsDelete      bool      `db:"is_delete" json:"is_delete"`
 	DeleteByTutor *int      `db:"delete_by_tutor" json:"delete_by_tutor"`
+
+	// Статус вопроса в этой версии и комментарий к смене статуса (например, причина отклонения).
+	Status        string `db:"status" json:"status"`
+	StatusComment string `db:"status_comment" json:"status_comment,omitempty"`
 }
@@ -0,0 +1,63 @@
+package models
+
+// Результат полнотекстового поиска: вопрос, лучший подходящий ответ и фрагменты с подсветкой.
+type SearchResult struct {
+	Question Question `json:"question"`
+
+	// ID ответа, если совпадение найдено в ответе.
+	AnswerID *int `json:"answer_id,omitempty"`
+
+	// Релевантность (ts_rank_cd), больше - лучше.
+	Rank float64 `json:"rank"`
+
+	// Фрагменты текста, найденные слова обрамлены тегами <mark></mark>.
+	QuestionHighlight string `json:"question_highlight"`
+	AnswerHighlight   string `json:"answer_highlight,omitempty"`
+}
+
+// Результат поиска по тегам: страница вопросов и количество найденных вопросов с каждым тегом.
+type TagSearchResult struct {
+	Total     int        `json:"total"`
+	Questions []Question `json:"questions"`
+	Facets    []TagFacet `json:"facets"`
+}
+
+// Количество найденных вопросов с тегом.
+type TagFacet struct {
+	TagID int    `json:"tag_id"`
+	Tag   string `json:"tag"`
+	Count int    `json:"count"`
+}
+
+// Результат нечеткого поиска. Если ничего не найдено, did_you_mean содержит похожие имена тегов.
+type FuzzySearchResult struct {
+	Total       int          `json:"total"`
+	Results     []FuzzyMatch `json:"results"`
+	Suggestions []string     `json:"did_you_mean,omitempty"`
+}
+
+// Вопрос, найденный нечетким поиском.
+type FuzzyMatch struct {
+	Question Question `json:"question"`
+
+	// Сходство с запросом от 0 до 1 (pg_trgm), больше - лучше.
+	Score float64 `json:"score"`
+
+	// Самый похожий тег вопроса, если вопрос найден по тегу.
+	Tag *string `json:"matched_tag,omitempty"`
+}
+
+// Результат поиска выбранным движком (SEARCH_BACKEND): postgres или index.
+type EngineSearchResult struct {
+	Total   int           `json:"total"`
+	Backend string        `json:"backend"`
+	Results []EngineMatch `json:"results"`
+}
+
+// Вопрос, найденный движком поиска.
+type EngineMatch struct {
+	Question Question `json:"question"`
+
+	// Релевантность: ts_rank_cd для postgres, BM25 для index. Больше - лучше.
+	Score float64 `json:"score"`
+}
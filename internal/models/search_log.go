@@ -0,0 +1,37 @@
+package models
+
+import "time"
+
+// Модель для swagger POST /search/clicks: какой результат поиска открыл клиент.
+type SearchClickRequestBody struct {
+	// ID поиска из заголовка X-Search-ID ответа.
+	SearchID   string `json:"search_id"`
+	QuestionID int    `json:"question_id"`
+
+	// Место результата в выдаче, начиная с 1.
+	Position *int `json:"position,omitempty"`
+}
+
+// Статистика поискового запроса за период.
+type SearchQueryStat struct {
+	// Запрос в нижнем регистре без лишних пробелов.
+	Query string `json:"query"`
+
+	// Сколько раз искали и сколько разных клиентов.
+	Searches int `json:"searches"`
+	Clients  int `json:"clients"`
+
+	// Сколько поисков ничего не нашли.
+	ZeroResults int `json:"zero_results"`
+
+	// Сколько поисков закончились переходом к результату и их доля.
+	Clicks           int     `json:"clicks"`
+	ClickThroughRate float64 `json:"click_through_rate"`
+
+	// Среднее место первого открытого результата.
+	AvgClickPosition *float64 `json:"avg_click_position"`
+
+	AvgResults     *float64  `json:"avg_results"`
+	AvgLatencyMs   float64   `json:"avg_latency_ms"`
+	LastSearchedAt time.Time `json:"last_searched_at"`
+}
@@ -0,0 +1,15 @@
+package models
+
+// Похожий вопрос и его сходство с исходным текстом.
+type SimilarQuestion struct {
+	Question Question `json:"question"`
+
+	// Косинусное сходство триграмм от 0 до 1, больше - похожее.
+	Score float64 `json:"score"`
+}
+
+// Ответ на создание вопроса, который похож на уже существующие.
+type DuplicateQuestionsResponse struct {
+	Message    string            `json:"message"`
+	Duplicates []SimilarQuestion `json:"duplicates"`
+}
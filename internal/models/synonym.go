@@ -0,0 +1,12 @@
+package models
+
+// Группа синонимов: равнозначные термины для поиска и поиска тегов.
+type SynonymGroup struct {
+	ID    int      `json:"id"`
+	Terms []string `json:"terms"`
+}
+
+// Модель для swagger POST и PUT.
+type SynonymGroupRequestBody struct {
+	Terms []string `json:"terms"`
+}
@@ -0,0 +1,13 @@
+package models
+
+// Модель для swagger смены статуса вопроса или ответа.
+type StatusChangeRequestBody struct {
+	// Новый статус: in_review, published, draft или archived.
+	Status string `json:"status"`
+
+	// Тьютор, который меняет статус. Записывается в версию.
+	TutorID int `json:"tutor_id"`
+
+	// Комментарий проверяющего. Обязателен при отклонении (in_review -> draft).
+	Comment string `json:"comment,omitempty"`
+}
@@ -2,9 +2,14 @@ package router
 
 import (
 	"knowledge-base/internal/app"
+	"knowledge-base/internal/metrics"
+	"knowledge-base/internal/middleware"
+	"knowledge-base/internal/tracing"
+	"net/http"
 
 	"github.com/gorilla/mux"
 	httpSwagger "github.com/swaggo/http-swagger"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gorilla/mux/otelmux"
 
 	"knowledge-base/internal/handler"
 )
@@ -13,6 +18,21 @@ import (
 func Setup(handlers *app.Handlers) *mux.Router {
 	router := mux.NewRouter()
 
+	// Цепочка middleware: спан трассировки, ID запроса, метрики, лог доступа, перехват паники, роль вызывающего.
+	middlewares := []mux.MiddlewareFunc{
+		otelmux.Middleware(tracing.ServiceName, otelmux.WithSpanNameFormatter(spanName)),
+		middleware.RequestID,
+		metrics.Middleware,
+		middleware.AccessLog,
+		middleware.Recover,
+		middleware.Role,
+	}
+	router.Use(middlewares...)
+
+	// Для ненайденных маршрутов mux не вызывает middleware, поэтому оборачиваем их отдельно.
+	router.NotFoundHandler = wrap(http.NotFoundHandler(), middlewares)
+	router.MethodNotAllowedHandler = wrap(http.HandlerFunc(methodNotAllowed), middlewares)
+
 	// Базовые маршруты (статус)
 	registerCommonRoutes(router)
 
@@ -24,7 +44,12 @@ func Setup(handlers *app.Handlers) *mux.Router {
 	registerQuestionVersionRoutes(router, handlers.QuestionVersion)
 	registerAnswerVersionRoutes(router, handlers.AnswerVersion)
 	registerQuestionTagRoutes(router, handlers.QuestionTag)
-	registerSearchRoutes(router, handlers.SimpleSearch)
+	registerSearchRoutes(router, handlers.SimpleSearch, handlers.SearchLog)
+	registerFullTextSearchRoutes(router, handlers.Search, handlers.SearchLog)
+	registerSynonymRoutes(router, handlers.Synonym)
+	registerAskRoutes(router, handlers.Ask)
+	registerSearchLogRoutes(router, handlers.SearchLog)
+	registerCommentRoutes(router, handlers.Comment)
 
 	// Документация
 	registerSwaggerRoutes(router)
@@ -32,10 +57,35 @@ func Setup(handlers *app.Handlers) *mux.Router {
 	return router
 }
 
+// Оборачивает хэндлер в цепочку middleware в том же порядке, что и router.Use.
+func wrap(handler http.Handler, middlewares []mux.MiddlewareFunc) http.Handler {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		handler = middlewares[i](handler)
+	}
+	return handler
+}
+
+// Маршрут только для тьюторов и модераторов: изменение базы знаний и отчеты /admin.
+// Учащимся открыты только чтение опубликованного, поиск, /ask, оценки ответов и свои комментарии.
+func staff(handler http.HandlerFunc) http.Handler {
+	return middleware.RequireStaff(handler)
+}
+
+// Имя спана запроса: метод и шаблон маршрута, например "GET /questions/{id}".
+func spanName(route string, r *http.Request) string {
+	return r.Method + " " + route
+}
+
+// Ответ для маршрута, который существует, но не поддерживает метод запроса.
+func methodNotAllowed(w http.ResponseWriter, r *http.Request) {
+	http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+}
+
 // registerCommonRoutes регистрирует общие маршруты.
 func registerCommonRoutes(router *mux.Router) {
 	router.HandleFunc("/", handler.StatusHandler).Methods("GET")
 	router.HandleFunc("/status", handler.StatusHandler).Methods("GET")
+	router.Handle("/metrics", metrics.Handler()).Methods("GET")
 }
 
 // Регистрирует маршруты для тьюторов.
@@ -45,10 +95,11 @@ func registerTutorRoutes(router *mux.Router, handler *handler.TutorHandler) {
 	subrouter := router.PathPrefix("/tutors").Subrouter()
 
 	subrouter.HandleFunc("", handler.GetAllTutors).Methods("GET")
+	subrouter.HandleFunc("/export", handler.ExportTutors).Methods("GET")
 	subrouter.HandleFunc("/{id}", handler.GetTutorByID).Methods("GET")
-	subrouter.HandleFunc("/{id}", handler.DeleteTutorByID).Methods("DELETE")
-	subrouter.HandleFunc("", handler.PostTutorString).Methods("POST")
-	subrouter.HandleFunc("/{id}", handler.PutTutorString).Methods("PUT")
+	subrouter.Handle("/{id}", staff(handler.DeleteTutorByID)).Methods("DELETE")
+	subrouter.Handle("", staff(handler.PostTutorString)).Methods("POST")
+	subrouter.Handle("/{id}", staff(handler.PutTutorString)).Methods("PUT")
 }
 
 // Регистрирует маршруты для вопросов.
@@ -58,10 +109,18 @@ func registerQuestionRoutes(router *mux.Router, handler *handler.QuestionHandler
 	subrouter := router.PathPrefix("/questions").Subrouter()
 
 	subrouter.HandleFunc("", handler.GetAllQuestions).Methods("GET")
+	subrouter.HandleFunc("/export", handler.ExportQuestions).Methods("GET")
 	subrouter.HandleFunc("/{id}", handler.GetQuestionByID).Methods("GET")
-	subrouter.HandleFunc("/{id}/deleteBy/{tutor_id}", handler.DeleteQuestionByID).Methods("DELETE")
-	subrouter.HandleFunc("", handler.PostQuestionString).Methods("POST")
-	subrouter.HandleFunc("/{id}", handler.PutQuestionString).Methods("PUT")
+	subrouter.HandleFunc("/{id}/similar", handler.GetSimilarQuestions).Methods("GET")
+	subrouter.HandleFunc("/{id}/suggested-tags", handler.GetSuggestedTags).Methods("GET")
+	subrouter.Handle("/{id}/deleteBy/{tutor_id}", staff(handler.DeleteQuestionByID)).Methods("DELETE")
+	subrouter.Handle("", staff(handler.PostQuestionString)).Methods("POST")
+	subrouter.Handle("/{id}", staff(handler.PutQuestionString)).Methods("PUT")
+	subrouter.Handle("/{id}/status", staff(handler.PutQuestionStatus)).Methods("PUT")
+
+	// Вложенные списки вопросов тьютора и тега.
+	router.HandleFunc("/tutors/{id}/questions", handler.GetQuestionsByTutorID).Methods("GET")
+	router.HandleFunc("/tags/{id}/questions", handler.GetQuestionsByTagID).Methods("GET")
 }
 
 // Регистрирует маршруты для ответов.
@@ -70,10 +129,26 @@ func registerAnswerRoutes(router *mux.Router, handler *handler.AnswerHandler) {
 	subrouter := router.PathPrefix("/answers").Subrouter()
 
 	subrouter.HandleFunc("", handler.GetAllAnswers).Methods("GET")
+	subrouter.HandleFunc("/export", handler.ExportAnswers).Methods("GET")
 	subrouter.HandleFunc("/{id}", handler.GetAnswerByID).Methods("GET")
-	subrouter.HandleFunc("/{id}/deleteBy/{tutor_id}", handler.DeleteAnswerByID).Methods("DELETE")
-	subrouter.HandleFunc("", handler.PostAnswerString).Methods("POST")
-	subrouter.HandleFunc("/{id}", handler.PutAnswerString).Methods("PUT")
+	subrouter.Handle("/{id}/deleteBy/{tutor_id}", staff(handler.DeleteAnswerByID)).Methods("DELETE")
+	subrouter.Handle("", staff(handler.PostAnswerString)).Methods("POST")
+	subrouter.Handle("/{id}", staff(handler.PutAnswerString)).Methods("PUT")
+	subrouter.Handle("/{id}/status", staff(handler.PutAnswerStatus)).Methods("PUT")
+
+	// Оценки учащихся и голоса тьюторов, отчет по полезности.
+	subrouter.HandleFunc("/{id}/feedback", handler.PostAnswerFeedback).Methods("POST")
+	subrouter.Handle("/{id}/upvotes", staff(handler.PostAnswerUpvote)).Methods("POST")
+	subrouter.Handle("/{id}/upvotes/{tutor_id}", staff(handler.DeleteAnswerUpvote)).Methods("DELETE")
+	router.Handle("/admin/answers/feedback", staff(handler.GetFeedbackReport)).Methods("GET")
+
+	// Ответы на вопрос: принятый ответ, все ответы и их порядок. Ответы тьютора.
+	router.HandleFunc("/questions/{id}/answer", handler.GetAnswerByQuestionID).Methods("GET")
+	router.HandleFunc("/questions/{id}/answers", handler.GetAnswersByQuestionID).Methods("GET")
+	router.Handle("/questions/{id}/answers/order", staff(handler.PutAnswerOrder)).Methods("PUT")
+	router.Handle("/questions/{id}/accepted-answer", staff(handler.PutAcceptedAnswer)).Methods("PUT")
+	router.Handle("/questions/{id}/accepted-answer", staff(handler.DeleteAcceptedAnswer)).Methods("DELETE")
+	router.HandleFunc("/tutors/{id}/answers", handler.GetAnswersByTutorID).Methods("GET")
 }
 
 // Регистрирует маршруты для тегов.
@@ -83,10 +158,11 @@ func registerTagRoutes(router *mux.Router, handler *handler.TagHandler) {
 	subrouter := router.PathPrefix("/tags").Subrouter()
 
 	subrouter.HandleFunc("", handler.GetAllTags).Methods("GET")
+	subrouter.HandleFunc("/export", handler.ExportTags).Methods("GET")
 	subrouter.HandleFunc("/{id}", handler.GetTagByID).Methods("GET")
 	subrouter.HandleFunc("/name/{name}", handler.GetTagByName).Methods("GET")
-	subrouter.HandleFunc("/{id}", handler.DeleteTagByID).Methods("DELETE")
-	subrouter.HandleFunc("", handler.PostTagString).Methods("POST")
+	subrouter.Handle("/{id}", staff(handler.DeleteTagByID)).Methods("DELETE")
+	subrouter.Handle("", staff(handler.PostTagString)).Methods("POST")
 }
 
 // Регистрирует маршруты для версий вопросов.
@@ -105,15 +181,74 @@ func registerQuestionTagRoutes(router *mux.Router, handler *handler.QuestionTagH
 	// Из за того, что PathPrefix возвращает Route, который управляет одним, а не множеством путей, надо вызвать Subrouter()
 	subrouter := router.PathPrefix("/question-tags").Subrouter()
 
-	subrouter.HandleFunc("/{question_id}/{tag_id}", handler.AddTagToQuestion).Methods("POST")
+	subrouter.Handle("/{question_id}/{tag_id}", staff(handler.AddTagToQuestion)).Methods("POST")
 	subrouter.HandleFunc("", handler.GetAllQuestionTagRelations).Methods("GET")
+	subrouter.HandleFunc("/export", handler.ExportQuestionTagRelations).Methods("GET")
 	subrouter.HandleFunc("/by-tag/{tag_id}", handler.GetAllQuestionTagRelationsByTagID).Methods("GET")
-	subrouter.HandleFunc("/{question_id}/{tag_id}", handler.DeleteQuestionTagRelationByID).Methods("DELETE")
+	subrouter.Handle("/{question_id}/{tag_id}", staff(handler.DeleteQuestionTagRelationByID)).Methods("DELETE")
+
+	// Теги вопроса: чтение и замена всего набора.
+	router.HandleFunc("/questions/{id}/tags", handler.GetQuestionTags).Methods("GET")
+	router.Handle("/questions/{id}/tags", staff(handler.PutQuestionTags)).Methods("PUT")
 }
 
-// Регистрирует регистрирует маршруты поиска.
-func registerSearchRoutes(router *mux.Router, handler *handler.SimpleSearchHandler) {
-	router.HandleFunc("/simple-search/{name}", handler.SearchHandler).Methods("GET")
+// Регистрирует регистрирует маршруты поиска. Каждый поиск записывается в журнал.
+func registerSearchRoutes(router *mux.Router, handler *handler.SimpleSearchHandler, searchLog *handler.SearchLogHandler) {
+	router.HandleFunc("/simple-search/{name}", searchLog.Track("simple-search", handler.SearchHandler)).Methods("GET")
+}
+
+// Регистрирует маршруты полнотекстового поиска. Каждый поиск записывается в журнал.
+func registerFullTextSearchRoutes(router *mux.Router, handler *handler.SearchHandler, searchLog *handler.SearchLogHandler) {
+	router.HandleFunc("/search", searchLog.Track("search", handler.Search)).Methods("GET")
+	router.HandleFunc("/search/tags", searchLog.Track("search/tags", handler.SearchByTags)).Methods("GET")
+	router.HandleFunc("/search/query", searchLog.Track("search/query", handler.SearchByQuery)).Methods("GET")
+	router.HandleFunc("/search/fuzzy", searchLog.Track("search/fuzzy", handler.FuzzySearch)).Methods("GET")
+	router.HandleFunc("/search/engine", searchLog.Track("search/engine", handler.EngineSearch)).Methods("GET")
+}
+
+// Регистрирует маршруты переходов к результатам поиска и отчетов по журналу поиска.
+func registerSearchLogRoutes(router *mux.Router, handler *handler.SearchLogHandler) {
+	router.HandleFunc("/search/clicks", handler.PostSearchClick).Methods("POST")
+	router.Handle("/admin/search/{report}", staff(handler.GetSearchReport)).Methods("GET")
+}
+
+// Регистрирует маршруты словаря синонимов.
+func registerSynonymRoutes(router *mux.Router, handler *handler.SynonymHandler) {
+	subrouter := router.PathPrefix("/synonyms").Subrouter()
+
+	subrouter.HandleFunc("", handler.GetAllSynonymGroups).Methods("GET")
+	subrouter.HandleFunc("/{id}", handler.GetSynonymGroupByID).Methods("GET")
+	subrouter.Handle("", staff(handler.PostSynonymGroup)).Methods("POST")
+	subrouter.Handle("/{id}", staff(handler.PutSynonymGroup)).Methods("PUT")
+	subrouter.Handle("/{id}", staff(handler.DeleteSynonymGroupByID)).Methods("DELETE")
+}
+
+// Регистрирует маршруты для свободных вопросов и списка вопросов без ответа.
+func registerAskRoutes(router *mux.Router, handler *handler.AskHandler) {
+	router.HandleFunc("/ask", handler.Ask).Methods("POST")
+
+	subrouter := router.PathPrefix("/unanswered").Subrouter()
+
+	subrouter.Handle("", staff(handler.GetUnanswered)).Methods("GET")
+	subrouter.Handle("/{id}/question", staff(handler.CreateQuestionFromUnanswered)).Methods("POST")
+	subrouter.Handle("/{id}/dismiss", staff(handler.DismissUnanswered)).Methods("POST")
+}
+
+// Регистрирует маршруты комментариев к вопросам и ответам.
+func registerCommentRoutes(router *mux.Router, handler *handler.CommentHandler) {
+	subrouter := router.PathPrefix("/comments").Subrouter()
+
+	subrouter.HandleFunc("/{id}", handler.GetCommentByID).Methods("GET")
+	subrouter.HandleFunc("/{id}", handler.PutComment).Methods("PUT")
+	subrouter.HandleFunc("/{id}", handler.DeleteOwnComment).Methods("DELETE")
+	subrouter.Handle("/{id}/deleteBy/{tutor_id}", staff(handler.DeleteCommentByTutor)).Methods("DELETE")
+
+	// Ветки комментариев вопроса и ответа, упоминания тьютора.
+	router.HandleFunc("/questions/{id}/comments", handler.GetQuestionComments).Methods("GET")
+	router.HandleFunc("/questions/{id}/comments", handler.PostQuestionComment).Methods("POST")
+	router.HandleFunc("/answers/{id}/comments", handler.GetAnswerComments).Methods("GET")
+	router.HandleFunc("/answers/{id}/comments", handler.PostAnswerComment).Methods("POST")
+	router.HandleFunc("/tutors/{id}/mentions", handler.GetTutorMentions).Methods("GET")
 }
 
 // Регистрирует регистрирует маршруты для Swagger.
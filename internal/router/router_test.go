@@ -0,0 +1,132 @@
+package router
+
+import (
+	"knowledge-base/internal/app"
+	"knowledge-base/internal/handler"
+	"knowledge-base/internal/middleware"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+// Маршруты, которые меняют данные, но открыты учащимся.
+var learnerRoutes = map[string]bool{
+	"POST /answers/{id}/feedback":   true,
+	"POST /search/clicks":           true,
+	"POST /ask":                     true,
+	"PUT /comments/{id}":            true,
+	"DELETE /comments/{id}":         true,
+	"POST /questions/{id}/comments": true,
+	"POST /answers/{id}/comments":   true,
+}
+
+// Маршрут с подставленными значениями переменных пути.
+var pathVar = regexp.MustCompile(`\{[^}]+\}`)
+
+// Маршрутизатор с хэндлерами без сервисов: до хэндлера запрос без прав дойти не должен.
+func testRouter() *mux.Router {
+	return Setup(&app.Handlers{
+		Tutor:           &handler.TutorHandler{},
+		Question:        &handler.QuestionHandler{},
+		Answer:          &handler.AnswerHandler{},
+		Tag:             &handler.TagHandler{},
+		QuestionVersion: &handler.QuestionVersionHandler{},
+		AnswerVersion:   &handler.AnswerVersionHandler{},
+		QuestionTag:     &handler.QuestionTagHandler{},
+		SimpleSearch:    &handler.SimpleSearchHandler{},
+		Search:          &handler.SearchHandler{},
+		Synonym:         &handler.SynonymHandler{},
+		Ask:             &handler.AskHandler{},
+		SearchLog:       &handler.SearchLogHandler{},
+		Comment:         &handler.CommentHandler{},
+	})
+}
+
+// Каждый изменяющий маршрут и каждый отчет /admin, кроме открытых учащимся, требует токен тьютора или модератора.
+func TestStaffRoutes(t *testing.T) {
+	middleware.SetRoleTokens(middleware.RoleTokens{Tutor: "tutor-secret", Moderator: "moderator-secret"})
+	defer middleware.SetRoleTokens(middleware.RoleTokens{})
+
+	router := testRouter()
+
+	tests := []struct {
+		name       string
+		header     string
+		wantStatus int
+	}{
+		{name: "без токена", wantStatus: http.StatusUnauthorized},
+		{name: "неверный токен", header: "Bearer guess", wantStatus: http.StatusUnauthorized},
+	}
+
+	checked := 0
+	seenLearner := map[string]bool{}
+	err := router.Walk(func(route *mux.Route, router *mux.Router, ancestors []*mux.Route) error {
+		template, err := route.GetPathTemplate()
+		if err != nil {
+			return nil
+		}
+		methods, err := route.GetMethods()
+		if err != nil {
+			return nil
+		}
+
+		for _, method := range methods {
+			key := method + " " + template
+			if learnerRoutes[key] {
+				seenLearner[key] = true
+				continue
+			}
+			if method == http.MethodGet && !strings.HasPrefix(template, "/admin/") && !strings.HasPrefix(template, "/unanswered") {
+				continue
+			}
+
+			checked++
+			path := pathVar.ReplaceAllString(template, "1")
+			for _, tt := range tests {
+				r := httptest.NewRequest(method, path, strings.NewReader(`{}`))
+				r.Header.Set("Content-Type", "application/json")
+				if tt.header != "" {
+					r.Header.Set("Authorization", tt.header)
+				}
+				w := httptest.NewRecorder()
+				router.ServeHTTP(w, r)
+
+				if w.Code != tt.wantStatus {
+					t.Errorf("%s, %s: status = %d, want %d", key, tt.name, w.Code, tt.wantStatus)
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if checked == 0 {
+		t.Fatal("не найдено ни одного маршрута для проверки")
+	}
+	for key := range learnerRoutes {
+		if !seenLearner[key] {
+			t.Errorf("маршрут %s для учащихся не зарегистрирован", key)
+		}
+	}
+}
+
+// Публичные маршруты чтения не требуют токена.
+func TestPublicRoutes(t *testing.T) {
+	router := testRouter()
+
+	for _, path := range []string{"/status", "/metrics"} {
+		r := httptest.NewRequest(http.MethodGet, path, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, r)
+
+		if w.Code != http.StatusOK {
+			t.Errorf("GET %s: status = %d, want %d", path, w.Code, http.StatusOK)
+		}
+	}
+}
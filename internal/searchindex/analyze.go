@@ -0,0 +1,67 @@
+package searchindex
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/kljensen/snowball/english"
+	"github.com/kljensen/snowball/russian"
+)
+
+// Разрыв позиций между фрагментами документа (вопрос и ответы), чтобы фраза не находилась на их стыке.
+const fragmentGap = 100
+
+// Основа слова и его позиция в тексте.
+type token struct {
+	term string
+	pos  int
+}
+
+// Разбиение текста на основы слов со Snowball стеммингом: русские слова - русским стеммером, остальные - английским.
+// Стоп-слова пропускаются, но занимают позицию, чтобы фразы сравнивались так же, как в исходном тексте.
+func analyze(text string, start int) ([]token, int) {
+	var tokens []token
+	pos := start
+
+	for _, word := range strings.FieldsFunc(strings.ToLower(text), isWordSeparator) {
+		word = strings.ReplaceAll(word, "ё", "е")
+		if term := stem(word); term != "" {
+			tokens = append(tokens, token{term: term, pos: pos})
+		}
+		pos++
+	}
+
+	return tokens, pos
+}
+
+// Слова состоят из букв и цифр.
+func isWordSeparator(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+}
+
+// Основа слова или пустая строка для стоп-слова.
+func stem(word string) string {
+	for _, r := range word {
+		if unicode.Is(unicode.Cyrillic, r) {
+			if russian.IsStopWord(word) {
+				return ""
+			}
+			return russian.Stem(word, false)
+		}
+	}
+
+	if english.IsStopWord(word) {
+		return ""
+	}
+	return english.Stem(word, false)
+}
+
+// Analyze возвращает основы слов текста в том виде, в котором они хранятся в индексе.
+func Analyze(text string) []string {
+	tokens, _ := analyze(text, 0)
+	terms := make([]string, 0, len(tokens))
+	for _, token := range tokens {
+		terms = append(terms, token.term)
+	}
+	return terms
+}
@@ -0,0 +1,336 @@
+package searchindex
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Параметры BM25.
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+// Тег документа: ID для удаления тега и ключ для поиска (нормализованное имя).
+type Tag struct {
+	ID  int
+	Key string
+}
+
+// Документ для индексации: вопрос с текстами ответов и тегами.
+type Document struct {
+	ID        int
+	Texts     []string
+	Tags      []Tag
+	CreatedAt time.Time
+}
+
+// Документ в индексе: позиции основ слов, длина и теги. Поля экспортируются для сохранения в файл.
+// После добавления в индекс Terms не меняется, а Tags только заменяется новым срезом (RemoveTag),
+// поэтому копию документа можно читать без блокировки.
+type document struct {
+	ID        int
+	Terms     map[string][]int
+	Length    int
+	Tags      []Tag
+	CreatedAt time.Time
+}
+
+// Найденный документ и его релевантность.
+type Hit struct {
+	ID    int
+	Score float64
+}
+
+// Index - обратный индекс в памяти. Безопасен для одновременного использования.
+type Index struct {
+	mu sync.RWMutex
+
+	docs map[int]*document
+
+	// Основа слова -> документ -> позиции.
+	postings map[string]map[int][]int
+
+	// Ключ тега -> документы.
+	tags map[string]map[int]bool
+
+	// Сумма длин документов для средней длины в BM25.
+	totalLength int
+
+	// Счетчик изменений и его значение в последнем сохраненном снимке.
+	changes uint64
+	saved   uint64
+
+	// Сохранения идут по очереди, чтобы более старый снимок не заменил файл более нового.
+	saveMu sync.Mutex
+}
+
+// New создает пустой индекс.
+func New() *Index {
+	return &Index{
+		docs:     make(map[int]*document),
+		postings: make(map[string]map[int][]int),
+		tags:     make(map[string]map[int]bool),
+	}
+}
+
+// Put добавляет документ или заменяет документ с тем же ID.
+func (index *Index) Put(doc Document) {
+	stored := &document{ID: doc.ID, Terms: make(map[string][]int), Tags: doc.Tags, CreatedAt: doc.CreatedAt}
+
+	pos := 0
+	for _, text := range doc.Texts {
+		var tokens []token
+		tokens, pos = analyze(text, pos)
+		for _, token := range tokens {
+			stored.Terms[token.term] = append(stored.Terms[token.term], token.pos)
+		}
+		stored.Length += len(tokens)
+		pos += fragmentGap
+	}
+
+	index.mu.Lock()
+	defer index.mu.Unlock()
+
+	index.remove(doc.ID)
+	index.add(stored)
+	index.changes++
+}
+
+// Remove удаляет документ, если он есть.
+func (index *Index) Remove(id int) {
+	index.mu.Lock()
+	defer index.mu.Unlock()
+
+	if index.remove(id) {
+		index.changes++
+	}
+}
+
+// RemoveTag убирает тег из всех документов.
+func (index *Index) RemoveTag(tagID int) {
+	index.mu.Lock()
+	defer index.mu.Unlock()
+
+	for _, doc := range index.docs {
+		for i, tag := range doc.Tags {
+			if tag.ID != tagID {
+				continue
+			}
+			doc.Tags = append(doc.Tags[:i:i], doc.Tags[i+1:]...)
+			delete(index.tags[tag.Key], doc.ID)
+			if len(index.tags[tag.Key]) == 0 {
+				delete(index.tags, tag.Key)
+			}
+			index.changes++
+			break
+		}
+	}
+}
+
+// Len возвращает количество документов.
+func (index *Index) Len() int {
+	index.mu.RLock()
+	defer index.mu.RUnlock()
+	return len(index.docs)
+}
+
+// Добавление документа в обратные списки. Вызывается под блокировкой.
+func (index *Index) add(doc *document) {
+	index.docs[doc.ID] = doc
+	index.totalLength += doc.Length
+
+	for term, positions := range doc.Terms {
+		if index.postings[term] == nil {
+			index.postings[term] = make(map[int][]int)
+		}
+		index.postings[term][doc.ID] = positions
+	}
+
+	for _, tag := range doc.Tags {
+		if index.tags[tag.Key] == nil {
+			index.tags[tag.Key] = make(map[int]bool)
+		}
+		index.tags[tag.Key][doc.ID] = true
+	}
+}
+
+// Удаление документа из обратных списков. Вызывается под блокировкой.
+func (index *Index) remove(id int) bool {
+	doc, ok := index.docs[id]
+	if !ok {
+		return false
+	}
+
+	for term := range doc.Terms {
+		delete(index.postings[term], id)
+		if len(index.postings[term]) == 0 {
+			delete(index.postings, term)
+		}
+	}
+
+	for _, tag := range doc.Tags {
+		delete(index.tags[tag.Key], id)
+		if len(index.tags[tag.Key]) == 0 {
+			delete(index.tags, tag.Key)
+		}
+	}
+
+	index.totalLength -= doc.Length
+	delete(index.docs, id)
+	return true
+}
+
+// Search ищет документы, которые подходят под все условия без минуса и ни под одно условие с минусом.
+// Результаты отсортированы по BM25: сумма по условиям лучшей из равнозначных фраз. Возвращает страницу и общее количество.
+func (index *Index) Search(clauses []Clause, limit int, offset int) ([]Hit, int) {
+	index.mu.RLock()
+	defer index.mu.RUnlock()
+
+	scores := map[int]float64(nil)
+	for _, clause := range clauses {
+		if clause.negated || clause.empty() {
+			continue
+		}
+
+		clauseScores := index.clauseScores(clause)
+		if scores == nil {
+			scores = clauseScores
+			continue
+		}
+
+		// Остаются документы, подходящие под все условия.
+		for id, score := range scores {
+			if clauseScore, ok := clauseScores[id]; ok {
+				scores[id] = score + clauseScore
+			} else {
+				delete(scores, id)
+			}
+		}
+	}
+
+	// Запрос из одних исключений или стоп-слов ничего не находит.
+	if len(scores) == 0 {
+		return []Hit{}, 0
+	}
+
+	for _, clause := range clauses {
+		if !clause.negated || clause.empty() {
+			continue
+		}
+		for id := range index.clauseScores(clause) {
+			delete(scores, id)
+		}
+	}
+
+	hits := make([]Hit, 0, len(scores))
+	for id, score := range scores {
+		hits = append(hits, Hit{ID: id, Score: score})
+	}
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		return hits[i].ID < hits[j].ID
+	})
+
+	total := len(hits)
+	if offset >= total {
+		return []Hit{}, total
+	}
+	return hits[offset:min(offset+limit, total)], total
+}
+
+// Релевантность документов, подходящих под условие: лучшая из его фраз.
+func (index *Index) clauseScores(clause Clause) map[int]float64 {
+	scores := make(map[int]float64)
+	for _, phrase := range clause.phrases {
+		for id, frequency := range index.phraseFrequencies(phrase) {
+			if score := index.bm25(phrase, id, frequency); score > scores[id] {
+				scores[id] = score
+			}
+		}
+	}
+	return scores
+}
+
+// Количество вхождений фразы в каждый документ, где она есть.
+func (index *Index) phraseFrequencies(phrase []phraseTerm) map[int]int {
+	frequencies := make(map[int]int)
+
+	// Перебираются документы с первой основой, остальные проверяются по позициям.
+	for id, positions := range index.postings[phrase[0].term] {
+		count := 0
+		for _, start := range positions {
+			if index.phraseAt(phrase, id, start) {
+				count++
+			}
+		}
+		if count > 0 {
+			frequencies[id] = count
+		}
+	}
+
+	return frequencies
+}
+
+// Проверка, что фраза стоит в документе начиная с позиции start.
+func (index *Index) phraseAt(phrase []phraseTerm, id int, start int) bool {
+	for _, part := range phrase[1:] {
+		positions := index.postings[part.term][id]
+		want := start + part.offset
+		i := sort.SearchInts(positions, want)
+		if i == len(positions) || positions[i] != want {
+			return false
+		}
+	}
+	return true
+}
+
+// BM25 фразы в документе: сумма по основам фразы, частота - количество вхождений фразы.
+func (index *Index) bm25(phrase []phraseTerm, id int, frequency int) float64 {
+	count := float64(len(index.docs))
+	averageLength := float64(index.totalLength) / count
+	if averageLength == 0 {
+		averageLength = 1
+	}
+
+	tf := float64(frequency)
+	norm := tf * (bm25K1 + 1) / (tf + bm25K1*(1-bm25B+bm25B*float64(index.docs[id].Length)/averageLength))
+
+	score := 0.0
+	for _, part := range phrase {
+		withTerm := float64(len(index.postings[part.term]))
+		idf := math.Log(1 + (count-withTerm+0.5)/(withTerm+0.5))
+		score += idf * norm
+	}
+	return score
+}
+
+// ByTag возвращает ID документов, у которых есть тег с одним из ключей, новые сначала.
+func (index *Index) ByTag(keys []string) []int {
+	index.mu.RLock()
+	defer index.mu.RUnlock()
+
+	found := make(map[int]bool)
+	for _, key := range keys {
+		for id := range index.tags[key] {
+			found[id] = true
+		}
+	}
+
+	ids := make([]int, 0, len(found))
+	for id := range found {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		a, b := index.docs[ids[i]], index.docs[ids[j]]
+		if !a.CreatedAt.Equal(b.CreatedAt) {
+			return a.CreatedAt.After(b.CreatedAt)
+		}
+		return a.ID > b.ID
+	})
+	return ids
+}
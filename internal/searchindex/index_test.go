@@ -0,0 +1,162 @@
+package searchindex
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestAnalyze(t *testing.T) {
+	tests := []struct {
+		a string
+		b string
+	}{
+		{a: "сортировка", b: "сортировки"},
+		{a: "Быстрой сортировкой", b: "быстрая сортировка"},
+		{a: "настроить", b: "настроит"},
+		{a: "ёлка", b: "елки"},
+		{a: "migrations", b: "migration"},
+		{a: "Testing", b: "tests"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.a, func(t *testing.T) {
+			if a, b := Analyze(tt.a), Analyze(tt.b); !reflect.DeepEqual(a, b) {
+				t.Errorf("Analyze(%q) = %q, Analyze(%q) = %q, want same stems", tt.a, a, tt.b, b)
+			}
+		})
+	}
+
+	// Стоп-слова пропускаются.
+	if terms := Analyze("как и в the of"); len(terms) != 0 {
+		t.Errorf("Analyze(stop words) = %q, want none", terms)
+	}
+}
+
+// Индекс из вопросов migrations/002_seed_data.sql.
+func seedIndex() *Index {
+	index := New()
+	created := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	docs := []Document{
+		{ID: 1, Texts: []string{"Как настроить соединение с PostgreSQL в Golang?", "Используйте пакет database/sql и драйвер pgx для PostgreSQL."}, Tags: []Tag{{ID: 1, Key: "golang"}, {ID: 2, Key: "postgreskl"}}},
+		{ID: 3, Texts: []string{"Как работает алгоритм быстрой сортировки?"}, Tags: []Tag{{ID: 5, Key: "algoritmi"}}},
+		{ID: 7, Texts: []string{"Как создать Dockerfile для Go приложения?", "Многоэтапная сборка docker образа."}, Tags: []Tag{{ID: 13, Key: "doker"}}},
+		{ID: 19, Texts: []string{"Как работать с транзакциями в PostgreSQL?"}, Tags: []Tag{{ID: 2, Key: "postgreskl"}}},
+	}
+	for i, doc := range docs {
+		doc.CreatedAt = created.AddDate(0, 0, i)
+		index.Put(doc)
+	}
+	return index
+}
+
+func TestSearch(t *testing.T) {
+	index := seedIndex()
+
+	clause := func(text string, negated bool) Clause {
+		return NewClause([]string{text}, negated)
+	}
+
+	tests := []struct {
+		name    string
+		clauses []Clause
+		want    []int
+	}{
+		{name: "словоформа", clauses: []Clause{clause("сортировка", false)}, want: []int{3}},
+		{name: "несколько документов", clauses: []Clause{clause("postgresql", false)}, want: []int{19, 1}},
+		{name: "все условия", clauses: []Clause{clause("postgresql", false), clause("транзакции", false)}, want: []int{19}},
+		{name: "исключение", clauses: []Clause{clause("postgresql", false), clause("golang", true)}, want: []int{19}},
+		{name: "фраза", clauses: []Clause{clause("быстрая сортировка", false)}, want: []int{3}},
+		{name: "фраза не в том порядке", clauses: []Clause{clause("сортировка быстрая", false)}, want: []int{}},
+		{name: "фраза на стыке вопроса и ответа", clauses: []Clause{clause("приложения многоэтапная", false)}, want: []int{}},
+		{name: "синоним в условии", clauses: []Clause{NewClause([]string{"докер", "docker"}, false)}, want: []int{7}},
+		{name: "только исключения", clauses: []Clause{clause("golang", true)}, want: []int{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hits, total := index.Search(tt.clauses, 10, 0)
+			ids := []int{}
+			for _, hit := range hits {
+				ids = append(ids, hit.ID)
+			}
+			if !reflect.DeepEqual(ids, tt.want) || total != len(tt.want) {
+				t.Errorf("Search() = %v (total %d), want %v", ids, total, tt.want)
+			}
+		})
+	}
+}
+
+func TestBM25Order(t *testing.T) {
+	index := New()
+	index.Put(Document{ID: 1, Texts: []string{"индексы ускоряют поиск по таблице"}})
+	index.Put(Document{ID: 2, Texts: []string{"индексы индексы ускоряют поиск"}})
+	index.Put(Document{ID: 3, Texts: []string{"индексы"}})
+	index.Put(Document{ID: 4, Texts: []string{"транзакции в postgresql"}})
+
+	tests := []struct {
+		name  string
+		query string
+		want  []int
+	}{
+		// Короткий документ выше длинного, документ с двумя вхождениями выше документа той же длины с одним.
+		{name: "частота и длина", query: "индексы", want: []int{3, 2, 1}},
+		{name: "одно совпадение", query: "таблице", want: []int{1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hits, _ := index.Search([]Clause{NewClause([]string{tt.query}, false)}, 10, 0)
+			ids := []int{}
+			for _, hit := range hits {
+				ids = append(ids, hit.ID)
+			}
+			if !reflect.DeepEqual(ids, tt.want) {
+				t.Errorf("Search(%q) = %v, want %v", tt.query, ids, tt.want)
+			}
+		})
+	}
+
+	// Редкое слово весит больше частого в том же документе.
+	rare, _ := index.Search([]Clause{NewClause([]string{"таблице"}, false)}, 10, 0)
+	common, _ := index.Search([]Clause{NewClause([]string{"индексы"}, false)}, 10, 0)
+	var commonScore float64
+	for _, hit := range common {
+		if hit.ID == 1 {
+			commonScore = hit.Score
+		}
+	}
+	if rare[0].Score <= commonScore {
+		t.Errorf("score of rare term = %v, want more than common term %v", rare[0].Score, commonScore)
+	}
+}
+
+func TestRemove(t *testing.T) {
+	index := seedIndex()
+
+	index.Remove(1)
+	index.Remove(100)
+	if hits, _ := index.Search([]Clause{NewClause([]string{"postgresql"}, false)}, 10, 0); len(hits) != 1 || hits[0].ID != 19 {
+		t.Errorf("Search() after Remove(1) = %v, want only 19", hits)
+	}
+	if ids := index.ByTag([]string{"golang"}); len(ids) != 0 {
+		t.Errorf("ByTag(golang) after Remove(1) = %v, want none", ids)
+	}
+
+	// Замена документа убирает старые слова.
+	index.Put(Document{ID: 3, Texts: []string{"Сортировка слиянием"}})
+	if hits, _ := index.Search([]Clause{NewClause([]string{"алгоритм"}, false)}, 10, 0); len(hits) != 0 {
+		t.Errorf("Search(алгоритм) after replacing 3 = %v, want none", hits)
+	}
+
+	index.RemoveTag(2)
+	if ids := index.ByTag([]string{"postgreskl"}); len(ids) != 0 {
+		t.Errorf("ByTag(postgreskl) after RemoveTag(2) = %v, want none", ids)
+	}
+	if ids := index.ByTag([]string{"doker", "algoritmi"}); !reflect.DeepEqual(ids, []int{7}) {
+		t.Errorf("ByTag(doker, algoritmi) = %v, want [7]", ids)
+	}
+	if index.Len() != 3 {
+		t.Errorf("Len() = %d, want 3", index.Len())
+	}
+}
@@ -0,0 +1,90 @@
+package searchindex
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Часть запроса до анализа: слово или фраза в кавычках, с минусом - исключение.
+type QueryPart struct {
+	Text    string
+	Negated bool
+}
+
+// ParseQuery разбивает запрос на слова и "фразы", минус перед словом или фразой исключает ее.
+// Незакрытая кавычка считается закрытой в конце запроса.
+func ParseQuery(text string) []QueryPart {
+	var parts []QueryPart
+	runes := []rune(text)
+
+	for i := 0; i < len(runes); {
+		if unicode.IsSpace(runes[i]) {
+			i++
+			continue
+		}
+
+		var part QueryPart
+		if runes[i] == '-' {
+			part.Negated = true
+			i++
+		}
+
+		start, end := i, i
+		if i < len(runes) && runes[i] == '"' {
+			start = i + 1
+			end = start
+			for end < len(runes) && runes[end] != '"' {
+				end++
+			}
+			i = end + 1
+		} else {
+			for end < len(runes) && !unicode.IsSpace(runes[end]) {
+				end++
+			}
+			i = end
+		}
+
+		part.Text = strings.TrimSpace(string(runes[start:end]))
+		if part.Text != "" {
+			parts = append(parts, part)
+		}
+	}
+
+	return parts
+}
+
+// Основа слова фразы и ее сдвиг от начала фразы (стоп-слова занимают место, но не хранятся).
+type phraseTerm struct {
+	term   string
+	offset int
+}
+
+// Условие запроса: документ подходит, если содержит хотя бы одну из фраз (например, слово и его синонимы).
+type Clause struct {
+	phrases [][]phraseTerm
+	negated bool
+}
+
+// NewClause создает условие из равнозначных текстов: слова запроса и его синонимов.
+// Тексты из одних стоп-слов пропускаются.
+func NewClause(texts []string, negated bool) Clause {
+	clause := Clause{negated: negated}
+	for _, text := range texts {
+		tokens, _ := analyze(text, 0)
+		if len(tokens) == 0 {
+			continue
+		}
+
+		phrase := make([]phraseTerm, 0, len(tokens))
+		for _, token := range tokens {
+			phrase = append(phrase, phraseTerm{term: token.term, offset: token.pos - tokens[0].pos})
+		}
+		clause.phrases = append(clause.phrases, phrase)
+	}
+	return clause
+}
+
+// Пустое условие (только стоп-слова) не участвует в поиске.
+func (clause Clause) empty() bool {
+	return len(clause.phrases) == 0
+}
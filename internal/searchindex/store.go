@@ -0,0 +1,100 @@
+package searchindex
+
+import (
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Версия формата файла. Меняется вместе с анализом текста, чтобы старый файл не загружался.
+const fileVersion = 1
+
+// Файл с устаревшей версией формата.
+var ErrStaleFile = errors.New("файл индекса другой версии")
+
+// Содержимое файла индекса. Обратные списки не сохраняются, а строятся заново при загрузке.
+type snapshot struct {
+	Version     int
+	Fingerprint string
+	Docs        []*document
+}
+
+// Save записывает индекс в файл. fingerprint - отпечаток данных, по которым построен индекс,
+// по нему при загрузке проверяется, что индекс не устарел. Файл заменяется целиком через временный файл.
+// Под блокировкой индекса только копируются документы, запись в файл идет без нее и не задерживает поиск и обновления.
+func (index *Index) Save(path string, fingerprint string) error {
+	index.saveMu.Lock()
+	defer index.saveMu.Unlock()
+
+	index.mu.RLock()
+	data := snapshot{Version: fileVersion, Fingerprint: fingerprint, Docs: make([]*document, 0, len(index.docs))}
+	for _, doc := range index.docs {
+		copied := *doc
+		data.Docs = append(data.Docs, &copied)
+	}
+	changes := index.changes
+	index.mu.RUnlock()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("ошибка создания каталога индекса: %w", err)
+	}
+
+	file, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("ошибка создания файла индекса: %w", err)
+	}
+	defer os.Remove(file.Name())
+
+	if err := gob.NewEncoder(file).Encode(data); err != nil {
+		file.Close()
+		return fmt.Errorf("ошибка записи файла индекса: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("ошибка записи файла индекса: %w", err)
+	}
+	if err := os.Rename(file.Name(), path); err != nil {
+		return fmt.Errorf("ошибка замены файла индекса: %w", err)
+	}
+
+	// Изменения, сделанные во время записи, останутся несохраненными до следующего Save.
+	index.mu.Lock()
+	index.saved = changes
+	index.mu.Unlock()
+	return nil
+}
+
+// Dirty сообщает, были ли изменения после последнего сохранения.
+func (index *Index) Dirty() bool {
+	index.mu.RLock()
+	defer index.mu.RUnlock()
+	return index.changes != index.saved
+}
+
+// Load читает индекс из файла и возвращает его вместе с отпечатком данных, сохраненным в Save.
+func Load(path string) (*Index, string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, "", err
+	}
+	defer file.Close()
+
+	var data snapshot
+	if err := gob.NewDecoder(file).Decode(&data); err != nil {
+		return nil, "", fmt.Errorf("ошибка чтения файла индекса: %w", err)
+	}
+	if data.Version != fileVersion {
+		return nil, "", ErrStaleFile
+	}
+
+	index := New()
+	for _, doc := range data.Docs {
+		if doc.Terms == nil {
+			doc.Terms = make(map[string][]int)
+		}
+		index.add(doc)
+	}
+
+	return index, data.Fingerprint, nil
+}
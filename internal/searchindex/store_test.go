@@ -0,0 +1,98 @@
+package searchindex
+
+import (
+	"encoding/gob"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestSaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "index", "search.idx")
+	index := seedIndex()
+
+	if !index.Dirty() {
+		t.Fatal("Dirty() = false after Put")
+	}
+	if err := index.Save(path, "questions:4:19"); err != nil {
+		t.Fatal(err)
+	}
+	if index.Dirty() {
+		t.Error("Dirty() = true after Save")
+	}
+
+	loaded, fingerprint, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fingerprint != "questions:4:19" {
+		t.Errorf("fingerprint = %q, want %q", fingerprint, "questions:4:19")
+	}
+	if loaded.Dirty() {
+		t.Error("Dirty() = true after Load")
+	}
+
+	// Загруженный индекс находит то же самое.
+	clauses := []Clause{NewClause([]string{"postgresql"}, false)}
+	want, _ := index.Search(clauses, 10, 0)
+	got, _ := loaded.Search(clauses, 10, 0)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Search() after Load = %v, want %v", got, want)
+	}
+	if ids := loaded.ByTag([]string{"postgreskl"}); !reflect.DeepEqual(ids, []int{19, 1}) {
+		t.Errorf("ByTag() after Load = %v, want [19 1]", ids)
+	}
+}
+
+func TestLoadStaleFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "search.idx")
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := gob.NewEncoder(file).Encode(snapshot{Version: fileVersion + 1}); err != nil {
+		t.Fatal(err)
+	}
+	file.Close()
+
+	if _, _, err := Load(path); !errors.Is(err, ErrStaleFile) {
+		t.Errorf("Load() error = %v, want ErrStaleFile", err)
+	}
+}
+
+// Изменения во время сохранения не теряются: индекс остается измененным до следующего Save.
+func TestSaveConcurrentChanges(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "search.idx")
+	index := seedIndex()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			if err := index.Save(path, "fingerprint"); err != nil {
+				t.Error(err)
+			}
+		}()
+		go func(id int) {
+			defer wg.Done()
+			index.Put(Document{ID: 100 + id, Texts: []string{"новый вопрос"}, Tags: []Tag{{ID: 2, Key: "postgreskl"}}})
+			index.RemoveTag(2)
+		}(i)
+	}
+	wg.Wait()
+
+	if err := index.Save(path, "fingerprint"); err != nil {
+		t.Fatal(err)
+	}
+	loaded, _, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Len() != index.Len() {
+		t.Errorf("Len() after Load = %d, want %d", loaded.Len(), index.Len())
+	}
+}
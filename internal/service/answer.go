@@ -1,11 +1,24 @@
 package service
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"knowledge-base/internal/models"
+
+	"go.opentelemetry.io/otel/attribute"
 )
 
+// Порядок ответов вопроса: принятый ответ первым, остальные по position.
+const answerOrder = `is_accepted desc, position, id`
+
+// Порядок ответов вопроса по рейтингу: при равном рейтинге - как в answerOrder.
+const answerScoreOrder = `score desc, ` + answerOrder
+
+// Ошибка порядка ответов: переданы не все ответы вопроса, лишние или повторяющиеся ID.
+var ErrInvalidAnswerOrder = errors.New("нужно передать ID всех ответов вопроса, каждый по одному разу")
+
 // Структура для работы со всеми ф-ями service/answer.go.
 type AnswerService struct {
 	db *sql.DB
@@ -16,71 +29,189 @@ func NewAnswerService(db *sql.DB) *AnswerService {
 	return &AnswerService{db: db}
 }
 
-func (answerService *AnswerService) GetAll() ([]models.Answer, error) {
+func (answerService *AnswerService) GetAll(ctx context.Context, params ListParams) ([]models.Answer, PageInfo, error) {
+
+	// Ограничение времени выполнения запроса.
+	ctx, finish := beginQuery(ctx, "AnswerService.GetAll")
+	defer finish()
+
+	// Условия фильтрации и сортировки из параметров списка.
+	// Учащемуся видны только опубликованные ответы на опубликованные вопросы.
+	visible := fixedFilter{Condition: visibleAnswer(ctx, "answers")}
+	where, args := AnswerListSpec.whereClause(params, visible)
+	orderBy := AnswerListSpec.orderClause(params)
+	page, pageArgs := AnswerListSpec.pageClause(params, len(args))
+
+	// Общее количество записей с учетом фильтров, без учета страницы и курсора.
+	countWhere, countArgs := AnswerListSpec.whereClause(params.withoutCursor(), visible)
+	var total int
+	err := answerService.db.QueryRowContext(ctx, `select count(*) from answers`+countWhere, countArgs...).Scan(&total)
+	if err != nil {
+		return nil, PageInfo{}, queryError(ctx, err)
+	}
 
 	//Создание sql запроса для получения данных по всем ответам.
-	var query string = `select id, answer_text, tutor_id, question_id, created_at, is_edit from answers order by id`
+	var query string = `select id, answer_text, tutor_id, question_id, created_at, is_edit, is_accepted, position, helpful_count, not_helpful_count, upvote_count, score, status, ` + AnswerListSpec.keyColumn(params) + ` from answers` + where + orderBy + page
 
 	// Выполнение функции, которая проводит sql запрос и возвращает таблицу с несколькими строками.
-	rows, err := answerService.db.Query(query)
+	rows, err := answerService.db.QueryContext(ctx, query, append(args, pageArgs...)...)
 	if err != nil {
-		return nil, err
+		return nil, PageInfo{}, queryError(ctx, err)
 	}
 
 	// Закрытие, чтобы не было утечки соединений, надо закрыть.
 	defer rows.Close()
 
+	// Значения ключа сортировки каждой строки для курсоров соседних страниц.
+	var keys []cursorKey
+
 	// Запись полученных данных из БД в массив формата []models.Answer.
 	var answers []models.Answer
 	for rows.Next() {
 		var answer models.Answer
-		err := rows.Scan(&answer.ID, &answer.AnswersText, &answer.TutorID, &answer.QuestionID, &answer.CreatedAt, &answer.IsEdit)
+		var key cursorKey
+		err := rows.Scan(&answer.ID, &answer.AnswersText, &answer.TutorID, &answer.QuestionID, &answer.CreatedAt, &answer.IsEdit, &answer.IsAccepted, &answer.Position, &answer.HelpfulCount, &answer.NotHelpfulCount, &answer.Upvotes, &answer.Score, &key.Key)
 		if err != nil {
-			return nil, err
+			return nil, PageInfo{}, queryError(ctx, err)
 		}
+		key.ID = answer.ID
 		answers = append(answers, answer)
+		keys = append(keys, key)
+	}
+
+	// Проверка ошибки, которая могла прервать чтение строк.
+	if err := rows.Err(); err != nil {
+		return nil, PageInfo{}, queryError(ctx, err)
+	}
+
+	recordRows(ctx, len(answers))
+
+	// Лишняя строка отбрасывается, по крайним строкам строятся курсоры.
+	answers, info := keysetPage(AnswerListSpec, params, answers, keys, total)
+
+	return answers, info, nil
+
+}
+
+// Export читает все записи с учетом фильтров и сортировки и передает их по одной в fn, не собирая в память.
+// Если fn возвращает ошибку, чтение прекращается и ошибка возвращается как есть.
+func (answerService *AnswerService) Export(ctx context.Context, params ListParams, fn func(models.Answer) error) error {
+
+	// Ограничение времени выгрузки.
+	ctx, finish := beginExport(ctx, "AnswerService.Export")
+	defer finish()
+
+	// Условия фильтрации и сортировки из параметров списка, без страницы.
+	where, args := AnswerListSpec.whereClause(params.withoutCursor(), fixedFilter{Condition: visibleAnswer(ctx, "answers")})
+	orderBy := AnswerListSpec.orderClause(params.withoutCursor())
+
+	//Создание sql запроса для выгрузки всех ответов.
+	var query string = `select id, answer_text, tutor_id, question_id, created_at, is_edit, is_accepted, position, helpful_count, not_helpful_count, upvote_count, score, status from answers` + where + orderBy
+
+	// Выполнение функции, которая проводит sql запрос и возвращает таблицу с несколькими строками.
+	rows, err := answerService.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return queryError(ctx, err)
+	}
+
+	// Закрытие, чтобы не было утечки соединений, надо закрыть.
+	defer rows.Close()
+
+	// Каждая строка сразу передается дальше, в памяти хранится только одна.
+	var count int
+	for rows.Next() {
+		var answer models.Answer
+		err := rows.Scan(&answer.ID, &answer.AnswersText, &answer.TutorID, &answer.QuestionID, &answer.CreatedAt, &answer.IsEdit, &answer.IsAccepted, &answer.Position, &answer.HelpfulCount, &answer.NotHelpfulCount, &answer.Upvotes, &answer.Score, &answer.Status)
+		if err != nil {
+			return queryError(ctx, err)
+		}
+		if err := fn(answer); err != nil {
+			return err
+		}
+		count++
 	}
 
-	return answers, nil
+	// Проверка ошибки, которая могла прервать чтение строк.
+	if err := rows.Err(); err != nil {
+		return queryError(ctx, err)
+	}
+
+	recordRows(ctx, count)
 
+	return nil
 }
 
-func (answerService *AnswerService) GetByID(id int) (models.Answer, error) {
+func (answerService *AnswerService) GetByID(ctx context.Context, id int) (models.Answer, error) {
+
+	// Ограничение времени выполнения запроса.
+	ctx, finish := beginQuery(ctx, "AnswerService.GetByID", attribute.Int("answer.id", id))
+	defer finish()
 
 	//Создание sql запроса для получения данных по одному конкретному ответу.
-	var query string = `select id, answer_text, tutor_id, question_id, created_at, is_edit from answers where id = $1`
+	var query string = `select id, answer_text, tutor_id, question_id, created_at, is_edit, is_accepted, position, helpful_count, not_helpful_count, upvote_count, score, status from answers where id = $1 and ` + visibleAnswer(ctx, "answers")
+
+	// Выполнение функции, которая проводит sql запрос и возвращает таблицу из одной строки.
+	row := answerService.db.QueryRowContext(ctx, query, id)
+
+	var answer models.Answer
+
+	// Запись полученных данных из БД в перемнную типа models.Answer.
+	err := row.Scan(&answer.ID, &answer.AnswersText, &answer.TutorID, &answer.QuestionID, &answer.CreatedAt, &answer.IsEdit, &answer.IsAccepted, &answer.Position, &answer.HelpfulCount, &answer.NotHelpfulCount, &answer.Upvotes, &answer.Score, &answer.Status)
+	if err != nil {
+		return models.Answer{}, queryError(ctx, err)
+	}
+
+	return answer, nil
+}
+
+// GetByQuestionID возвращает принятый ответ на вопрос, а если принятого нет - первый по порядку.
+// Если ответов нет, возвращается sql.ErrNoRows.
+func (answerService *AnswerService) GetByQuestionID(ctx context.Context, questionID int) (models.Answer, error) {
+
+	// Ограничение времени выполнения запроса.
+	ctx, finish := beginQuery(ctx, "AnswerService.GetByQuestionID", attribute.Int("question.id", questionID))
+	defer finish()
+
+	//Создание sql запроса для получения ответа на конкретный вопрос.
+	var query string = `select id, answer_text, tutor_id, question_id, created_at, is_edit, is_accepted, position, helpful_count, not_helpful_count, upvote_count, score, status from answers where question_id = $1 and ` + visibleAnswer(ctx, "answers") + ` order by ` + answerOrder + ` limit 1`
 
 	// Выполнение функции, которая проводит sql запрос и возвращает таблицу из одной строки.
-	row := answerService.db.QueryRow(query, id)
+	row := answerService.db.QueryRowContext(ctx, query, questionID)
 
 	var answer models.Answer
 
 	// Запись полученных данных из БД в перемнную типа models.Answer.
-	err := row.Scan(&answer.ID, &answer.AnswersText, &answer.TutorID, &answer.QuestionID, &answer.CreatedAt, &answer.IsEdit)
+	err := row.Scan(&answer.ID, &answer.AnswersText, &answer.TutorID, &answer.QuestionID, &answer.CreatedAt, &answer.IsEdit, &answer.IsAccepted, &answer.Position, &answer.HelpfulCount, &answer.NotHelpfulCount, &answer.Upvotes, &answer.Score, &answer.Status)
 	if err != nil {
-		return models.Answer{}, err
+		return models.Answer{}, queryError(ctx, err)
 	}
 
 	return answer, nil
 }
 
-func (answerService *AnswerService) DeleteByID(id int, deleteByTutor int) error {
+func (answerService *AnswerService) DeleteByID(ctx context.Context, id int, deleteByTutor int) error {
+
+	// Ограничение времени выполнения запроса.
+	ctx, finish := beginQuery(ctx, "AnswerService.DeleteByID", attribute.Int("answer.id", id))
+	defer finish()
 
 	//Создание sql запроса для удаления данных одного кокретного овтета.
 	queryDelete := `delete from answers where id = $1`
 
 	// Выполнение функции, которая проводит sql запрос без возврата данных.
-	result, err := (answerService.db.Exec(queryDelete, id))
+	result, err := (answerService.db.ExecContext(ctx, queryDelete, id))
 	if err != nil {
-		return err
+		return queryError(ctx, err)
 	}
 
 	// Выполнение функции, которая возаращает количество удаленных строк.
 	rowsAffected, err := result.RowsAffected()
 	if err != nil {
-		return err
+		return queryError(ctx, err)
 	}
 
+	recordRows(ctx, int(rowsAffected))
+
 	//Проверка было ли удаление строки. Если rowsAffected = 0, то не было.
 	if rowsAffected == 0 {
 		return fmt.Errorf("question with id %d not found", id)
@@ -89,29 +220,56 @@ func (answerService *AnswerService) DeleteByID(id int, deleteByTutor int) error
 	//Создание sql запроса для учета удаления в версиях.
 	queryUpdateVersions := `update answer_versions set is_delete = true, delete_by_tutor = $1 where answer_id = $2`
 
-	_, err = answerService.db.Exec(queryUpdateVersions, deleteByTutor, id)
+	_, err = answerService.db.ExecContext(ctx, queryUpdateVersions, deleteByTutor, id)
 	if err != nil {
-		return err
+		return queryError(ctx, err)
 	}
 
+	notifyAnswerChanged(ctx, id)
+
 	return nil
 }
 
-func (answerService *AnswerService) PostString(answerText string, tutorId *int, questionId int) (int, error) {
+func (answerService *AnswerService) PostString(ctx context.Context, answerText string, tutorId *int, questionId int) (int, error) {
+
+	// Ограничение времени выполнения запроса.
+	ctx, finish := beginQuery(ctx, "AnswerService.PostString", attribute.Int("question.id", questionId))
+	defer finish()
+
+	// Ответ и его первая версия создаются в одной транзакции.
+	tx, err := answerService.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, queryError(ctx, err)
+	}
+
+	// Откат, если транзакция не была зафиксирована.
+	defer tx.Rollback()
+
+	// Блокировка вопроса, как в Accept: иначе два одновременных первых ответа оба станут принятыми
+	// и получат одну позицию. Если вопроса нет, возвращается sql.ErrNoRows.
+	var lockedID int
+	err = tx.QueryRowContext(ctx, `select id from questions where id = $1 for update`, questionId).Scan(&lockedID)
+	if err != nil {
+		return 0, queryError(ctx, err)
+	}
 
 	var answerID int
 
 	//Создание sql запроса для появления новой записи в таблице овтетов.
-	query := `insert into answers (answer_text, tutor_id, question_id) 
-              values ($1, $2, $3) returning id`
+	// Первый ответ вопроса становится принятым, следующие добавляются в конец.
+	query := `insert into answers (answer_text, tutor_id, question_id, is_accepted, position)
+              select $1, $2, $3,
+                     not exists (select 1 from answers where question_id = $3),
+                     coalesce((select max(position) from answers where question_id = $3), 0) + 1
+              returning id`
 
 	// Выполнение функции, которая проводит sql запрос и возвращает таблицу из одной строки.
-	row := answerService.db.QueryRow(query, answerText, tutorId, questionId)
+	row := tx.QueryRowContext(ctx, query, answerText, tutorId, questionId)
 
 	// Получение id созданной записи.
-	err := row.Scan(&answerID)
+	err = row.Scan(&answerID)
 	if err != nil {
-		return 0, err
+		return 0, queryError(ctx, err)
 	}
 
 	//Создание sql запроса для появления новой записи в таблице версий овтетов.
@@ -120,61 +278,80 @@ func (answerService *AnswerService) PostString(answerText string, tutorId *int,
 		values ($1, $2, $3, $4, 1)`
 
 	// Выполнение функции, которая проводит sql запрос без возврата данных.
-	_, err = answerService.db.Exec(queryQuestionVersion, answerID, answerText, questionId, tutorId)
+	_, err = tx.ExecContext(ctx, queryQuestionVersion, answerID, answerText, questionId, tutorId)
 	if err != nil {
-		return 0, fmt.Errorf("failed to save first version: %v", err)
+		return 0, fmt.Errorf("failed to save first version: %w", queryError(ctx, err))
+	}
+
+	// Фиксация транзакции.
+	if err := tx.Commit(); err != nil {
+		return 0, queryError(ctx, err)
 	}
 
+	notifyAnswerChanged(ctx, answerID)
+
 	return answerID, nil
 }
 
-func (answerService *AnswerService) PutString(answerText string, tutorId *int, questionId int, id int) (models.Answer, error) {
+func (answerService *AnswerService) PutString(ctx context.Context, answerText string, tutorId *int, questionId int, id int) (models.Answer, error) {
+
+	// Ограничение времени выполнения запроса.
+	ctx, finish := beginQuery(ctx, "AnswerService.PutString", attribute.Int("answer.id", id))
+	defer finish()
 
 	//Создание sql запроса для обновления данных конкретного вопроса.
+	// Ответ, перенесенный к другому вопросу, перестает быть принятым и встает в конец ответов нового вопроса.
+	// Правка не публикуется без проверки: кроме черновика, ответ возвращается на проверку модератору.
 	query := `update answers 
-              set answer_text = $1, tutor_id = $2, question_id = $3, is_edit = true
+              set answer_text = $1, tutor_id = $2, question_id = $3, is_edit = true,
+                  status = case when status = '` + StatusDraft + `' then status else '` + StatusInReview + `' end,
+                  is_accepted = is_accepted and question_id = $3,
+                  position = case when question_id = $3 then position
+                                  else coalesce((select max(a.position) from answers a where a.question_id = $3), 0) + 1 end
               where id = $4
-              returning answer_text, tutor_id, question_id, created_at, is_edit`
+              returning answer_text, tutor_id, question_id, created_at, is_edit, is_accepted, position, helpful_count, not_helpful_count, upvote_count, score, status`
 
 	var answer models.Answer
 
 	// Выполнение функции, которая проводит sql запрос и возвращает таблицу из одной строки. Заполнение полей переменной типа models.Answer.
-	err := answerService.db.QueryRow(
-		query, answerText, tutorId, questionId, id).Scan(&answer.AnswersText, &answer.TutorID, &answer.QuestionID, &answer.CreatedAt, &answer.IsEdit)
+	err := answerService.db.QueryRowContext(ctx,
+		query, answerText, tutorId, questionId, id).Scan(&answer.AnswersText, &answer.TutorID, &answer.QuestionID, &answer.CreatedAt, &answer.IsEdit, &answer.IsAccepted, &answer.Position, &answer.HelpfulCount, &answer.NotHelpfulCount, &answer.Upvotes, &answer.Score, &answer.Status)
 
 	if err != nil {
-		return models.Answer{}, err
+		return models.Answer{}, queryError(ctx, err)
 	}
 
 	//Создание sql запроса для появления новой записи в таблице версий ответов.
 	queryAnswerVersion := `insert into answer_versions 
-		(answer_id, answer_text, question_id, tutor_id, version_number) 
-		values ($1, $2, $3, $4, $5)`
+		(answer_id, answer_text, question_id, tutor_id, version_number, status) 
+		values ($1, $2, $3, $4, $5, $6)`
 
 	//Выполнение функции, которая вернет переменную типа models.QuestionVersion для получения version_number
-	answerVersion, err := answerService.getVersionByID(id)
+	answerVersion, err := answerService.getVersionByID(ctx, id)
 	if err != nil {
-		return models.Answer{}, fmt.Errorf("failed to save first version: %v", err)
+		return models.Answer{}, fmt.Errorf("failed to save first version: %w", queryError(ctx, err))
 	}
 
 	answerVersion.VersionNumber += 1
 
 	// Выполнение функции, которая проводит sql запрос без возврата данных.
-	_, err = answerService.db.Exec(queryAnswerVersion, id, answerText, questionId, tutorId, answerVersion.VersionNumber)
+	_, err = answerService.db.ExecContext(ctx, queryAnswerVersion, id, answerText, questionId, tutorId, answerVersion.VersionNumber, answer.Status)
 	if err != nil {
-		return models.Answer{}, fmt.Errorf("failed to save first version: %v", err)
+		return models.Answer{}, fmt.Errorf("failed to save first version: %w", queryError(ctx, err))
 	}
 
+	notifyAnswerChanged(ctx, id)
+
 	return answer, nil
 }
 
-func (answerService *AnswerService) getVersionByID(id int) (models.AnswerVersion, error) {
+func (answerService *AnswerService) getVersionByID(ctx context.Context, id int) (models.AnswerVersion, error) {
 
 	//Создание sql запроса для получения данных по одному конкретному ответу.
 	var query string = `select max(version_number) from answer_versions where answer_id = $1`
 
 	// Выполнение функции, которая проводит sql запрос и возвращает таблицу из одной строки.
-	row := answerService.db.QueryRow(query, id)
+	row := answerService.db.QueryRowContext(ctx, query, id)
 
 	var answer models.AnswerVersion
 
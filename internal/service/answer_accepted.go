@@ -0,0 +1,200 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"knowledge-base/internal/models"
+
+	"github.com/lib/pq"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// GetAllByQuestionID возвращает все ответы на вопрос: принятый первым, остальные по порядку,
+// а с byScore - по рейтингу, лучшие сначала. Если вопроса нет, возвращается sql.ErrNoRows.
+func (answerService *AnswerService) GetAllByQuestionID(ctx context.Context, questionID int, byScore bool) ([]models.Answer, error) {
+
+	// Ограничение времени выполнения запроса.
+	ctx, finish := beginQuery(ctx, "AnswerService.GetAllByQuestionID", attribute.Int("question.id", questionID))
+	defer finish()
+
+	orderBy := answerOrder
+	if byScore {
+		orderBy = answerScoreOrder
+	}
+
+	answers, err := questionAnswers(ctx, answerService.db, questionID, orderBy)
+	if err != nil {
+		return nil, queryError(ctx, err)
+	}
+
+	// Пустой список отличается от несуществующего вопроса.
+	if len(answers) == 0 {
+		var exists bool
+		err := answerService.db.QueryRowContext(ctx, `select exists (select 1 from questions where id = $1 and `+visibleQuestion(ctx, "questions")+`)`, questionID).Scan(&exists)
+		if err != nil {
+			return nil, queryError(ctx, err)
+		}
+		if !exists {
+			return nil, sql.ErrNoRows
+		}
+	}
+
+	recordRows(ctx, len(answers))
+
+	return answers, nil
+}
+
+// Accept делает ответ принятым ответом вопроса, прежний принятый ответ перестает им быть.
+// Если у вопроса нет такого ответа, возвращается sql.ErrNoRows.
+func (answerService *AnswerService) Accept(ctx context.Context, questionID int, answerID int) (models.Answer, error) {
+
+	// Ограничение времени выполнения запроса.
+	ctx, finish := beginQuery(ctx, "AnswerService.Accept", attribute.Int("question.id", questionID), attribute.Int("answer.id", answerID))
+	defer finish()
+
+	// Снятие прежнего и выбор нового принятого ответа - в одной транзакции.
+	tx, err := answerService.db.BeginTx(ctx, nil)
+	if err != nil {
+		return models.Answer{}, queryError(ctx, err)
+	}
+
+	// Откат, если транзакция не была зафиксирована.
+	defer tx.Rollback()
+
+	// Блокировка вопроса, чтобы два одновременных выбора не нарушили уникальность принятого ответа.
+	var id int
+	err = tx.QueryRowContext(ctx, `select id from questions where id = $1 for update`, questionID).Scan(&id)
+	if err != nil {
+		return models.Answer{}, queryError(ctx, err)
+	}
+
+	_, err = tx.ExecContext(ctx, `update answers set is_accepted = false where question_id = $1 and is_accepted and id <> $2`, questionID, answerID)
+	if err != nil {
+		return models.Answer{}, queryError(ctx, err)
+	}
+
+	var answer models.Answer
+	err = tx.QueryRowContext(ctx, `update answers set is_accepted = true where id = $1 and question_id = $2
+		returning id, answer_text, tutor_id, question_id, created_at, is_edit, is_accepted, position, helpful_count, not_helpful_count, upvote_count, score, status`, answerID, questionID).
+		Scan(&answer.ID, &answer.AnswersText, &answer.TutorID, &answer.QuestionID, &answer.CreatedAt, &answer.IsEdit, &answer.IsAccepted, &answer.Position, &answer.HelpfulCount, &answer.NotHelpfulCount, &answer.Upvotes, &answer.Score, &answer.Status)
+	if err != nil {
+		return models.Answer{}, queryError(ctx, err)
+	}
+
+	// Фиксация транзакции.
+	if err := tx.Commit(); err != nil {
+		return models.Answer{}, queryError(ctx, err)
+	}
+
+	return answer, nil
+}
+
+// Unaccept снимает отметку принятого ответа вопроса. Если принятого ответа нет, возвращается sql.ErrNoRows.
+func (answerService *AnswerService) Unaccept(ctx context.Context, questionID int) error {
+
+	// Ограничение времени выполнения запроса.
+	ctx, finish := beginQuery(ctx, "AnswerService.Unaccept", attribute.Int("question.id", questionID))
+	defer finish()
+
+	result, err := answerService.db.ExecContext(ctx, `update answers set is_accepted = false where question_id = $1 and is_accepted`, questionID)
+	if err != nil {
+		return queryError(ctx, err)
+	}
+
+	// Выполнение функции, которая возаращает количество измененных строк.
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return queryError(ctx, err)
+	}
+
+	recordRows(ctx, int(rowsAffected))
+
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// Reorder задает порядок ответов вопроса. answerIDs должен содержать все ответы вопроса по одному разу,
+// иначе возвращается ErrInvalidAnswerOrder. Принятый ответ в списке ответов все равно остается первым.
+func (answerService *AnswerService) Reorder(ctx context.Context, questionID int, answerIDs []int) ([]models.Answer, error) {
+
+	// Ограничение времени выполнения запроса.
+	ctx, finish := beginQuery(ctx, "AnswerService.Reorder", attribute.Int("question.id", questionID))
+	defer finish()
+
+	// Проверка и изменение порядка - в одной транзакции.
+	tx, err := answerService.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, queryError(ctx, err)
+	}
+
+	// Откат, если транзакция не была зафиксирована.
+	defer tx.Rollback()
+
+	// Блокировка вопроса, чтобы набор его ответов не изменился до конца транзакции.
+	var id int
+	err = tx.QueryRowContext(ctx, `select id from questions where id = $1 for update`, questionID).Scan(&id)
+	if err != nil {
+		return nil, queryError(ctx, err)
+	}
+
+	// Переданные ID должны совпадать с ответами вопроса без повторов.
+	var total, matched, distinct int
+	err = tx.QueryRowContext(ctx, `select
+			(select count(*) from answers where question_id = $1),
+			(select count(*) from answers where question_id = $1 and id = any($2)),
+			(select count(distinct ids) from unnest($2::int[]) as ids)`, questionID, pq.Array(answerIDs)).Scan(&total, &matched, &distinct)
+	if err != nil {
+		return nil, queryError(ctx, err)
+	}
+	if len(answerIDs) != total || matched != total || distinct != total {
+		return nil, ErrInvalidAnswerOrder
+	}
+
+	_, err = tx.ExecContext(ctx, `update answers a set position = o.position
+		from unnest($2::int[]) with ordinality as o(id, position)
+		where a.id = o.id and a.question_id = $1`, questionID, pq.Array(answerIDs))
+	if err != nil {
+		return nil, queryError(ctx, err)
+	}
+
+	answers, err := questionAnswers(ctx, tx, questionID, answerOrder)
+	if err != nil {
+		return nil, queryError(ctx, err)
+	}
+
+	// Фиксация транзакции.
+	if err := tx.Commit(); err != nil {
+		return nil, queryError(ctx, err)
+	}
+
+	recordRows(ctx, len(answers))
+
+	return answers, nil
+}
+
+// Ответы вопроса в порядке orderBy (answerOrder или answerScoreOrder).
+func questionAnswers(ctx context.Context, db queryer, questionID int, orderBy string) ([]models.Answer, error) {
+	var query string = `select id, answer_text, tutor_id, question_id, created_at, is_edit, is_accepted, position, helpful_count, not_helpful_count, upvote_count, score, status
+		from answers where question_id = $1 and ` + visibleAnswer(ctx, "answers") + ` order by ` + orderBy
+
+	rows, err := db.QueryContext(ctx, query, questionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	// Пустой список возвращается как [], а не null.
+	answers := []models.Answer{}
+	for rows.Next() {
+		var answer models.Answer
+		err := rows.Scan(&answer.ID, &answer.AnswersText, &answer.TutorID, &answer.QuestionID, &answer.CreatedAt, &answer.IsEdit, &answer.IsAccepted, &answer.Position, &answer.HelpfulCount, &answer.NotHelpfulCount, &answer.Upvotes, &answer.Score, &answer.Status)
+		if err != nil {
+			return nil, err
+		}
+		answers = append(answers, answer)
+	}
+	return answers, rows.Err()
+}
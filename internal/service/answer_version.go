@@ -1,8 +1,11 @@
 package service
 
 import (
+	"context"
 	"database/sql"
 	"knowledge-base/internal/models"
+
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // Структура для работы со всеми ф-ями service/answer_version.go.
@@ -15,31 +18,74 @@ func NewAnswerVersionService(db *sql.DB) *AnswerVersionService {
 	return &AnswerVersionService{db: db}
 }
 
-func (answerVersionService *AnswerVersionService) GetAllByID(id int) ([]models.AnswerVersion, error) {
+func (answerVersionService *AnswerVersionService) GetAllByID(ctx context.Context, id int, params ListParams) ([]models.AnswerVersion, PageInfo, error) {
+
+	// Ограничение времени выполнения запроса.
+	ctx, finish := beginQuery(ctx, "AnswerVersionService.GetAllByID", attribute.Int("answer.id", id))
+	defer finish()
+
+	// Учащемуся история видна только у опубликованного ответа. Тьюторам и модераторам видна и история удаленных ответов.
+	if publishedOnly(ctx) {
+		if err := checkVisible(ctx, answerVersionService.db, "answers", id); err != nil {
+			return nil, PageInfo{}, queryError(ctx, err)
+		}
+	}
+
+	// Версии только одной сущности, фильтры и сортировка из параметров списка.
+	// Учащемуся видны только опубликованные версии.
+	fixed := fixedFilter{Condition: "answer_id = $%d", Value: id}
+	visible := fixedFilter{Condition: visibleVersion(ctx, "answer_versions")}
+	where, args := AnswerVersionListSpec.whereClause(params, fixed, visible)
+	orderBy := AnswerVersionListSpec.orderClause(params)
+	page, pageArgs := AnswerVersionListSpec.pageClause(params, len(args))
+
+	// Общее количество версий с учетом фильтров, без учета страницы и курсора.
+	countWhere, countArgs := AnswerVersionListSpec.whereClause(params.withoutCursor(), fixed, visible)
+	var total int
+	err := answerVersionService.db.QueryRowContext(ctx, `select count(*) from answer_versions`+countWhere, countArgs...).Scan(&total)
+	if err != nil {
+		return nil, PageInfo{}, queryError(ctx, err)
+	}
 
 	// Создание sql запроса для получения данных о версиях конкретного ответа.
-	var query string = `select id, answer_id, answer_text, question_id, tutor_id, created_at, version_number, is_delete, delete_by_tutor from answer_versions where answer_id = $1 order by version_number`
+	var query string = `select id, answer_id, answer_text, question_id, tutor_id, created_at, version_number, is_delete, delete_by_tutor, status, status_comment, ` + AnswerVersionListSpec.keyColumn(params) + ` from answer_versions` + where + orderBy + page
 
 	// Выполнение функции, которая проводит sql запрос и возвращает таблицу с несколькими строками.
-	rows, err := answerVersionService.db.Query(query, id)
+	rows, err := answerVersionService.db.QueryContext(ctx, query, append(args, pageArgs...)...)
 	if err != nil {
-		return nil, err
+		return nil, PageInfo{}, queryError(ctx, err)
 	}
 
 	// Закрытие, чтобы не было утечки соединений, надо закрыть.
 	defer rows.Close()
 
+	// Значения ключа сортировки каждой строки для курсоров соседних страниц.
+	var keys []cursorKey
+
 	var answerVersions []models.AnswerVersion
 
 	// Запись полученных данных из БД в массив формата []models.AnswerVersion.
 	for rows.Next() {
 		var answerVersion models.AnswerVersion
-		err := rows.Scan(&answerVersion.ID, &answerVersion.AnswerID, &answerVersion.AnswerText, &answerVersion.QuestionID, &answerVersion.TutorID, &answerVersion.CreatedAt, &answerVersion.VersionNumber, &answerVersion.IsDelete, &answerVersion.DeleteByTutor)
+		var key cursorKey
+		err := rows.Scan(&answerVersion.ID, &answerVersion.AnswerID, &answerVersion.AnswerText, &answerVersion.QuestionID, &answerVersion.TutorID, &answerVersion.CreatedAt, &answerVersion.VersionNumber, &answerVersion.IsDelete, &answerVersion.DeleteByTutor, &answerVersion.Status, &answerVersion.StatusComment, &key.Key)
 		if err != nil {
-			return nil, err
+			return nil, PageInfo{}, queryError(ctx, err)
 		}
+		key.ID = answerVersion.ID
 		answerVersions = append(answerVersions, answerVersion)
+		keys = append(keys, key)
 	}
 
-	return answerVersions, nil
+	// Проверка ошибки, которая могла прервать чтение строк.
+	if err := rows.Err(); err != nil {
+		return nil, PageInfo{}, queryError(ctx, err)
+	}
+
+	recordRows(ctx, len(answerVersions))
+
+	// Лишняя строка отбрасывается, по крайним строкам строятся курсоры.
+	answerVersions, info := keysetPage(AnswerVersionListSpec, params, answerVersions, keys, total)
+
+	return answerVersions, info, nil
 }
@@ -0,0 +1,282 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"knowledge-base/internal/models"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/lib/pq"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// Максимальная длина комментария к оценке ответа.
+const maxFeedbackComment = 1000
+
+// Сколько последних комментариев показывать в отчете по полезности.
+const feedbackReportComments = 3
+
+// Ошибки оценок ответов.
+var (
+	ErrInvalidFeedback = errors.New("неверная оценка ответа")
+	ErrOwnAnswerVote   = errors.New("нельзя голосовать за свой ответ")
+	ErrAlreadyVoted    = errors.New("тьютор уже голосовал за этот ответ")
+	ErrVoteForbidden   = errors.New("голос отдает и снимает только сам тьютор по личному токену")
+)
+
+// RateAnswer записывает оценку учащегося "полезен / не полезен" с необязательным комментарием.
+// Учащийся оценивает ответ один раз, повторная оценка заменяет прежнюю (created = false).
+// Если ответа нет или он не виден вызывающему, возвращается sql.ErrNoRows.
+func (answerService *AnswerService) RateAnswer(ctx context.Context, answerID int, clientID string, helpful bool, comment string) (models.Answer, bool, error) {
+
+	// Ограничение времени выполнения запроса.
+	ctx, finish := beginQuery(ctx, "AnswerService.RateAnswer", attribute.Int("answer.id", answerID))
+	defer finish()
+
+	comment = strings.TrimSpace(comment)
+	if utf8.RuneCountInString(comment) > maxFeedbackComment {
+		return models.Answer{}, false, fmt.Errorf("%w: комментарий длиннее %d символов", ErrInvalidFeedback, maxFeedbackComment)
+	}
+
+	// Оценка и пересчет счетчиков - в одной транзакции.
+	tx, err := answerService.db.BeginTx(ctx, nil)
+	if err != nil {
+		return models.Answer{}, false, queryError(ctx, err)
+	}
+
+	// Откат, если транзакция не была зафиксирована.
+	defer tx.Rollback()
+
+	// Учащийся оценивает только опубликованный ответ.
+	if err := checkVisible(ctx, tx, "answers", answerID); err != nil {
+		return models.Answer{}, false, queryError(ctx, err)
+	}
+
+	// Блокировка ответа, чтобы одновременные оценки не потеряли пересчет.
+	var id int
+	err = tx.QueryRowContext(ctx, `select id from answers where id = $1 for update`, answerID).Scan(&id)
+	if err != nil {
+		return models.Answer{}, false, queryError(ctx, err)
+	}
+
+	// xmax = 0 только у вставленной строки, у обновленной он заполнен.
+	var created bool
+	err = tx.QueryRowContext(ctx, `insert into answer_feedback (answer_id, client_id, helpful, comment) values ($1, $2, $3, $4)
+		on conflict (answer_id, client_id) do update set helpful = excluded.helpful, comment = excluded.comment, updated_at = now()
+		returning xmax = 0`, answerID, clientID, helpful, comment).Scan(&created)
+	if err != nil {
+		return models.Answer{}, false, queryError(ctx, err)
+	}
+
+	answer, err := recountAnswerVotes(ctx, tx, answerID)
+	if err != nil {
+		return models.Answer{}, false, queryError(ctx, err)
+	}
+
+	// Фиксация транзакции.
+	if err := tx.Commit(); err != nil {
+		return models.Answer{}, false, queryError(ctx, err)
+	}
+
+	return answer, created, nil
+}
+
+// Upvote добавляет голос тьютора, который вызывает по личному токену, за ответ коллеги.
+// Без личного токена возвращается ErrVoteForbidden, за свой ответ голосовать нельзя (ErrOwnAnswerVote),
+// повторный голос возвращает ErrAlreadyVoted. Если ответа нет или он не виден, возвращается sql.ErrNoRows.
+func (answerService *AnswerService) Upvote(ctx context.Context, answerID int) (models.Answer, error) {
+
+	// Голосующий тьютор определяется токеном, а не телом запроса.
+	tutorID := TutorIDFromContext(ctx)
+	if tutorID == 0 {
+		return models.Answer{}, ErrVoteForbidden
+	}
+
+	// Ограничение времени выполнения запроса.
+	ctx, finish := beginQuery(ctx, "AnswerService.Upvote", attribute.Int("answer.id", answerID), attribute.Int("tutor.id", tutorID))
+	defer finish()
+
+	// Голос и пересчет счетчиков - в одной транзакции.
+	tx, err := answerService.db.BeginTx(ctx, nil)
+	if err != nil {
+		return models.Answer{}, queryError(ctx, err)
+	}
+
+	// Откат, если транзакция не была зафиксирована.
+	defer tx.Rollback()
+
+	// Голос только за ответ, видимый вызывающему.
+	if err := checkVisible(ctx, tx, "answers", answerID); err != nil {
+		return models.Answer{}, queryError(ctx, err)
+	}
+
+	// Блокировка ответа и проверка автора.
+	var authorID *int
+	err = tx.QueryRowContext(ctx, `select tutor_id from answers where id = $1 for update`, answerID).Scan(&authorID)
+	if err != nil {
+		return models.Answer{}, queryError(ctx, err)
+	}
+	if authorID != nil && *authorID == tutorID {
+		return models.Answer{}, ErrOwnAnswerVote
+	}
+
+	result, err := tx.ExecContext(ctx, `insert into answer_votes (answer_id, tutor_id) values ($1, $2) on conflict do nothing`, answerID, tutorID)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23503" {
+			return models.Answer{}, fmt.Errorf("%w: тьютор %d не найден", ErrInvalidFeedback, tutorID)
+		}
+		return models.Answer{}, queryError(ctx, err)
+	}
+
+	// Выполнение функции, которая возаращает количество добавленных строк.
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return models.Answer{}, queryError(ctx, err)
+	}
+	if rowsAffected == 0 {
+		return models.Answer{}, ErrAlreadyVoted
+	}
+
+	answer, err := recountAnswerVotes(ctx, tx, answerID)
+	if err != nil {
+		return models.Answer{}, queryError(ctx, err)
+	}
+
+	// Фиксация транзакции.
+	if err := tx.Commit(); err != nil {
+		return models.Answer{}, queryError(ctx, err)
+	}
+
+	return answer, nil
+}
+
+// RemoveUpvote снимает голос тьютора за ответ. Тьютор по личному токену снимает только свой голос, модератор - любой,
+// иначе возвращается ErrVoteForbidden. Если голоса нет или ответ не виден, возвращается sql.ErrNoRows.
+func (answerService *AnswerService) RemoveUpvote(ctx context.Context, answerID int, tutorID int) error {
+
+	// Чужой голос может снять только модератор.
+	if RoleFromContext(ctx) != RoleModerator && TutorIDFromContext(ctx) != tutorID {
+		return ErrVoteForbidden
+	}
+
+	// Ограничение времени выполнения запроса.
+	ctx, finish := beginQuery(ctx, "AnswerService.RemoveUpvote", attribute.Int("answer.id", answerID), attribute.Int("tutor.id", tutorID))
+	defer finish()
+
+	// Удаление голоса и пересчет счетчиков - в одной транзакции.
+	tx, err := answerService.db.BeginTx(ctx, nil)
+	if err != nil {
+		return queryError(ctx, err)
+	}
+
+	// Откат, если транзакция не была зафиксирована.
+	defer tx.Rollback()
+
+	// Голос снимается только с ответа, видимого вызывающему.
+	if err := checkVisible(ctx, tx, "answers", answerID); err != nil {
+		return queryError(ctx, err)
+	}
+
+	var id int
+	err = tx.QueryRowContext(ctx, `select id from answers where id = $1 for update`, answerID).Scan(&id)
+	if err != nil {
+		return queryError(ctx, err)
+	}
+
+	result, err := tx.ExecContext(ctx, `delete from answer_votes where answer_id = $1 and tutor_id = $2`, answerID, tutorID)
+	if err != nil {
+		return queryError(ctx, err)
+	}
+
+	// Выполнение функции, которая возаращает количество удаленных строк.
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return queryError(ctx, err)
+	}
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	if _, err := recountAnswerVotes(ctx, tx, answerID); err != nil {
+		return queryError(ctx, err)
+	}
+
+	// Фиксация транзакции.
+	if err := tx.Commit(); err != nil {
+		return queryError(ctx, err)
+	}
+
+	return nil
+}
+
+// FeedbackReport возвращает ответы с худшей долей оценок "полезен" среди ответов, у которых не меньше minFeedback оценок.
+// При равной доле первыми идут ответы с большим числом оценок.
+func (answerService *AnswerService) FeedbackReport(ctx context.Context, minFeedback int, limit int) ([]models.AnswerFeedbackStat, error) {
+
+	// Ограничение времени выполнения запроса.
+	ctx, finish := beginQuery(ctx, "AnswerService.FeedbackReport")
+	defer finish()
+
+	var query string = `select a.id, a.answer_text, a.tutor_id, a.question_id, a.created_at, a.is_edit, a.is_accepted, a.position, a.helpful_count, a.not_helpful_count, a.upvote_count, a.score, a.status,
+			a.helpful_count + a.not_helpful_count as feedback,
+			a.helpful_count::double precision / (a.helpful_count + a.not_helpful_count) as helpful_ratio,
+			coalesce((
+				select array_agg(f.comment order by f.updated_at desc)
+				from (
+					select comment, updated_at from answer_feedback
+					where answer_id = a.id and comment <> ''
+					order by updated_at desc
+					limit $3
+				) f
+			), '{}')
+		from answers a
+		where a.helpful_count + a.not_helpful_count >= greatest($1, 1)
+		order by helpful_ratio, feedback desc, a.id
+		limit $2`
+
+	rows, err := answerService.db.QueryContext(ctx, query, minFeedback, limit, feedbackReportComments)
+	if err != nil {
+		return nil, queryError(ctx, err)
+	}
+	defer rows.Close()
+
+	// Пустой отчет возвращается как [], а не null.
+	stats := []models.AnswerFeedbackStat{}
+	for rows.Next() {
+		var stat models.AnswerFeedbackStat
+		answer := &stat.Answer
+		err := rows.Scan(&answer.ID, &answer.AnswersText, &answer.TutorID, &answer.QuestionID, &answer.CreatedAt, &answer.IsEdit, &answer.IsAccepted, &answer.Position, &answer.HelpfulCount, &answer.NotHelpfulCount, &answer.Upvotes, &answer.Score, &answer.Status,
+			&stat.Feedback, &stat.HelpfulRatio, pq.Array(&stat.RecentComments))
+		if err != nil {
+			return nil, queryError(ctx, err)
+		}
+		stats = append(stats, stat)
+	}
+
+	// Проверка ошибки, которая могла прервать чтение строк.
+	if err := rows.Err(); err != nil {
+		return nil, queryError(ctx, err)
+	}
+
+	recordRows(ctx, len(stats))
+
+	return stats, nil
+}
+
+// Пересчитывает счетчики оценок ответа по таблицам оценок и возвращает ответ.
+func recountAnswerVotes(ctx context.Context, tx *sql.Tx, answerID int) (models.Answer, error) {
+	var query string = `update answers set
+			helpful_count = (select count(*) from answer_feedback where answer_id = $1 and helpful),
+			not_helpful_count = (select count(*) from answer_feedback where answer_id = $1 and not helpful),
+			upvote_count = (select count(*) from answer_votes where answer_id = $1)
+		where id = $1
+		returning id, answer_text, tutor_id, question_id, created_at, is_edit, is_accepted, position, helpful_count, not_helpful_count, upvote_count, score, status`
+
+	var answer models.Answer
+	err := tx.QueryRowContext(ctx, query, answerID).Scan(&answer.ID, &answer.AnswersText, &answer.TutorID, &answer.QuestionID, &answer.CreatedAt, &answer.IsEdit, &answer.IsAccepted, &answer.Position, &answer.HelpfulCount, &answer.NotHelpfulCount, &answer.Upvotes, &answer.Score, &answer.Status)
+	return answer, err
+}
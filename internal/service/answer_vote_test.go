@@ -0,0 +1,54 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// Проверка права голоса выполняется до обращения к БД, поэтому сервису БД не нужна.
+func TestUpvoteForbidden(t *testing.T) {
+	answerService := &AnswerService{}
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+	}{
+		{name: "учащийся", ctx: context.Background()},
+		{name: "общий токен тьютора", ctx: WithRole(context.Background(), RoleTutor)},
+		{name: "модератор", ctx: WithRole(context.Background(), RoleModerator)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := answerService.Upvote(tt.ctx, 1)
+			if !errors.Is(err, ErrVoteForbidden) {
+				t.Errorf("Upvote() error = %v, want %v", err, ErrVoteForbidden)
+			}
+		})
+	}
+}
+
+func TestRemoveUpvoteForbidden(t *testing.T) {
+	answerService := &AnswerService{}
+	tutor := WithTutorID(WithRole(context.Background(), RoleTutor), 7)
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		tutorID int
+	}{
+		{name: "учащийся", ctx: context.Background(), tutorID: 7},
+		{name: "общий токен тьютора", ctx: WithRole(context.Background(), RoleTutor), tutorID: 7},
+		{name: "чужой голос", ctx: tutor, tutorID: 8},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := answerService.RemoveUpvote(tt.ctx, 1, tt.tutorID)
+			if !errors.Is(err, ErrVoteForbidden) {
+				t.Errorf("RemoveUpvote() error = %v, want %v", err, ErrVoteForbidden)
+			}
+		})
+	}
+}
@@ -0,0 +1,341 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"knowledge-base/internal/models"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/lib/pq"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// Максимальная длина свободного вопроса в символах.
+const maxAskLength = 1000
+
+// Состояния запроса без ответа.
+const (
+	DemandOpen      = "open"
+	DemandResolved  = "resolved"
+	DemandDismissed = "dismissed"
+)
+
+// Ошибки свободного вопроса и списка вопросов без ответа.
+var (
+	// Пустой или слишком длинный вопрос.
+	ErrInvalidAsk = errors.New("неверный вопрос")
+
+	// Запрос уже закрыт: по нему создан вопрос или он отклонен.
+	ErrDemandClosed = errors.New("запрос уже обработан")
+
+	// Вопрос с таким текстом уже есть.
+	ErrQuestionExists = errors.New("вопрос с таким текстом уже есть")
+)
+
+// Настройки ответов на свободные вопросы.
+type AskSettings struct {
+	// Уверенность от 0 до 1, с которой найденный вопрос считается ответом.
+	ConfidenceThreshold float64
+
+	// Количество альтернатив в ответе.
+	Alternatives int
+}
+
+// Настройки по умолчанию.
+var askSettings = AskSettings{
+	ConfidenceThreshold: 0.5,
+	Alternatives:        3,
+}
+
+// Минимальное сходство (similarity) для отбора кандидатов trigram индексом. Меньше порога уверенности,
+// чтобы в альтернативы попадали и не очень похожие вопросы.
+const askCandidateThreshold = 0.1
+
+// SetAskSettings задает настройки ответов на свободные вопросы. Значения вне диапазона не меняют настройку.
+func SetAskSettings(settings AskSettings) {
+	if settings.ConfidenceThreshold > 0 && settings.ConfidenceThreshold <= 1 {
+		askSettings.ConfidenceThreshold = settings.ConfidenceThreshold
+	}
+	if settings.Alternatives > 0 {
+		askSettings.Alternatives = settings.Alternatives
+	}
+}
+
+// Структура для работы со всеми ф-ями service/ask.go.
+type AskService struct {
+	db *sql.DB
+}
+
+// Функция для создания объекта типа AskService.
+func NewAskService(db *sql.DB) *AskService {
+	return &AskService{db: db}
+}
+
+// Ask ищет вопрос с ответом, самый похожий на свободный вопрос, по косинусному сходству триграмм.
+// Если уверенность ниже порога, запрос записывается в список вопросов без ответа.
+// Без расширения pg_trgm возвращается ErrTrigramUnavailable.
+func (askService *AskService) Ask(ctx context.Context, text string) (models.AskResult, error) {
+	if err := requireTrigram(); err != nil {
+		return models.AskResult{}, err
+	}
+
+	// Ограничение времени выполнения запроса.
+	ctx, finish := beginQuery(ctx, "AskService.Ask")
+	defer finish()
+
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return models.AskResult{}, fmt.Errorf("%w: пустой вопрос", ErrInvalidAsk)
+	}
+	if utf8.RuneCountInString(text) > maxAskLength {
+		return models.AskResult{}, fmt.Errorf("%w: не длиннее %d символов", ErrInvalidAsk, maxAskLength)
+	}
+
+	matches, err := askService.candidates(ctx, text, askSettings.Alternatives+1)
+	if err != nil {
+		return models.AskResult{}, queryError(ctx, err)
+	}
+
+	result := models.AskResult{Alternatives: []models.AskMatch{}}
+	if len(matches) > 0 {
+		result.Confidence = matches[0].Confidence
+	}
+
+	if result.Confidence >= askSettings.ConfidenceThreshold {
+		result.Answered = true
+		result.Best = &matches[0]
+		result.Alternatives = append(result.Alternatives, matches[1:]...)
+	} else {
+		// Ответа нет: все кандидаты - альтернативы, запрос попадает в список для тьюторов.
+		if len(matches) > askSettings.Alternatives {
+			matches = matches[:askSettings.Alternatives]
+		}
+		result.Alternatives = append(result.Alternatives, matches...)
+
+		var query string = `insert into unanswered_demand (query_text, normalized_text, best_confidence)
+			values ($1, lower(regexp_replace($1, '\s+', ' ', 'g')), $2)
+			on conflict (normalized_text) where status = 'open'
+			do update set ask_count = unanswered_demand.ask_count + 1, last_asked_at = now(),
+				best_confidence = greatest(unanswered_demand.best_confidence, excluded.best_confidence)
+			returning id`
+
+		var demandID int
+		if err := askService.db.QueryRowContext(ctx, query, text, result.Confidence).Scan(&demandID); err != nil {
+			return models.AskResult{}, queryError(ctx, err)
+		}
+		result.DemandID = &demandID
+	}
+
+	recordRows(ctx, len(matches))
+
+	return result, nil
+}
+
+// Вопросы с ответом, похожие на текст, самые похожие сначала.
+func (askService *AskService) candidates(ctx context.Context, text string, limit int) ([]models.AskMatch, error) {
+
+	// Порог оператора % задается только на время транзакции, чтобы работал trigram индекс.
+	tx, err := askService.db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return nil, err
+	}
+
+	// Откат, если транзакция не была зафиксирована.
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `select set_config('pg_trgm.similarity_threshold', $1, true)`,
+		strconv.FormatFloat(askCandidateThreshold, 'f', -1, 64))
+	if err != nil {
+		return nil, err
+	}
+
+	// Ответом вопроса считается принятый ответ, а если его нет - первый по порядку.
+	// Учащемуся подбираются только опубликованные вопросы и ответы.
+	var query string = `select q.id, q.question_text, q.tutor_id, q.created_at, q.is_edit, q.status,
+			a.id, a.answer_text, a.tutor_id, a.question_id, a.created_at, a.is_edit, a.is_accepted, a.position, a.helpful_count, a.not_helpful_count, a.upvote_count, a.score, a.status,
+			trgm_cosine(lower(q.question_text), lower($1)) as confidence
+		from questions q
+		join lateral (
+			select * from answers a where a.question_id = q.id and ` + visibleAnswer(ctx, "a") + ` order by ` + answerOrder + ` limit 1
+		) a on true
+		where lower(q.question_text) % lower($1) and ` + visibleQuestion(ctx, "q") + `
+		order by confidence desc, q.id
+		limit $2`
+
+	rows, err := tx.QueryContext(ctx, query, text, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var matches []models.AskMatch
+	for rows.Next() {
+		var match models.AskMatch
+		question, answer := &match.Question, &match.Answer
+		err := rows.Scan(&question.ID, &question.QuestionText, &question.TutorID, &question.CreatedAt, &question.IsEdit, &question.Status,
+			&answer.ID, &answer.AnswersText, &answer.TutorID, &answer.QuestionID, &answer.CreatedAt, &answer.IsEdit, &answer.IsAccepted, &answer.Position, &answer.HelpfulCount, &answer.NotHelpfulCount, &answer.Upvotes, &answer.Score, &answer.Status,
+			&match.Confidence)
+		if err != nil {
+			return nil, err
+		}
+		matches = append(matches, match)
+	}
+	return matches, rows.Err()
+}
+
+// GetDemand возвращает запросы без ответа с состоянием status: самые частые и недавние сначала.
+func (askService *AskService) GetDemand(ctx context.Context, status string, limit int, offset int) ([]models.UnansweredDemand, int, error) {
+
+	// Ограничение времени выполнения запроса.
+	ctx, finish := beginQuery(ctx, "AskService.GetDemand", attribute.String("demand.status", status))
+	defer finish()
+
+	var total int
+	err := askService.db.QueryRowContext(ctx, `select count(*) from unanswered_demand where status = $1`, status).Scan(&total)
+	if err != nil {
+		return nil, 0, queryError(ctx, err)
+	}
+
+	var query string = demandColumns + ` from unanswered_demand where status = $1
+		order by ask_count desc, last_asked_at desc, id
+		limit $2 offset $3`
+
+	rows, err := askService.db.QueryContext(ctx, query, status, limit, offset)
+	if err != nil {
+		return nil, 0, queryError(ctx, err)
+	}
+	defer rows.Close()
+
+	// Пустой список возвращается как [], а не null.
+	demand := []models.UnansweredDemand{}
+	for rows.Next() {
+		item, err := scanDemand(rows)
+		if err != nil {
+			return nil, 0, queryError(ctx, err)
+		}
+		demand = append(demand, item)
+	}
+
+	// Проверка ошибки, которая могла прервать чтение строк.
+	if err := rows.Err(); err != nil {
+		return nil, 0, queryError(ctx, err)
+	}
+
+	recordRows(ctx, len(demand))
+
+	return demand, total, nil
+}
+
+// CreateQuestion создает вопрос по открытому запросу без ответа и закрывает запрос.
+// Пустой questionText - текст запроса. Если запроса нет, возвращается sql.ErrNoRows, если он закрыт - ErrDemandClosed.
+func (askService *AskService) CreateQuestion(ctx context.Context, id int, questionText string, tutorID *int) (models.UnansweredDemand, error) {
+
+	// Ограничение времени выполнения запроса.
+	ctx, finish := beginQuery(ctx, "AskService.CreateQuestion", attribute.Int("demand.id", id))
+	defer finish()
+
+	// Вопрос, версия и закрытие запроса - в одной транзакции.
+	tx, err := askService.db.BeginTx(ctx, nil)
+	if err != nil {
+		return models.UnansweredDemand{}, queryError(ctx, err)
+	}
+
+	// Откат, если транзакция не была зафиксирована.
+	defer tx.Rollback()
+
+	// Блокировка запроса, чтобы по нему не создали два вопроса.
+	var queryText, status string
+	err = tx.QueryRowContext(ctx, `select query_text, status from unanswered_demand where id = $1 for update`, id).Scan(&queryText, &status)
+	if err != nil {
+		return models.UnansweredDemand{}, queryError(ctx, err)
+	}
+	if status != DemandOpen {
+		return models.UnansweredDemand{}, ErrDemandClosed
+	}
+
+	questionText = strings.TrimSpace(questionText)
+	if questionText == "" {
+		questionText = queryText
+	}
+
+	var questionID int
+	err = tx.QueryRowContext(ctx, `insert into questions (question_text, tutor_id) values ($1, $2) returning id`, questionText, tutorID).Scan(&questionID)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+			return models.UnansweredDemand{}, ErrQuestionExists
+		}
+		return models.UnansweredDemand{}, queryError(ctx, err)
+	}
+
+	_, err = tx.ExecContext(ctx, `insert into question_versions (question_id, question_text, tutor_id, version_number) values ($1, $2, $3, 1)`,
+		questionID, questionText, tutorID)
+	if err != nil {
+		return models.UnansweredDemand{}, queryError(ctx, err)
+	}
+
+	row := tx.QueryRowContext(ctx, `update unanswered_demand set status = $2, question_id = $3 where id = $1 returning `+demandReturning,
+		id, DemandResolved, questionID)
+	demand, err := scanDemand(row)
+	if err != nil {
+		return models.UnansweredDemand{}, queryError(ctx, err)
+	}
+
+	// Фиксация транзакции.
+	if err := tx.Commit(); err != nil {
+		return models.UnansweredDemand{}, queryError(ctx, err)
+	}
+
+	notifyQuestionsChanged(ctx, questionID)
+
+	return demand, nil
+}
+
+// Dismiss отклоняет открытый запрос без ответа. Если запроса нет, возвращается sql.ErrNoRows, если он закрыт - ErrDemandClosed.
+func (askService *AskService) Dismiss(ctx context.Context, id int) (models.UnansweredDemand, error) {
+
+	// Ограничение времени выполнения запроса.
+	ctx, finish := beginQuery(ctx, "AskService.Dismiss", attribute.Int("demand.id", id))
+	defer finish()
+
+	row := askService.db.QueryRowContext(ctx, `update unanswered_demand set status = $2 where id = $1 and status = $3 returning `+demandReturning,
+		id, DemandDismissed, DemandOpen)
+	demand, err := scanDemand(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		// Отличие закрытого запроса от несуществующего.
+		var exists bool
+		if err := askService.db.QueryRowContext(ctx, `select exists(select 1 from unanswered_demand where id = $1)`, id).Scan(&exists); err != nil {
+			return models.UnansweredDemand{}, queryError(ctx, err)
+		}
+		if exists {
+			return models.UnansweredDemand{}, ErrDemandClosed
+		}
+	}
+	if err != nil {
+		return models.UnansweredDemand{}, queryError(ctx, err)
+	}
+
+	return demand, nil
+}
+
+// Колонки запроса без ответа в порядке scanDemand.
+const demandReturning = `id, query_text, ask_count, best_confidence, status, question_id, created_at, last_asked_at`
+
+const demandColumns = `select ` + demandReturning
+
+// Общий интерфейс *sql.Row и *sql.Rows для чтения одной строки.
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// Чтение запроса без ответа из строки с колонками demandReturning.
+func scanDemand(row rowScanner) (models.UnansweredDemand, error) {
+	var demand models.UnansweredDemand
+	err := row.Scan(&demand.ID, &demand.QueryText, &demand.AskCount, &demand.BestConfidence, &demand.Status,
+		&demand.QuestionID, &demand.CreatedAt, &demand.LastAskedAt)
+	return demand, err
+}
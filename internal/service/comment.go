@@ -0,0 +1,545 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"knowledge-base/internal/models"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/lib/pq"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// К чему относится комментарий.
+const (
+	CommentOnQuestion = "question"
+	CommentOnAnswer   = "answer"
+)
+
+// Автор комментария.
+const (
+	CommentAuthorTutor   = "tutor"
+	CommentAuthorLearner = "learner"
+)
+
+// Ограничения комментария.
+const (
+	maxCommentText     = 5000
+	maxLearnerName     = 50
+	defaultLearnerName = "Учащийся"
+)
+
+// Ошибки комментариев.
+var (
+	ErrInvalidComment   = errors.New("неверный комментарий")
+	ErrCommentForbidden = errors.New("комментарий может изменить или удалить только его автор")
+	ErrCommentDeleted   = errors.New("комментарий удален")
+)
+
+// Вопрос или ответ, к которому относятся комментарии.
+type CommentTarget struct {
+	// CommentOnQuestion или CommentOnAnswer.
+	Kind string
+	ID   int
+}
+
+// Колонка комментария и таблица для цели. Имена берутся только отсюда, поэтому их можно подставлять в SQL.
+func (target CommentTarget) columns() (column string, table string) {
+	if target.Kind == CommentOnAnswer {
+		return "answer_id", "answers"
+	}
+	return "question_id", "questions"
+}
+
+// Новый комментарий.
+type CommentInput struct {
+	Text string
+
+	// Автор-тьютор. nil - автор учащийся, которого определяет ClientID.
+	TutorID     *int
+	LearnerName string
+	ClientID    string
+
+	ParentID *int
+}
+
+// Колонки комментария с именем автора. Используются с "from comments c left join tutors t on t.id = c.tutor_id".
+const commentColumns = `c.id, c.question_id, c.answer_id, c.parent_id, c.author_type, c.tutor_id, coalesce(t.full_name, c.learner_name, ''),
+	c.comment_text, c.created_at, c.updated_at, c.is_edit, c.is_deleted, c.deleted_at, c.delete_by_tutor`
+
+// Структура для работы со всеми ф-ями service/comment.go.
+type CommentService struct {
+	db *sql.DB
+}
+
+// Функция для создания объекта типа CommentService.
+func NewCommentService(db *sql.DB) *CommentService {
+	return &CommentService{db: db}
+}
+
+// GetThread возвращает комментарии вопроса или ответа деревом: ветки по порядку создания, ответы вложены в replies.
+// Удаленные комментарии остаются в дереве без текста. Если вопроса или ответа нет или он не виден вызывающему, возвращается sql.ErrNoRows.
+func (commentService *CommentService) GetThread(ctx context.Context, target CommentTarget) ([]models.Comment, error) {
+
+	// Ограничение времени выполнения запроса.
+	ctx, finish := beginQuery(ctx, "CommentService.GetThread", attribute.String("comment.target", target.Kind), attribute.Int("comment.target_id", target.ID))
+	defer finish()
+
+	column, table := target.columns()
+
+	if err := checkVisible(ctx, commentService.db, table, target.ID); err != nil {
+		return nil, queryError(ctx, err)
+	}
+
+	comments, err := queryComments(ctx, commentService.db, `select `+commentColumns+`
+		from comments c left join tutors t on t.id = c.tutor_id
+		where c.`+column+` = $1
+		order by c.id`, target.ID)
+	if err != nil {
+		return nil, queryError(ctx, err)
+	}
+
+	recordRows(ctx, len(comments))
+
+	return commentTree(comments), nil
+}
+
+// GetByID возвращает комментарий без ответов на него.
+// Если комментария нет или его вопрос или ответ не виден вызывающему, возвращается sql.ErrNoRows.
+func (commentService *CommentService) GetByID(ctx context.Context, id int) (models.Comment, error) {
+
+	// Ограничение времени выполнения запроса.
+	ctx, finish := beginQuery(ctx, "CommentService.GetByID", attribute.Int("comment.id", id))
+	defer finish()
+
+	comment, err := getComment(ctx, commentService.db, id)
+	if err != nil {
+		return models.Comment{}, queryError(ctx, err)
+	}
+
+	target := CommentTarget{Kind: CommentOnQuestion}
+	if comment.AnswerID != nil {
+		target = CommentTarget{Kind: CommentOnAnswer, ID: *comment.AnswerID}
+	} else if comment.QuestionID != nil {
+		target.ID = *comment.QuestionID
+	}
+	_, table := target.columns()
+	if err := checkVisible(ctx, commentService.db, table, target.ID); err != nil {
+		return models.Comment{}, queryError(ctx, err)
+	}
+
+	return comment, nil
+}
+
+// Create добавляет комментарий к вопросу или ответу и записывает упомянутых тьюторов.
+// Ответ на комментарий должен относиться к тому же вопросу или ответу.
+// Если вопроса или ответа нет или он не виден вызывающему, возвращается sql.ErrNoRows.
+func (commentService *CommentService) Create(ctx context.Context, target CommentTarget, input CommentInput) (models.Comment, error) {
+
+	// Ограничение времени выполнения запроса.
+	ctx, finish := beginQuery(ctx, "CommentService.Create", attribute.String("comment.target", target.Kind), attribute.Int("comment.target_id", target.ID))
+	defer finish()
+
+	text, err := commentText(input.Text)
+	if err != nil {
+		return models.Comment{}, err
+	}
+
+	authorType := CommentAuthorTutor
+	var learnerName, clientID *string
+	if input.TutorID == nil {
+		authorType = CommentAuthorLearner
+		name := strings.TrimSpace(input.LearnerName)
+		if name == "" {
+			name = defaultLearnerName
+		}
+		if utf8.RuneCountInString(name) > maxLearnerName {
+			return models.Comment{}, fmt.Errorf("%w: learner_name длиннее %d символов", ErrInvalidComment, maxLearnerName)
+		}
+		learnerName, clientID = &name, &input.ClientID
+	}
+
+	column, table := target.columns()
+
+	// Комментарий и упоминания - в одной транзакции.
+	tx, err := commentService.db.BeginTx(ctx, nil)
+	if err != nil {
+		return models.Comment{}, queryError(ctx, err)
+	}
+
+	// Откат, если транзакция не была зафиксирована.
+	defer tx.Rollback()
+
+	if err := checkVisible(ctx, tx, table, target.ID); err != nil {
+		return models.Comment{}, queryError(ctx, err)
+	}
+
+	// Ответ возможен только на неудаленный комментарий той же ветки.
+	if input.ParentID != nil {
+		var parentTarget sql.NullInt64
+		var parentDeleted bool
+		err = tx.QueryRowContext(ctx, `select `+column+`, is_deleted from comments where id = $1`, *input.ParentID).Scan(&parentTarget, &parentDeleted)
+		if errors.Is(err, sql.ErrNoRows) || (err == nil && (!parentTarget.Valid || int(parentTarget.Int64) != target.ID)) {
+			return models.Comment{}, fmt.Errorf("%w: комментарий %d не найден в этой ветке", ErrInvalidComment, *input.ParentID)
+		}
+		if err != nil {
+			return models.Comment{}, queryError(ctx, err)
+		}
+		if parentDeleted {
+			return models.Comment{}, ErrCommentDeleted
+		}
+	}
+
+	var id int
+	err = tx.QueryRowContext(ctx, `insert into comments (`+column+`, parent_id, author_type, tutor_id, learner_name, client_id, comment_text)
+		values ($1, $2, $3, $4, $5, $6, $7) returning id`,
+		target.ID, input.ParentID, authorType, input.TutorID, learnerName, clientID, text).Scan(&id)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23503" && input.TutorID != nil {
+			return models.Comment{}, fmt.Errorf("%w: тьютор %d не найден", ErrInvalidComment, *input.TutorID)
+		}
+		return models.Comment{}, queryError(ctx, err)
+	}
+
+	if err := saveMentions(ctx, tx, id, text); err != nil {
+		return models.Comment{}, queryError(ctx, err)
+	}
+
+	comment, err := getComment(ctx, tx, id)
+	if err != nil {
+		return models.Comment{}, queryError(ctx, err)
+	}
+
+	// Фиксация транзакции.
+	if err := tx.Commit(); err != nil {
+		return models.Comment{}, queryError(ctx, err)
+	}
+
+	return comment, nil
+}
+
+// Update изменяет текст комментария и пересчитывает упоминания. Комментарий тьютора изменяет только он сам (tutorID),
+// комментарий учащегося - тот же клиент без tutorID, иначе возвращается ErrCommentForbidden.
+func (commentService *CommentService) Update(ctx context.Context, id int, text string, tutorID *int, clientID string) (models.Comment, error) {
+
+	// Ограничение времени выполнения запроса.
+	ctx, finish := beginQuery(ctx, "CommentService.Update", attribute.Int("comment.id", id))
+	defer finish()
+
+	text, err := commentText(text)
+	if err != nil {
+		return models.Comment{}, err
+	}
+
+	// Проверка автора, текст и упоминания - в одной транзакции.
+	tx, err := commentService.db.BeginTx(ctx, nil)
+	if err != nil {
+		return models.Comment{}, queryError(ctx, err)
+	}
+
+	// Откат, если транзакция не была зафиксирована.
+	defer tx.Rollback()
+
+	if err := checkCommentAuthor(ctx, tx, id, tutorID, clientID); err != nil {
+		return models.Comment{}, err
+	}
+
+	_, err = tx.ExecContext(ctx, `update comments set comment_text = $2, is_edit = true, updated_at = now() where id = $1`, id, text)
+	if err != nil {
+		return models.Comment{}, queryError(ctx, err)
+	}
+
+	_, err = tx.ExecContext(ctx, `delete from comment_mentions where comment_id = $1`, id)
+	if err != nil {
+		return models.Comment{}, queryError(ctx, err)
+	}
+	if err := saveMentions(ctx, tx, id, text); err != nil {
+		return models.Comment{}, queryError(ctx, err)
+	}
+
+	comment, err := getComment(ctx, tx, id)
+	if err != nil {
+		return models.Comment{}, queryError(ctx, err)
+	}
+
+	// Фиксация транзакции.
+	if err := tx.Commit(); err != nil {
+		return models.Comment{}, queryError(ctx, err)
+	}
+
+	return comment, nil
+}
+
+// DeleteByTutor мягко удаляет комментарий с отметкой удалившего тьютора, как при удалении вопросов и ответов.
+// Если комментария нет, возвращается sql.ErrNoRows, если он уже удален - ErrCommentDeleted.
+func (commentService *CommentService) DeleteByTutor(ctx context.Context, id int, deleteByTutor int) error {
+
+	// Ограничение времени выполнения запроса.
+	ctx, finish := beginQuery(ctx, "CommentService.DeleteByTutor", attribute.Int("comment.id", id))
+	defer finish()
+
+	return softDeleteComment(ctx, commentService.db, id, &deleteByTutor)
+}
+
+// DeleteOwn мягко удаляет комментарий учащегося с того же клиента, который его написал.
+func (commentService *CommentService) DeleteOwn(ctx context.Context, id int, clientID string) error {
+
+	// Ограничение времени выполнения запроса.
+	ctx, finish := beginQuery(ctx, "CommentService.DeleteOwn", attribute.Int("comment.id", id))
+	defer finish()
+
+	// Проверка автора и удаление - в одной транзакции.
+	tx, err := commentService.db.BeginTx(ctx, nil)
+	if err != nil {
+		return queryError(ctx, err)
+	}
+
+	// Откат, если транзакция не была зафиксирована.
+	defer tx.Rollback()
+
+	if err := checkCommentAuthor(ctx, tx, id, nil, clientID); err != nil {
+		return err
+	}
+
+	if err := softDeleteComment(ctx, tx, id, nil); err != nil {
+		return err
+	}
+
+	// Фиксация транзакции.
+	if err := tx.Commit(); err != nil {
+		return queryError(ctx, err)
+	}
+
+	return nil
+}
+
+// GetMentions возвращает неудаленные комментарии, в которых упомянут тьютор, новые сначала, и их общее количество.
+// Комментарии к вопросам и ответам, которые не видны вызывающему, не возвращаются.
+func (commentService *CommentService) GetMentions(ctx context.Context, tutorID int, limit int, offset int) ([]models.Comment, int, error) {
+
+	// Ограничение времени выполнения запроса.
+	ctx, finish := beginQuery(ctx, "CommentService.GetMentions", attribute.Int("tutor.id", tutorID))
+	defer finish()
+
+	where := ` where m.tutor_id = $1 and not c.is_deleted
+		and (c.question_id is null or exists (select 1 from questions cq where cq.id = c.question_id and ` + visibleQuestion(ctx, "cq") + `))
+		and (c.answer_id is null or exists (select 1 from answers ca where ca.id = c.answer_id and ` + visibleAnswer(ctx, "ca") + `))`
+
+	var total int
+	err := commentService.db.QueryRowContext(ctx, `select count(*) from comment_mentions m join comments c on c.id = m.comment_id`+where, tutorID).Scan(&total)
+	if err != nil {
+		return nil, 0, queryError(ctx, err)
+	}
+
+	comments, err := queryComments(ctx, commentService.db, `select `+commentColumns+`
+		from comment_mentions m
+		join comments c on c.id = m.comment_id
+		left join tutors t on t.id = c.tutor_id`+where+`
+		order by c.id desc
+		limit $2 offset $3`, tutorID, limit, offset)
+	if err != nil {
+		return nil, 0, queryError(ctx, err)
+	}
+
+	recordRows(ctx, len(comments))
+
+	return comments, total, nil
+}
+
+// Проверка текста комментария.
+func commentText(text string) (string, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return "", fmt.Errorf("%w: пустой текст", ErrInvalidComment)
+	}
+	if utf8.RuneCountInString(text) > maxCommentText {
+		return "", fmt.Errorf("%w: текст длиннее %d символов", ErrInvalidComment, maxCommentText)
+	}
+	return text, nil
+}
+
+// Проверка, что комментарий изменяет его автор. Строка комментария блокируется до конца транзакции.
+func checkCommentAuthor(ctx context.Context, tx *sql.Tx, id int, tutorID *int, clientID string) error {
+	var authorType string
+	var authorTutor sql.NullInt64
+	var authorClient sql.NullString
+	var deleted bool
+	err := tx.QueryRowContext(ctx, `select author_type, tutor_id, client_id, is_deleted from comments where id = $1 for update`, id).
+		Scan(&authorType, &authorTutor, &authorClient, &deleted)
+	if err != nil {
+		return queryError(ctx, err)
+	}
+	if deleted {
+		return ErrCommentDeleted
+	}
+
+	switch {
+	case tutorID != nil:
+		if authorType != CommentAuthorTutor || !authorTutor.Valid || int(authorTutor.Int64) != *tutorID {
+			return ErrCommentForbidden
+		}
+	case authorType != CommentAuthorLearner || !authorClient.Valid || authorClient.String != clientID:
+		return ErrCommentForbidden
+	}
+	return nil
+}
+
+// Изменение строк в базе или в транзакции.
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// Мягкое удаление: отметка, время и удаливший тьютор. Упоминания удаленного комментария больше не показываются.
+func softDeleteComment(ctx context.Context, db execer, id int, deleteByTutor *int) error {
+	result, err := db.ExecContext(ctx, `update comments set is_deleted = true, deleted_at = now(), delete_by_tutor = $2
+		where id = $1 and not is_deleted`, id, deleteByTutor)
+	if err != nil {
+		return queryError(ctx, err)
+	}
+
+	// Выполнение функции, которая возаращает количество измененных строк.
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return queryError(ctx, err)
+	}
+
+	recordRows(ctx, int(rowsAffected))
+
+	// Комментария нет или он уже удален.
+	if rowsAffected == 0 {
+		var exists bool
+		if err := db.QueryRowContext(ctx, `select exists (select 1 from comments where id = $1)`, id).Scan(&exists); err != nil {
+			return queryError(ctx, err)
+		}
+		if exists {
+			return ErrCommentDeleted
+		}
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// Запись упомянутых в тексте тьюторов.
+func saveMentions(ctx context.Context, tx *sql.Tx, commentID int, text string) error {
+	tutors, err := resolveMentions(ctx, tx, text)
+	if err != nil || len(tutors) == 0 {
+		return err
+	}
+
+	ids := make([]int, 0, len(tutors))
+	for _, tutor := range tutors {
+		ids = append(ids, tutor.ID)
+	}
+
+	_, err = tx.ExecContext(ctx, `insert into comment_mentions (comment_id, tutor_id) select $1, unnest($2::int[]) on conflict do nothing`,
+		commentID, pq.Array(ids))
+	return err
+}
+
+// Комментарий по ID с упоминаниями.
+func getComment(ctx context.Context, db queryer, id int) (models.Comment, error) {
+	comments, err := queryComments(ctx, db, `select `+commentColumns+`
+		from comments c left join tutors t on t.id = c.tutor_id
+		where c.id = $1`, id)
+	if err != nil {
+		return models.Comment{}, err
+	}
+	if len(comments) == 0 {
+		return models.Comment{}, sql.ErrNoRows
+	}
+	return comments[0], nil
+}
+
+// Выполняет запрос с колонками commentColumns и добавляет упоминания одним запросом на все комментарии.
+func queryComments(ctx context.Context, db queryer, query string, args ...interface{}) ([]models.Comment, error) {
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	// Пустой список возвращается как [], а не null.
+	comments := []models.Comment{}
+	index := map[int]int{}
+	var ids []int
+	for rows.Next() {
+		var comment models.Comment
+		err := rows.Scan(&comment.ID, &comment.QuestionID, &comment.AnswerID, &comment.ParentID, &comment.AuthorType, &comment.TutorID, &comment.AuthorName,
+			&comment.Text, &comment.CreatedAt, &comment.UpdatedAt, &comment.IsEdit, &comment.IsDeleted, &comment.DeletedAt, &comment.DeleteByTutor)
+		if err != nil {
+			return nil, err
+		}
+
+		// Текст удаленного комментария не показывается.
+		if comment.IsDeleted {
+			comment.Text = ""
+		} else {
+			index[comment.ID] = len(comments)
+			ids = append(ids, comment.ID)
+		}
+		comment.Mentions = []models.Tutor{}
+		comments = append(comments, comment)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return comments, nil
+	}
+
+	mentionRows, err := db.QueryContext(ctx, `select m.comment_id, t.id, t.full_name, t.email
+		from comment_mentions m join tutors t on t.id = m.tutor_id
+		where m.comment_id = any($1)
+		order by m.comment_id, t.full_name`, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer mentionRows.Close()
+
+	for mentionRows.Next() {
+		var commentID int
+		var tutor models.Tutor
+		if err := mentionRows.Scan(&commentID, &tutor.ID, &tutor.FullName, &tutor.Email); err != nil {
+			return nil, err
+		}
+		comment := &comments[index[commentID]]
+		comment.Mentions = append(comment.Mentions, tutor)
+	}
+
+	return comments, mentionRows.Err()
+}
+
+// Собирает дерево из комментариев, отсортированных по порядку создания.
+func commentTree(comments []models.Comment) []models.Comment {
+	children := map[int][]int{}
+	var roots []int
+	for i, comment := range comments {
+		if comment.ParentID == nil {
+			roots = append(roots, i)
+			continue
+		}
+		children[*comment.ParentID] = append(children[*comment.ParentID], i)
+	}
+
+	var build func(i int) models.Comment
+	build = func(i int) models.Comment {
+		comment := comments[i]
+		for _, child := range children[comment.ID] {
+			comment.Replies = append(comment.Replies, build(child))
+		}
+		return comment
+	}
+
+	// Пустой список возвращается как [], а не null.
+	tree := []models.Comment{}
+	for _, i := range roots {
+		tree = append(tree, build(i))
+	}
+	return tree
+}
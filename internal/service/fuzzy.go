@@ -0,0 +1,242 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"knowledge-base/internal/models"
+	"sort"
+	"strconv"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// Пороги нечеткого поиска (pg_trgm), от 0 до 1: чем больше, тем строже совпадение.
+type FuzzySettings struct {
+	// Минимальное сходство имени тега с запросом (similarity).
+	TagThreshold float64
+
+	// Минимальное сходство запроса со словами текста вопроса (word_similarity).
+	TextThreshold float64
+
+	// Минимальное сходство тега для подсказок "возможно, вы имели в виду".
+	SuggestThreshold float64
+
+	// Максимальное количество подсказок.
+	SuggestLimit int
+}
+
+// Пороги по умолчанию: первые два совпадают с настройками pg_trgm.
+var fuzzySettings = FuzzySettings{
+	TagThreshold:     0.3,
+	TextThreshold:    0.6,
+	SuggestThreshold: 0.2,
+	SuggestLimit:     5,
+}
+
+// SetFuzzySettings задает пороги нечеткого поиска. Значения вне диапазона (0, 1] не меняют настройку.
+func SetFuzzySettings(settings FuzzySettings) {
+	for _, threshold := range []struct {
+		value  float64
+		target *float64
+	}{
+		{settings.TagThreshold, &fuzzySettings.TagThreshold},
+		{settings.TextThreshold, &fuzzySettings.TextThreshold},
+		{settings.SuggestThreshold, &fuzzySettings.SuggestThreshold},
+	} {
+		if threshold.value > 0 && threshold.value <= 1 {
+			*threshold.target = threshold.value
+		}
+	}
+	if settings.SuggestLimit > 0 {
+		fuzzySettings.SuggestLimit = settings.SuggestLimit
+	}
+}
+
+// FuzzySearch ищет вопросы с опечатками в запросе: по похожим именам тегов и похожим словам в тексте вопроса.
+// Результаты отсортированы по сходству. Если ничего не найдено, возвращаются похожие имена тегов.
+// Без расширения pg_trgm возвращается ErrTrigramUnavailable.
+func (searchService *SearchService) FuzzySearch(ctx context.Context, term string, limit int, offset int) (models.FuzzySearchResult, error) {
+	if err := requireTrigram(); err != nil {
+		return models.FuzzySearchResult{}, err
+	}
+
+	// Ограничение времени выполнения запроса.
+	ctx, finish := beginQuery(ctx, "SearchService.FuzzySearch", attribute.String("search.query", term))
+	defer finish()
+
+	// Пороги операторов % и <% задаются только на время транзакции, чтобы работали trigram индексы.
+	tx, err := searchService.db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return models.FuzzySearchResult{}, queryError(ctx, err)
+	}
+
+	// Откат, если транзакция не была зафиксирована.
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `select set_config('pg_trgm.similarity_threshold', $1, true), set_config('pg_trgm.word_similarity_threshold', $2, true)`,
+		strconv.FormatFloat(fuzzySettings.TagThreshold, 'f', -1, 64), strconv.FormatFloat(fuzzySettings.TextThreshold, 'f', -1, 64))
+	if err != nil {
+		return models.FuzzySearchResult{}, queryError(ctx, err)
+	}
+
+	// Вопросы с похожими тегами и с похожими словами в тексте, для каждого вопроса - лучшее сходство.
+	// Учитываются только вопросы, которые видны вызывающему.
+	var matched string = `with matched_tags as (
+			select t.id, t.tag, similarity(lower(t.tag), lower($1)) as score
+			from tags t
+			where lower(t.tag) % lower($1)
+		),
+		matched as (
+			select question_id, max(score) as score, (array_agg(tag order by score desc) filter (where tag is not null))[1] as tag
+			from (
+				select qt.question_id, mt.score, mt.tag
+				from questions_tags qt join matched_tags mt on mt.id = qt.tag_id
+				union all
+				select q.id, word_similarity(lower($1), lower(q.question_text)), null
+				from questions q
+				where lower($1) <% lower(q.question_text)
+			) found
+			join questions q on q.id = found.question_id
+			where ` + visibleQuestion(ctx, "q") + `
+			group by question_id
+		)`
+
+	result := models.FuzzySearchResult{Results: []models.FuzzyMatch{}}
+
+	// Общее количество найденных вопросов.
+	err = tx.QueryRowContext(ctx, matched+` select count(*) from matched`, term).Scan(&result.Total)
+	if err != nil {
+		return models.FuzzySearchResult{}, queryError(ctx, err)
+	}
+
+	//Создание sql запроса для получения страницы найденных вопросов.
+	var query string = matched + ` select q.id, q.question_text, q.tutor_id, q.created_at, q.is_edit, q.status, m.score, m.tag
+		from questions q join matched m on m.question_id = q.id
+		order by m.score desc, q.id
+		limit $2 offset $3`
+
+	// Выполнение функции, которая проводит sql запрос и возвращает таблицу с несколькими строками.
+	rows, err := tx.QueryContext(ctx, query, term, limit, offset)
+	if err != nil {
+		return models.FuzzySearchResult{}, queryError(ctx, err)
+	}
+
+	// Закрытие, чтобы не было утечки соединений, надо закрыть.
+	defer rows.Close()
+
+	// Запись полученных данных из БД в массив формата []models.FuzzyMatch.
+	for rows.Next() {
+		var match models.FuzzyMatch
+		question := &match.Question
+		err := rows.Scan(&question.ID, &question.QuestionText, &question.TutorID, &question.CreatedAt, &question.IsEdit, &question.Status, &match.Score, &match.Tag)
+		if err != nil {
+			return models.FuzzySearchResult{}, queryError(ctx, err)
+		}
+		result.Results = append(result.Results, match)
+	}
+
+	// Проверка ошибки, которая могла прервать чтение строк.
+	if err := rows.Err(); err != nil {
+		return models.FuzzySearchResult{}, queryError(ctx, err)
+	}
+
+	// Подсказки нужны, только если ничего не найдено.
+	if result.Total == 0 {
+		result.Suggestions, err = suggestTags(ctx, tx, term)
+		if err != nil {
+			return models.FuzzySearchResult{}, queryError(ctx, err)
+		}
+	}
+
+	recordRows(ctx, len(result.Results))
+
+	return result, nil
+}
+
+// Suggest возвращает имена тегов, похожие на name, самые похожие первыми.
+func (tagService *TagService) Suggest(ctx context.Context, name string) ([]string, error) {
+
+	// Ограничение времени выполнения запроса.
+	ctx, finish := beginQuery(ctx, "TagService.Suggest", attribute.String("tag.name", name))
+	defer finish()
+
+	suggestions, err := suggestTags(ctx, tagService.db, name)
+	if err != nil {
+		return nil, queryError(ctx, err)
+	}
+	return suggestions, nil
+}
+
+// Имена тегов, похожие на name, для подсказок "возможно, вы имели в виду".
+func suggestTags(ctx context.Context, db queryer, name string) ([]string, error) {
+	if !trigramEnabled {
+		return suggestTagsLocal(ctx, db, name)
+	}
+
+	var query string = `select tag from (
+			select tag, similarity(lower(tag), lower($1)) as score from tags
+		) t
+		where score >= $2
+		order by score desc, tag
+		limit $3`
+
+	rows, err := db.QueryContext(ctx, query, name, fuzzySettings.SuggestThreshold, fuzzySettings.SuggestLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var suggestions []string
+	for rows.Next() {
+		var tag string
+		if err := rows.Scan(&tag); err != nil {
+			return nil, err
+		}
+		suggestions = append(suggestions, tag)
+	}
+	return suggestions, rows.Err()
+}
+
+// Подсказки тегов без расширения pg_trgm: сходство всех имен тегов с name считается в Go так же, как similarity.
+// Тегов немного, поэтому читаются все имена.
+func suggestTagsLocal(ctx context.Context, db queryer, name string) ([]string, error) {
+	rows, err := db.QueryContext(ctx, `select tag from tags`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	type scored struct {
+		tag   string
+		score float64
+	}
+	var found []scored
+	for rows.Next() {
+		var tag string
+		if err := rows.Scan(&tag); err != nil {
+			return nil, err
+		}
+		if score := trigramSimilarity(tag, name); score >= fuzzySettings.SuggestThreshold {
+			found = append(found, scored{tag: tag, score: score})
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	sort.Slice(found, func(i, j int) bool {
+		if found[i].score != found[j].score {
+			return found[i].score > found[j].score
+		}
+		return found[i].tag < found[j].tag
+	})
+	if len(found) > fuzzySettings.SuggestLimit {
+		found = found[:fuzzySettings.SuggestLimit]
+	}
+
+	var suggestions []string
+	for _, item := range found {
+		suggestions = append(suggestions, item.tag)
+	}
+	return suggestions, nil
+}
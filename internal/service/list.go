@@ -0,0 +1,390 @@
+package service
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Тип значения фильтра в query параметрах.
+type FilterKind int
+
+const (
+	FilterInt FilterKind = iota
+	FilterTime
+	FilterBool
+	FilterStatus
+)
+
+// Описание фильтра списка: колонка, оператор сравнения и тип значения.
+type Filter struct {
+	Column string
+	Op     string
+	Kind   FilterKind
+}
+
+// Фильтры, общие для списков.
+var (
+	filterTutorID       = Filter{Column: "tutor_id", Op: "=", Kind: FilterInt}
+	filterQuestionID    = Filter{Column: "question_id", Op: "=", Kind: FilterInt}
+	filterTagID         = Filter{Column: "tag_id", Op: "=", Kind: FilterInt}
+	filterCreatedAfter  = Filter{Column: "created_at", Op: ">", Kind: FilterTime}
+	filterCreatedBefore = Filter{Column: "created_at", Op: "<", Kind: FilterTime}
+	filterIsEdit        = Filter{Column: "is_edit", Op: "=", Kind: FilterBool}
+	filterIsAccepted    = Filter{Column: "is_accepted", Op: "=", Kind: FilterBool}
+	filterStatus        = Filter{Column: "status", Op: "=", Kind: FilterStatus}
+)
+
+// Описание списка: по каким полям можно сортировать и фильтровать.
+// Имена полей из query параметров сопоставляются с колонками только через эти белые списки.
+type ListSpec struct {
+	// Имя поля сортировки -> колонка.
+	Sort map[string]string
+
+	// Поле сортировки по умолчанию.
+	DefaultSort string
+
+	// Колонки для однозначного порядка при равных значениях поля сортировки.
+	TieBreak []string
+
+	// Имя query параметра -> фильтр.
+	Filters map[string]Filter
+
+	// Поля, по которым поддерживается курсорная пагинация, и их SQL тип.
+	// Для курсора нужны колонки без NULL и единственная колонка TieBreak (id).
+	CursorTypes map[string]string
+}
+
+// Параметры выборки списка после разбора query параметров.
+type ListParams struct {
+	Limit  int
+	Offset int
+
+	// Имя поля сортировки из ListSpec.Sort и направление.
+	Sort string
+	Desc bool
+
+	// Значения фильтров по имени query параметра: int, time.Time или bool.
+	Filters map[string]interface{}
+
+	// Курсор страницы. Если задан, Offset не используется.
+	Cursor *Cursor
+}
+
+// Сведения о странице списка для заголовков ответа.
+type PageInfo struct {
+	// Количество записей с учетом фильтров.
+	Total int
+
+	// Курсоры соседних страниц (пустые, если страницы нет или курсоры не поддерживаются).
+	NextCursor string
+	PrevCursor string
+}
+
+// Курсор для постраничного чтения по ключу (keyset): поле сортировки, его значение и id последней строки.
+// Клиенту передается непрозрачной строкой.
+type Cursor struct {
+	Sort string `json:"s"`
+	Desc bool   `json:"d,omitempty"`
+	Key  string `json:"k"`
+	ID   int    `json:"i"`
+
+	// Курсор на предыдущую страницу: строки до ключа, а не после.
+	Back bool `json:"b,omitempty"`
+}
+
+// Ошибка разбора курсора.
+var ErrInvalidCursor = errors.New("неверный курсор")
+
+// EncodeCursor кодирует курсор в строку для query параметра cursor.
+func EncodeCursor(cursor Cursor) string {
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeCursor разбирает строку курсора и проверяет, что он подходит для списка spec.
+func DecodeCursor(token string, spec ListSpec) (Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return Cursor{}, ErrInvalidCursor
+	}
+
+	var cursor Cursor
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return Cursor{}, ErrInvalidCursor
+	}
+
+	sqlType, ok := spec.CursorTypes[cursor.Sort]
+	if !ok {
+		return Cursor{}, ErrInvalidCursor
+	}
+
+	// Ключ приводится в запросе к типу колонки, поэтому подделанный курсор проверяется здесь,
+	// чтобы клиент получил 400, а не ошибку БД.
+	if cursor.ID <= 0 || !validCursorKey(cursor.Key, sqlType) {
+		return Cursor{}, ErrInvalidCursor
+	}
+
+	return cursor, nil
+}
+
+// Формат timestamp в текстовом виде Postgres, в котором keyColumn читает ключ курсора.
+const cursorTimestampLayout = "2006-01-02 15:04:05.999999999"
+
+// Проверка, что ключ курсора приводится к SQL типу из ListSpec.CursorTypes.
+func validCursorKey(key string, sqlType string) bool {
+	switch sqlType {
+	case "int":
+		_, err := strconv.ParseInt(key, 10, 32)
+		return err == nil
+	case "timestamp":
+		_, err := time.Parse(cursorTimestampLayout, key)
+		return err == nil
+	case "text":
+		return !strings.ContainsRune(key, 0)
+	}
+	return false
+}
+
+// Значение поля сортировки и id строки, из которых строится курсор.
+type cursorKey struct {
+	Key string
+	ID  int
+}
+
+// Списки, которые поддерживают пагинацию.
+var (
+	QuestionListSpec = ListSpec{
+		Sort:        map[string]string{"id": "id", "created_at": "created_at", "question_text": "question_text", "tutor_id": "tutor_id"},
+		DefaultSort: "id",
+		TieBreak:    []string{"id"},
+		Filters: map[string]Filter{
+			"tutor_id":       filterTutorID,
+			"created_after":  filterCreatedAfter,
+			"created_before": filterCreatedBefore,
+			"is_edit":        filterIsEdit,
+			"status":         filterStatus,
+		},
+		CursorTypes: map[string]string{"id": "int", "created_at": "timestamp", "question_text": "text"},
+	}
+
+	AnswerListSpec = ListSpec{
+		Sort:        map[string]string{"id": "id", "created_at": "created_at", "question_id": "question_id", "tutor_id": "tutor_id", "score": "score"},
+		DefaultSort: "id",
+		TieBreak:    []string{"id"},
+		Filters: map[string]Filter{
+			"tutor_id":       filterTutorID,
+			"question_id":    filterQuestionID,
+			"created_after":  filterCreatedAfter,
+			"created_before": filterCreatedBefore,
+			"is_edit":        filterIsEdit,
+			"is_accepted":    filterIsAccepted,
+			"status":         filterStatus,
+		},
+		CursorTypes: map[string]string{"id": "int", "created_at": "timestamp", "score": "int"},
+	}
+
+	QuestionVersionListSpec = ListSpec{
+		Sort:        map[string]string{"version_number": "version_number", "created_at": "created_at", "id": "id"},
+		DefaultSort: "version_number",
+		TieBreak:    []string{"id"},
+		Filters: map[string]Filter{
+			"created_after":  filterCreatedAfter,
+			"created_before": filterCreatedBefore,
+		},
+		CursorTypes: map[string]string{"version_number": "int", "created_at": "timestamp", "id": "int"},
+	}
+
+	AnswerVersionListSpec = ListSpec{
+		Sort:        map[string]string{"version_number": "version_number", "created_at": "created_at", "id": "id"},
+		DefaultSort: "version_number",
+		TieBreak:    []string{"id"},
+		Filters: map[string]Filter{
+			"created_after":  filterCreatedAfter,
+			"created_before": filterCreatedBefore,
+		},
+		CursorTypes: map[string]string{"version_number": "int", "created_at": "timestamp", "id": "int"},
+	}
+
+	TutorListSpec = ListSpec{
+		Sort:        map[string]string{"id": "id", "full_name": "full_name", "email": "email"},
+		DefaultSort: "id",
+		TieBreak:    []string{"id"},
+		Filters:     map[string]Filter{},
+	}
+
+	TagListSpec = ListSpec{
+		Sort:        map[string]string{"id": "id", "tag": "tag", "tutor_id": "tutor_id"},
+		DefaultSort: "id",
+		TieBreak:    []string{"id"},
+		Filters: map[string]Filter{
+			"tutor_id": filterTutorID,
+		},
+	}
+
+	QuestionTagListSpec = ListSpec{
+		Sort:        map[string]string{"question_id": "question_id", "tag_id": "tag_id"},
+		DefaultSort: "question_id",
+		TieBreak:    []string{"question_id", "tag_id"},
+		Filters: map[string]Filter{
+			"question_id": filterQuestionID,
+			"tag_id":      filterTagID,
+		},
+	}
+)
+
+// Условие where по фильтрам и курсору из params. Плейсхолдеры нумеруются с $1.
+// fixed - обязательные условия сервиса, например версии одного вопроса.
+func (spec ListSpec) whereClause(params ListParams, fixed ...fixedFilter) (string, []interface{}) {
+
+	// Порядок фильтров фиксирован, чтобы текст запроса не зависел от обхода map.
+	names := make([]string, 0, len(params.Filters))
+	for name := range params.Filters {
+		if _, ok := spec.Filters[name]; ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var conditions []string
+	var args []interface{}
+	for _, filter := range fixed {
+		if filter.Value == nil {
+			conditions = append(conditions, filter.Condition)
+			continue
+		}
+		args = append(args, filter.Value)
+		conditions = append(conditions, fmt.Sprintf(filter.Condition, len(args)))
+	}
+	for _, name := range names {
+		filter := spec.Filters[name]
+		args = append(args, params.Filters[name])
+		conditions = append(conditions, fmt.Sprintf("%s %s $%d", filter.Column, filter.Op, len(args)))
+	}
+
+	// Строки после (или до) ключа курсора. Сравнение кортежей использует индекс (колонка, id).
+	if cursor := params.Cursor; cursor != nil {
+		op := ">"
+		if cursor.Desc != cursor.Back {
+			op = "<"
+		}
+		args = append(args, cursor.Key, cursor.ID)
+		conditions = append(conditions, fmt.Sprintf("(%s, %s) %s ($%d::%s, $%d)",
+			spec.Sort[cursor.Sort], spec.TieBreak[0], op, len(args)-1, spec.CursorTypes[cursor.Sort], len(args)))
+	}
+
+	if len(conditions) == 0 {
+		return "", nil
+	}
+	return " where " + strings.Join(conditions, " and "), args
+}
+
+// Сортировка по полю из params и колонкам TieBreak в том же направлении.
+func (spec ListSpec) orderClause(params ListParams) string {
+	column, ok := spec.Sort[params.Sort]
+	if !ok {
+		column = spec.Sort[spec.DefaultSort]
+	}
+
+	// Для курсора на предыдущую страницу строки читаются в обратном порядке и потом разворачиваются.
+	direction := "asc"
+	if params.Desc != (params.Cursor != nil && params.Cursor.Back) {
+		direction = "desc"
+	}
+
+	columns := []string{column + " " + direction}
+	for _, tieBreak := range spec.TieBreak {
+		if tieBreak != column {
+			columns = append(columns, tieBreak+" "+direction)
+		}
+	}
+	return " order by " + strings.Join(columns, ", ")
+}
+
+// Ограничение страницы. argCount - сколько аргументов уже занято в запросе.
+// Для списков с курсором читается на одну строку больше, чтобы узнать, есть ли следующая страница.
+func (spec ListSpec) pageClause(params ListParams, argCount int) (string, []interface{}) {
+	limit := params.Limit
+	if spec.CursorTypes != nil {
+		limit++
+	}
+
+	offset := params.Offset
+	if params.Cursor != nil {
+		offset = 0
+	}
+
+	return fmt.Sprintf(" limit $%d offset $%d", argCount+1, argCount+2), []interface{}{limit, offset}
+}
+
+// Колонка со значением поля сортировки в виде текста для построения курсора.
+// Для полей без курсора (где возможен NULL) значение не используется, но должно читаться в строку.
+func (spec ListSpec) keyColumn(params ListParams) string {
+	column, ok := spec.Sort[params.Sort]
+	if !ok {
+		column = spec.Sort[spec.DefaultSort]
+	}
+	return "coalesce((" + column + ")::text, '')"
+}
+
+// Параметры без курсора - для подсчета общего количества записей.
+func (params ListParams) withoutCursor() ListParams {
+	params.Cursor = nil
+	return params
+}
+
+// Обязательное условие сервиса для списка.
+// Condition - SQL условие с одним плейсхолдером %d для номера аргумента, например "question_id = $%d".
+// Если Value = nil, условие без аргументов подставляется как есть.
+type fixedFilter struct {
+	Condition string
+	Value     interface{}
+}
+
+// Обрезает лишнюю строку страницы и строит курсоры соседних страниц.
+// keys - значения ключа для каждой строки items в порядке чтения из БД.
+// Если по полю сортировки курсор не поддерживается, курсоры остаются пустыми.
+func keysetPage[T any](spec ListSpec, params ListParams, items []T, keys []cursorKey, total int) ([]T, PageInfo) {
+	info := PageInfo{Total: total}
+	back := params.Cursor != nil && params.Cursor.Back
+
+	// Прочитана лишняя строка - значит дальше в направлении чтения есть еще записи.
+	hasMore := len(items) > params.Limit
+	if hasMore {
+		items = items[:params.Limit]
+		keys = keys[:params.Limit]
+	}
+
+	// Для предыдущей страницы строки читались в обратном порядке.
+	if back {
+		for i, j := 0, len(items)-1; i < j; i, j = i+1, j-1 {
+			items[i], items[j] = items[j], items[i]
+			keys[i], keys[j] = keys[j], keys[i]
+		}
+	}
+
+	if _, ok := spec.CursorTypes[params.Sort]; !ok || len(items) == 0 {
+		return items, info
+	}
+
+	newCursor := func(key cursorKey, back bool) string {
+		return EncodeCursor(Cursor{Sort: params.Sort, Desc: params.Desc, Key: key.Key, ID: key.ID, Back: back})
+	}
+
+	// Следующая страница: вперед есть записи, если прочитана лишняя строка или мы пришли назад от курсора.
+	if (!back && hasMore) || back {
+		info.NextCursor = newCursor(keys[len(keys)-1], false)
+	}
+
+	// Предыдущая страница: есть, если мы пришли вперед по курсору или со смещением, либо назад и прочитана лишняя строка.
+	if (!back && (params.Cursor != nil || params.Offset > 0)) || (back && hasMore) {
+		info.PrevCursor = newCursor(keys[0], true)
+	}
+
+	return items, info
+}
@@ -0,0 +1,149 @@
+package service
+
+import (
+	"encoding/base64"
+	"errors"
+	"slices"
+	"strconv"
+	"testing"
+)
+
+func TestDecodeCursor(t *testing.T) {
+	raw := func(json string) string {
+		return base64.RawURLEncoding.EncodeToString([]byte(json))
+	}
+
+	tests := []struct {
+		name  string
+		token string
+		want  *Cursor
+	}{
+		{name: "id", token: EncodeCursor(Cursor{Sort: "id", Key: "42", ID: 42}), want: &Cursor{Sort: "id", Key: "42", ID: 42}},
+		{name: "created_at", token: EncodeCursor(Cursor{Sort: "created_at", Desc: true, Key: "2025-01-02 10:20:30.123456", ID: 7}), want: &Cursor{Sort: "created_at", Desc: true, Key: "2025-01-02 10:20:30.123456", ID: 7}},
+		{name: "created_at без долей секунды", token: EncodeCursor(Cursor{Sort: "created_at", Key: "2025-01-02 10:20:30", ID: 7, Back: true}), want: &Cursor{Sort: "created_at", Key: "2025-01-02 10:20:30", ID: 7, Back: true}},
+		{name: "question_text", token: EncodeCursor(Cursor{Sort: "question_text", Key: "Как настроить Docker?", ID: 3}), want: &Cursor{Sort: "question_text", Key: "Как настроить Docker?", ID: 3}},
+		{name: "не base64", token: "!!!", want: nil},
+		{name: "не JSON", token: raw("id=1"), want: nil},
+		{name: "поле без курсора", token: EncodeCursor(Cursor{Sort: "tutor_id", Key: "1", ID: 1}), want: nil},
+		{name: "текст вместо числа", token: EncodeCursor(Cursor{Sort: "id", Key: "1 or 1=1", ID: 1}), want: nil},
+		{name: "число вне int", token: EncodeCursor(Cursor{Sort: "id", Key: "99999999999", ID: 1}), want: nil},
+		{name: "неверная дата", token: EncodeCursor(Cursor{Sort: "created_at", Key: "yesterday", ID: 1}), want: nil},
+		{name: "дата в другом формате", token: EncodeCursor(Cursor{Sort: "created_at", Key: "2025-01-02T10:20:30Z", ID: 1}), want: nil},
+		{name: "нулевой байт в тексте", token: EncodeCursor(Cursor{Sort: "question_text", Key: "a\x00b", ID: 1}), want: nil},
+		{name: "нет id", token: raw(`{"s":"id","k":"5"}`), want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cursor, err := DecodeCursor(tt.token, QuestionListSpec)
+			if tt.want == nil {
+				if !errors.Is(err, ErrInvalidCursor) {
+					t.Errorf("DecodeCursor() = %+v, %v, want ErrInvalidCursor", cursor, err)
+				}
+				return
+			}
+			if err != nil || cursor != *tt.want {
+				t.Errorf("DecodeCursor() = %+v, %v, want %+v", cursor, err, *tt.want)
+			}
+		})
+	}
+}
+
+func TestKeysetPage(t *testing.T) {
+	// Строки, прочитанные из БД: id и ключ сортировки совпадают.
+	rows := func(ids ...int) ([]int, []cursorKey) {
+		keys := make([]cursorKey, 0, len(ids))
+		for _, id := range ids {
+			keys = append(keys, cursorKey{Key: strconv.Itoa(id), ID: id})
+		}
+		return ids, keys
+	}
+	cursor := func(id int, back bool) string {
+		return EncodeCursor(Cursor{Sort: "id", Key: strconv.Itoa(id), ID: id, Back: back})
+	}
+
+	tests := []struct {
+		name   string
+		params ListParams
+		read   []int
+		want   []int
+		next   string
+		prev   string
+	}{
+		{
+			name:   "первая страница, дальше есть записи",
+			params: ListParams{Limit: 3, Sort: "id"},
+			read:   []int{1, 2, 3, 4},
+			want:   []int{1, 2, 3},
+			next:   cursor(3, false),
+		},
+		{
+			name:   "единственная страница",
+			params: ListParams{Limit: 3, Sort: "id"},
+			read:   []int{1, 2},
+			want:   []int{1, 2},
+		},
+		{
+			name:   "вперед по курсору, последняя страница",
+			params: ListParams{Limit: 3, Sort: "id", Cursor: &Cursor{Sort: "id", Key: "3", ID: 3}},
+			read:   []int{4, 5},
+			want:   []int{4, 5},
+			prev:   cursor(4, true),
+		},
+		{
+			name:   "со смещением",
+			params: ListParams{Limit: 2, Offset: 2, Sort: "id"},
+			read:   []int{3, 4, 5},
+			want:   []int{3, 4},
+			next:   cursor(4, false),
+			prev:   cursor(3, true),
+		},
+		{
+			name:   "назад по курсору, строки прочитаны в обратном порядке",
+			params: ListParams{Limit: 2, Sort: "id", Cursor: &Cursor{Sort: "id", Key: "5", ID: 5, Back: true}},
+			read:   []int{4, 3, 2},
+			want:   []int{3, 4},
+			next:   cursor(4, false),
+			prev:   cursor(3, true),
+		},
+		{
+			name:   "назад до начала списка",
+			params: ListParams{Limit: 2, Sort: "id", Cursor: &Cursor{Sort: "id", Key: "3", ID: 3, Back: true}},
+			read:   []int{2, 1},
+			want:   []int{1, 2},
+			next:   cursor(2, false),
+		},
+		{
+			name:   "поле без курсора",
+			params: ListParams{Limit: 2, Sort: "tutor_id"},
+			read:   []int{1, 2, 3},
+			want:   []int{1, 2},
+		},
+		{
+			name:   "пустая страница",
+			params: ListParams{Limit: 2, Sort: "id"},
+			read:   []int{},
+			want:   []int{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			items, keys := rows(tt.read...)
+			got, info := keysetPage(QuestionListSpec, tt.params, items, keys, 10)
+
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("items = %v, want %v", got, tt.want)
+			}
+			if info.Total != 10 {
+				t.Errorf("Total = %d, want 10", info.Total)
+			}
+			if info.NextCursor != tt.next {
+				t.Errorf("NextCursor = %q, want %q", info.NextCursor, tt.next)
+			}
+			if info.PrevCursor != tt.prev {
+				t.Errorf("PrevCursor = %q, want %q", info.PrevCursor, tt.prev)
+			}
+		})
+	}
+}
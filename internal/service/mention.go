@@ -0,0 +1,149 @@
+package service
+
+import (
+	"context"
+	"knowledge-base/internal/models"
+	"strings"
+	"unicode"
+
+	"github.com/lib/pq"
+)
+
+// Сколько слов после @ проверяется как имя тьютора: "@Иван Иванов" или "@Иван Петрович Сидоров".
+const maxMentionWords = 3
+
+// Упоминание из текста: email или варианты имени, самый длинный первым.
+type mention struct {
+	email string
+	names []string
+}
+
+// Знаки препинания, которые завершают упоминание: "@Иван Иванов, посмотрите".
+const mentionTrailing = `.,;:!?)]}"'»`
+
+// parseMentions находит упоминания @email и @имя. Имя может быть записано через пробел или подчеркивание:
+// "@Иван Иванов" и "@Иван_Иванов". @ внутри слова (адрес ivan@mail.ru в тексте) упоминанием не считается.
+func parseMentions(text string) []mention {
+	runes := []rune(text)
+
+	var mentions []mention
+	for i, r := range runes {
+		if r != '@' {
+			continue
+		}
+		if i > 0 && !unicode.IsSpace(runes[i-1]) && !strings.ContainsRune(`([{"'«`, runes[i-1]) {
+			continue
+		}
+
+		// Первое слово - до пробела.
+		end := i + 1
+		for end < len(runes) && !unicode.IsSpace(runes[end]) {
+			end++
+		}
+		token := string(runes[i+1 : end])
+		trimmed := strings.TrimRight(token, mentionTrailing)
+		if trimmed == "" {
+			continue
+		}
+
+		if strings.Contains(trimmed, "@") {
+			mentions = append(mentions, mention{email: strings.ToLower(trimmed)})
+			continue
+		}
+
+		// Следующие слова добавляются, пока имя не закончилось знаком препинания или новым упоминанием.
+		// Имя через подчеркивание уже записано целиком.
+		words := strings.Fields(strings.ReplaceAll(trimmed, "_", " "))
+		closed := trimmed != token || strings.Contains(trimmed, "_")
+		rest := runes[end:]
+		if len(rest) > 200 {
+			rest = rest[:200]
+		}
+		for _, word := range strings.Fields(string(rest)) {
+			if closed || len(words) >= maxMentionWords || strings.HasPrefix(word, "@") {
+				break
+			}
+			clean := strings.TrimRight(word, mentionTrailing)
+			if clean == "" {
+				break
+			}
+			words = append(words, clean)
+			closed = clean != word
+		}
+		if len(words) > maxMentionWords {
+			words = words[:maxMentionWords]
+		}
+
+		var names []string
+		for n := len(words); n > 0; n-- {
+			names = append(names, strings.ToLower(strings.Join(words[:n], " ")))
+		}
+		mentions = append(mentions, mention{names: names})
+	}
+
+	return mentions
+}
+
+// resolveMentions возвращает тьюторов, упомянутых в тексте, по одному разу в порядке упоминания.
+// Для имени выбирается самый длинный вариант, совпавший с полным именем тьютора без учета регистра.
+func resolveMentions(ctx context.Context, db queryer, text string) ([]models.Tutor, error) {
+	mentions := parseMentions(text)
+	if len(mentions) == 0 {
+		return nil, nil
+	}
+
+	var emails, names []string
+	for _, m := range mentions {
+		if m.email != "" {
+			emails = append(emails, m.email)
+		}
+		names = append(names, m.names...)
+	}
+
+	// При совпадении имени у нескольких тьюторов выбирается тьютор с меньшим ID.
+	rows, err := db.QueryContext(ctx, `select id, full_name, email from tutors
+		where lower(email) = any($1) or lower(full_name) = any($2)
+		order by id`, pq.Array(emails), pq.Array(names))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	byEmail := map[string]models.Tutor{}
+	byName := map[string]models.Tutor{}
+	for rows.Next() {
+		var tutor models.Tutor
+		if err := rows.Scan(&tutor.ID, &tutor.FullName, &tutor.Email); err != nil {
+			return nil, err
+		}
+		byEmail[strings.ToLower(tutor.Email)] = tutor
+		if _, ok := byName[strings.ToLower(tutor.FullName)]; !ok {
+			byName[strings.ToLower(tutor.FullName)] = tutor
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	seen := map[int]bool{}
+	var tutors []models.Tutor
+	for _, m := range mentions {
+		var tutor models.Tutor
+		var ok bool
+		if m.email != "" {
+			tutor, ok = byEmail[m.email]
+		}
+		for _, name := range m.names {
+			if ok {
+				break
+			}
+			tutor, ok = byName[name]
+		}
+		if ok && !seen[tutor.ID] {
+			seen[tutor.ID] = true
+			tutors = append(tutors, tutor)
+		}
+	}
+
+	return tutors, nil
+}
@@ -0,0 +1,33 @@
+package service
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseMentions(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []mention
+	}{
+		{name: "email", text: "@ivan@mail.ru спасибо", want: []mention{{email: "ivan@mail.ru"}}},
+		{name: "email с точкой в конце", text: "Вопрос к @IVAN@MAIL.RU.", want: []mention{{email: "ivan@mail.ru"}}},
+		{name: "имя через пробел", text: "Спасибо, @Иван Иванов, посмотрите", want: []mention{{names: []string{"иван иванов", "иван"}}}},
+		{name: "имя через подчеркивание", text: "@Иван_Иванов посмотрите", want: []mention{{names: []string{"иван иванов", "иван"}}}},
+		{name: "не больше трех слов", text: "@Иван Петрович Сидоров Кузьмин", want: []mention{{names: []string{"иван петрович сидоров", "иван петрович", "иван"}}}},
+		{name: "в скобках", text: "(@Анна) и @Петр", want: []mention{{names: []string{"анна"}}, {names: []string{"петр"}}}},
+		{name: "два упоминания подряд", text: "@Анна @Петр", want: []mention{{names: []string{"анна"}}, {names: []string{"петр"}}}},
+		{name: "адрес в тексте", text: "пишите на ivan@mail.ru", want: nil},
+		{name: "@ без имени", text: "@ один и @, два", want: nil},
+		{name: "без упоминаний", text: "Как настроить Docker?", want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseMentions(tt.text); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseMentions(%q) = %+v, want %+v", tt.text, got, tt.want)
+			}
+		})
+	}
+}
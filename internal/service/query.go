@@ -0,0 +1,118 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"knowledge-base/internal/logging"
+	"knowledge-base/internal/metrics"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Ошибки, которые возвращаются, если запрос к БД прерван.
+var (
+	// Клиент отключился или отменил запрос.
+	ErrCanceled = errors.New("запрос отменен")
+
+	// Запрос к БД не уложился в отведенное время.
+	ErrTimeout = errors.New("превышено время ожидания запроса к БД")
+)
+
+// Время на выполнение одного вызова сервиса по умолчанию.
+var queryTimeout = 5 * time.Second
+
+// Время на потоковую выгрузку таблицы по умолчанию.
+var exportTimeout = 10 * time.Minute
+
+// SetQueryTimeout задает ограничение времени на один вызов сервиса.
+func SetQueryTimeout(timeout time.Duration) {
+	if timeout > 0 {
+		queryTimeout = timeout
+	}
+}
+
+// SetExportTimeout задает ограничение времени на потоковую выгрузку таблицы.
+func SetExportTimeout(timeout time.Duration) {
+	if timeout > 0 {
+		exportTimeout = timeout
+	}
+}
+
+// Ключ для хранения имени и ограничения времени вызова сервиса в контексте.
+type queryInfoKey struct{}
+
+// Имя вызова сервиса и его ограничение времени для логов.
+type queryInfo struct {
+	name    string
+	timeout time.Duration
+}
+
+// Трейсер для спанов вызовов сервисов.
+var tracer = otel.Tracer("knowledge-base/internal/service")
+
+// Начало вызова сервиса: контекст с ограничением времени, спаном трассировки и именем вызова для логов.
+// attrs добавляются в спан (например ID сущности).
+// Возвращенную функцию надо вызвать по завершении работы с БД, она же записывает время вызова в метрики.
+func beginQuery(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, func()) {
+	return beginQueryTimeout(ctx, name, queryTimeout, attrs...)
+}
+
+// Начало потоковой выгрузки: то же, что beginQuery, но с ограничением времени на выгрузку всей таблицы.
+func beginExport(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, func()) {
+	return beginQueryTimeout(ctx, name, exportTimeout, attrs...)
+}
+
+func beginQueryTimeout(ctx context.Context, name string, timeout time.Duration, attrs ...attribute.KeyValue) (context.Context, func()) {
+	start := time.Now()
+	ctx, span := tracer.Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attribute.String("db.system", "postgresql")),
+		trace.WithAttributes(attrs...),
+	)
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	ctx = context.WithValue(ctx, queryInfoKey{}, queryInfo{name: name, timeout: timeout})
+	return ctx, func() {
+		cancel()
+		span.End()
+		metrics.ObserveQuery(name, time.Since(start))
+	}
+}
+
+// Записывает в спан количество строк, которое вернул или изменил запрос.
+func recordRows(ctx context.Context, count int) {
+	trace.SpanFromContext(ctx).SetAttributes(attribute.Int("db.rows", count))
+}
+
+// Преобразует ошибку БД: если контекст завершен, возвращает ErrCanceled или ErrTimeout.
+// Ошибки, кроме sql.ErrNoRows, пишутся в лог вместе с ID запроса.
+func queryError(ctx context.Context, err error) error {
+	if err == nil || errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+
+	info, _ := ctx.Value(queryInfoKey{}).(queryInfo)
+	name := info.name
+	logger := logging.FromContext(ctx).With("query", name)
+
+	span := trace.SpanFromContext(ctx)
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+
+	switch ctx.Err() {
+	case context.DeadlineExceeded:
+		logger.Warn("query timed out", "timeout", info.timeout.String(), "error", err.Error())
+		return fmt.Errorf("%s: %w", name, ErrTimeout)
+	case context.Canceled:
+		logger.Info("query canceled", "error", err.Error())
+		return fmt.Errorf("%s: %w", name, ErrCanceled)
+	}
+
+	logger.Error("query failed", "error", err.Error())
+	return err
+}
@@ -1,9 +1,12 @@
 package service
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"knowledge-base/internal/models"
+
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // Структура для работы со всеми ф-ями service/question.go.
@@ -16,71 +19,180 @@ func NewQuestionService(db *sql.DB) *QuestionService {
 	return &QuestionService{db: db}
 }
 
-func (questionService *QuestionService) GetAll() ([]models.Question, error) {
+func (questionService *QuestionService) GetAll(ctx context.Context, params ListParams) ([]models.Question, PageInfo, error) {
+
+	// Ограничение времени выполнения запроса.
+	ctx, finish := beginQuery(ctx, "QuestionService.GetAll")
+	defer finish()
+
+	return questionService.list(ctx, params, fixedFilter{Condition: visibleQuestion(ctx, "questions")})
+}
+
+// GetAllByTagID возвращает страницу вопросов, к которым прикреплен тег.
+func (questionService *QuestionService) GetAllByTagID(ctx context.Context, tagID int, params ListParams) ([]models.Question, PageInfo, error) {
+
+	// Ограничение времени выполнения запроса.
+	ctx, finish := beginQuery(ctx, "QuestionService.GetAllByTagID", attribute.Int("tag.id", tagID))
+	defer finish()
+
+	return questionService.list(ctx, params,
+		fixedFilter{Condition: "id in (select question_id from questions_tags where tag_id = $%d)", Value: tagID},
+		fixedFilter{Condition: visibleQuestion(ctx, "questions")})
+}
+
+// Страница вопросов по параметрам списка и обязательным условиям fixed.
+func (questionService *QuestionService) list(ctx context.Context, params ListParams, fixed ...fixedFilter) ([]models.Question, PageInfo, error) {
+
+	// Условия фильтрации и сортировки из параметров списка.
+	where, args := QuestionListSpec.whereClause(params, fixed...)
+	orderBy := QuestionListSpec.orderClause(params)
+	page, pageArgs := QuestionListSpec.pageClause(params, len(args))
+
+	// Общее количество записей с учетом фильтров, без учета страницы и курсора.
+	countWhere, countArgs := QuestionListSpec.whereClause(params.withoutCursor(), fixed...)
+	var total int
+	err := questionService.db.QueryRowContext(ctx, `select count(*) from questions`+countWhere, countArgs...).Scan(&total)
+	if err != nil {
+		return nil, PageInfo{}, queryError(ctx, err)
+	}
 
 	//Создание sql запроса для получения данных по всем вопросам.
-	var query string = `select id, question_text, tutor_id, created_at, is_edit from questions order by id`
+	var query string = `select id, question_text, tutor_id, created_at, is_edit, status, ` + QuestionListSpec.keyColumn(params) + ` from questions` + where + orderBy + page
 
 	// Выполнение функции, которая проводит sql запрос и возвращает таблицу с несколькими строками.
-	rows, err := questionService.db.Query(query)
+	rows, err := questionService.db.QueryContext(ctx, query, append(args, pageArgs...)...)
 	if err != nil {
-		return nil, err
+		return nil, PageInfo{}, queryError(ctx, err)
 	}
 
 	// Закрытие, чтобы не было утечки соединений, надо закрыть.
 	defer rows.Close()
 
+	// Значения ключа сортировки каждой строки для курсоров соседних страниц.
+	var keys []cursorKey
+
 	var questions []models.Question
 
 	// Запись полученных данных из БД в массив формата []models.Question.
 	for rows.Next() {
 		var question models.Question
-		err := rows.Scan(&question.ID, &question.QuestionText, &question.TutorID, &question.CreatedAt, &question.IsEdit)
+		var key cursorKey
+		err := rows.Scan(&question.ID, &question.QuestionText, &question.TutorID, &question.CreatedAt, &question.IsEdit, &question.Status, &key.Key)
 		if err != nil {
-			return nil, err
+			return nil, PageInfo{}, queryError(ctx, err)
 		}
+		key.ID = question.ID
 		questions = append(questions, question)
+		keys = append(keys, key)
+	}
+
+	// Проверка ошибки, которая могла прервать чтение строк.
+	if err := rows.Err(); err != nil {
+		return nil, PageInfo{}, queryError(ctx, err)
+	}
+
+	recordRows(ctx, len(questions))
+
+	// Лишняя строка отбрасывается, по крайним строкам строятся курсоры.
+	questions, info := keysetPage(QuestionListSpec, params, questions, keys, total)
+
+	return questions, info, nil
+}
+
+// Export читает все записи с учетом фильтров и сортировки и передает их по одной в fn, не собирая в память.
+// Если fn возвращает ошибку, чтение прекращается и ошибка возвращается как есть.
+func (questionService *QuestionService) Export(ctx context.Context, params ListParams, fn func(models.Question) error) error {
+
+	// Ограничение времени выгрузки.
+	ctx, finish := beginExport(ctx, "QuestionService.Export")
+	defer finish()
+
+	// Условия фильтрации и сортировки из параметров списка, без страницы.
+	where, args := QuestionListSpec.whereClause(params.withoutCursor(), fixedFilter{Condition: visibleQuestion(ctx, "questions")})
+	orderBy := QuestionListSpec.orderClause(params.withoutCursor())
+
+	//Создание sql запроса для выгрузки всех вопросов.
+	var query string = `select id, question_text, tutor_id, created_at, is_edit, status from questions` + where + orderBy
+
+	// Выполнение функции, которая проводит sql запрос и возвращает таблицу с несколькими строками.
+	rows, err := questionService.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return queryError(ctx, err)
 	}
 
-	return questions, nil
+	// Закрытие, чтобы не было утечки соединений, надо закрыть.
+	defer rows.Close()
 
+	// Каждая строка сразу передается дальше, в памяти хранится только одна.
+	var count int
+	for rows.Next() {
+		var question models.Question
+		err := rows.Scan(&question.ID, &question.QuestionText, &question.TutorID, &question.CreatedAt, &question.IsEdit, &question.Status)
+		if err != nil {
+			return queryError(ctx, err)
+		}
+		if err := fn(question); err != nil {
+			return err
+		}
+		count++
+	}
+
+	// Проверка ошибки, которая могла прервать чтение строк.
+	if err := rows.Err(); err != nil {
+		return queryError(ctx, err)
+	}
+
+	recordRows(ctx, count)
+
+	return nil
 }
 
-func (questionService *QuestionService) GetByID(id int) (models.Question, error) {
+func (questionService *QuestionService) GetByID(ctx context.Context, id int) (models.Question, error) {
+
+	// Ограничение времени выполнения запроса.
+	ctx, finish := beginQuery(ctx, "QuestionService.GetByID", attribute.Int("question.id", id))
+	defer finish()
 
 	//Создание sql запроса для получения данных по одному конкретному вопросу.
-	var query string = `select id, question_text, tutor_id, created_at, is_edit from questions where id = $1`
+	// Неопубликованный вопрос для учащегося не найден.
+	var query string = `select id, question_text, tutor_id, created_at, is_edit, status from questions where id = $1 and ` + visibleQuestion(ctx, "questions")
 
 	// Выполнение функции, которая проводит sql запрос и возвращает таблицу из одной строки.
-	row := questionService.db.QueryRow(query, id)
+	row := questionService.db.QueryRowContext(ctx, query, id)
 	var question models.Question
 
 	// Запись полученных данных из БД в перемнную типа models.Question.
-	err := row.Scan(&question.ID, &question.QuestionText, &question.TutorID, &question.CreatedAt, &question.IsEdit)
+	err := row.Scan(&question.ID, &question.QuestionText, &question.TutorID, &question.CreatedAt, &question.IsEdit, &question.Status)
 	if err != nil {
-		return models.Question{}, err
+		return models.Question{}, queryError(ctx, err)
 	}
 
 	return question, nil
 }
 
-func (questionService *QuestionService) DeleteByID(id int, deleteByTutor int) error {
+func (questionService *QuestionService) DeleteByID(ctx context.Context, id int, deleteByTutor int) error {
+
+	// Ограничение времени выполнения запроса.
+	ctx, finish := beginQuery(ctx, "QuestionService.DeleteByID", attribute.Int("question.id", id))
+	defer finish()
 
 	//Создание sql запроса для удаления данных одного кокретного вопроса.
 	queryDelete := `delete from questions where id = $1`
 
 	// Выполнение функции, которая проводит sql запрос без возврата данных.
-	result, err := questionService.db.Exec(queryDelete, id)
+	result, err := questionService.db.ExecContext(ctx, queryDelete, id)
 	if err != nil {
-		return err
+		return queryError(ctx, err)
 	}
 
 	// Выполнение функции, которая возаращает количество удаленных строк.
 	rowsAffected, err := result.RowsAffected()
 	if err != nil {
-		return err
+		return queryError(ctx, err)
 	}
 
+	recordRows(ctx, int(rowsAffected))
+
 	//Проверка было ли удаление строки. Если rowsAffected = 0, то не было.
 	if rowsAffected == 0 {
 		return fmt.Errorf("question with id %d not found", id)
@@ -89,26 +201,33 @@ func (questionService *QuestionService) DeleteByID(id int, deleteByTutor int) er
 	//Создание sql запроса для учета удаления в версиях.
 	queryUpdateVersions := `update question_versions set is_delete = true, delete_by_tutor = $1 where question_id = $2`
 
-	_, err = questionService.db.Exec(queryUpdateVersions, deleteByTutor, id)
+	_, err = questionService.db.ExecContext(ctx, queryUpdateVersions, deleteByTutor, id)
 	if err != nil {
-		return err
+		return queryError(ctx, err)
 	}
 
+	notifyQuestionsChanged(ctx, id)
+
 	return nil
 }
 
-func (questionService *QuestionService) PostString(questionText string, tutorId *int) (int, error) {
+func (questionService *QuestionService) PostString(ctx context.Context, questionText string, tutorId *int) (int, error) {
+
+	// Ограничение времени выполнения запроса.
+	ctx, finish := beginQuery(ctx, "QuestionService.PostString")
+	defer finish()
 
 	var questionID int
 
 	//Создание sql запроса для появления новой записи в таблице вопросов.
+	// Новый вопрос - черновик, публично он виден после проверки.
 	queryQuestion := `insert into questions (question_text, tutor_id) 
               values ($1, $2) returning id`
 
 	// Выполнение функции, которая проводит sql запрос и возвращает таблицу из одной строки.
-	err := questionService.db.QueryRow(queryQuestion, questionText, tutorId).Scan(&questionID)
+	err := questionService.db.QueryRowContext(ctx, queryQuestion, questionText, tutorId).Scan(&questionID)
 	if err != nil {
-		return 0, err
+		return 0, queryError(ctx, err)
 	}
 
 	//Создание sql запроса для появления новой записи в таблице версий вопросов.
@@ -117,61 +236,71 @@ func (questionService *QuestionService) PostString(questionText string, tutorId
 		values ($1, $2, $3, 1)`
 
 	// Выполнение функции, которая проводит sql запрос без возврата данных.
-	_, err = questionService.db.Exec(queryQuestionVersion, questionID, questionText, tutorId)
+	_, err = questionService.db.ExecContext(ctx, queryQuestionVersion, questionID, questionText, tutorId)
 	if err != nil {
-		return 0, fmt.Errorf("failed to save first version: %v", err)
+		return 0, fmt.Errorf("failed to save first version: %w", queryError(ctx, err))
 	}
 
+	notifyQuestionsChanged(ctx, questionID)
+
 	return questionID, nil
 }
 
-func (questionService *QuestionService) PutString(questionText string, tutorId *int, id int) (models.Question, error) {
+func (questionService *QuestionService) PutString(ctx context.Context, questionText string, tutorId *int, id int) (models.Question, error) {
+
+	// Ограничение времени выполнения запроса.
+	ctx, finish := beginQuery(ctx, "QuestionService.PutString", attribute.Int("question.id", id))
+	defer finish()
 
 	//Создание sql запроса для обновления данных конкретного вопроса.
+	// Правка не публикуется без проверки: кроме черновика, вопрос возвращается на проверку модератору.
 	queryQuestion := `update questions 
-              set question_text = $1, tutor_id = $2, is_edit = true
+              set question_text = $1, tutor_id = $2, is_edit = true,
+                  status = case when status = '` + StatusDraft + `' then status else '` + StatusInReview + `' end
               where id = $3
-              returning question_text, tutor_id, created_at, is_edit`
+              returning question_text, tutor_id, created_at, is_edit, status`
 
 	var question models.Question
 
 	// Выполнение функции, которая проводит sql запрос и возвращает таблицу из одной строки. Заполнение полей переменной типа models.Question.
-	err := questionService.db.QueryRow(
-		queryQuestion, questionText, tutorId, id).Scan(&question.QuestionText, &question.TutorID, &question.CreatedAt, &question.IsEdit)
+	err := questionService.db.QueryRowContext(ctx,
+		queryQuestion, questionText, tutorId, id).Scan(&question.QuestionText, &question.TutorID, &question.CreatedAt, &question.IsEdit, &question.Status)
 
 	if err != nil {
-		return models.Question{}, err
+		return models.Question{}, queryError(ctx, err)
 	}
 
 	//Создание sql запроса для появления новой записи в таблице версий вопросов.
 	queryQuestionVersion := `insert into question_versions 
-		(question_id, question_text, tutor_id, version_number) 
-		values ($1, $2, $3, $4)`
+		(question_id, question_text, tutor_id, version_number, status) 
+		values ($1, $2, $3, $4, $5)`
 
 	//Выполнение функции, которая вернет переменную типа models.QuestionVersion для получения version_number
-	questionVersion, err := questionService.getVersionByID(id)
+	questionVersion, err := questionService.getVersionByID(ctx, id)
 	if err != nil {
-		return models.Question{}, fmt.Errorf("failed to save first version: %v", err)
+		return models.Question{}, fmt.Errorf("failed to save first version: %w", queryError(ctx, err))
 	}
 
 	questionVersion.VersionNumber += 1
 
 	// Выполнение функции, которая проводит sql запрос без возврата данных.
-	_, err = questionService.db.Exec(queryQuestionVersion, id, question.QuestionText, tutorId, questionVersion.VersionNumber)
+	_, err = questionService.db.ExecContext(ctx, queryQuestionVersion, id, question.QuestionText, tutorId, questionVersion.VersionNumber, question.Status)
 	if err != nil {
-		return models.Question{}, fmt.Errorf("failed to save first version: %v", err)
+		return models.Question{}, fmt.Errorf("failed to save first version: %w", queryError(ctx, err))
 	}
 
+	notifyQuestionsChanged(ctx, id)
+
 	return question, nil
 }
 
-func (questionService *QuestionService) getVersionByID(id int) (models.QuestionVersion, error) {
+func (questionService *QuestionService) getVersionByID(ctx context.Context, id int) (models.QuestionVersion, error) {
 
 	//Создание sql запроса для получения данных по одному конкретному вопросу.
 	var query string = `select max(version_number) from question_versions where question_id = $1`
 
 	// Выполнение функции, которая проводит sql запрос и возвращает таблицу из одной строки.
-	row := questionService.db.QueryRow(query, id)
+	row := questionService.db.QueryRowContext(ctx, query, id)
 
 	var question models.QuestionVersion
 
@@ -0,0 +1,159 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"knowledge-base/internal/models"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// Связанные данные, которые можно запросить вместе с вопросом.
+type QuestionExpand struct {
+	Answer   bool
+	Answers  bool
+	Tags     bool
+	Author   bool
+	Versions bool
+	Stats    bool
+}
+
+// GetDetail возвращает вопрос и запрошенные связанные данные.
+// На каждое расширение выполняется один запрос, поэтому их число не зависит от количества тегов или версий.
+func (questionService *QuestionService) GetDetail(ctx context.Context, id int, expand QuestionExpand) (models.QuestionDetail, error) {
+
+	// Ограничение времени выполнения запроса.
+	ctx, finish := beginQuery(ctx, "QuestionService.GetDetail", attribute.Int("question.id", id))
+	defer finish()
+
+	//Создание sql запроса для получения данных по одному конкретному вопросу.
+	// Неопубликованный вопрос для учащегося не найден.
+	var query string = `select id, question_text, tutor_id, created_at, is_edit, status from questions where id = $1 and ` + visibleQuestion(ctx, "questions")
+
+	// Выполнение функции, которая проводит sql запрос и возвращает таблицу из одной строки.
+	var detail models.QuestionDetail
+	question := &detail.Question
+	err := questionService.db.QueryRowContext(ctx, query, id).Scan(&question.ID, &question.QuestionText, &question.TutorID, &question.CreatedAt, &question.IsEdit, &question.Status)
+	if err != nil {
+		return models.QuestionDetail{}, queryError(ctx, err)
+	}
+
+	if expand.Answer {
+		detail.Answer, err = questionService.detailAnswer(ctx, id)
+		if err != nil {
+			return models.QuestionDetail{}, queryError(ctx, err)
+		}
+	}
+
+	if expand.Answers {
+		detail.Answers, err = questionAnswers(ctx, questionService.db, id, answerOrder)
+		if err != nil {
+			return models.QuestionDetail{}, queryError(ctx, err)
+		}
+	}
+
+	if expand.Tags {
+		detail.Tags, err = questionTags(ctx, questionService.db, id)
+		if err != nil {
+			return models.QuestionDetail{}, queryError(ctx, err)
+		}
+	}
+
+	// У вопроса может не быть автора, если тьютор удален.
+	if expand.Author && question.TutorID != nil {
+		detail.Author, err = questionService.detailAuthor(ctx, *question.TutorID)
+		if err != nil {
+			return models.QuestionDetail{}, queryError(ctx, err)
+		}
+	}
+
+	if expand.Versions {
+		detail.Versions, err = questionService.detailVersions(ctx, id)
+		if err != nil {
+			return models.QuestionDetail{}, queryError(ctx, err)
+		}
+	}
+
+	if expand.Stats {
+		detail.Stats, err = questionService.detailStats(ctx, id)
+		if err != nil {
+			return models.QuestionDetail{}, queryError(ctx, err)
+		}
+	}
+
+	return detail, nil
+}
+
+// Принятый (или первый по порядку) ответ на вопрос или nil, если ответа нет.
+func (questionService *QuestionService) detailAnswer(ctx context.Context, questionID int) (*models.Answer, error) {
+	var query string = `select id, answer_text, tutor_id, question_id, created_at, is_edit, is_accepted, position, helpful_count, not_helpful_count, upvote_count, score, status from answers where question_id = $1 and ` + visibleAnswer(ctx, "answers") + ` order by ` + answerOrder + ` limit 1`
+
+	var answer models.Answer
+	err := questionService.db.QueryRowContext(ctx, query, questionID).Scan(&answer.ID, &answer.AnswersText, &answer.TutorID, &answer.QuestionID, &answer.CreatedAt, &answer.IsEdit, &answer.IsAccepted, &answer.Position, &answer.HelpfulCount, &answer.NotHelpfulCount, &answer.Upvotes, &answer.Score, &answer.Status)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &answer, nil
+}
+
+// Автор вопроса или nil, если тьютор не найден.
+func (questionService *QuestionService) detailAuthor(ctx context.Context, tutorID int) (*models.Tutor, error) {
+	var query string = `select id, full_name, email from tutors where id = $1`
+
+	var tutor models.Tutor
+	err := questionService.db.QueryRowContext(ctx, query, tutorID).Scan(&tutor.ID, &tutor.FullName, &tutor.Email)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &tutor, nil
+}
+
+// Все версии вопроса по порядку. Учащемуся - только опубликованные.
+func (questionService *QuestionService) detailVersions(ctx context.Context, questionID int) ([]models.QuestionVersion, error) {
+	var query string = `select id, question_id, question_text, tutor_id, created_at, version_number, is_delete, delete_by_tutor, status, status_comment from question_versions where question_id = $1 and ` + visibleVersion(ctx, "question_versions") + ` order by version_number`
+
+	rows, err := questionService.db.QueryContext(ctx, query, questionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var versions []models.QuestionVersion
+	for rows.Next() {
+		var version models.QuestionVersion
+		if err := rows.Scan(&version.ID, &version.QuestionID, &version.QuestionText, &version.TutorID, &version.CreatedAt, &version.VersionNumber, &version.IsDelete, &version.DeleteByTutor, &version.Status, &version.StatusComment); err != nil {
+			return nil, err
+		}
+		versions = append(versions, version)
+	}
+	return versions, rows.Err()
+}
+
+// Счетчики версий и тегов одним запросом. Учащемуся считаются только видимые ему версии.
+func (questionService *QuestionService) detailStats(ctx context.Context, questionID int) (*models.QuestionStats, error) {
+
+	// Версии ответа видны учащемуся, только если виден сам ответ. Тьюторам считаются и версии удаленных ответов.
+	answerVersions := visibleVersion(ctx, "av")
+	if publishedOnly(ctx) {
+		answerVersions += ` and exists (select 1 from answers a where a.id = av.answer_id and ` + visibleAnswer(ctx, "a") + `)`
+	}
+
+	var query string = `select
+		(select count(*) from question_versions where question_id = $1 and ` + visibleVersion(ctx, "question_versions") + `),
+		(select count(*) from answer_versions av where av.question_id = $1 and ` + answerVersions + `),
+		(select count(*) from questions_tags where question_id = $1)`
+
+	var stats models.QuestionStats
+	err := questionService.db.QueryRowContext(ctx, query, questionID).Scan(&stats.VersionCount, &stats.AnswerVersionCount, &stats.TagCount)
+	if err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
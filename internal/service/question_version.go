@@ -1,8 +1,11 @@
 package service
 
 import (
+	"context"
 	"database/sql"
 	"knowledge-base/internal/models"
+
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // Структура для работы со всеми ф-ями service/question_version.go.
@@ -15,31 +18,74 @@ func NewQuestionVersionService(db *sql.DB) *QuestionVersionService {
 	return &QuestionVersionService{db: db}
 }
 
-func (questionVersionService *QuestionVersionService) GetAllByID(id int) ([]models.QuestionVersion, error) {
+func (questionVersionService *QuestionVersionService) GetAllByID(ctx context.Context, id int, params ListParams) ([]models.QuestionVersion, PageInfo, error) {
+
+	// Ограничение времени выполнения запроса.
+	ctx, finish := beginQuery(ctx, "QuestionVersionService.GetAllByID", attribute.Int("question.id", id))
+	defer finish()
+
+	// Учащемуся история видна только у опубликованного вопроса. Тьюторам и модераторам видна и история удаленных вопросов.
+	if publishedOnly(ctx) {
+		if err := checkVisible(ctx, questionVersionService.db, "questions", id); err != nil {
+			return nil, PageInfo{}, queryError(ctx, err)
+		}
+	}
+
+	// Версии только одной сущности, фильтры и сортировка из параметров списка.
+	// Учащемуся видны только опубликованные версии.
+	fixed := fixedFilter{Condition: "question_id = $%d", Value: id}
+	visible := fixedFilter{Condition: visibleVersion(ctx, "question_versions")}
+	where, args := QuestionVersionListSpec.whereClause(params, fixed, visible)
+	orderBy := QuestionVersionListSpec.orderClause(params)
+	page, pageArgs := QuestionVersionListSpec.pageClause(params, len(args))
+
+	// Общее количество версий с учетом фильтров, без учета страницы и курсора.
+	countWhere, countArgs := QuestionVersionListSpec.whereClause(params.withoutCursor(), fixed, visible)
+	var total int
+	err := questionVersionService.db.QueryRowContext(ctx, `select count(*) from question_versions`+countWhere, countArgs...).Scan(&total)
+	if err != nil {
+		return nil, PageInfo{}, queryError(ctx, err)
+	}
 
 	//Создание sql запроса для получения данных о версиях конкретного вопроса.
-	var query string = `select id, question_id, question_text, tutor_id, created_at, version_number, is_delete, delete_by_tutor from question_versions where question_id = $1 order by version_number`
+	var query string = `select id, question_id, question_text, tutor_id, created_at, version_number, is_delete, delete_by_tutor, status, status_comment, ` + QuestionVersionListSpec.keyColumn(params) + ` from question_versions` + where + orderBy + page
 
 	// Выполнение функции, которая проводит sql запрос и возвращает таблицу с несколькими строками.
-	rows, err := questionVersionService.db.Query(query, id)
+	rows, err := questionVersionService.db.QueryContext(ctx, query, append(args, pageArgs...)...)
 	if err != nil {
-		return nil, err
+		return nil, PageInfo{}, queryError(ctx, err)
 	}
 
 	// Закрытие, чтобы не было утечки соединений, надо закрыть.
 	defer rows.Close()
 
+	// Значения ключа сортировки каждой строки для курсоров соседних страниц.
+	var keys []cursorKey
+
 	var questionVersions []models.QuestionVersion
 
 	// Запись полученных данных из БД в массив формата []models.QuestionVersion.
 	for rows.Next() {
 		var questionVersion models.QuestionVersion
-		err := rows.Scan(&questionVersion.ID, &questionVersion.QuestionID, &questionVersion.QuestionText, &questionVersion.TutorID, &questionVersion.CreatedAt, &questionVersion.VersionNumber, &questionVersion.IsDelete, &questionVersion.DeleteByTutor)
+		var key cursorKey
+		err := rows.Scan(&questionVersion.ID, &questionVersion.QuestionID, &questionVersion.QuestionText, &questionVersion.TutorID, &questionVersion.CreatedAt, &questionVersion.VersionNumber, &questionVersion.IsDelete, &questionVersion.DeleteByTutor, &questionVersion.Status, &questionVersion.StatusComment, &key.Key)
 		if err != nil {
-			return nil, err
+			return nil, PageInfo{}, queryError(ctx, err)
 		}
+		key.ID = questionVersion.ID
 		questionVersions = append(questionVersions, questionVersion)
+		keys = append(keys, key)
 	}
 
-	return questionVersions, nil
+	// Проверка ошибки, которая могла прервать чтение строк.
+	if err := rows.Err(); err != nil {
+		return nil, PageInfo{}, queryError(ctx, err)
+	}
+
+	recordRows(ctx, len(questionVersions))
+
+	// Лишняя строка отбрасывается, по крайним строкам строятся курсоры.
+	questionVersions, info := keysetPage(QuestionVersionListSpec, params, questionVersions, keys, total)
+
+	return questionVersions, info, nil
 }
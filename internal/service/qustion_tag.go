@@ -1,9 +1,14 @@
 package service
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"knowledge-base/internal/models"
+
+	"github.com/lib/pq"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // Структура для работы со всеми ф-ями service/qustion_tag.go.
@@ -16,26 +21,51 @@ func NewQuestionTagService(db *sql.DB) *QuestionTagService {
 	return &QuestionTagService{db: db}
 }
 
-func (questionTagService *QuestionTagService) AddToQuestion(questionID, tagID int) error {
+func (questionTagService *QuestionTagService) AddToQuestion(ctx context.Context, questionID, tagID int) error {
+
+	// Ограничение времени выполнения запроса.
+	ctx, finish := beginQuery(ctx, "QuestionTagService.AddToQuestion", attribute.Int("question.id", questionID), attribute.Int("tag.id", tagID))
+	defer finish()
 
 	//Создание sql запроса для прикрепления тега к вопросу.
 	var query string = `insert into questions_tags (question_id, tag_id) values ($1, $2)`
 
 	// Выполнение функции, которая проводит sql запрос без возврата данных.
-	_, err := questionTagService.db.Exec(query, questionID, tagID)
+	_, err := questionTagService.db.ExecContext(ctx, query, questionID, tagID)
+	if err != nil {
+		return queryError(ctx, err)
+	}
 
-	return err
+	notifyQuestionsChanged(ctx, questionID)
+
+	return nil
 }
 
-func (questionTagService *QuestionTagService) GetAllRelations() ([]models.QuestionTag, error) {
+func (questionTagService *QuestionTagService) GetAllRelations(ctx context.Context, params ListParams) ([]models.QuestionTag, int, error) {
+
+	// Ограничение времени выполнения запроса.
+	ctx, finish := beginQuery(ctx, "QuestionTagService.GetAllRelations")
+	defer finish()
+
+	// Условия фильтрации и сортировки из параметров списка.
+	where, args := QuestionTagListSpec.whereClause(params)
+	orderBy := QuestionTagListSpec.orderClause(params)
+	page, pageArgs := QuestionTagListSpec.pageClause(params, len(args))
+
+	// Общее количество записей с учетом фильтров, без учета страницы.
+	var total int
+	err := questionTagService.db.QueryRowContext(ctx, `select count(*) from questions_tags`+where, args...).Scan(&total)
+	if err != nil {
+		return nil, 0, queryError(ctx, err)
+	}
 
 	//Создание sql запроса для получения данных по всем связям.
-	var query string = `select question_id, tag_id from questions_tags order by question_id, tag_id`
+	var query string = `select question_id, tag_id, added_by, is_auto from questions_tags` + where + orderBy + page
 
 	// Выполнение функции, которая проводит sql запрос и возвращает таблицу с несколькими строками.
-	rows, err := questionTagService.db.Query(query)
+	rows, err := questionTagService.db.QueryContext(ctx, query, append(args, pageArgs...)...)
 	if err != nil {
-		return nil, err
+		return nil, 0, queryError(ctx, err)
 	}
 
 	// Закрытие, чтобы не было утечки соединений, надо закрыть.
@@ -46,25 +76,84 @@ func (questionTagService *QuestionTagService) GetAllRelations() ([]models.Questi
 	// Запись полученных данных из БД в массив формата []models.QuestionTag.
 	for rows.Next() {
 		var relation models.QuestionTag
-		err := rows.Scan(&relation.QuestionID, &relation.TagID)
+		err := rows.Scan(&relation.QuestionID, &relation.TagID, &relation.AddedBy, &relation.IsAuto)
 		if err != nil {
-			return nil, err
+			return nil, 0, queryError(ctx, err)
 		}
 		relations = append(relations, relation)
 	}
 
-	return relations, nil
+	// Проверка ошибки, которая могла прервать чтение строк.
+	if err := rows.Err(); err != nil {
+		return nil, 0, queryError(ctx, err)
+	}
+
+	recordRows(ctx, len(relations))
+
+	return relations, total, nil
+}
+
+// ExportRelations читает все записи с учетом фильтров и сортировки и передает их по одной в fn, не собирая в память.
+// Если fn возвращает ошибку, чтение прекращается и ошибка возвращается как есть.
+func (questionTagService *QuestionTagService) ExportRelations(ctx context.Context, params ListParams, fn func(models.QuestionTag) error) error {
+
+	// Ограничение времени выгрузки.
+	ctx, finish := beginExport(ctx, "QuestionTagService.ExportRelations")
+	defer finish()
+
+	// Условия фильтрации и сортировки из параметров списка, без страницы.
+	where, args := QuestionTagListSpec.whereClause(params.withoutCursor())
+	orderBy := QuestionTagListSpec.orderClause(params.withoutCursor())
+
+	//Создание sql запроса для выгрузки всех связей вопросов и тегов.
+	var query string = `select question_id, tag_id, added_by, is_auto from questions_tags` + where + orderBy
+
+	// Выполнение функции, которая проводит sql запрос и возвращает таблицу с несколькими строками.
+	rows, err := questionTagService.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return queryError(ctx, err)
+	}
+
+	// Закрытие, чтобы не было утечки соединений, надо закрыть.
+	defer rows.Close()
+
+	// Каждая строка сразу передается дальше, в памяти хранится только одна.
+	var count int
+	for rows.Next() {
+		var relation models.QuestionTag
+		err := rows.Scan(&relation.QuestionID, &relation.TagID, &relation.AddedBy, &relation.IsAuto)
+		if err != nil {
+			return queryError(ctx, err)
+		}
+		if err := fn(relation); err != nil {
+			return err
+		}
+		count++
+	}
+
+	// Проверка ошибки, которая могла прервать чтение строк.
+	if err := rows.Err(); err != nil {
+		return queryError(ctx, err)
+	}
+
+	recordRows(ctx, count)
+
+	return nil
 }
 
-func (questionTagService *QuestionTagService) GetAllRelationsByTagID(tagID int) ([]models.QuestionTag, error) {
+func (questionTagService *QuestionTagService) GetAllRelationsByTagID(ctx context.Context, tagID int) ([]models.QuestionTag, error) {
+
+	// Ограничение времени выполнения запроса.
+	ctx, finish := beginQuery(ctx, "QuestionTagService.GetAllRelationsByTagID", attribute.Int("tag.id", tagID))
+	defer finish()
 
 	//Создание sql запроса для получения данных по всем связям.
-	var query string = `select tag_id, question_id from questions_tags where tag_id = $1`
+	var query string = `select tag_id, question_id, added_by, is_auto from questions_tags where tag_id = $1`
 
 	// Выполнение функции, которая проводит sql запрос и возвращает таблицу с несколькими строками.
-	rows, err := questionTagService.db.Query(query, tagID)
+	rows, err := questionTagService.db.QueryContext(ctx, query, tagID)
 	if err != nil {
-		return nil, err
+		return nil, queryError(ctx, err)
 	}
 
 	// Закрытие, чтобы не было утечки соединений, надо закрыть.
@@ -75,36 +164,176 @@ func (questionTagService *QuestionTagService) GetAllRelationsByTagID(tagID int)
 	// Запись полученных данных из БД в массив формата []models.QuestionTag.
 	for rows.Next() {
 		var relation models.QuestionTag
-		err := rows.Scan(&relation.TagID, &relation.QuestionID)
+		err := rows.Scan(&relation.TagID, &relation.QuestionID, &relation.AddedBy, &relation.IsAuto)
 		if err != nil {
-			return nil, err
+			return nil, queryError(ctx, err)
 		}
 		relations = append(relations, relation)
 	}
 
+	// Проверка ошибки, которая могла прервать чтение строк.
+	if err := rows.Err(); err != nil {
+		return nil, queryError(ctx, err)
+	}
+
+	recordRows(ctx, len(relations))
+
 	return relations, nil
 }
 
-func (questionTagService *QuestionTagService) DeleteRelationByID(questionID int, tagID int) error {
+func (questionTagService *QuestionTagService) DeleteRelationByID(ctx context.Context, questionID int, tagID int) error {
+
+	// Ограничение времени выполнения запроса.
+	ctx, finish := beginQuery(ctx, "QuestionTagService.DeleteRelationByID", attribute.Int("question.id", questionID), attribute.Int("tag.id", tagID))
+	defer finish()
 
 	//Создание sql запроса для удаления данных одной конкретной связи.
 	var queryDelete string = `delete from questions_tags where question_id = $1 and tag_id = $2`
 
 	// Выполнение функции, которая проводит sql запрос без возврата данных.
-	result, err := questionTagService.db.Exec(queryDelete, questionID, tagID)
+	result, err := questionTagService.db.ExecContext(ctx, queryDelete, questionID, tagID)
 	if err != nil {
-		return err
+		return queryError(ctx, err)
 	}
 
 	// Выполнение функции, которая возаращает количество удаленных строк.
 	rowsAffected, err := result.RowsAffected()
 	if err != nil {
-		return err
+		return queryError(ctx, err)
 	}
 
+	recordRows(ctx, int(rowsAffected))
+
 	//Проверка было ли удаление строки. Если rowsAffected = 0, то не было.
 	if rowsAffected == 0 {
 		return fmt.Errorf("question with id %d,%d not found", questionID, tagID)
 	}
+
+	notifyQuestionsChanged(ctx, questionID)
+
 	return nil
 }
+
+// Ошибка замены тегов: среди переданных ID есть несуществующий тег.
+var ErrTagNotFound = errors.New("тег не найден")
+
+// GetTagsByQuestionID возвращает теги вопроса. Если вопроса нет или он не виден вызывающему, возвращается sql.ErrNoRows.
+func (questionTagService *QuestionTagService) GetTagsByQuestionID(ctx context.Context, questionID int) ([]models.Tag, error) {
+
+	// Ограничение времени выполнения запроса.
+	ctx, finish := beginQuery(ctx, "QuestionTagService.GetTagsByQuestionID", attribute.Int("question.id", questionID))
+	defer finish()
+
+	// Проверка, что вопрос существует и виден, чтобы отличить его отсутствие от вопроса без тегов.
+	if err := checkVisible(ctx, questionTagService.db, "questions", questionID); err != nil {
+		return nil, queryError(ctx, err)
+	}
+
+	tags, err := questionTags(ctx, questionTagService.db, questionID)
+	if err != nil {
+		return nil, queryError(ctx, err)
+	}
+
+	recordRows(ctx, len(tags))
+
+	return tags, nil
+}
+
+// ReplaceTags заменяет весь набор тегов вопроса в одной транзакции и возвращает новый набор.
+// Если вопроса нет, возвращается sql.ErrNoRows, если нет какого-то из тегов - ErrTagNotFound.
+func (questionTagService *QuestionTagService) ReplaceTags(ctx context.Context, questionID int, tagIDs []int) ([]models.Tag, error) {
+
+	// Ограничение времени выполнения запроса.
+	ctx, finish := beginQuery(ctx, "QuestionTagService.ReplaceTags", attribute.Int("question.id", questionID), attribute.Int("tags.count", len(tagIDs)))
+	defer finish()
+
+	// Начало транзакции: либо заменяется весь набор, либо ничего.
+	tx, err := questionTagService.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, queryError(ctx, err)
+	}
+
+	// Откат, если транзакция не была зафиксирована.
+	defer tx.Rollback()
+
+	// Блокировка строки вопроса, чтобы параллельные замены тегов выполнялись по очереди.
+	var id int
+	err = tx.QueryRowContext(ctx, `select id from questions where id = $1 for update`, questionID).Scan(&id)
+	if err != nil {
+		return nil, queryError(ctx, err)
+	}
+
+	// Проверка, что все теги существуют.
+	var found int
+	err = tx.QueryRowContext(ctx, `select count(*) from tags where id = any($1)`, pq.Array(tagIDs)).Scan(&found)
+	if err != nil {
+		return nil, queryError(ctx, err)
+	}
+	if found != countDistinct(tagIDs) {
+		return nil, ErrTagNotFound
+	}
+
+	// Удаление старого набора и вставка нового.
+	_, err = tx.ExecContext(ctx, `delete from questions_tags where question_id = $1`, questionID)
+	if err != nil {
+		return nil, queryError(ctx, err)
+	}
+
+	_, err = tx.ExecContext(ctx, `insert into questions_tags (question_id, tag_id) select $1, tag_id from unnest($2::int[]) as ids(tag_id) group by tag_id`, questionID, pq.Array(tagIDs))
+	if err != nil {
+		return nil, queryError(ctx, err)
+	}
+
+	// Новый набор тегов читается в той же транзакции.
+	tags, err := questionTags(ctx, tx, questionID)
+	if err != nil {
+		return nil, queryError(ctx, err)
+	}
+
+	// Фиксация транзакции.
+	if err := tx.Commit(); err != nil {
+		return nil, queryError(ctx, err)
+	}
+
+	recordRows(ctx, len(tags))
+
+	notifyQuestionsChanged(ctx, questionID)
+
+	return tags, nil
+}
+
+// Общий интерфейс *sql.DB и *sql.Tx для запросов на чтение.
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// Теги вопроса, отсортированные по имени.
+func questionTags(ctx context.Context, db queryer, questionID int) ([]models.Tag, error) {
+	var query string = `select t.id, t.tutor_id, t.tag from tags t join questions_tags qt on qt.tag_id = t.id where qt.question_id = $1 order by t.tag`
+
+	rows, err := db.QueryContext(ctx, query, questionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	// Пустой набор возвращается как [], а не null.
+	tags := []models.Tag{}
+	for rows.Next() {
+		var tag models.Tag
+		if err := rows.Scan(&tag.ID, &tag.TutorID, &tag.Tag); err != nil {
+			return nil, err
+		}
+		tags = append(tags, tag)
+	}
+	return tags, rows.Err()
+}
+
+// Количество различных значений.
+func countDistinct(values []int) int {
+	seen := make(map[int]struct{}, len(values))
+	for _, value := range values {
+		seen[value] = struct{}{}
+	}
+	return len(seen)
+}
@@ -0,0 +1,335 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"knowledge-base/internal/models"
+
+	"github.com/lib/pq"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// Структура для работы со всеми ф-ями service/search.go.
+type SearchService struct {
+	db *sql.DB
+}
+
+// Функция для создания объекта типа SearchService.
+func NewSearchService(db *sql.DB) *SearchService {
+	return &SearchService{db: db}
+}
+
+// Параметры полнотекстового поиска.
+type SearchParams struct {
+	// Строка поиска в синтаксисе websearch: слова, "фраза", -исключение, or.
+	Query string
+
+	// Вопрос должен иметь все эти теги (без учета регистра).
+	Tags []string
+
+	// Только вопросы этого тьютора.
+	TutorID *int
+
+	Limit  int
+	Offset int
+}
+
+// Запрос к tsquery по обеим конфигурациям, как и вектор в таблицах, с заменой терминов на группы синонимов.
+// placeholder - плейсхолдер текста запроса.
+func searchTSQuery(placeholder string) string {
+	return `expand_synonyms('russian', websearch_to_tsquery('russian', ` + placeholder + `)) || expand_synonyms('english', websearch_to_tsquery('english', ` + placeholder + `))`
+}
+
+// Настройки фрагментов с подсветкой.
+const searchHeadlineOptions = `StartSel=<mark>, StopSel=</mark>, MaxWords=35, MinWords=15, MaxFragments=2`
+
+// Search ищет вопросы, в тексте которых или в тексте ответа есть слова запроса.
+// Результаты отсортированы по релевантности, для каждого вопроса берется лучший подходящий ответ.
+func (searchService *SearchService) Search(ctx context.Context, params SearchParams) ([]models.SearchResult, int, error) {
+
+	// Ограничение времени выполнения запроса.
+	ctx, finish := beginQuery(ctx, "SearchService.Search", attribute.String("search.query", params.Query))
+	defer finish()
+
+	// Условия по тегам и тьютору.
+	args := []interface{}{params.Query}
+	arg := func(value interface{}) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	// Слова, набранные латиницей или в другой раскладке, ищутся и в русском написании:
+	// "kak nastroit docker" находит "Как настроить Docker".
+	tsquery := searchTSQuery("$1")
+	if variants := translitTSQuery(params.Query, arg); variants != "" {
+		tsquery = "(" + tsquery + ") || " + variants
+	}
+
+	// Учащемуся видны только опубликованные вопросы и ответы.
+	where := ` where (q.search_vector @@ query.ts or a.id is not null) and ` + visibleQuestion(ctx, "q")
+	// Тег сравнивается так же, как в поиске по тегу: в любой раскладке и алфавите и с синонимами.
+	for _, tag := range params.Tags {
+		where += ` and exists (select 1 from questions_tags qt join tags t on t.id = qt.tag_id where qt.question_id = q.id and ` + tagNameCondition(arg(pq.Array(tagNameVariants(tag)))) + `)`
+	}
+	if params.TutorID != nil {
+		args = append(args, *params.TutorID)
+		where += fmt.Sprintf(` and q.tutor_id = $%d`, len(args))
+	}
+
+	// Лучший подходящий ответ на каждый вопрос.
+	from := ` from questions q
+		cross join (select ` + tsquery + ` as ts) query
+		left join lateral (
+			select a.id, a.answer_text, ts_rank_cd(a.search_vector, query.ts) as rank
+			from answers a
+			where a.question_id = q.id and a.search_vector @@ query.ts and ` + visibleAnswer(ctx, "a") + `
+			order by rank desc, a.id
+			limit 1
+		) a on true`
+
+	// Общее количество найденных вопросов.
+	var total int
+	err := searchService.db.QueryRowContext(ctx, `select count(*)`+from+where, args...).Scan(&total)
+	if err != nil {
+		return nil, 0, queryError(ctx, err)
+	}
+
+	//Создание sql запроса для получения страницы результатов с фрагментами.
+	var query string = `select q.id, q.question_text, q.tutor_id, q.created_at, q.is_edit, q.status, a.id,
+			ts_rank_cd(q.search_vector, query.ts) + coalesce(a.rank, 0) as rank,
+			ts_headline('russian', q.question_text, query.ts, '` + searchHeadlineOptions + `'),
+			coalesce(ts_headline('russian', a.answer_text, query.ts, '` + searchHeadlineOptions + `'), '')` +
+		from + where +
+		fmt.Sprintf(` order by rank desc, q.id limit $%d offset $%d`, len(args)+1, len(args)+2)
+
+	// Выполнение функции, которая проводит sql запрос и возвращает таблицу с несколькими строками.
+	rows, err := searchService.db.QueryContext(ctx, query, append(args, params.Limit, params.Offset)...)
+	if err != nil {
+		return nil, 0, queryError(ctx, err)
+	}
+
+	// Закрытие, чтобы не было утечки соединений, надо закрыть.
+	defer rows.Close()
+
+	// Запись полученных данных из БД в массив формата []models.SearchResult.
+	results := []models.SearchResult{}
+	for rows.Next() {
+		var result models.SearchResult
+		question := &result.Question
+		err := rows.Scan(&question.ID, &question.QuestionText, &question.TutorID, &question.CreatedAt, &question.IsEdit, &question.Status,
+			&result.AnswerID, &result.Rank, &result.QuestionHighlight, &result.AnswerHighlight)
+		if err != nil {
+			return nil, 0, queryError(ctx, err)
+		}
+		results = append(results, result)
+	}
+
+	// Проверка ошибки, которая могла прервать чтение строк.
+	if err := rows.Err(); err != nil {
+		return nil, 0, queryError(ctx, err)
+	}
+
+	recordRows(ctx, len(results))
+
+	return results, total, nil
+}
+
+// SearchByTags ищет вопросы по условию из тегов и считает по найденным вопросам количество вопросов с каждым тегом.
+// Условие выполняется операциями над множествами (intersect, union, except) по таблице questions_tags.
+func (searchService *SearchService) SearchByTags(ctx context.Context, tagQuery TagQuery, limit int, offset int) (models.TagSearchResult, error) {
+
+	// Ограничение времени выполнения запроса.
+	ctx, finish := beginQuery(ctx, "SearchService.SearchByTags")
+	defer finish()
+
+	// Множество ID найденных вопросов, которые видны вызывающему.
+	var args []interface{}
+	matched := `with matched as (select m.question_id from (` + tagQuery.sql(&args) + `) m
+		join questions q on q.id = m.question_id where ` + visibleQuestion(ctx, "q") + `)`
+
+	result := models.TagSearchResult{Questions: []models.Question{}, Facets: []models.TagFacet{}}
+
+	// Общее количество найденных вопросов.
+	err := searchService.db.QueryRowContext(ctx, matched+` select count(*) from matched`, args...).Scan(&result.Total)
+	if err != nil {
+		return models.TagSearchResult{}, queryError(ctx, err)
+	}
+
+	//Создание sql запроса для получения страницы найденных вопросов, новые сначала.
+	var query string = matched + ` select q.id, q.question_text, q.tutor_id, q.created_at, q.is_edit, q.status
+		from questions q join matched m on m.question_id = q.id
+		order by q.created_at desc, q.id desc` +
+		fmt.Sprintf(` limit $%d offset $%d`, len(args)+1, len(args)+2)
+
+	// Выполнение функции, которая проводит sql запрос и возвращает таблицу с несколькими строками.
+	rows, err := searchService.db.QueryContext(ctx, query, append(args, limit, offset)...)
+	if err != nil {
+		return models.TagSearchResult{}, queryError(ctx, err)
+	}
+
+	// Закрытие, чтобы не было утечки соединений, надо закрыть.
+	defer rows.Close()
+
+	// Запись полученных данных из БД в массив формата []models.Question.
+	for rows.Next() {
+		var question models.Question
+		err := rows.Scan(&question.ID, &question.QuestionText, &question.TutorID, &question.CreatedAt, &question.IsEdit, &question.Status)
+		if err != nil {
+			return models.TagSearchResult{}, queryError(ctx, err)
+		}
+		result.Questions = append(result.Questions, question)
+	}
+
+	// Проверка ошибки, которая могла прервать чтение строк.
+	if err := rows.Err(); err != nil {
+		return models.TagSearchResult{}, queryError(ctx, err)
+	}
+
+	// Счетчики тегов по всем найденным вопросам, а не только по странице.
+	var facetsQuery string = matched + ` select t.id, t.tag, count(*) as hits
+		from questions_tags qt join matched m on m.question_id = qt.question_id join tags t on t.id = qt.tag_id
+		group by t.id, t.tag
+		order by hits desc, t.tag`
+
+	facetRows, err := searchService.db.QueryContext(ctx, facetsQuery, args...)
+	if err != nil {
+		return models.TagSearchResult{}, queryError(ctx, err)
+	}
+	defer facetRows.Close()
+
+	for facetRows.Next() {
+		var facet models.TagFacet
+		if err := facetRows.Scan(&facet.TagID, &facet.Tag, &facet.Count); err != nil {
+			return models.TagSearchResult{}, queryError(ctx, err)
+		}
+		result.Facets = append(result.Facets, facet)
+	}
+
+	// Проверка ошибки, которая могла прервать чтение строк.
+	if err := facetRows.Err(); err != nil {
+		return models.TagSearchResult{}, queryError(ctx, err)
+	}
+
+	recordRows(ctx, len(result.Questions))
+
+	return result, nil
+}
+
+// SearchByQuery ищет вопросы по запросу на языке поиска (см. ParseSearchQuery).
+// Если в запросе есть слова или фразы без минуса, результаты отсортированы по релевантности и содержат фрагменты с подсветкой,
+// иначе - по дате создания, новые сначала.
+func (searchService *SearchService) SearchByQuery(ctx context.Context, searchQuery SearchQuery, limit int, offset int) ([]models.SearchResult, int, error) {
+
+	// Ограничение времени выполнения запроса.
+	ctx, finish := beginQuery(ctx, "SearchService.SearchByQuery", attribute.Int("search.terms", len(searchQuery.Terms)))
+	defer finish()
+
+	// Учащемуся видны только опубликованные вопросы и ответы.
+	compiled := searchQuery.compile(visibleAnswer(ctx, "a"))
+	compiled.where += ` and ` + visibleQuestion(ctx, "q")
+	args := compiled.args
+
+	// Общее количество найденных вопросов.
+	var total int
+	err := searchService.db.QueryRowContext(ctx, `select count(*) from questions q`+compiled.where, args...).Scan(&total)
+	if err != nil {
+		return nil, 0, queryError(ctx, err)
+	}
+
+	//Создание sql запроса для получения страницы результатов.
+	var query string
+	if compiled.rank != "" {
+		// Лучший ответ и подсветка по всем словам запроса без минуса.
+		query = `select q.id, q.question_text, q.tutor_id, q.created_at, q.is_edit, q.status, a.id,
+				ts_rank_cd(q.search_vector, query.ts) + coalesce(a.rank, 0) as rank,
+				ts_headline('russian', q.question_text, query.ts, '` + searchHeadlineOptions + `'),
+				coalesce(ts_headline('russian', a.answer_text, query.ts, '` + searchHeadlineOptions + `'), '')
+			from questions q
+			cross join (select ` + compiled.rank + ` as ts) query
+			left join lateral (
+				select a.id, a.answer_text, ts_rank_cd(a.search_vector, query.ts) as rank
+				from answers a
+				where a.question_id = q.id and a.search_vector @@ query.ts and ` + visibleAnswer(ctx, "a") + `
+				order by rank desc, a.id
+				limit 1
+			) a on true` +
+			compiled.where +
+			fmt.Sprintf(` order by rank desc, q.id limit $%d offset $%d`, len(args)+1, len(args)+2)
+	} else {
+		query = `select q.id, q.question_text, q.tutor_id, q.created_at, q.is_edit, q.status, null::int, 0::float8, '', ''
+			from questions q` +
+			compiled.where +
+			fmt.Sprintf(` order by q.created_at desc, q.id desc limit $%d offset $%d`, len(args)+1, len(args)+2)
+	}
+
+	// Выполнение функции, которая проводит sql запрос и возвращает таблицу с несколькими строками.
+	rows, err := searchService.db.QueryContext(ctx, query, append(args, limit, offset)...)
+	if err != nil {
+		return nil, 0, queryError(ctx, err)
+	}
+
+	// Закрытие, чтобы не было утечки соединений, надо закрыть.
+	defer rows.Close()
+
+	// Запись полученных данных из БД в массив формата []models.SearchResult.
+	results := []models.SearchResult{}
+	for rows.Next() {
+		var result models.SearchResult
+		question := &result.Question
+		err := rows.Scan(&question.ID, &question.QuestionText, &question.TutorID, &question.CreatedAt, &question.IsEdit, &question.Status,
+			&result.AnswerID, &result.Rank, &result.QuestionHighlight, &result.AnswerHighlight)
+		if err != nil {
+			return nil, 0, queryError(ctx, err)
+		}
+		results = append(results, result)
+	}
+
+	// Проверка ошибки, которая могла прервать чтение строк.
+	if err := rows.Err(); err != nil {
+		return nil, 0, queryError(ctx, err)
+	}
+
+	recordRows(ctx, len(results))
+
+	return results, total, nil
+}
+
+// EngineSearch ищет вопросы по словам, "фразам" и -исключениям движком поиска из настройки SEARCH_BACKEND.
+// Совпадения в тексте вопроса и его ответов учитываются вместе, результаты отсортированы по релевантности.
+func (searchService *SearchService) EngineSearch(ctx context.Context, query string, limit int, offset int) (models.EngineSearchResult, error) {
+
+	// Ограничение времени выполнения запроса.
+	ctx, finish := beginQuery(ctx, "SearchService.EngineSearch", attribute.String("search.query", query))
+	defer finish()
+
+	engine := searchEngineFor(ctx, searchService.db)
+	result := models.EngineSearchResult{Backend: engine.Name(), Results: []models.EngineMatch{}}
+
+	hits, total, err := engine.SearchText(ctx, query, limit, offset)
+	if err != nil {
+		return models.EngineSearchResult{}, queryError(ctx, err)
+	}
+	result.Total = total
+
+	ids := make([]int, 0, len(hits))
+	for _, hit := range hits {
+		ids = append(ids, hit.QuestionID)
+	}
+
+	questions, err := questionsByIDs(ctx, searchService.db, ids)
+	if err != nil {
+		return models.EngineSearchResult{}, queryError(ctx, err)
+	}
+
+	// Вопросы в порядке релевантности. Вопрос, удаленный после поиска, пропускается.
+	for _, hit := range hits {
+		if question, ok := questions[hit.QuestionID]; ok {
+			result.Results = append(result.Results, models.EngineMatch{Question: question, Score: hit.Score})
+		}
+	}
+
+	recordRows(ctx, len(result.Results))
+
+	return result, nil
+}
@@ -0,0 +1,194 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"knowledge-base/internal/models"
+
+	"github.com/lib/pq"
+)
+
+// Названия движков поиска для настройки SEARCH_BACKEND.
+const (
+	SearchBackendPostgres = "postgres"
+	SearchBackendIndex    = "index"
+)
+
+// Найденный вопрос и его релевантность.
+type SearchHit struct {
+	QuestionID int
+	Score      float64
+}
+
+// SearchEngine - движок поиска по вопросам: полнотекстовый поиск Postgres или встроенный индекс.
+type SearchEngine interface {
+	// Название движка.
+	Name() string
+
+	// Вопросы по тексту запроса (слова, "фразы", -исключения) по убыванию релевантности и их общее количество.
+	SearchText(ctx context.Context, query string, limit int, offset int) ([]SearchHit, int, error)
+
+	// Вопросы с тегом name (с учетом алфавита, раскладки и синонимов), новые сначала.
+	QuestionsByTag(ctx context.Context, name string) ([]int, error)
+
+	// Уведомления об изменениях данных после их сохранения в БД.
+	QuestionsChanged(ctx context.Context, questionIDs ...int)
+	AnswerChanged(ctx context.Context, answerID int)
+	TagDeleted(ctx context.Context, tagID int)
+}
+
+// Движок поиска, заданный настройкой. Если не задан, сервисы используют Postgres.
+var searchEngine SearchEngine
+
+// SetSearchEngine задает движок поиска для всех сервисов.
+func SetSearchEngine(engine SearchEngine) {
+	searchEngine = engine
+}
+
+// Движок поиска из настройки или Postgres поверх db.
+// Индекс хранит только опубликованные вопросы, поэтому тьюторам и модераторам, которым видно все, ищет Postgres.
+func searchEngineFor(ctx context.Context, db *sql.DB) SearchEngine {
+	if searchEngine != nil && publishedOnly(ctx) {
+		return searchEngine
+	}
+	return &postgresSearchEngine{db: db}
+}
+
+// Уведомление движка об изменении вопросов.
+func notifyQuestionsChanged(ctx context.Context, questionIDs ...int) {
+	if searchEngine != nil {
+		searchEngine.QuestionsChanged(ctx, questionIDs...)
+	}
+}
+
+// Уведомление движка об изменении ответа.
+func notifyAnswerChanged(ctx context.Context, answerID int) {
+	if searchEngine != nil {
+		searchEngine.AnswerChanged(ctx, answerID)
+	}
+}
+
+// Уведомление движка об удалении тега.
+func notifyTagDeleted(ctx context.Context, tagID int) {
+	if searchEngine != nil {
+		searchEngine.TagDeleted(ctx, tagID)
+	}
+}
+
+// Движок поиска на полнотекстовом поиске Postgres. Индексы БД обновляются сами, поэтому уведомления не нужны.
+type postgresSearchEngine struct {
+	db *sql.DB
+}
+
+// NewPostgresSearchEngine создает движок поиска на полнотекстовом поиске Postgres.
+func NewPostgresSearchEngine(db *sql.DB) SearchEngine {
+	return &postgresSearchEngine{db: db}
+}
+
+func (engine *postgresSearchEngine) Name() string {
+	return SearchBackendPostgres
+}
+
+func (engine *postgresSearchEngine) SearchText(ctx context.Context, query string, limit int, offset int) ([]SearchHit, int, error) {
+
+	args := []interface{}{query}
+	arg := func(value interface{}) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	// Слова, набранные латиницей или в другой раскладке, ищутся и в русском написании, как в SearchService.Search.
+	tsquery := searchTSQuery("$1")
+	if variants := translitTSQuery(query, arg); variants != "" {
+		tsquery = "(" + tsquery + ") || " + variants
+	}
+
+	// Вопросы, у которых совпал текст вопроса или хотя бы одного ответа.
+	var matched string = ` from questions q
+		cross join (select ` + tsquery + ` as ts) query
+		left join lateral (
+			select max(ts_rank_cd(a.search_vector, query.ts)) as rank
+			from answers a
+			where a.question_id = q.id and a.search_vector @@ query.ts and ` + visibleAnswer(ctx, "a") + `
+		) a on true
+		where (q.search_vector @@ query.ts or a.rank is not null) and ` + visibleQuestion(ctx, "q")
+
+	var total int
+	err := engine.db.QueryRowContext(ctx, `select count(*)`+matched, args...).Scan(&total)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := engine.db.QueryContext(ctx, `select q.id, ts_rank_cd(q.search_vector, query.ts) + coalesce(a.rank, 0) as rank`+matched+
+		fmt.Sprintf(` order by rank desc, q.id limit $%d offset $%d`, len(args)+1, len(args)+2), append(args, limit, offset)...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	hits := []SearchHit{}
+	for rows.Next() {
+		var hit SearchHit
+		if err := rows.Scan(&hit.QuestionID, &hit.Score); err != nil {
+			return nil, 0, err
+		}
+		hits = append(hits, hit)
+	}
+	return hits, total, rows.Err()
+}
+
+func (engine *postgresSearchEngine) QuestionsByTag(ctx context.Context, name string) ([]int, error) {
+	var query string = `select q.id
+		from questions q
+		where q.id in (
+			select qt.question_id
+			from questions_tags qt
+			inner join tags t on qt.tag_id = t.id
+			where ` + tagNameCondition("$1") + `
+		) and ` + visibleQuestion(ctx, "q") + `
+		order by q.created_at desc`
+
+	rows, err := engine.db.QueryContext(ctx, query, pq.Array(tagNameVariants(name)))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+func (engine *postgresSearchEngine) QuestionsChanged(ctx context.Context, questionIDs ...int) {}
+
+func (engine *postgresSearchEngine) AnswerChanged(ctx context.Context, answerID int) {}
+
+func (engine *postgresSearchEngine) TagDeleted(ctx context.Context, tagID int) {}
+
+// Вопросы по ID. Вопросов, которых уже нет в БД или которые не видны вызывающему, в результате нет.
+func questionsByIDs(ctx context.Context, db queryer, ids []int) (map[int]models.Question, error) {
+	var query string = `select id, question_text, tutor_id, created_at, is_edit, status from questions where id = any($1) and ` + visibleQuestion(ctx, "questions")
+
+	rows, err := db.QueryContext(ctx, query, pq.Array(ids))
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения вопросов: %w", err)
+	}
+	defer rows.Close()
+
+	questions := make(map[int]models.Question, len(ids))
+	for rows.Next() {
+		var question models.Question
+		if err := rows.Scan(&question.ID, &question.QuestionText, &question.TutorID, &question.CreatedAt, &question.IsEdit, &question.Status); err != nil {
+			return nil, err
+		}
+		questions[question.ID] = question
+	}
+	return questions, rows.Err()
+}
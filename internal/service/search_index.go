@@ -0,0 +1,341 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"knowledge-base/internal/logging"
+	"knowledge-base/internal/searchindex"
+	"os"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/lib/pq"
+)
+
+// Время на обновление индекса после изменения данных.
+const indexUpdateTimeout = 10 * time.Second
+
+// Настройки встроенного поискового индекса.
+type IndexSearchOptions struct {
+	// Файл, в котором индекс хранится между запусками.
+	Path string
+
+	// Как часто изменения индекса записываются в файл.
+	SaveInterval time.Duration
+}
+
+// Движок поиска на встроенном обратном индексе (internal/searchindex).
+// Индекс строится из вопросов, ответов и тегов и обновляется уведомлениями сервисов после изменений в БД.
+type indexSearchEngine struct {
+	db      *sql.DB
+	index   *searchindex.Index
+	options IndexSearchOptions
+}
+
+// NewIndexSearchEngine загружает индекс из файла или, если файла нет или он не совпадает с данными в БД,
+// строит его заново и сохраняет. Затем запускает периодическое сохранение изменений в файл.
+func NewIndexSearchEngine(ctx context.Context, db *sql.DB, options IndexSearchOptions) (SearchEngine, error) {
+	engine := &indexSearchEngine{db: db, options: options}
+
+	fingerprint, err := engine.fingerprint(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка проверки данных для индекса: %w", err)
+	}
+
+	index, saved, err := searchindex.Load(options.Path)
+	switch {
+	case err == nil && saved == fingerprint:
+		engine.index = index
+		logging.FromContext(ctx).Info("поисковый индекс загружен из файла", "path", options.Path, "documents", index.Len())
+	default:
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			logging.FromContext(ctx).Warn("файл поискового индекса не прочитан, индекс будет построен заново", "path", options.Path, "error", err)
+		}
+
+		engine.index = searchindex.New()
+		docs, err := engine.documents(ctx, nil)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка построения индекса: %w", err)
+		}
+		for _, doc := range docs {
+			engine.index.Put(doc)
+		}
+		if err := engine.index.Save(options.Path, fingerprint); err != nil {
+			return nil, err
+		}
+		logging.FromContext(ctx).Info("поисковый индекс построен", "path", options.Path, "documents", engine.index.Len())
+	}
+
+	go engine.saveLoop()
+
+	return engine, nil
+}
+
+func (engine *indexSearchEngine) Name() string {
+	return SearchBackendIndex
+}
+
+func (engine *indexSearchEngine) SearchText(ctx context.Context, query string, limit int, offset int) ([]SearchHit, int, error) {
+	parts := searchindex.ParseQuery(query)
+
+	synonyms, err := engine.textSynonyms(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// Каждое слово или фраза ищется вместе со своими синонимами.
+	clauses := make([]searchindex.Clause, 0, len(parts))
+	for _, part := range parts {
+		texts := append([]string{part.Text}, synonyms[analyzedKey(part.Text)]...)
+
+		// Слово, набранное латиницей или в другой раскладке, ищется и в русском написании.
+		// Если один из вариантов - стоп-слово ("kak" - "как"), слово пропускается, как и само стоп-слово.
+		if !strings.ContainsFunc(part.Text, unicode.IsSpace) {
+			variants := searchWordVariants(part.Text)
+			if slices.ContainsFunc(variants, func(variant string) bool { return len(searchindex.Analyze(variant)) == 0 }) {
+				continue
+			}
+			texts = append(texts, variants[1:]...)
+		}
+		clauses = append(clauses, searchindex.NewClause(texts, part.Negated))
+	}
+
+	found, total := engine.index.Search(clauses, limit, offset)
+
+	hits := make([]SearchHit, 0, len(found))
+	for _, hit := range found {
+		hits = append(hits, SearchHit{QuestionID: hit.ID, Score: hit.Score})
+	}
+	return hits, total, nil
+}
+
+func (engine *indexSearchEngine) QuestionsByTag(ctx context.Context, name string) ([]int, error) {
+	variants := tagNameVariants(name)
+
+	// Синонимы любого из вариантов имени, как в tagNameCondition.
+	var query string = `select distinct g.term
+		from synonyms s join synonyms g on g.group_id = s.group_id
+		where translit_key(s.term) in (select translit_key(v) from unnest($1::text[]) v)`
+
+	rows, err := engine.db.QueryContext(ctx, query, pq.Array(variants))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	names := variants
+	for rows.Next() {
+		var term string
+		if err := rows.Scan(&term); err != nil {
+			return nil, err
+		}
+		names = append(names, term)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, 0, len(names))
+	for _, name := range names {
+		keys = append(keys, translitKey(name))
+	}
+	return engine.index.ByTag(keys), nil
+}
+
+func (engine *indexSearchEngine) QuestionsChanged(ctx context.Context, questionIDs ...int) {
+	if len(questionIDs) == 0 {
+		return
+	}
+
+	// Индекс обновляется, даже если клиент уже отключился: данные в БД уже изменены.
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), indexUpdateTimeout)
+	defer cancel()
+
+	docs, err := engine.documents(ctx, questionIDs)
+	if err != nil {
+		logging.FromContext(ctx).Error("ошибка обновления поискового индекса", "question_ids", questionIDs, "error", err)
+		return
+	}
+
+	// Вопросов, которых нет среди документов, нет в БД или они не опубликованы.
+	found := make(map[int]bool, len(docs))
+	for _, doc := range docs {
+		engine.index.Put(doc)
+		found[doc.ID] = true
+	}
+	for _, id := range questionIDs {
+		if !found[id] {
+			engine.index.Remove(id)
+		}
+	}
+}
+
+func (engine *indexSearchEngine) AnswerChanged(ctx context.Context, answerID int) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), indexUpdateTimeout)
+	defer cancel()
+
+	// Все вопросы, к которым относился ответ: после изменения ответ мог перейти к другому вопросу, а после удаления его нет в answers.
+	var query string = `select question_id from answers where id = $1
+		union
+		select question_id from answer_versions where answer_id = $1`
+
+	rows, err := engine.db.QueryContext(ctx, query, answerID)
+	if err != nil {
+		logging.FromContext(ctx).Error("ошибка обновления поискового индекса", "answer_id", answerID, "error", err)
+		return
+	}
+	defer rows.Close()
+
+	var questionIDs []int
+	for rows.Next() {
+		var id sql.NullInt64
+		if err := rows.Scan(&id); err != nil {
+			logging.FromContext(ctx).Error("ошибка обновления поискового индекса", "answer_id", answerID, "error", err)
+			return
+		}
+		if id.Valid {
+			questionIDs = append(questionIDs, int(id.Int64))
+		}
+	}
+	if err := rows.Err(); err != nil {
+		logging.FromContext(ctx).Error("ошибка обновления поискового индекса", "answer_id", answerID, "error", err)
+		return
+	}
+
+	engine.QuestionsChanged(ctx, questionIDs...)
+}
+
+func (engine *indexSearchEngine) TagDeleted(ctx context.Context, tagID int) {
+	engine.index.RemoveTag(tagID)
+}
+
+// Периодическое сохранение изменений индекса в файл.
+func (engine *indexSearchEngine) saveLoop() {
+	ticker := time.NewTicker(engine.options.SaveInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if !engine.index.Dirty() {
+			continue
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), indexUpdateTimeout)
+		fingerprint, err := engine.fingerprint(ctx)
+		cancel()
+		if err == nil {
+			err = engine.index.Save(engine.options.Path, fingerprint)
+		}
+		if err != nil {
+			logging.FromContext(ctx).Error("ошибка сохранения поискового индекса", "path", engine.options.Path, "error", err)
+		}
+	}
+}
+
+// Отпечаток данных, из которых строится индекс: количество и последние ID строк.
+// Любое изменение через API добавляет версию, строку или меняет связи с тегами, поэтому отпечаток меняется,
+// и индекс, сохраненный до изменений (например, при остановке до очередного сохранения), строится заново.
+func (engine *indexSearchEngine) fingerprint(ctx context.Context) (string, error) {
+	var query string = `select concat_ws('/',
+		(select count(*) || '.' || coalesce(max(id), 0) from questions),
+		(select count(*) || '.' || coalesce(max(id), 0) from answers),
+		(select count(*) || '.' || coalesce(max(id), 0) from question_versions),
+		(select count(*) || '.' || coalesce(max(id), 0) from answer_versions),
+		(select count(*) || '.' || coalesce(max(id), 0) from tags),
+		(select count(*) || '.' || coalesce(sum(question_id::bigint * 1000003 + tag_id), 0) from questions_tags))`
+
+	var fingerprint string
+	err := engine.db.QueryRowContext(ctx, query).Scan(&fingerprint)
+	return fingerprint, err
+}
+
+// Документы индекса: опубликованные вопросы с текстами опубликованных ответов и тегами. Если ids пустой, загружаются все вопросы.
+func (engine *indexSearchEngine) documents(ctx context.Context, ids []int) ([]searchindex.Document, error) {
+	var query string = `select q.id, q.question_text, q.created_at,
+			coalesce((select array_agg(a.answer_text order by a.id) from answers a where a.question_id = q.id and a.status = '` + StatusPublished + `'), '{}'),
+			coalesce((select array_agg(t.id order by t.id) from questions_tags qt join tags t on t.id = qt.tag_id where qt.question_id = q.id), '{}'),
+			coalesce((select array_agg(t.tag order by t.id) from questions_tags qt join tags t on t.id = qt.tag_id where qt.question_id = q.id), '{}')
+		from questions q
+		where q.status = '` + StatusPublished + `' and ($1::int[] is null or q.id = any($1))`
+
+	var filter interface{}
+	if len(ids) > 0 {
+		filter = pq.Array(ids)
+	}
+
+	rows, err := engine.db.QueryContext(ctx, query, filter)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var docs []searchindex.Document
+	for rows.Next() {
+		var (
+			doc       searchindex.Document
+			question  string
+			createdAt sql.NullTime
+			answers   []string
+			tagIDs    []int64
+			tagNames  []string
+		)
+		if err := rows.Scan(&doc.ID, &question, &createdAt, pq.Array(&answers), pq.Array(&tagIDs), pq.Array(&tagNames)); err != nil {
+			return nil, err
+		}
+
+		doc.CreatedAt = createdAt.Time
+		doc.Texts = append([]string{question}, answers...)
+		for i := range tagIDs {
+			doc.Tags = append(doc.Tags, searchindex.Tag{ID: int(tagIDs[i]), Key: translitKey(tagNames[i])})
+		}
+		docs = append(docs, doc)
+	}
+	return docs, rows.Err()
+}
+
+// Синонимы для поиска по тексту: основы слов термина -> остальные термины его групп.
+// Словарь небольшой и читается при каждом поиске, поэтому изменения действуют сразу, как и в Postgres.
+func (engine *indexSearchEngine) textSynonyms(ctx context.Context) (map[string][]string, error) {
+	rows, err := engine.db.QueryContext(ctx, `select group_id, term from synonyms order by group_id, id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	groups := make(map[int][]string)
+	for rows.Next() {
+		var groupID int
+		var term string
+		if err := rows.Scan(&groupID, &term); err != nil {
+			return nil, err
+		}
+		groups[groupID] = append(groups[groupID], term)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	synonyms := make(map[string][]string)
+	for _, terms := range groups {
+		for _, term := range terms {
+			key := analyzedKey(term)
+			if key == "" {
+				continue
+			}
+			for _, other := range terms {
+				if other != term {
+					synonyms[key] = append(synonyms[key], other)
+				}
+			}
+		}
+	}
+	return synonyms, nil
+}
+
+// Текст после анализа (основы слов без стоп-слов): "базы данных" и "база данных" дают один ключ.
+func analyzedKey(text string) string {
+	return strings.Join(searchindex.Analyze(text), " ")
+}
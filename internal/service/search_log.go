@@ -0,0 +1,160 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"knowledge-base/internal/models"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// Отчеты по журналу поиска.
+const (
+	// Самые частые запросы.
+	SearchReportTopQueries = "top-queries"
+
+	// Запросы, которые ничего не нашли: о чем стоит написать вопросы.
+	SearchReportZeroResults = "zero-results"
+
+	// Доля поисков, после которых клиент открыл результат.
+	SearchReportClickThrough = "click-through"
+)
+
+// Ошибка переданного перехода к результату поиска.
+var ErrInvalidSearchClick = errors.New("неверный переход к результату поиска")
+
+// Запись журнала поиска.
+type SearchLogEntry struct {
+	SearchID string
+	Endpoint string
+	Query    string
+
+	// Количество найденных результатов, nil - если эндпоинт его не сообщает.
+	ResultCount *int
+
+	Latency  time.Duration
+	ClientID string
+}
+
+// Структура для работы со всеми ф-ями service/search_log.go.
+type SearchLogService struct {
+	db *sql.DB
+}
+
+// Функция для создания объекта типа SearchLogService.
+func NewSearchLogService(db *sql.DB) *SearchLogService {
+	return &SearchLogService{db: db}
+}
+
+// Record записывает поиск в журнал.
+func (searchLogService *SearchLogService) Record(ctx context.Context, entry SearchLogEntry) error {
+
+	// Ограничение времени выполнения запроса.
+	ctx, finish := beginQuery(ctx, "SearchLogService.Record", attribute.String("search.endpoint", entry.Endpoint))
+	defer finish()
+
+	var query string = `insert into search_log (search_id, endpoint, query, normalized_query, result_count, latency_ms, client_id)
+		values ($1, $2, $3, lower(trim(regexp_replace($3, '\s+', ' ', 'g'))), $4, $5, $6)
+		on conflict (search_id) do nothing`
+
+	_, err := searchLogService.db.ExecContext(ctx, query, entry.SearchID, entry.Endpoint, entry.Query, entry.ResultCount,
+		float64(entry.Latency.Microseconds())/1000, entry.ClientID)
+	return queryError(ctx, err)
+}
+
+// RecordClick записывает переход клиента к результату поиска. Повторный переход к тому же вопросу не учитывается.
+func (searchLogService *SearchLogService) RecordClick(ctx context.Context, searchID string, questionID int, position *int, clientID string) error {
+
+	// Ограничение времени выполнения запроса.
+	ctx, finish := beginQuery(ctx, "SearchLogService.RecordClick", attribute.Int("question.id", questionID))
+	defer finish()
+
+	if searchID == "" || len(searchID) > 32 {
+		return fmt.Errorf("%w: нужен search_id из заголовка X-Search-ID", ErrInvalidSearchClick)
+	}
+	if questionID <= 0 {
+		return fmt.Errorf("%w: нужен question_id", ErrInvalidSearchClick)
+	}
+	if position != nil && *position < 1 {
+		return fmt.Errorf("%w: position начинается с 1", ErrInvalidSearchClick)
+	}
+
+	var query string = `insert into search_clicks (search_id, question_id, position, client_id)
+		values ($1, $2, $3, $4)
+		on conflict (search_id, question_id) do nothing`
+
+	_, err := searchLogService.db.ExecContext(ctx, query, searchID, questionID, position, clientID)
+	return queryError(ctx, err)
+}
+
+// Report возвращает статистику запросов с момента since для отчета report (SearchReport*).
+func (searchLogService *SearchLogService) Report(ctx context.Context, report string, since time.Time, limit int) ([]models.SearchQueryStat, error) {
+
+	// Ограничение времени выполнения запроса.
+	ctx, finish := beginQuery(ctx, "SearchLogService.Report", attribute.String("search.report", report))
+	defer finish()
+
+	// Отбор и порядок запросов для отчета.
+	var having, orderBy string
+	switch report {
+	case SearchReportTopQueries:
+		orderBy = `searches desc, last_searched_at desc`
+	case SearchReportZeroResults:
+		having = ` having count(*) filter (where l.result_count = 0) > 0`
+		orderBy = `zero_results desc, searches desc`
+	case SearchReportClickThrough:
+		orderBy = `searches desc, click_through_rate`
+	default:
+		return nil, fmt.Errorf("неизвестный отчет %q", report)
+	}
+
+	// Переходы считаются по поискам: поиск с переходом - тот, после которого открыли хотя бы один результат.
+	var query string = `select l.normalized_query,
+			count(*) as searches,
+			count(distinct l.client_id),
+			count(*) filter (where l.result_count = 0) as zero_results,
+			count(c.search_id),
+			count(c.search_id)::double precision / count(*) as click_through_rate,
+			avg(c.first_position),
+			avg(l.result_count),
+			avg(l.latency_ms),
+			max(l.created_at) as last_searched_at
+		from search_log l
+		left join (
+			select search_id, min(position) as first_position from search_clicks group by search_id
+		) c on c.search_id = l.search_id
+		where l.created_at >= $1
+		group by l.normalized_query` + having + `
+		order by ` + orderBy + `, l.normalized_query
+		limit $2`
+
+	rows, err := searchLogService.db.QueryContext(ctx, query, since, limit)
+	if err != nil {
+		return nil, queryError(ctx, err)
+	}
+	defer rows.Close()
+
+	// Пустой отчет возвращается как [], а не null.
+	stats := []models.SearchQueryStat{}
+	for rows.Next() {
+		var stat models.SearchQueryStat
+		err := rows.Scan(&stat.Query, &stat.Searches, &stat.Clients, &stat.ZeroResults, &stat.Clicks, &stat.ClickThroughRate,
+			&stat.AvgClickPosition, &stat.AvgResults, &stat.AvgLatencyMs, &stat.LastSearchedAt)
+		if err != nil {
+			return nil, queryError(ctx, err)
+		}
+		stats = append(stats, stat)
+	}
+
+	// Проверка ошибки, которая могла прервать чтение строк.
+	if err := rows.Err(); err != nil {
+		return nil, queryError(ctx, err)
+	}
+
+	recordRows(ctx, len(stats))
+
+	return stats, nil
+}
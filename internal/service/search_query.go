@@ -0,0 +1,343 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/lib/pq"
+)
+
+// Максимальное количество условий в поисковом запросе.
+const maxSearchQueryTerms = 20
+
+// Формат дат в условии created.
+const searchDateLayout = "2006-01-02"
+
+// Ошибка разбора поискового запроса.
+var ErrInvalidSearchQuery = errors.New("неверный поисковый запрос")
+
+// Поле условия поискового запроса.
+type SearchField string
+
+const (
+	// Слово или фраза в тексте вопроса или ответа.
+	SearchFieldText SearchField = "text"
+
+	// tag:go - у вопроса есть тег.
+	SearchFieldTag SearchField = "tag"
+
+	// author:ivanov - имя автора вопроса содержит значение или email совпадает с ним.
+	SearchFieldAuthor SearchField = "author"
+
+	// created:>2025-01-01 - дата создания вопроса.
+	SearchFieldCreated SearchField = "created"
+
+	// is:answered, is:unanswered, is:edited - состояние вопроса.
+	SearchFieldIs SearchField = "is"
+)
+
+// Значения условия is. Вместо %s подставляется условие видимости ответа a.
+var searchIsValues = map[string]string{
+	"answered":   `exists (select 1 from answers a where a.question_id = q.id and %s)`,
+	"unanswered": `not exists (select 1 from answers a where a.question_id = q.id and %s)`,
+	"edited":     `coalesce(q.is_edit, false)`,
+}
+
+// Разобранный поисковый запрос: все условия должны выполняться одновременно.
+// Это и есть дерево разбора языка запросов: в языке нет OR и скобок, поэтому корень - конъюнкция,
+// а листья - условия SearchTerm, каждое со своим отрицанием. Промежуточные узлы появятся вместе с OR и группировкой.
+type SearchQuery struct {
+	Terms []SearchTerm
+}
+
+// Одно условие поискового запроса.
+type SearchTerm struct {
+	Field SearchField
+
+	// Значение без кавычек. Для created - дата или диапазон дат в исходном виде.
+	Value string
+
+	// Значение было в кавычках: для текста ищется точная фраза.
+	Phrase bool
+
+	// Условие с минусом: подходят вопросы, для которых оно не выполняется.
+	Negated bool
+
+	// Для created: полуинтервал дат [From, To), нулевая граница - без ограничения.
+	From time.Time
+	To   time.Time
+
+	// Позиция условия в запросе (с 1).
+	Pos int
+}
+
+// ParseSearchQuery разбирает запрос вида `tag:go author:ivanov "exact phrase" -docker created:>2025-01-01 is:unanswered`.
+// Условия разделяются пробелами, минус перед условием его отрицает, значения с пробелами записываются в кавычках.
+// Для created поддерживаются >, >=, <, <=, точная дата и диапазон 2025-01-01..2025-02-01 (включительно).
+func ParseSearchQuery(input string) (SearchQuery, error) {
+	runes := []rune(input)
+	var query SearchQuery
+
+	for i := 0; i < len(runes); {
+		if unicode.IsSpace(runes[i]) {
+			i++
+			continue
+		}
+
+		term := SearchTerm{Field: SearchFieldText, Pos: i + 1}
+
+		// Отрицание.
+		if runes[i] == '-' {
+			term.Negated = true
+			i++
+			if i == len(runes) || unicode.IsSpace(runes[i]) {
+				return SearchQuery{}, fmt.Errorf("%w: после \"-\" ожидалось условие на позиции %d", ErrInvalidSearchQuery, term.Pos)
+			}
+		}
+
+		// Фраза в кавычках.
+		if runes[i] == '"' {
+			value, next, err := readSearchQuoted(runes, i)
+			if err != nil {
+				return SearchQuery{}, err
+			}
+			term.Value, term.Phrase = value, true
+			i = next
+			query.Terms = append(query.Terms, term)
+			continue
+		}
+
+		// Слово до пробела или кавычки.
+		start := i
+		for i < len(runes) && !unicode.IsSpace(runes[i]) && runes[i] != '"' {
+			i++
+		}
+		word := string(runes[start:i])
+
+		colon := strings.IndexRune(word, ':')
+		if !searchFieldLike(word, colon) {
+			term.Value = word
+			query.Terms = append(query.Terms, term)
+			continue
+		}
+
+		// Условие по полю: поле:значение или поле:"значение с пробелами".
+		term.Field = SearchField(strings.ToLower(word[:colon]))
+		term.Value = word[colon+1:]
+		fieldPos := start + 1
+		valuePos := start + len([]rune(word[:colon])) + 2
+		if term.Value == "" && i < len(runes) && runes[i] == '"' {
+			value, next, err := readSearchQuoted(runes, i)
+			if err != nil {
+				return SearchQuery{}, err
+			}
+			term.Value, term.Phrase = value, true
+			i = next
+		}
+
+		if err := term.check(fieldPos, valuePos); err != nil {
+			return SearchQuery{}, err
+		}
+		query.Terms = append(query.Terms, term)
+	}
+
+	if len(query.Terms) == 0 {
+		return SearchQuery{}, fmt.Errorf("%w: пустой запрос", ErrInvalidSearchQuery)
+	}
+	if len(query.Terms) > maxSearchQueryTerms {
+		return SearchQuery{}, fmt.Errorf("%w: не больше %d условий", ErrInvalidSearchQuery, maxSearchQueryTerms)
+	}
+
+	return query, nil
+}
+
+// Слово похоже на условие поле:значение: перед двоеточием только латинские буквы, и это не адрес вида http://.
+// Остальные слова с двоеточием (10:30, ошибка:timeout, https://go.dev) ищутся как текст.
+func searchFieldLike(word string, colon int) bool {
+	if colon <= 0 || strings.HasPrefix(word[colon+1:], "//") {
+		return false
+	}
+	for _, r := range word[:colon] {
+		if (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') {
+			return false
+		}
+	}
+	return true
+}
+
+// Значение в кавычках, начиная с позиции открывающей кавычки. Возвращает значение и позицию после закрывающей.
+func readSearchQuoted(runes []rune, open int) (string, int, error) {
+	end := open + 1
+	for end < len(runes) && runes[end] != '"' {
+		end++
+	}
+	if end == len(runes) {
+		return "", 0, fmt.Errorf("%w: незакрытая кавычка на позиции %d", ErrInvalidSearchQuery, open+1)
+	}
+
+	value := strings.TrimSpace(string(runes[open+1 : end]))
+	if value == "" {
+		return "", 0, fmt.Errorf("%w: пустое значение в кавычках на позиции %d", ErrInvalidSearchQuery, open+1)
+	}
+	return value, end + 1, nil
+}
+
+// Проверка поля и значения условия. fieldPos и pos - позиции поля и значения для сообщений об ошибках.
+func (term *SearchTerm) check(fieldPos int, pos int) error {
+	switch term.Field {
+	case SearchFieldTag, SearchFieldAuthor, SearchFieldIs, SearchFieldCreated:
+	default:
+		return fmt.Errorf("%w: неизвестное поле %q на позиции %d, доступны tag, author, created, is", ErrInvalidSearchQuery, string(term.Field), fieldPos)
+	}
+
+	if term.Value == "" {
+		return fmt.Errorf("%w: пустое значение поля %s на позиции %d", ErrInvalidSearchQuery, term.Field, pos)
+	}
+
+	switch term.Field {
+	case SearchFieldTag, SearchFieldAuthor:
+		return nil
+	case SearchFieldIs:
+		term.Value = strings.ToLower(term.Value)
+		if _, ok := searchIsValues[term.Value]; !ok {
+			return fmt.Errorf("%w: неизвестное значение is:%s на позиции %d, доступны answered, unanswered, edited", ErrInvalidSearchQuery, term.Value, pos)
+		}
+		return nil
+	}
+
+	return term.parseDates(pos)
+}
+
+// Разбор условия created в полуинтервал [From, To).
+func (term *SearchTerm) parseDates(pos int) error {
+	value := term.Value
+
+	parse := func(text string, offset int) (time.Time, error) {
+		date, err := time.Parse(searchDateLayout, text)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("%w: неверная дата %q на позиции %d, ожидается ГГГГ-ММ-ДД", ErrInvalidSearchQuery, text, pos+offset)
+		}
+		return date, nil
+	}
+
+	// Диапазон дат включительно.
+	if from, to, ok := strings.Cut(value, ".."); ok {
+		start, err := parse(from, 0)
+		if err != nil {
+			return err
+		}
+		end, err := parse(to, len([]rune(from))+2)
+		if err != nil {
+			return err
+		}
+		if end.Before(start) {
+			return fmt.Errorf("%w: конец диапазона раньше начала на позиции %d", ErrInvalidSearchQuery, pos)
+		}
+		term.From, term.To = start, end.AddDate(0, 0, 1)
+		return nil
+	}
+
+	// Сравнение с датой: дата означает весь день.
+	for _, op := range []string{">=", "<=", ">", "<", "="} {
+		if !strings.HasPrefix(value, op) {
+			continue
+		}
+		date, err := parse(value[len(op):], len(op))
+		if err != nil {
+			return err
+		}
+		switch op {
+		case ">=":
+			term.From = date
+		case ">":
+			term.From = date.AddDate(0, 0, 1)
+		case "<=":
+			term.To = date.AddDate(0, 0, 1)
+		case "<":
+			term.To = date
+		case "=":
+			term.From, term.To = date, date.AddDate(0, 0, 1)
+		}
+		return nil
+	}
+
+	date, err := parse(value, 0)
+	if err != nil {
+		return err
+	}
+	term.From, term.To = date, date.AddDate(0, 0, 1)
+	return nil
+}
+
+// Запрос, скомпилированный в SQL: условие where по вопросам q, tsquery для ранжирования и значения плейсхолдеров.
+type compiledSearchQuery struct {
+	where string
+
+	// Выражение tsquery из всех слов и фраз без минуса, пустое, если их нет.
+	rank string
+
+	args []interface{}
+}
+
+// Компиляция запроса в SQL. Все значения передаются через плейсхолдеры.
+// visibleAnswers - условие видимости ответа a, ответы вне его не учитываются.
+func (query SearchQuery) compile(visibleAnswers string) compiledSearchQuery {
+	var compiled compiledSearchQuery
+	var conditions, ranks []string
+
+	arg := func(value interface{}) string {
+		compiled.args = append(compiled.args, value)
+		return fmt.Sprintf("$%d", len(compiled.args))
+	}
+
+	for _, term := range query.Terms {
+		var condition string
+
+		switch term.Field {
+		case SearchFieldText:
+			fn := "plainto_tsquery"
+			if term.Phrase {
+				fn = "phraseto_tsquery"
+			}
+			placeholder := arg(term.Value)
+			tsquery := fmt.Sprintf(`(expand_synonyms('russian', %s('russian', %s)) || expand_synonyms('english', %s('english', %s)))`, fn, placeholder, fn, placeholder)
+			condition = fmt.Sprintf(`(q.search_vector @@ %s or exists (select 1 from answers a where a.question_id = q.id and a.search_vector @@ %s and %s))`, tsquery, tsquery, visibleAnswers)
+			if !term.Negated {
+				ranks = append(ranks, tsquery)
+			}
+		case SearchFieldTag:
+			// Тег сравнивается так же, как в поиске по тегу: в любой раскладке и алфавите и с синонимами.
+			condition = `exists (select 1 from questions_tags qt join tags t on t.id = qt.tag_id where qt.question_id = q.id and ` +
+				tagNameCondition(arg(pq.Array(tagNameVariants(term.Value)))) + `)`
+		case SearchFieldAuthor:
+			placeholder := arg(term.Value)
+			condition = fmt.Sprintf(`exists (select 1 from tutors tu where tu.id = q.tutor_id and (strpos(lower(tu.full_name), lower(%s)) > 0 or lower(tu.email) = lower(%s)))`, placeholder, placeholder)
+		case SearchFieldCreated:
+			var bounds []string
+			if !term.From.IsZero() {
+				bounds = append(bounds, `q.created_at >= `+arg(term.From))
+			}
+			if !term.To.IsZero() {
+				bounds = append(bounds, `q.created_at < `+arg(term.To))
+			}
+			condition = "(" + strings.Join(bounds, " and ") + ")"
+		case SearchFieldIs:
+			condition = searchIsValues[term.Value]
+			if strings.Contains(condition, "%s") {
+				condition = fmt.Sprintf(condition, visibleAnswers)
+			}
+		}
+
+		if term.Negated {
+			condition = "not (" + condition + ")"
+		}
+		conditions = append(conditions, condition)
+	}
+
+	compiled.where = ` where ` + strings.Join(conditions, " and ")
+	compiled.rank = strings.Join(ranks, " || ")
+	return compiled
+}
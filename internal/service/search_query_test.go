@@ -0,0 +1,181 @@
+package service
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func date(value string) time.Time {
+	parsed, err := time.Parse(searchDateLayout, value)
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}
+
+func TestParseSearchQuery(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []SearchTerm
+	}{
+		{
+			name:  "слова",
+			input: "docker  compose",
+			want: []SearchTerm{
+				{Field: SearchFieldText, Value: "docker", Pos: 1},
+				{Field: SearchFieldText, Value: "compose", Pos: 9},
+			},
+		},
+		{
+			name:  "фраза в кавычках",
+			input: `"exact phrase" go`,
+			want: []SearchTerm{
+				{Field: SearchFieldText, Value: "exact phrase", Phrase: true, Pos: 1},
+				{Field: SearchFieldText, Value: "go", Pos: 16},
+			},
+		},
+		{
+			name:  "отрицание слова и фразы",
+			input: `-docker -"старая версия"`,
+			want: []SearchTerm{
+				{Field: SearchFieldText, Value: "docker", Negated: true, Pos: 1},
+				{Field: SearchFieldText, Value: "старая версия", Phrase: true, Negated: true, Pos: 9},
+			},
+		},
+		{
+			name:  "поля",
+			input: `tag:go Author:ivanov is:Unanswered -is:edited`,
+			want: []SearchTerm{
+				{Field: SearchFieldTag, Value: "go", Pos: 1},
+				{Field: SearchFieldAuthor, Value: "ivanov", Pos: 8},
+				{Field: SearchFieldIs, Value: "unanswered", Pos: 22},
+				{Field: SearchFieldIs, Value: "edited", Negated: true, Pos: 36},
+			},
+		},
+		{
+			name:  "значение поля в кавычках",
+			input: `tag:"база данных" author:"Иван Иванов"`,
+			want: []SearchTerm{
+				{Field: SearchFieldTag, Value: "база данных", Phrase: true, Pos: 1},
+				{Field: SearchFieldAuthor, Value: "Иван Иванов", Phrase: true, Pos: 19},
+			},
+		},
+		{
+			name:  "слова с двоеточием - текст",
+			input: `10:30 https://go.dev ошибка:timeout`,
+			want: []SearchTerm{
+				{Field: SearchFieldText, Value: "10:30", Pos: 1},
+				{Field: SearchFieldText, Value: "https://go.dev", Pos: 7},
+				{Field: SearchFieldText, Value: "ошибка:timeout", Pos: 22},
+			},
+		},
+		{
+			name:  "created больше",
+			input: "created:>2025-01-01",
+			want:  []SearchTerm{{Field: SearchFieldCreated, Value: ">2025-01-01", From: date("2025-01-02"), Pos: 1}},
+		},
+		{
+			name:  "created больше или равно",
+			input: "created:>=2025-01-01",
+			want:  []SearchTerm{{Field: SearchFieldCreated, Value: ">=2025-01-01", From: date("2025-01-01"), Pos: 1}},
+		},
+		{
+			name:  "created меньше",
+			input: "created:<2025-01-01",
+			want:  []SearchTerm{{Field: SearchFieldCreated, Value: "<2025-01-01", To: date("2025-01-01"), Pos: 1}},
+		},
+		{
+			name:  "created меньше или равно",
+			input: "created:<=2025-01-01",
+			want:  []SearchTerm{{Field: SearchFieldCreated, Value: "<=2025-01-01", To: date("2025-01-02"), Pos: 1}},
+		},
+		{
+			name:  "created равно",
+			input: "created:=2025-01-01",
+			want:  []SearchTerm{{Field: SearchFieldCreated, Value: "=2025-01-01", From: date("2025-01-01"), To: date("2025-01-02"), Pos: 1}},
+		},
+		{
+			name:  "created дата",
+			input: "created:2025-01-01",
+			want:  []SearchTerm{{Field: SearchFieldCreated, Value: "2025-01-01", From: date("2025-01-01"), To: date("2025-01-02"), Pos: 1}},
+		},
+		{
+			name:  "created диапазон",
+			input: "-created:2025-01-01..2025-01-31",
+			want:  []SearchTerm{{Field: SearchFieldCreated, Value: "2025-01-01..2025-01-31", From: date("2025-01-01"), To: date("2025-02-01"), Negated: true, Pos: 1}},
+		},
+		{
+			name:  "created диапазон из одного дня",
+			input: "created:2025-01-01..2025-01-01",
+			want:  []SearchTerm{{Field: SearchFieldCreated, Value: "2025-01-01..2025-01-01", From: date("2025-01-01"), To: date("2025-01-02"), Pos: 1}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseSearchQuery(tt.input)
+			if err != nil {
+				t.Fatalf("ParseSearchQuery(%q) error: %v", tt.input, err)
+			}
+			if !reflect.DeepEqual(got.Terms, tt.want) {
+				t.Errorf("ParseSearchQuery(%q) =\n%+v\nwant\n%+v", tt.input, got.Terms, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseSearchQueryErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+
+		// Фрагмент сообщения об ошибке, включая позицию.
+		want string
+	}{
+		{name: "пустой запрос", input: "   ", want: "пустой запрос"},
+		{name: "незакрытая кавычка", input: `go "exact phrase`, want: "незакрытая кавычка на позиции 4"},
+		{name: "незакрытая кавычка значения поля", input: `tag:"база`, want: "незакрытая кавычка на позиции 5"},
+		{name: "пустые кавычки", input: `go ""`, want: "пустое значение в кавычках на позиции 4"},
+		{name: "минус без условия", input: "go - docker", want: `после "-" ожидалось условие на позиции 4`},
+		{name: "неизвестное поле", input: "go foo:bar", want: `неизвестное поле "foo" на позиции 4`},
+		{name: "неизвестное поле с минусом", input: "go -foo:bar", want: `неизвестное поле "foo" на позиции 5`},
+		{name: "пустое значение поля", input: "tag:", want: "пустое значение поля tag на позиции 5"},
+		{name: "неизвестное значение is", input: "is:closed", want: "неизвестное значение is:closed на позиции 4"},
+		{name: "неверная дата", input: "created:>2025-13-01", want: `неверная дата "2025-13-01" на позиции 10`},
+		{name: "неверный конец диапазона", input: "created:2025-01-01..2025-02-30", want: `неверная дата "2025-02-30" на позиции 21`},
+		{name: "обратный диапазон", input: "created:2025-02-01..2025-01-01", want: "конец диапазона раньше начала на позиции 9"},
+		{name: "слишком много условий", input: strings.Repeat("go ", maxSearchQueryTerms+1), want: "не больше 20 условий"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseSearchQuery(tt.input)
+			if !errors.Is(err, ErrInvalidSearchQuery) {
+				t.Fatalf("ParseSearchQuery(%q) error = %v, want ErrInvalidSearchQuery", tt.input, err)
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("ParseSearchQuery(%q) error = %q, want %q", tt.input, err.Error(), tt.want)
+			}
+		})
+	}
+}
+
+// Условие tag: сравнивает теги через translit_key и синонимы, а не точным совпадением имени.
+func TestCompileTagCondition(t *testing.T) {
+	query, err := ParseSearchQuery("tag:вщслук")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	compiled := query.compile("true")
+	if !strings.Contains(compiled.where, "translit_key(t.tag)") || !strings.Contains(compiled.where, "synonyms") {
+		t.Errorf("tag condition does not use tagNameCondition: %s", compiled.where)
+	}
+	if len(compiled.args) != 1 {
+		t.Fatalf("args = %v, want one array of name variants", compiled.args)
+	}
+}
@@ -0,0 +1,150 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"knowledge-base/internal/models"
+	"strconv"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// Настройки поиска похожих вопросов: пороги косинусного сходства триграмм от 0 до 1.
+type SimilarSettings struct {
+	// Сходство, с которого новый вопрос считается дубликатом существующего.
+	DuplicateThreshold float64
+
+	// Минимальное сходство для списка похожих вопросов.
+	RelatedThreshold float64
+
+	// Количество похожих вопросов по умолчанию.
+	Limit int
+}
+
+// Настройки по умолчанию.
+var similarSettings = SimilarSettings{
+	DuplicateThreshold: 0.8,
+	RelatedThreshold:   0.3,
+	Limit:              10,
+}
+
+// SetSimilarSettings задает настройки поиска похожих вопросов. Значения вне диапазона (0, 1] не меняют настройку.
+func SetSimilarSettings(settings SimilarSettings) {
+	if settings.DuplicateThreshold > 0 && settings.DuplicateThreshold <= 1 {
+		similarSettings.DuplicateThreshold = settings.DuplicateThreshold
+	}
+	if settings.RelatedThreshold > 0 && settings.RelatedThreshold <= 1 {
+		similarSettings.RelatedThreshold = settings.RelatedThreshold
+	}
+	if settings.Limit > 0 {
+		similarSettings.Limit = settings.Limit
+	}
+}
+
+// FindDuplicates возвращает вопросы, которые похожи на текст не меньше порога дубликатов, самые похожие сначала.
+// Без расширения pg_trgm дубликаты не ищутся.
+func (questionService *QuestionService) FindDuplicates(ctx context.Context, questionText string) ([]models.SimilarQuestion, error) {
+	if !trigramEnabled {
+		return nil, nil
+	}
+
+	// Ограничение времени выполнения запроса.
+	ctx, finish := beginQuery(ctx, "QuestionService.FindDuplicates")
+	defer finish()
+
+	duplicates, err := similarQuestions(ctx, questionService.db, questionText, 0, similarSettings.DuplicateThreshold, similarSettings.Limit)
+	if err != nil {
+		return nil, queryError(ctx, err)
+	}
+
+	recordRows(ctx, len(duplicates))
+
+	return duplicates, nil
+}
+
+// GetSimilar возвращает вопросы, похожие на вопрос id, самые похожие сначала. Если вопроса нет, возвращается sql.ErrNoRows.
+// limit <= 0 - количество по умолчанию. Без расширения pg_trgm возвращается ErrTrigramUnavailable.
+func (questionService *QuestionService) GetSimilar(ctx context.Context, id int, limit int) ([]models.SimilarQuestion, error) {
+	if err := requireTrigram(); err != nil {
+		return nil, err
+	}
+
+	// Ограничение времени выполнения запроса.
+	ctx, finish := beginQuery(ctx, "QuestionService.GetSimilar", attribute.Int("question.id", id))
+	defer finish()
+
+	var questionText string
+	err := questionService.db.QueryRowContext(ctx, `select question_text from questions where id = $1 and `+visibleQuestion(ctx, "questions"), id).Scan(&questionText)
+	if err != nil {
+		return nil, queryError(ctx, err)
+	}
+
+	if limit <= 0 {
+		limit = similarSettings.Limit
+	}
+
+	similar, err := similarQuestions(ctx, questionService.db, questionText, id, similarSettings.RelatedThreshold, limit)
+	if err != nil {
+		return nil, queryError(ctx, err)
+	}
+
+	recordRows(ctx, len(similar))
+
+	return similar, nil
+}
+
+// Порог similarity для отбора кандидатов trigram индексом при пороге косинуса threshold.
+// similarity - это c/(a+b-c), где a и b - триграммы текстов, c - общие. Она не меньше квадрата косинуса c²/(ab),
+// потому что ab - c(a+b-c) = (a-c)(b-c) >= 0. Поэтому вопросы с косинусом не меньше threshold не теряются
+// при любой разнице в длине текстов ("в Go" и "в Golang").
+func similarityPrefilter(threshold float64) float64 {
+	return threshold * threshold
+}
+
+// Вопросы, сходство которых с текстом не меньше threshold, кроме вопроса excludeID.
+// Кандидаты отбираются trigram индексом по similarity с порогом similarityPrefilter(threshold).
+func similarQuestions(ctx context.Context, db *sql.DB, questionText string, excludeID int, threshold float64, limit int) ([]models.SimilarQuestion, error) {
+
+	// Порог оператора % задается только на время транзакции, чтобы работал trigram индекс.
+	tx, err := db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return nil, err
+	}
+
+	// Откат, если транзакция не была зафиксирована.
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `select set_config('pg_trgm.similarity_threshold', $1, true)`,
+		strconv.FormatFloat(similarityPrefilter(threshold), 'f', -1, 64))
+	if err != nil {
+		return nil, err
+	}
+
+	var query string = `select id, question_text, tutor_id, created_at, is_edit, status, score
+		from (
+			select q.id, q.question_text, q.tutor_id, q.created_at, q.is_edit, q.status, trgm_cosine(lower(q.question_text), lower($1)) as score
+			from questions q
+			where lower(q.question_text) % lower($1) and q.id <> $2 and ` + visibleQuestion(ctx, "q") + `
+		) candidates
+		where score >= $3
+		order by score desc, id
+		limit $4`
+
+	rows, err := tx.QueryContext(ctx, query, questionText, excludeID, threshold, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	// Пустой список возвращается как [], а не null.
+	similar := []models.SimilarQuestion{}
+	for rows.Next() {
+		var match models.SimilarQuestion
+		question := &match.Question
+		if err := rows.Scan(&question.ID, &question.QuestionText, &question.TutorID, &question.CreatedAt, &question.IsEdit, &question.Status, &match.Score); err != nil {
+			return nil, err
+		}
+		similar = append(similar, match)
+	}
+	return similar, rows.Err()
+}
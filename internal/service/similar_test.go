@@ -0,0 +1,48 @@
+package service
+
+import (
+	"math"
+	"testing"
+)
+
+// Косинус триграмм, как trgm_cosine из migrations/008_similar_questions.sql, и similarity из pg_trgm.
+func trigramScores(a string, b string) (cosine float64, similarity float64) {
+	setA, setB := trigramSet(a), trigramSet(b)
+	common := 0
+	for trigram := range setA {
+		if setB[trigram] {
+			common++
+		}
+	}
+	cosine = float64(common) / math.Sqrt(float64(len(setA)*len(setB)))
+	similarity = float64(common) / float64(len(setA)+len(setB)-common)
+	return cosine, similarity
+}
+
+// Дубликаты разной длины, которые находит косинус, не должны отсеиваться trigram индексом.
+func TestSimilarityPrefilter(t *testing.T) {
+	tests := []struct {
+		text      string
+		question  string
+		threshold float64
+	}{
+		// Вопросы из migrations/002_seed_data.sql, порог - SIMILAR_DUPLICATE_THRESHOLD по умолчанию.
+		{text: "Что такое индексы в базах данных?", question: "Что такое индексы в базах данных и зачем они нужны?", threshold: 0.8},
+		{text: "Как реализовать аутентификацию?", question: "Как реализовать аутентификацию в веб-приложении?", threshold: 0.8},
+		{text: "Разница между INNER JOIN?", question: "В чем разница между INNER JOIN и LEFT JOIN?", threshold: 0.8},
+		{text: "в Go", question: "в Golang", threshold: 0.59},
+		{text: "PostgreSQL Golang", question: "Как настроить соединение с PostgreSQL в Golang?", threshold: 0.3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			cosine, similarity := trigramScores(tt.text, tt.question)
+			if cosine < tt.threshold {
+				t.Fatalf("cosine = %.3f, below threshold %.2f: not a duplicate", cosine, tt.threshold)
+			}
+			if prefilter := similarityPrefilter(tt.threshold); similarity < prefilter {
+				t.Errorf("similarity = %.3f < prefilter %.3f: duplicate with cosine %.3f is dropped", similarity, prefilter, cosine)
+			}
+		})
+	}
+}
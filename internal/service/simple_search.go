@@ -1,9 +1,13 @@
 package service
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"knowledge-base/internal/models"
+	"slices"
+
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // Структура для работы со всеми ф-ями service/simple_search.go.
@@ -16,32 +20,69 @@ func NewSimpleSearchService(db *sql.DB) *SimpleSearchService {
 	return &SimpleSearchService{db: db}
 }
 
-func (simpleSearchService SimpleSearchService) SearchLogic(name string) ([]models.Question, error) {
-	var query string = `select q.*
-        from public.questions q
-        inner join public.questions_tags qt on q.id = qt.question_id
-        inner join public.tags t on qt.tag_id = t.id
-        where lower(trim(t.tag)) = lower(trim($1))
-        order by q.created_at desc`
+// SearchLogic ищет вопросы с тегом name. Если таких нет, возвращает похожие имена тегов ("возможно, вы имели в виду")
+// и вопросы с самым похожим из них.
+func (simpleSearchService SimpleSearchService) SearchLogic(ctx context.Context, name string) ([]models.Question, []string, error) {
+
+	// Ограничение времени выполнения запроса.
+	ctx, finish := beginQuery(ctx, "SimpleSearchService.SearchLogic", attribute.String("tag.name", name))
+	defer finish()
 
-	rows, err := simpleSearchService.db.Query(query, name)
+	questions, err := simpleSearchService.questionsByTag(ctx, name)
 	if err != nil {
-		return nil, fmt.Errorf("ошибка поиска: %v", err)
+		return nil, nil, err
 	}
-	defer rows.Close()
 
-	var questions []models.Question
-	for rows.Next() {
-		var question models.Question
-		err := rows.Scan(
-			&question.ID, &question.QuestionText,
-			&question.TutorID, &question.CreatedAt, &question.IsEdit)
+	// Ничего не найдено - вероятно, в имени тега опечатка. Подсказки те же, что в нечетком поиске (FuzzySearch).
+	var suggestions []string
+	if len(questions) == 0 {
+		suggestions, err = suggestTags(ctx, simpleSearchService.db, name)
+		if err != nil {
+			return nil, nil, queryError(ctx, err)
+		}
+
+		// Тег с тем же именем есть, но вопросов с ним нет - подсказывать его бессмысленно.
+		keys := make(map[string]bool)
+		for _, variant := range tagNameVariants(name) {
+			keys[translitKey(variant)] = true
+		}
+		suggestions = slices.DeleteFunc(suggestions, func(suggestion string) bool { return keys[translitKey(suggestion)] })
+	}
+	if len(suggestions) > 0 {
+		questions, err = simpleSearchService.questionsByTag(ctx, suggestions[0])
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
-		questions = append(questions, question)
 	}
 
-	return questions, nil
+	recordRows(ctx, len(questions))
+
+	return questions, suggestions, nil
+}
+
+// Вопросы с тегом name.
+func (simpleSearchService SimpleSearchService) questionsByTag(ctx context.Context, name string) ([]models.Question, error) {
 
+	// Тег сравнивается по ключу транслитерации с учетом раскладки и синонимов, как в TagService.GetByName:
+	// "докер", "docker" и "вщслук" находят вопросы с тегом docker, "бд" - с тегом "база данных".
+	// Вопросы ищет движок поиска из настройки SEARCH_BACKEND.
+	ids, err := searchEngineFor(ctx, simpleSearchService.db).QuestionsByTag(ctx, name)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка поиска: %w", queryError(ctx, err))
+	}
+
+	found, err := questionsByIDs(ctx, simpleSearchService.db, ids)
+	if err != nil {
+		return nil, queryError(ctx, err)
+	}
+
+	// Вопросы в порядке движка, новые сначала.
+	var questions []models.Question
+	for _, id := range ids {
+		if question, ok := found[id]; ok {
+			questions = append(questions, question)
+		}
+	}
+
+	return questions, nil
 }
@@ -0,0 +1,257 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"knowledge-base/internal/models"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/lib/pq"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// Ограничения группы синонимов.
+const (
+	maxSynonymTerms      = 20
+	maxSynonymTermLength = 50
+)
+
+// Ошибка проверки терминов группы синонимов.
+var ErrInvalidSynonyms = errors.New("неверная группа синонимов")
+
+// Термин уже входит в другую группу синонимов.
+var ErrSynonymExists = errors.New("термин уже входит в другую группу синонимов")
+
+// Структура для работы со всеми ф-ями service/synonym.go.
+type SynonymService struct {
+	db *sql.DB
+}
+
+// Функция для создания объекта типа SynonymService.
+func NewSynonymService(db *sql.DB) *SynonymService {
+	return &SynonymService{db: db}
+}
+
+// Запрос групп с терминами в порядке добавления.
+const synonymGroupsQuery = `select g.id, array_agg(s.term order by s.id)
+	from synonym_groups g join synonyms s on s.group_id = g.id`
+
+func (synonymService *SynonymService) GetAll(ctx context.Context) ([]models.SynonymGroup, error) {
+
+	// Ограничение времени выполнения запроса.
+	ctx, finish := beginQuery(ctx, "SynonymService.GetAll")
+	defer finish()
+
+	// Выполнение функции, которая проводит sql запрос и возвращает таблицу с несколькими строками.
+	rows, err := synonymService.db.QueryContext(ctx, synonymGroupsQuery+` group by g.id order by g.id`)
+	if err != nil {
+		return nil, queryError(ctx, err)
+	}
+
+	// Закрытие, чтобы не было утечки соединений, надо закрыть.
+	defer rows.Close()
+
+	// Запись полученных данных из БД в массив формата []models.SynonymGroup.
+	groups := []models.SynonymGroup{}
+	for rows.Next() {
+		var group models.SynonymGroup
+		if err := rows.Scan(&group.ID, pq.Array(&group.Terms)); err != nil {
+			return nil, queryError(ctx, err)
+		}
+		groups = append(groups, group)
+	}
+
+	// Проверка ошибки, которая могла прервать чтение строк.
+	if err := rows.Err(); err != nil {
+		return nil, queryError(ctx, err)
+	}
+
+	recordRows(ctx, len(groups))
+
+	return groups, nil
+}
+
+// GetByID возвращает группу синонимов. Если группы нет, возвращается sql.ErrNoRows.
+func (synonymService *SynonymService) GetByID(ctx context.Context, id int) (models.SynonymGroup, error) {
+
+	// Ограничение времени выполнения запроса.
+	ctx, finish := beginQuery(ctx, "SynonymService.GetByID", attribute.Int("synonym_group.id", id))
+	defer finish()
+
+	group, err := synonymGroup(ctx, synonymService.db, id)
+	if err != nil {
+		return models.SynonymGroup{}, queryError(ctx, err)
+	}
+	return group, nil
+}
+
+// Create создает группу синонимов. Если термин уже входит в другую группу, возвращается ErrSynonymExists.
+func (synonymService *SynonymService) Create(ctx context.Context, terms []string) (models.SynonymGroup, error) {
+
+	// Ограничение времени выполнения запроса.
+	ctx, finish := beginQuery(ctx, "SynonymService.Create")
+	defer finish()
+
+	terms, err := normalizeSynonymTerms(terms)
+	if err != nil {
+		return models.SynonymGroup{}, err
+	}
+
+	tx, err := synonymService.db.BeginTx(ctx, nil)
+	if err != nil {
+		return models.SynonymGroup{}, queryError(ctx, err)
+	}
+
+	// Откат, если транзакция не была зафиксирована.
+	defer tx.Rollback()
+
+	var id int
+	err = tx.QueryRowContext(ctx, `insert into synonym_groups default values returning id`).Scan(&id)
+	if err != nil {
+		return models.SynonymGroup{}, queryError(ctx, err)
+	}
+
+	if err := insertSynonyms(ctx, tx, id, terms); err != nil {
+		return models.SynonymGroup{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return models.SynonymGroup{}, queryError(ctx, err)
+	}
+
+	return models.SynonymGroup{ID: id, Terms: terms}, nil
+}
+
+// Replace заменяет термины группы. Если группы нет, возвращается sql.ErrNoRows,
+// если термин уже входит в другую группу - ErrSynonymExists.
+func (synonymService *SynonymService) Replace(ctx context.Context, id int, terms []string) (models.SynonymGroup, error) {
+
+	// Ограничение времени выполнения запроса.
+	ctx, finish := beginQuery(ctx, "SynonymService.Replace", attribute.Int("synonym_group.id", id))
+	defer finish()
+
+	terms, err := normalizeSynonymTerms(terms)
+	if err != nil {
+		return models.SynonymGroup{}, err
+	}
+
+	tx, err := synonymService.db.BeginTx(ctx, nil)
+	if err != nil {
+		return models.SynonymGroup{}, queryError(ctx, err)
+	}
+
+	// Откат, если транзакция не была зафиксирована.
+	defer tx.Rollback()
+
+	// Блокировка группы, чтобы параллельные замены выполнялись по очереди.
+	err = tx.QueryRowContext(ctx, `select id from synonym_groups where id = $1 for update`, id).Scan(&id)
+	if err != nil {
+		return models.SynonymGroup{}, queryError(ctx, err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `delete from synonyms where group_id = $1`, id); err != nil {
+		return models.SynonymGroup{}, queryError(ctx, err)
+	}
+
+	if err := insertSynonyms(ctx, tx, id, terms); err != nil {
+		return models.SynonymGroup{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return models.SynonymGroup{}, queryError(ctx, err)
+	}
+
+	return models.SynonymGroup{ID: id, Terms: terms}, nil
+}
+
+// DeleteByID удаляет группу вместе с терминами. Если группы нет, возвращается sql.ErrNoRows.
+func (synonymService *SynonymService) DeleteByID(ctx context.Context, id int) error {
+
+	// Ограничение времени выполнения запроса.
+	ctx, finish := beginQuery(ctx, "SynonymService.DeleteByID", attribute.Int("synonym_group.id", id))
+	defer finish()
+
+	result, err := synonymService.db.ExecContext(ctx, `delete from synonym_groups where id = $1`, id)
+	if err != nil {
+		return queryError(ctx, err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return queryError(ctx, err)
+	}
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// Группа синонимов по ID.
+func synonymGroup(ctx context.Context, db *sql.DB, id int) (models.SynonymGroup, error) {
+	group := models.SynonymGroup{}
+	err := db.QueryRowContext(ctx, synonymGroupsQuery+` where g.id = $1 group by g.id`, id).Scan(&group.ID, pq.Array(&group.Terms))
+	return group, err
+}
+
+// Добавление терминов в группу. Нарушение уникальности термина означает, что он уже есть в другой группе.
+func insertSynonyms(ctx context.Context, tx *sql.Tx, groupID int, terms []string) error {
+	_, err := tx.ExecContext(ctx, `insert into synonyms (group_id, term) select $1, unnest($2::text[])`, groupID, pq.Array(terms))
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+		return ErrSynonymExists
+	}
+	if err != nil {
+		return queryError(ctx, err)
+	}
+	return nil
+}
+
+// Проверка терминов: пробелы по краям убираются, повторы без учета регистра пропускаются,
+// в группе должно остаться от 2 до maxSynonymTerms терминов.
+func normalizeSynonymTerms(terms []string) ([]string, error) {
+	seen := make(map[string]bool, len(terms))
+	normalized := make([]string, 0, len(terms))
+
+	for _, term := range terms {
+		term = strings.TrimSpace(term)
+		if term == "" {
+			return nil, fmt.Errorf("%w: пустой термин", ErrInvalidSynonyms)
+		}
+		if utf8.RuneCountInString(term) > maxSynonymTermLength {
+			return nil, fmt.Errorf("%w: термин %q длиннее %d символов", ErrInvalidSynonyms, term, maxSynonymTermLength)
+		}
+
+		key := strings.ToLower(term)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		normalized = append(normalized, term)
+	}
+
+	if len(normalized) < 2 {
+		return nil, fmt.Errorf("%w: нужно хотя бы два разных термина", ErrInvalidSynonyms)
+	}
+	if len(normalized) > maxSynonymTerms {
+		return nil, fmt.Errorf("%w: не больше %d терминов", ErrInvalidSynonyms, maxSynonymTerms)
+	}
+
+	return normalized, nil
+}
+
+// Условие на тег t.tag: совпадает по ключу транслитерации с одним из вариантов имени (массив text[] в placeholder)
+// или с синонимом любого из них. Синонимы читаются при каждом запросе, поэтому изменения словаря действуют сразу.
+func tagNameCondition(placeholder string) string {
+	return fmt.Sprintf(`translit_key(t.tag) in (
+			select translit_key(v) from unnest(%[1]s::text[]) v
+			union
+			select translit_key(g.term)
+			from synonyms s join synonyms g on g.group_id = s.group_id
+			where translit_key(s.term) in (select translit_key(v) from unnest(%[1]s::text[]) v)
+		)`, placeholder)
+}
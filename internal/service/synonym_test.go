@@ -0,0 +1,55 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestNormalizeSynonymTerms(t *testing.T) {
+	many := make([]string, 0, maxSynonymTerms+1)
+	for i := 0; i <= maxSynonymTerms; i++ {
+		many = append(many, fmt.Sprintf("term%d", i))
+	}
+
+	tests := []struct {
+		name  string
+		terms []string
+		want  []string
+
+		// Фрагмент ошибки, пустой - ошибки нет.
+		err string
+	}{
+		{name: "группа", terms: []string{"k8s", "kubernetes", "кубер"}, want: []string{"k8s", "kubernetes", "кубер"}},
+		{name: "пробелы по краям", terms: []string{" бд ", "база данных\t"}, want: []string{"бд", "база данных"}},
+		{name: "повторы без учета регистра", terms: []string{"Docker", "docker", "докер", "ДОКЕР"}, want: []string{"Docker", "докер"}},
+		{name: "максимум терминов", terms: many[:maxSynonymTerms], want: many[:maxSynonymTerms]},
+		{name: "термин максимальной длины", terms: []string{strings.Repeat("я", maxSynonymTermLength), "a"}, want: []string{strings.Repeat("я", maxSynonymTermLength), "a"}},
+		{name: "пустой список", terms: nil, err: "нужно хотя бы два разных термина"},
+		{name: "один термин", terms: []string{"go"}, err: "нужно хотя бы два разных термина"},
+		{name: "один термин дважды", terms: []string{"Go", "go"}, err: "нужно хотя бы два разных термина"},
+		{name: "пустой термин", terms: []string{"go", "  "}, err: "пустой термин"},
+		{name: "слишком длинный термин", terms: []string{"go", strings.Repeat("я", maxSynonymTermLength+1)}, err: "длиннее 50 символов"},
+		{name: "слишком много терминов", terms: many, err: "не больше 20 терминов"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := normalizeSynonymTerms(tt.terms)
+			if tt.err != "" {
+				if !errors.Is(err, ErrInvalidSynonyms) || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("normalizeSynonymTerms(%q) error = %v, want ErrInvalidSynonyms with %q", tt.terms, err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("normalizeSynonymTerms(%q) error = %v", tt.terms, err)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("normalizeSynonymTerms(%q) = %q, want %q", tt.terms, got, tt.want)
+			}
+		})
+	}
+}
@@ -1,9 +1,13 @@
 package service
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"knowledge-base/internal/models"
+
+	"github.com/lib/pq"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // Структура для работы со всеми ф-ями service/tag.go.
@@ -16,15 +20,31 @@ func NewTagService(db *sql.DB) *TagService {
 	return &TagService{db: db}
 }
 
-func (tagService *TagService) GetAll() ([]models.Tag, error) {
+func (tagService *TagService) GetAll(ctx context.Context, params ListParams) ([]models.Tag, int, error) {
+
+	// Ограничение времени выполнения запроса.
+	ctx, finish := beginQuery(ctx, "TagService.GetAll")
+	defer finish()
+
+	// Условия фильтрации и сортировки из параметров списка.
+	where, args := TagListSpec.whereClause(params)
+	orderBy := TagListSpec.orderClause(params)
+	page, pageArgs := TagListSpec.pageClause(params, len(args))
+
+	// Общее количество записей с учетом фильтров, без учета страницы.
+	var total int
+	err := tagService.db.QueryRowContext(ctx, `select count(*) from tags`+where, args...).Scan(&total)
+	if err != nil {
+		return nil, 0, queryError(ctx, err)
+	}
 
 	//Создание sql запроса для получения данных по всем тегам.
-	var query string = `select id, tutor_id, tag from tags order by id`
+	var query string = `select id, tutor_id, tag from tags` + where + orderBy + page
 
 	// Выполнение функции, которая проводит sql запрос и возвращает таблицу с несколькими строками.
-	rows, err := tagService.db.Query(query)
+	rows, err := tagService.db.QueryContext(ctx, query, append(args, pageArgs...)...)
 	if err != nil {
-		return nil, err
+		return nil, 0, queryError(ctx, err)
 	}
 
 	// Закрытие, чтобы не было утечки соединений, надо закрыть.
@@ -37,78 +57,159 @@ func (tagService *TagService) GetAll() ([]models.Tag, error) {
 		var tag models.Tag
 		err := rows.Scan(&tag.ID, &tag.TutorID, &tag.Tag)
 		if err != nil {
-			return nil, err
+			return nil, 0, queryError(ctx, err)
 		}
 		tags = append(tags, tag)
 	}
 
-	return tags, nil
+	// Проверка ошибки, которая могла прервать чтение строк.
+	if err := rows.Err(); err != nil {
+		return nil, 0, queryError(ctx, err)
+	}
+
+	recordRows(ctx, len(tags))
+
+	return tags, total, nil
+}
+
+// Export читает все записи с учетом фильтров и сортировки и передает их по одной в fn, не собирая в память.
+// Если fn возвращает ошибку, чтение прекращается и ошибка возвращается как есть.
+func (tagService *TagService) Export(ctx context.Context, params ListParams, fn func(models.Tag) error) error {
+
+	// Ограничение времени выгрузки.
+	ctx, finish := beginExport(ctx, "TagService.Export")
+	defer finish()
+
+	// Условия фильтрации и сортировки из параметров списка, без страницы.
+	where, args := TagListSpec.whereClause(params.withoutCursor())
+	orderBy := TagListSpec.orderClause(params.withoutCursor())
+
+	//Создание sql запроса для выгрузки всех тегов.
+	var query string = `select id, tutor_id, tag from tags` + where + orderBy
+
+	// Выполнение функции, которая проводит sql запрос и возвращает таблицу с несколькими строками.
+	rows, err := tagService.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return queryError(ctx, err)
+	}
+
+	// Закрытие, чтобы не было утечки соединений, надо закрыть.
+	defer rows.Close()
+
+	// Каждая строка сразу передается дальше, в памяти хранится только одна.
+	var count int
+	for rows.Next() {
+		var tag models.Tag
+		err := rows.Scan(&tag.ID, &tag.TutorID, &tag.Tag)
+		if err != nil {
+			return queryError(ctx, err)
+		}
+		if err := fn(tag); err != nil {
+			return err
+		}
+		count++
+	}
+
+	// Проверка ошибки, которая могла прервать чтение строк.
+	if err := rows.Err(); err != nil {
+		return queryError(ctx, err)
+	}
+
+	recordRows(ctx, count)
+
+	return nil
 }
 
-func (tagService *TagService) GetByID(id int) (models.Tag, error) {
+func (tagService *TagService) GetByID(ctx context.Context, id int) (models.Tag, error) {
+
+	// Ограничение времени выполнения запроса.
+	ctx, finish := beginQuery(ctx, "TagService.GetByID", attribute.Int("tag.id", id))
+	defer finish()
 
 	//Создание sql запроса для получения данных по одному конкретному тегу.
 	var query string = `select id, tutor_id, tag from tags where id = $1`
 
 	// Выполнение функции, которая проводит sql запрос и возвращает таблицу из одной строки.
-	row := tagService.db.QueryRow(query, id)
+	row := tagService.db.QueryRowContext(ctx, query, id)
 
 	var tag models.Tag
 
 	// Запись полученных данных из БД в перемнную типа models.Tag.
 	err := row.Scan(&tag.ID, &tag.TutorID, &tag.Tag)
 	if err != nil {
-		return models.Tag{}, err
+		return models.Tag{}, queryError(ctx, err)
 	}
 
 	return tag, nil
 }
 
-func (tagService *TagService) GetByName(name string) (models.Tag, error) {
+func (tagService *TagService) GetByName(ctx context.Context, name string) (models.Tag, error) {
+
+	// Ограничение времени выполнения запроса.
+	ctx, finish := beginQuery(ctx, "TagService.GetByName", attribute.String("tag.name", name))
+	defer finish()
 
 	//Создание sql запроса для получения данных по одному конкретному тегу.
-	var query string = `select id, tutor_id, tag from tags where lower(trim(tag)) = lower(trim($1))`
+	// Имя сравнивается по ключу транслитерации с учетом раскладки и синонимов: "докер" и "вщслук" находят тег docker, "k8s" - kubernetes.
+	// Если подходит несколько тегов, первым берется совпавший как введено, затем совпавший без смены раскладки, затем синонимы.
+	var query string = `select t.id, t.tutor_id, t.tag from tags t
+		where ` + tagNameCondition("$1") + `
+		order by lower(trim(t.tag)) = $2 desc, translit_key(t.tag) = translit_key($2) desc, t.id
+		limit 1`
 
 	// Выполнение функции, которая проводит sql запрос и возвращает таблицу из одной строки.
-	row := tagService.db.QueryRow(query, name)
+	variants := tagNameVariants(name)
+	row := tagService.db.QueryRowContext(ctx, query, pq.Array(variants), variants[0])
 
 	var tag models.Tag
 
 	// Запись полученных данных из БД в перемнную типа models.Tag.
 	err := row.Scan(&tag.ID, &tag.TutorID, &tag.Tag)
 	if err != nil {
-		return models.Tag{}, err
+		return models.Tag{}, queryError(ctx, err)
 	}
 
 	return tag, nil
 }
 
-func (tagService *TagService) DeleteByID(id int) error {
+func (tagService *TagService) DeleteByID(ctx context.Context, id int) error {
+
+	// Ограничение времени выполнения запроса.
+	ctx, finish := beginQuery(ctx, "TagService.DeleteByID", attribute.Int("tag.id", id))
+	defer finish()
 
 	//Создание sql запроса для удаления данных одного кокретного тега.
 	var query string = `delete from tags where id = $1`
 
 	// Выполнение функции, которая проводит sql запрос без возврата данных.
-	result, err := tagService.db.Exec(query, id)
+	result, err := tagService.db.ExecContext(ctx, query, id)
 	if err != nil {
-		return err
+		return queryError(ctx, err)
 	}
 
 	// Выполнение функции, которая возаращает количество удаленных строк.
 	rowsAffected, err := result.RowsAffected()
 	if err != nil {
-		return err
+		return queryError(ctx, err)
 	}
 
+	recordRows(ctx, int(rowsAffected))
+
 	//Проверка было ли удаление строки. Если rowsAffected = 0, то не было.
 	if rowsAffected == 0 {
 		return fmt.Errorf("tag with id %d not found", id)
 	}
 
+	notifyTagDeleted(ctx, id)
+
 	return nil
 }
 
-func (tagService *TagService) PostString(tag string, tutorID *int) (int, error) {
+func (tagService *TagService) PostString(ctx context.Context, tag string, tutorID *int) (int, error) {
+
+	// Ограничение времени выполнения запроса.
+	ctx, finish := beginQuery(ctx, "TagService.PostString")
+	defer finish()
 
 	//Создание sql запроса для появления новой записи в таблице тегов.
 	var query string = `insert into tags (tag, tutor_id) values
@@ -117,12 +218,12 @@ func (tagService *TagService) PostString(tag string, tutorID *int) (int, error)
 	var id int
 
 	// Выполнение функции, которая проводит sql запрос и возвращает таблицу из одной строки.
-	row := tagService.db.QueryRow(query, tag, tutorID)
+	row := tagService.db.QueryRowContext(ctx, query, tag, tutorID)
 
 	// Получение id созданной записи.
 	err := row.Scan(&id)
 	if err != nil {
-		return 0, err
+		return 0, queryError(ctx, err)
 	}
 
 	return id, nil
@@ -0,0 +1,318 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/lib/pq"
+)
+
+// Максимальное количество тегов в одном условии, чтобы запрос к БД оставался небольшим.
+const maxTagQueryTags = 20
+
+// Ошибка разбора условия по тегам.
+var ErrInvalidTagQuery = errors.New("неверное условие по тегам")
+
+// Условие по тегам: дерево из тегов и операций И, ИЛИ, НЕ.
+type TagQuery interface {
+	// Подзапрос, который возвращает question_id подходящих вопросов. args пополняется значениями плейсхолдеров.
+	sql(args *[]interface{}) string
+}
+
+// Вопросы с тегом.
+type tagLeaf struct {
+	Tag string
+}
+
+// Вопросы, подходящие под все условия.
+type tagAnd struct {
+	Items []TagQuery
+}
+
+// Вопросы, подходящие хотя бы под одно условие.
+type tagOr struct {
+	Items []TagQuery
+}
+
+// Вопросы, не подходящие под условие.
+type tagNot struct {
+	Item TagQuery
+}
+
+// Тег сравнивается так же, как в поиске по тегу: в любой раскладке и алфавите и с синонимами ("k8s" - kubernetes).
+func (leaf tagLeaf) sql(args *[]interface{}) string {
+	*args = append(*args, pq.Array(tagNameVariants(leaf.Tag)))
+	return `select qt.question_id from questions_tags qt join tags t on t.id = qt.tag_id where ` + tagNameCondition(fmt.Sprintf("$%d", len(*args)))
+}
+
+func (and tagAnd) sql(args *[]interface{}) string {
+	return joinTagQueries(and.Items, " intersect ", args)
+}
+
+func (or tagOr) sql(args *[]interface{}) string {
+	return joinTagQueries(or.Items, " union ", args)
+}
+
+func (not tagNot) sql(args *[]interface{}) string {
+	return `select id as question_id from questions except (` + not.Item.sql(args) + `)`
+}
+
+// Объединение подзапросов операцией над множествами.
+func joinTagQueries(items []TagQuery, op string, args *[]interface{}) string {
+	parts := make([]string, 0, len(items))
+	for _, item := range items {
+		parts = append(parts, "("+item.sql(args)+")")
+	}
+	return strings.Join(parts, op)
+}
+
+// TagQueryFromLists строит условие из списков: все теги all, хотя бы один из anyOf и ни одного из none.
+// Если задан только none, подходят все вопросы без этих тегов.
+func TagQueryFromLists(all []string, anyOf []string, none []string) (TagQuery, error) {
+	if len(all)+len(anyOf)+len(none) == 0 {
+		return nil, fmt.Errorf("%w: нужен хотя бы один тег", ErrInvalidTagQuery)
+	}
+	if len(all)+len(anyOf)+len(none) > maxTagQueryTags {
+		return nil, fmt.Errorf("%w: не больше %d тегов", ErrInvalidTagQuery, maxTagQueryTags)
+	}
+
+	var items []TagQuery
+	for _, tag := range all {
+		items = append(items, tagLeaf{Tag: tag})
+	}
+	if len(anyOf) > 0 {
+		items = append(items, tagOr{Items: tagLeaves(anyOf)})
+	}
+	if len(none) > 0 {
+		items = append(items, tagNot{Item: tagOr{Items: tagLeaves(none)}})
+	}
+
+	if len(items) == 1 {
+		return items[0], nil
+	}
+	return tagAnd{Items: items}, nil
+}
+
+// Условия на каждый тег из списка.
+func tagLeaves(tags []string) []TagQuery {
+	leaves := make([]TagQuery, 0, len(tags))
+	for _, tag := range tags {
+		leaves = append(leaves, tagLeaf{Tag: tag})
+	}
+	return leaves
+}
+
+// ParseTagQuery разбирает выражение вида `go AND (postgres OR mysql) AND NOT orm`.
+// Операции: AND, OR, NOT (регистр не важен) или &, |, !. Приоритет: NOT, затем AND, затем OR.
+// Теги с пробелами и словами-операциями записываются в кавычках: "machine learning".
+func ParseTagQuery(expr string) (TagQuery, error) {
+	tokens, err := tokenizeTagQuery(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	parser := tagQueryParser{tokens: tokens}
+	query, err := parser.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if token := parser.peek(); token.kind != tagTokenEnd {
+		return nil, fmt.Errorf("%w: лишний %s на позиции %d", ErrInvalidTagQuery, token, token.pos)
+	}
+	if parser.tags > maxTagQueryTags {
+		return nil, fmt.Errorf("%w: не больше %d тегов", ErrInvalidTagQuery, maxTagQueryTags)
+	}
+
+	return query, nil
+}
+
+// Виды лексем выражения.
+type tagTokenKind int
+
+const (
+	tagTokenEnd tagTokenKind = iota
+	tagTokenTag
+	tagTokenAnd
+	tagTokenOr
+	tagTokenNot
+	tagTokenOpen
+	tagTokenClose
+)
+
+// Лексема выражения и ее позиция (с 1) для сообщений об ошибках.
+type tagToken struct {
+	kind  tagTokenKind
+	value string
+	pos   int
+}
+
+func (token tagToken) String() string {
+	switch token.kind {
+	case tagTokenEnd:
+		return "конец выражения"
+	case tagTokenTag:
+		return fmt.Sprintf("тег %q", token.value)
+	}
+	return fmt.Sprintf("%q", token.value)
+}
+
+// Разбиение выражения на лексемы.
+func tokenizeTagQuery(expr string) ([]tagToken, error) {
+	var tokens []tagToken
+	runes := []rune(expr)
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		pos := i + 1
+
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			tokens = append(tokens, tagToken{kind: tagTokenOpen, value: "(", pos: pos})
+			i++
+		case r == ')':
+			tokens = append(tokens, tagToken{kind: tagTokenClose, value: ")", pos: pos})
+			i++
+		case r == '&':
+			tokens = append(tokens, tagToken{kind: tagTokenAnd, value: "&", pos: pos})
+			i++
+		case r == '|':
+			tokens = append(tokens, tagToken{kind: tagTokenOr, value: "|", pos: pos})
+			i++
+		case r == '!':
+			tokens = append(tokens, tagToken{kind: tagTokenNot, value: "!", pos: pos})
+			i++
+		case r == '"':
+			// Тег в кавычках до закрывающей кавычки.
+			end := i + 1
+			for end < len(runes) && runes[end] != '"' {
+				end++
+			}
+			if end == len(runes) {
+				return nil, fmt.Errorf("%w: незакрытая кавычка на позиции %d", ErrInvalidTagQuery, pos)
+			}
+			tag := strings.TrimSpace(string(runes[i+1 : end]))
+			if tag == "" {
+				return nil, fmt.Errorf("%w: пустой тег на позиции %d", ErrInvalidTagQuery, pos)
+			}
+			tokens = append(tokens, tagToken{kind: tagTokenTag, value: tag, pos: pos})
+			i = end + 1
+		default:
+			// Слово до пробела, скобки или символа операции.
+			end := i
+			for end < len(runes) && !unicode.IsSpace(runes[end]) && !strings.ContainsRune(`()&|!"`, runes[end]) {
+				end++
+			}
+			word := string(runes[i:end])
+			switch strings.ToUpper(word) {
+			case "AND":
+				tokens = append(tokens, tagToken{kind: tagTokenAnd, value: word, pos: pos})
+			case "OR":
+				tokens = append(tokens, tagToken{kind: tagTokenOr, value: word, pos: pos})
+			case "NOT":
+				tokens = append(tokens, tagToken{kind: tagTokenNot, value: word, pos: pos})
+			default:
+				tokens = append(tokens, tagToken{kind: tagTokenTag, value: word, pos: pos})
+			}
+			i = end
+		}
+	}
+
+	return append(tokens, tagToken{kind: tagTokenEnd, pos: len(runes) + 1}), nil
+}
+
+// Разбор выражения рекурсивным спуском.
+type tagQueryParser struct {
+	tokens []tagToken
+	pos    int
+	tags   int
+}
+
+func (parser *tagQueryParser) peek() tagToken {
+	return parser.tokens[parser.pos]
+}
+
+func (parser *tagQueryParser) next() tagToken {
+	token := parser.tokens[parser.pos]
+	if token.kind != tagTokenEnd {
+		parser.pos++
+	}
+	return token
+}
+
+// or := and (OR and)*
+func (parser *tagQueryParser) parseOr() (TagQuery, error) {
+	item, err := parser.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	items := []TagQuery{item}
+	for parser.peek().kind == tagTokenOr {
+		parser.next()
+		item, err := parser.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+
+	if len(items) == 1 {
+		return items[0], nil
+	}
+	return tagOr{Items: items}, nil
+}
+
+// and := unary (AND unary)*
+func (parser *tagQueryParser) parseAnd() (TagQuery, error) {
+	item, err := parser.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+
+	items := []TagQuery{item}
+	for parser.peek().kind == tagTokenAnd {
+		parser.next()
+		item, err := parser.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+
+	if len(items) == 1 {
+		return items[0], nil
+	}
+	return tagAnd{Items: items}, nil
+}
+
+// unary := NOT unary | ( or ) | тег
+func (parser *tagQueryParser) parseUnary() (TagQuery, error) {
+	token := parser.next()
+
+	switch token.kind {
+	case tagTokenNot:
+		item, err := parser.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return tagNot{Item: item}, nil
+	case tagTokenOpen:
+		item, err := parser.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if closing := parser.next(); closing.kind != tagTokenClose {
+			return nil, fmt.Errorf("%w: ожидалась \")\" для скобки на позиции %d, а найден %s на позиции %d", ErrInvalidTagQuery, token.pos, closing, closing.pos)
+		}
+		return item, nil
+	case tagTokenTag:
+		parser.tags++
+		return tagLeaf{Tag: token.value}, nil
+	}
+
+	return nil, fmt.Errorf("%w: ожидался тег, а найден %s на позиции %d", ErrInvalidTagQuery, token, token.pos)
+}
@@ -0,0 +1,73 @@
+package service
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/lib/pq"
+)
+
+// Теги в условии сравниваются через tagNameCondition: каждому тегу - свой плейсхолдер с вариантами имени.
+func TestTagQuerySQL(t *testing.T) {
+	tests := []struct {
+		name         string
+		query        func() (TagQuery, error)
+		wantVariants [][]string
+		wantSQL      []string
+	}{
+		{
+			name:         "all=k8s",
+			query:        func() (TagQuery, error) { return TagQueryFromLists([]string{"k8s"}, nil, nil) },
+			wantVariants: [][]string{{"k8s", "л8ы"}},
+			wantSQL:      []string{"translit_key(t.tag) in", "unnest($1::text[])", "synonyms"},
+		},
+		{
+			name:         "выражение с раскладкой",
+			query:        func() (TagQuery, error) { return ParseTagQuery("голанг AND NOT ljrth") },
+			wantVariants: [][]string{{"голанг", "ujkfyu"}, {"ljrth", "докер"}},
+			wantSQL:      []string{"unnest($1::text[])", "unnest($2::text[])", "except"},
+		},
+		{
+			name: "списки all, any и none",
+			query: func() (TagQuery, error) {
+				return TagQueryFromLists([]string{" Go "}, []string{"postgresql", "mysql"}, []string{"orm"})
+			},
+			wantVariants: [][]string{{"go", "пщ"}, {"postgresql", "зщыепкуыйд"}, {"mysql", "ьныйд"}, {"orm", "щкь"}},
+			wantSQL:      []string{"unnest($1::text[])", "unnest($4::text[])", "intersect", "union", "except"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, err := tt.query()
+			if err != nil {
+				t.Fatalf("query error: %v", err)
+			}
+
+			var args []interface{}
+			sql := query.sql(&args)
+
+			if strings.Contains(sql, "lower(trim(") {
+				t.Errorf("tag compared exactly: %s", sql)
+			}
+			for _, part := range tt.wantSQL {
+				if !strings.Contains(sql, part) {
+					t.Errorf("sql does not contain %q: %s", part, sql)
+				}
+			}
+
+			var variants [][]string
+			for _, arg := range args {
+				array, ok := arg.(*pq.StringArray)
+				if !ok {
+					t.Fatalf("arg %v is %T, want *pq.StringArray", arg, arg)
+				}
+				variants = append(variants, []string(*array))
+			}
+			if !reflect.DeepEqual(variants, tt.wantVariants) {
+				t.Errorf("variants = %q, want %q", variants, tt.wantVariants)
+			}
+		})
+	}
+}
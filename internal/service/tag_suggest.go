@@ -0,0 +1,291 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"knowledge-base/internal/models"
+	"sort"
+
+	"github.com/lib/pq"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// Источники подсказок тегов.
+const (
+	suggestFromQuestion     = "question_text"
+	suggestFromAnswer       = "answer_text"
+	suggestFromCoOccurrence = "co_occurrence"
+)
+
+// Уверенность подсказки по источнику. Имя тега в тексте вопроса надежнее, чем в ответе,
+// а совместная встречаемость умножается на долю вопросов, где теги стоят вместе.
+const (
+	suggestQuestionWeight     = 0.9
+	suggestAnswerWeight       = 0.6
+	suggestCoOccurrenceWeight = 0.5
+
+	// Минимальное количество вопросов с парой тегов, чтобы совместная встречаемость учитывалась.
+	suggestMinCoOccurrence = 2
+)
+
+// Настройки подсказок тегов.
+type TagSuggestSettings struct {
+	// Минимальная уверенность подсказки от 0 до 1.
+	MinConfidence float64
+
+	// Максимальное количество подсказок.
+	Limit int
+
+	// Добавлять ли теги автоматически при создании вопроса.
+	AutoApply bool
+
+	// Уверенность, с которой тег добавляется автоматически.
+	AutoApplyThreshold float64
+}
+
+// Настройки по умолчанию: автоматическое добавление выключено.
+var tagSuggestSettings = TagSuggestSettings{
+	MinConfidence:      0.2,
+	Limit:              5,
+	AutoApplyThreshold: 0.8,
+}
+
+// SetTagSuggestSettings задает настройки подсказок тегов. Пороги вне диапазона (0, 1] не меняют настройку.
+func SetTagSuggestSettings(settings TagSuggestSettings) {
+	if settings.MinConfidence > 0 && settings.MinConfidence <= 1 {
+		tagSuggestSettings.MinConfidence = settings.MinConfidence
+	}
+	if settings.AutoApplyThreshold > 0 && settings.AutoApplyThreshold <= 1 {
+		tagSuggestSettings.AutoApplyThreshold = settings.AutoApplyThreshold
+	}
+	if settings.Limit > 0 {
+		tagSuggestSettings.Limit = settings.Limit
+	}
+	tagSuggestSettings.AutoApply = settings.AutoApply
+}
+
+// AutoApplyTagsEnabled сообщает, включено ли автоматическое добавление тегов при создании вопроса.
+func AutoApplyTagsEnabled() bool {
+	return tagSuggestSettings.AutoApply
+}
+
+// SuggestTags подсказывает теги для вопроса, которых у него еще нет, по убыванию уверенности.
+// Если вопроса нет или он не виден вызывающему, возвращается sql.ErrNoRows.
+func (questionService *QuestionService) SuggestTags(ctx context.Context, questionID int) ([]models.TagSuggestion, error) {
+
+	// Ограничение времени выполнения запроса.
+	ctx, finish := beginQuery(ctx, "QuestionService.SuggestTags", attribute.Int("question.id", questionID))
+	defer finish()
+
+	if err := checkVisible(ctx, questionService.db, "questions", questionID); err != nil {
+		return nil, queryError(ctx, err)
+	}
+
+	suggestions, err := suggestTagsForQuestion(ctx, questionService.db, questionID)
+	if err != nil {
+		return nil, queryError(ctx, err)
+	}
+
+	recordRows(ctx, len(suggestions))
+
+	return suggestions, nil
+}
+
+// SuggestNewQuestionTags подсказывает теги только что созданному вопросу для ответа его автору.
+// Новый вопрос - черновик, поэтому видимость не проверяется.
+func (questionService *QuestionService) SuggestNewQuestionTags(ctx context.Context, questionID int) ([]models.TagSuggestion, error) {
+
+	// Ограничение времени выполнения запроса.
+	ctx, finish := beginQuery(ctx, "QuestionService.SuggestNewQuestionTags", attribute.Int("question.id", questionID))
+	defer finish()
+
+	suggestions, err := suggestTagsForQuestion(ctx, questionService.db, questionID)
+	if err != nil {
+		return nil, queryError(ctx, err)
+	}
+
+	recordRows(ctx, len(suggestions))
+
+	return suggestions, nil
+}
+
+// ApplySuggestedTags добавляет вопросу подсказанные теги с уверенностью не меньше порога автоматического добавления
+// с отметкой is_auto. Возвращает добавленные теги.
+func (questionService *QuestionService) ApplySuggestedTags(ctx context.Context, questionID int, suggestions []models.TagSuggestion) ([]models.TagSuggestion, error) {
+
+	// Ограничение времени выполнения запроса.
+	ctx, finish := beginQuery(ctx, "QuestionService.ApplySuggestedTags", attribute.Int("question.id", questionID))
+	defer finish()
+
+	applied := []models.TagSuggestion{}
+	var tagIDs []int
+	for _, suggestion := range suggestions {
+		if suggestion.Confidence >= tagSuggestSettings.AutoApplyThreshold {
+			applied = append(applied, suggestion)
+			tagIDs = append(tagIDs, suggestion.TagID)
+		}
+	}
+	if len(tagIDs) == 0 {
+		return applied, nil
+	}
+
+	var query string = `insert into questions_tags (question_id, tag_id, is_auto)
+		select $1, tag_id, true
+		from unnest($2::int[]) as ids(tag_id)
+		on conflict do nothing`
+
+	_, err := questionService.db.ExecContext(ctx, query, questionID, pq.Array(tagIDs))
+	if err != nil {
+		return nil, queryError(ctx, err)
+	}
+
+	recordRows(ctx, len(applied))
+
+	notifyQuestionsChanged(ctx, questionID)
+
+	return applied, nil
+}
+
+// Подсказки тегов для вопроса: имена тегов и их синонимы в тексте вопроса и ответов,
+// затем теги, которые часто стоят вместе с тегами вопроса и найденными в тексте.
+// Уверенность из нескольких источников объединяется как 1 - (1-a)(1-b).
+func suggestTagsForQuestion(ctx context.Context, db *sql.DB, questionID int) ([]models.TagSuggestion, error) {
+
+	// Теги, которые уже есть у вопроса. Проверка существования вопроса - в том же запросе.
+	var current []int64
+	err := db.QueryRowContext(ctx, `select coalesce((select array_agg(tag_id) from questions_tags where question_id = q.id), '{}') from questions q where q.id = $1`,
+		questionID).Scan(pq.Array(&current))
+	if err != nil {
+		return nil, err
+	}
+
+	attached := make(map[int]bool, len(current))
+	seeds := make([]int, 0, len(current))
+	for _, id := range current {
+		attached[int(id)] = true
+		seeds = append(seeds, int(id))
+	}
+
+	suggestions := make(map[int]*models.TagSuggestion)
+	add := func(tagID int, tag string, confidence float64, source string) {
+		suggestion, ok := suggestions[tagID]
+		if !ok {
+			suggestion = &models.TagSuggestion{TagID: tagID, Tag: tag}
+			suggestions[tagID] = suggestion
+		}
+		suggestion.Confidence = 1 - (1-suggestion.Confidence)*(1-confidence)
+		suggestion.Sources = append(suggestion.Sources, source)
+	}
+
+	// Имя тега или его синоним как фраза в тексте вопроса или ответов (с учетом словоформ).
+	var keywordQuery string = `select t.id, t.tag,
+			bool_or(q.search_vector @@ term.ts) as in_question,
+			bool_or(exists (select 1 from answers a where a.question_id = q.id and a.search_vector @@ term.ts)) as in_answer
+		from questions q
+		cross join tags t
+		cross join lateral (
+			select phraseto_tsquery('russian', x.term) || phraseto_tsquery('english', x.term) as ts
+			from (
+				select t.tag as term
+				union
+				select g.term from synonyms s join synonyms g on g.group_id = s.group_id
+				where translit_key(s.term) = translit_key(t.tag)
+			) x
+		) term
+		where q.id = $1 and numnode(term.ts) > 0
+		group by t.id, t.tag
+		having bool_or(q.search_vector @@ term.ts)
+			or bool_or(exists (select 1 from answers a where a.question_id = q.id and a.search_vector @@ term.ts))`
+
+	rows, err := db.QueryContext(ctx, keywordQuery, questionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var tagID int
+		var tag string
+		var inQuestion, inAnswer bool
+		if err := rows.Scan(&tagID, &tag, &inQuestion, &inAnswer); err != nil {
+			return nil, err
+		}
+
+		seeds = append(seeds, tagID)
+		if attached[tagID] {
+			continue
+		}
+		if inQuestion {
+			add(tagID, tag, suggestQuestionWeight, suggestFromQuestion)
+		}
+		if inAnswer {
+			add(tagID, tag, suggestAnswerWeight, suggestFromAnswer)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Совместная встречаемость: доля вопросов с тегом-основой, у которых есть и другой тег. Сам вопрос не учитывается.
+	if len(seeds) > 0 {
+		var coQuery string = `with seed_counts as (
+				select tag_id, count(*) as total
+				from questions_tags
+				where tag_id = any($1) and question_id <> $2
+				group by tag_id
+			),
+			pairs as (
+				select a.tag_id as seed_id, b.tag_id, count(*) as together
+				from questions_tags a
+				join questions_tags b on b.question_id = a.question_id and b.tag_id <> a.tag_id
+				where a.tag_id = any($1) and a.question_id <> $2
+				group by a.tag_id, b.tag_id
+				having count(*) >= $3
+			)
+			select t.id, t.tag, max(p.together::double precision / sc.total)
+			from pairs p
+			join seed_counts sc on sc.tag_id = p.seed_id
+			join tags t on t.id = p.tag_id
+			group by t.id, t.tag`
+
+		coRows, err := db.QueryContext(ctx, coQuery, pq.Array(seeds), questionID, suggestMinCoOccurrence)
+		if err != nil {
+			return nil, err
+		}
+		defer coRows.Close()
+
+		for coRows.Next() {
+			var tagID int
+			var tag string
+			var share float64
+			if err := coRows.Scan(&tagID, &tag, &share); err != nil {
+				return nil, err
+			}
+			if !attached[tagID] {
+				add(tagID, tag, suggestCoOccurrenceWeight*share, suggestFromCoOccurrence)
+			}
+		}
+		if err := coRows.Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	// Самые уверенные подсказки не ниже порога.
+	result := []models.TagSuggestion{}
+	for _, suggestion := range suggestions {
+		if suggestion.Confidence >= tagSuggestSettings.MinConfidence {
+			result = append(result, *suggestion)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Confidence != result[j].Confidence {
+			return result[i].Confidence > result[j].Confidence
+		}
+		return result[i].Tag < result[j].Tag
+	})
+	if len(result) > tagSuggestSettings.Limit {
+		result = result[:tagSuggestSettings.Limit]
+	}
+
+	return result, nil
+}
@@ -0,0 +1,153 @@
+package service
+
+import (
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// Клавиши QWERTY и буквы ЙЦУКЕН на тех же местах.
+const (
+	latinLayout    = "qwertyuiop[]asdfghjkl;'zxcvbnm,.`"
+	cyrillicLayout = "йцукенгшщзхъфывапролджэячсмитьбюё"
+)
+
+// Замены букв при наборе не в той раскладке.
+var (
+	latinToCyrillicLayout = layoutReplacer(latinLayout, cyrillicLayout)
+	cyrillicToLatinLayout = layoutReplacer(cyrillicLayout, latinLayout)
+)
+
+// Замена каждой буквы from на букву to на той же клавише.
+func layoutReplacer(from string, to string) *strings.Replacer {
+	fromRunes, toRunes := []rune(from), []rune(to)
+	pairs := make([]string, 0, len(fromRunes)*2)
+	for i := range fromRunes {
+		pairs = append(pairs, string(fromRunes[i]), string(toRunes[i]))
+	}
+	return strings.NewReplacer(pairs...)
+}
+
+// Варианты имени тега для поиска: как введено и как если бы то же самое набрали в другой раскладке
+// ("вщслук" - это "docker", "ljrth" - это "докер").
+// Алфавит дальше не важен: варианты сравниваются с тегами по ключу транслитерации translit_key в БД.
+func tagNameVariants(name string) []string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	variants := []string{name}
+
+	for _, replacer := range []*strings.Replacer{latinToCyrillicLayout, cyrillicToLatinLayout} {
+		if variant := replacer.Replace(name); variant != name {
+			variants = append(variants, variant)
+		}
+	}
+	return variants
+}
+
+// Шаги translitKey в том же порядке, что и в функции translit_key из migrations/006_translit_search.sql.
+var (
+	translitLatin = strings.NewReplacer("ck", "k", "ph", "f", "th", "t", "x", "ks", "j", "dzh", "q", "k")
+
+	translitCyrillic = strings.NewReplacer(
+		"щ", "sch", "ш", "sh", "ч", "ch", "ж", "zh", "ц", "ts", "ю", "u", "я", "ia", "ъ", "", "ь", "",
+		"а", "a", "б", "b", "в", "v", "г", "g", "д", "d", "е", "e", "ё", "e", "з", "z", "и", "i", "й", "i",
+		"к", "k", "л", "l", "м", "m", "н", "n", "о", "o", "п", "p", "р", "r", "с", "s", "т", "t", "у", "u",
+		"ф", "f", "х", "h", "ы", "i", "э", "e", "w", "v", "y", "i",
+	)
+)
+
+// Ключ транслитерации для встроенного поискового индекса: то же, что translit_key в БД,
+// чтобы теги находились одинаково с любым движком поиска.
+func translitKey(value string) string {
+	value = translitLatin.Replace(strings.ToLower(strings.TrimSpace(value)))
+
+	// c перед h остается (ch), иначе читается как k.
+	runes := []rune(value)
+	for i, r := range runes {
+		if r == 'c' && (i+1 == len(runes) || runes[i+1] != 'h') {
+			runes[i] = 'k'
+		}
+	}
+	value = translitCyrillic.Replace(string(runes))
+
+	// Без разделителей и повторов букв.
+	var key []rune
+	for _, r := range value {
+		if unicode.IsSpace(r) || r == '_' || r == '.' || r == '-' {
+			continue
+		}
+		if len(key) > 0 && key[len(key)-1] == r {
+			continue
+		}
+		key = append(key, r)
+	}
+	return string(key)
+}
+
+// Латиница, которой пишут русские слова ("kak nastroit" - "как настроит"). Буквосочетания стоят раньше
+// отдельных букв, чтобы "sh" стало "ш", а не "сх".
+var latinToCyrillic = strings.NewReplacer(
+	"shch", "щ", "sch", "щ", "zh", "ж", "kh", "х", "ch", "ч", "sh", "ш", "ts", "ц", "yu", "ю", "ya", "я", "yo", "ё",
+	"ck", "к", "ph", "ф", "th", "т",
+	"a", "а", "b", "б", "c", "к", "d", "д", "e", "е", "f", "ф", "g", "г", "h", "х", "i", "и", "j", "дж", "k", "к",
+	"l", "л", "m", "м", "n", "н", "o", "о", "p", "п", "q", "к", "r", "р", "s", "с", "t", "т", "u", "у", "v", "в",
+	"w", "в", "x", "кс", "y", "ы", "z", "з",
+)
+
+// Варианты слова текстового запроса: как введено, в другой раскладке и русское слово, набранное латиницей
+// ("nastroit" - "настроит", "rfr" - "как"). Первым идет слово как введено.
+func searchWordVariants(word string) []string {
+	variants := tagNameVariants(word)
+	if variant := latinToCyrillic.Replace(variants[0]); variant != variants[0] {
+		variants = append(variants, variant)
+	}
+
+	unique := variants[:0]
+	for _, variant := range variants {
+		if !slices.Contains(unique, variant) {
+			unique = append(unique, variant)
+		}
+	}
+	return unique
+}
+
+// Запрос tsquery, в котором каждое слово заменено на любой из своих вариантов searchWordVariants,
+// а слово с "-" исключается во всех вариантах. Пустая строка, если вариантов ни у одного слова нет.
+// arg добавляет значение в аргументы запроса и возвращает его плейсхолдер.
+func translitTSQuery(query string, arg func(value interface{}) string) string {
+	type group struct {
+		variants []string
+		negated  bool
+	}
+
+	var groups []group
+	changed := false
+	for _, token := range strings.Fields(strings.ToLower(query)) {
+		if token == "or" {
+			continue
+		}
+		negated := strings.HasPrefix(token, "-")
+		words := strings.FieldsFunc(token, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) })
+		for _, word := range words {
+			// "or" в websearch_to_tsquery - оператор, а не слово.
+			variants := slices.DeleteFunc(searchWordVariants(word), func(variant string) bool { return variant == "or" })
+			if len(variants) == 0 {
+				continue
+			}
+			changed = changed || len(variants) > 1
+			groups = append(groups, group{variants: variants, negated: negated})
+		}
+	}
+	if !changed {
+		return ""
+	}
+
+	conditions := make([]string, 0, len(groups))
+	for _, group := range groups {
+		condition := "(" + searchTSQuery(arg(strings.Join(group.variants, " or "))) + ")"
+		if group.negated {
+			condition = "!!" + condition
+		}
+		conditions = append(conditions, condition)
+	}
+	return "(" + strings.Join(conditions, " && ") + ")"
+}
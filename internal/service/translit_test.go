@@ -0,0 +1,234 @@
+package service
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// Имена тегов из migrations/002_seed_data.sql и их написания в другом алфавите.
+var translitExamples = []struct {
+	name  string
+	other string
+}{
+	{name: "docker", other: "докер"},
+	{name: "golang", other: "голанг"},
+	{name: "postgresql", other: "постгрескл"},
+	{name: "react", other: "реакт"},
+	{name: "unit tests", other: "юнит тестс"},
+	{name: "база данных", other: "baza dannyh"},
+	{name: "чистый код", other: "chistyi kod"},
+	{name: "сортировка", other: "sortirovka"},
+}
+
+func TestTranslitKey(t *testing.T) {
+	for _, tt := range translitExamples {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, want := translitKey(tt.other), translitKey(tt.name); got != want {
+				t.Errorf("translitKey(%q) = %q, want %q as for %q", tt.other, got, want, tt.name)
+			}
+		})
+	}
+}
+
+func TestTagNameVariants(t *testing.T) {
+	tests := []struct {
+		input string
+		tag   string
+	}{
+		{input: "Docker", tag: "docker"},
+		{input: "докер", tag: "docker"},
+		{input: "вщслук", tag: "docker"},
+		{input: "ljrth", tag: "docker"},
+		{input: "голанг", tag: "golang"},
+		{input: "ujkfyu", tag: "golang"},
+		{input: "пщдфтп", tag: "golang"},
+		{input: ",fpf lfyys[", tag: "база данных"},
+		{input: "cjhnbhjdrf", tag: "сортировка"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			variants := tagNameVariants(tt.input)
+			matched := slices.ContainsFunc(variants, func(variant string) bool { return translitKey(variant) == translitKey(tt.tag) })
+			if !matched {
+				t.Errorf("tagNameVariants(%q) = %q, none matches tag %q", tt.input, variants, tt.tag)
+			}
+		})
+	}
+}
+
+func TestSearchWordVariants(t *testing.T) {
+	tests := []struct {
+		word string
+		want string
+	}{
+		{word: "kak", want: "как"},
+		{word: "nastroit", want: "настроит"},
+		{word: "soedinenie", want: "соединение"},
+		{word: "migratsii", want: "миграции"},
+		{word: "docker", want: "докер"},
+		{word: "shchi", want: "щи"},
+		{word: "rfr", want: "как"},
+		{word: "yfcnhjbnm", want: "настроить"},
+		{word: "Сортировка", want: "сортировка"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.word, func(t *testing.T) {
+			variants := searchWordVariants(tt.word)
+			if variants[0] != strings.ToLower(tt.word) {
+				t.Errorf("searchWordVariants(%q)[0] = %q, want the word itself", tt.word, variants[0])
+			}
+			if !slices.Contains(variants, tt.want) {
+				t.Errorf("searchWordVariants(%q) = %q, want %q among them", tt.word, variants, tt.want)
+			}
+		})
+	}
+}
+
+func TestTranslitTSQuery(t *testing.T) {
+	tests := []struct {
+		query string
+
+		// Аргументы запроса, пустой список - запрос без вариантов.
+		args []interface{}
+
+		negated bool
+	}{
+		{query: "kak nastroit docker", args: []interface{}{"kak or лфл or как", "nastroit or тфыекщше or настроит", "docker or вщслук or докер"}},
+		{query: `"Dockerfile" -docker`, args: []interface{}{"dockerfile or вщслукашду or докерфиле", "docker or вщслук or докер"}, negated: true},
+		{query: "10 or 20", args: nil},
+		{query: "  ", args: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			var args []interface{}
+			got := translitTSQuery(tt.query, func(value interface{}) string {
+				args = append(args, value)
+				return fmt.Sprintf("$%d", len(args))
+			})
+
+			if (got == "") != (len(tt.args) == 0) {
+				t.Fatalf("translitTSQuery(%q) = %q, want variants: %v", tt.query, got, len(tt.args) > 0)
+			}
+			if !slices.Equal(args, tt.args) {
+				t.Errorf("translitTSQuery(%q) args = %q, want %q", tt.query, args, tt.args)
+			}
+			if strings.Contains(got, "!!") != tt.negated {
+				t.Errorf("translitTSQuery(%q) = %q, negation expected: %v", tt.query, got, tt.negated)
+			}
+		})
+	}
+}
+
+// translitKey должен давать тот же ключ, что функция translit_key из миграции, иначе встроенный индекс
+// и Postgres находят разные теги. Функция из миграции выполняется здесь шаг за шагом: в ней вложенные вызовы,
+// поэтому порядок аргументов в тексте совпадает с порядком выполнения.
+func TestTranslitKeyMatchesMigration(t *testing.T) {
+	data, err := os.ReadFile("../../migrations/006_translit_search.sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body := strings.ReplaceAll(string(data), "\r\n", "\n")
+	start := strings.Index(body, "$$")
+	end := strings.LastIndex(body, "$$")
+	if start < 0 || end <= start {
+		t.Fatal("function body not found in migration")
+	}
+	body = body[start+2 : end]
+
+	calls := regexp.MustCompile(`\b(replace|regexp_replace|translate|lower|trim)\(`).FindAllStringSubmatch(body, -1)
+	literals := regexp.MustCompile(`'([^']*)'`).FindAllStringSubmatch(body, -1)
+
+	// Регулярные выражения Postgres, которых нет в regexp из Go (просмотр вперед и обратные ссылки).
+	patterns := map[string]func(value string, replacement string) string{
+		`c(?!h)`: func(value string, replacement string) string {
+			runes := []rune(value)
+			var result strings.Builder
+			for i, r := range runes {
+				if r == 'c' && (i+1 == len(runes) || runes[i+1] != 'h') {
+					result.WriteString(replacement)
+					continue
+				}
+				result.WriteRune(r)
+			}
+			return result.String()
+		},
+		`(.)\1+`: func(value string, replacement string) string {
+			if replacement != `\1` {
+				t.Fatalf("unexpected replacement %q", replacement)
+			}
+			var result []rune
+			for _, r := range value {
+				if len(result) == 0 || result[len(result)-1] != r {
+					result = append(result, r)
+				}
+			}
+			return string(result)
+		},
+	}
+
+	sqlTranslitKey := func(value string) string {
+		next := 0
+		literal := func() string {
+			if next == len(literals) {
+				t.Fatal("migration has fewer literals than calls need")
+			}
+			next++
+			return literals[next-1][1]
+		}
+
+		for i := len(calls) - 1; i >= 0; i-- {
+			switch calls[i][1] {
+			case "lower":
+				value = strings.ToLower(value)
+			case "trim":
+				value = strings.TrimSpace(value)
+			case "replace":
+				from, to := literal(), literal()
+				value = strings.ReplaceAll(value, from, to)
+			case "translate":
+				from, to := []rune(literal()), []rune(literal())
+				value = strings.Map(func(r rune) rune {
+					if i := slices.Index(from, r); i >= 0 {
+						if i >= len(to) {
+							return -1
+						}
+						return to[i]
+					}
+					return r
+				}, value)
+			case "regexp_replace":
+				pattern, replacement, flags := literal(), literal(), literal()
+				if flags != "g" {
+					t.Fatalf("unexpected regexp_replace flags %q", flags)
+				}
+				if replace, ok := patterns[pattern]; ok {
+					value = replace(value, replacement)
+				} else {
+					value = regexp.MustCompile(pattern).ReplaceAllString(value, replacement)
+				}
+			}
+		}
+		if next != len(literals) {
+			t.Fatalf("migration has %d literals, calls used %d", len(literals), next)
+		}
+		return value
+	}
+
+	words := []string{"cache", "checkout", "jquery", "php", "python", "xml", "c#", "щётка", "объект", "юникод", "Яндекс", "ёлка", "white-space", "snake_case", "node.js"}
+	for _, tt := range translitExamples {
+		words = append(words, tt.name, tt.other)
+	}
+
+	for _, word := range words {
+		if got, want := translitKey(word), sqlTranslitKey(word); got != want {
+			t.Errorf("translitKey(%q) = %q, translit_key in migration gives %q", word, got, want)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"unicode"
+)
+
+// Установлено ли в базе расширение pg_trgm. Без прав на создание расширения миграции нечеткого поиска
+// пропускаются: нечеткий поиск, похожие вопросы и /ask недоступны, подсказки тегов считаются в Go.
+var trigramEnabled = true
+
+// ErrTrigramUnavailable возвращается функциями, которым нужно расширение pg_trgm, если его нет.
+var ErrTrigramUnavailable = errors.New("недоступно: в базе нет расширения pg_trgm")
+
+// SetTrigramEnabled задает, установлено ли расширение pg_trgm.
+func SetTrigramEnabled(enabled bool) {
+	trigramEnabled = enabled
+}
+
+// Проверка, что расширение pg_trgm установлено.
+func requireTrigram() error {
+	if !trigramEnabled {
+		return ErrTrigramUnavailable
+	}
+	return nil
+}
+
+// Триграммы текста как в show_trgm из pg_trgm: слова из букв и цифр в нижнем регистре,
+// перед словом два пробела, после - один.
+func trigramSet(text string) map[string]bool {
+	set := make(map[string]bool)
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) })
+	for _, word := range words {
+		padded := []rune("  " + word + " ")
+		for i := 0; i+3 <= len(padded); i++ {
+			set[string(padded[i:i+3])] = true
+		}
+	}
+	return set
+}
+
+// Сходство текстов как similarity из pg_trgm: общих триграмм / всех различных триграмм обоих текстов.
+func trigramSimilarity(a string, b string) float64 {
+	setA, setB := trigramSet(a), trigramSet(b)
+	common := 0
+	for trigram := range setA {
+		if setB[trigram] {
+			common++
+		}
+	}
+	all := len(setA) + len(setB) - common
+	if all == 0 {
+		return 0
+	}
+	return float64(common) / float64(all)
+}
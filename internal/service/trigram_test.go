@@ -0,0 +1,51 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+)
+
+// Значения similarity из pg_trgm для тех же строк.
+func TestTrigramSimilarity(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want float64
+	}{
+		{a: "word", b: "two words", want: 4.0 / 11},
+		{a: "golang", b: "golang", want: 1},
+		{a: "Golang", b: "golang", want: 1},
+		{a: "postgre", b: "postgresql", want: 7.0 / 12},
+		{a: "docker", b: "python", want: 0},
+		{a: "", b: "golang", want: 0},
+		{a: "!!!", b: "", want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.a+"/"+tt.b, func(t *testing.T) {
+			if got := trigramSimilarity(tt.a, tt.b); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("trigramSimilarity(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+}
+
+// Без pg_trgm функции, которым нужно расширение, отвечают ErrTrigramUnavailable до обращения к БД.
+func TestTrigramDisabled(t *testing.T) {
+	SetTrigramEnabled(false)
+	defer SetTrigramEnabled(true)
+
+	if _, err := (&SearchService{}).FuzzySearch(context.Background(), "golang", 10, 0); !errors.Is(err, ErrTrigramUnavailable) {
+		t.Errorf("FuzzySearch() error = %v, want %v", err, ErrTrigramUnavailable)
+	}
+	if _, err := (&QuestionService{}).GetSimilar(context.Background(), 1, 10); !errors.Is(err, ErrTrigramUnavailable) {
+		t.Errorf("GetSimilar() error = %v, want %v", err, ErrTrigramUnavailable)
+	}
+	if _, err := (&AskService{}).Ask(context.Background(), "как настроить docker"); !errors.Is(err, ErrTrigramUnavailable) {
+		t.Errorf("Ask() error = %v, want %v", err, ErrTrigramUnavailable)
+	}
+	if duplicates, err := (&QuestionService{}).FindDuplicates(context.Background(), "как настроить docker"); err != nil || duplicates != nil {
+		t.Errorf("FindDuplicates() = %v, %v, want nil, nil", duplicates, err)
+	}
+}
@@ -1,9 +1,12 @@
 package service
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"knowledge-base/internal/models"
+
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // Структура для работы со всеми ф-ями service/tutor.go.
@@ -16,15 +19,31 @@ func NewTutor(db *sql.DB) *TutorService {
 	return &TutorService{db: db}
 }
 
-func (tutorService *TutorService) GetAll() ([]models.Tutor, error) {
+func (tutorService *TutorService) GetAll(ctx context.Context, params ListParams) ([]models.Tutor, int, error) {
+
+	// Ограничение времени выполнения запроса.
+	ctx, finish := beginQuery(ctx, "TutorService.GetAll")
+	defer finish()
+
+	// Условия фильтрации и сортировки из параметров списка.
+	where, args := TutorListSpec.whereClause(params)
+	orderBy := TutorListSpec.orderClause(params)
+	page, pageArgs := TutorListSpec.pageClause(params, len(args))
+
+	// Общее количество записей с учетом фильтров, без учета страницы.
+	var total int
+	err := tutorService.db.QueryRowContext(ctx, `select count(*) from tutors`+where, args...).Scan(&total)
+	if err != nil {
+		return nil, 0, queryError(ctx, err)
+	}
 
 	//Создание sql запроса для получения данных по всем тьюторам.
-	var query string = `select id, full_name, email from tutors order by id`
+	var query string = `select id, full_name, email from tutors` + where + orderBy + page
 
 	// Выполнение функции, которая проводит sql запрос и возвращает таблицу с несколькими строками.
-	rows, err := tutorService.db.Query(query)
+	rows, err := tutorService.db.QueryContext(ctx, query, append(args, pageArgs...)...)
 	if err != nil {
-		return nil, err
+		return nil, 0, queryError(ctx, err)
 	}
 
 	// Закрытие, чтобы не было утечки соединений, надо закрыть.
@@ -37,50 +56,115 @@ func (tutorService *TutorService) GetAll() ([]models.Tutor, error) {
 		var tutor models.Tutor
 		err := rows.Scan(&tutor.ID, &tutor.FullName, &tutor.Email)
 		if err != nil {
-			return nil, err
+			return nil, 0, queryError(ctx, err)
 		}
 		tutors = append(tutors, tutor)
 	}
 
-	return tutors, nil
+	// Проверка ошибки, которая могла прервать чтение строк.
+	if err := rows.Err(); err != nil {
+		return nil, 0, queryError(ctx, err)
+	}
+
+	recordRows(ctx, len(tutors))
+
+	return tutors, total, nil
 }
 
-func (tutorService *TutorService) GetByID(id int) (models.Tutor, error) {
+// Export читает все записи с учетом фильтров и сортировки и передает их по одной в fn, не собирая в память.
+// Если fn возвращает ошибку, чтение прекращается и ошибка возвращается как есть.
+func (tutorService *TutorService) Export(ctx context.Context, params ListParams, fn func(models.Tutor) error) error {
+
+	// Ограничение времени выгрузки.
+	ctx, finish := beginExport(ctx, "TutorService.Export")
+	defer finish()
+
+	// Условия фильтрации и сортировки из параметров списка, без страницы.
+	where, args := TutorListSpec.whereClause(params.withoutCursor())
+	orderBy := TutorListSpec.orderClause(params.withoutCursor())
+
+	//Создание sql запроса для выгрузки всех тьюторов.
+	var query string = `select id, full_name, email from tutors` + where + orderBy
+
+	// Выполнение функции, которая проводит sql запрос и возвращает таблицу с несколькими строками.
+	rows, err := tutorService.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return queryError(ctx, err)
+	}
+
+	// Закрытие, чтобы не было утечки соединений, надо закрыть.
+	defer rows.Close()
+
+	// Каждая строка сразу передается дальше, в памяти хранится только одна.
+	var count int
+	for rows.Next() {
+		var tutor models.Tutor
+		err := rows.Scan(&tutor.ID, &tutor.FullName, &tutor.Email)
+		if err != nil {
+			return queryError(ctx, err)
+		}
+		if err := fn(tutor); err != nil {
+			return err
+		}
+		count++
+	}
+
+	// Проверка ошибки, которая могла прервать чтение строк.
+	if err := rows.Err(); err != nil {
+		return queryError(ctx, err)
+	}
+
+	recordRows(ctx, count)
+
+	return nil
+}
+
+func (tutorService *TutorService) GetByID(ctx context.Context, id int) (models.Tutor, error) {
+
+	// Ограничение времени выполнения запроса.
+	ctx, finish := beginQuery(ctx, "TutorService.GetByID", attribute.Int("tutor.id", id))
+	defer finish()
 
 	//Создание sql запроса для получения данных по одному конкретному тьютору.
 	var query string = `select id, full_name, email from tutors where id = $1`
 
 	// Выполнение функции, которая проводит sql запрос и возвращает таблицу из одной строки.
-	row := tutorService.db.QueryRow(query, id)
+	row := tutorService.db.QueryRowContext(ctx, query, id)
 
 	var tutor models.Tutor
 
 	// Запись полученных данных из БД в перемнную типа models.Tutor.
 	err := row.Scan(&tutor.ID, &tutor.FullName, &tutor.Email)
 	if err != nil {
-		return models.Tutor{}, err
+		return models.Tutor{}, queryError(ctx, err)
 	}
 
 	return tutor, nil
 }
 
-func (tutorService *TutorService) DeleteByID(id int) error {
+func (tutorService *TutorService) DeleteByID(ctx context.Context, id int) error {
+
+	// Ограничение времени выполнения запроса.
+	ctx, finish := beginQuery(ctx, "TutorService.DeleteByID", attribute.Int("tutor.id", id))
+	defer finish()
 
 	//Создание sql запроса для удаления данных одного кокретного тьютора.
 	var query string = `delete from tutors where id = $1`
 
 	// Выполнение функции, которая проводит sql запрос без возврата данных.
-	result, err := tutorService.db.Exec(query, id)
+	result, err := tutorService.db.ExecContext(ctx, query, id)
 	if err != nil {
-		return err
+		return queryError(ctx, err)
 	}
 
 	// Выполнение функции, которая возаращает количество удаленных строк.
 	rowsAffected, err := result.RowsAffected()
 	if err != nil {
-		return err
+		return queryError(ctx, err)
 	}
 
+	recordRows(ctx, int(rowsAffected))
+
 	//Проверка было ли удаление строки. Если rowsAffected = 0, то не было.
 	if rowsAffected == 0 {
 		return fmt.Errorf("tutor with id %d not found", id)
@@ -89,7 +173,11 @@ func (tutorService *TutorService) DeleteByID(id int) error {
 	return nil
 }
 
-func (tutorService *TutorService) PostString(fullName string, email string) (int, error) {
+func (tutorService *TutorService) PostString(ctx context.Context, fullName string, email string) (int, error) {
+
+	// Ограничение времени выполнения запроса.
+	ctx, finish := beginQuery(ctx, "TutorService.PostString")
+	defer finish()
 
 	//Создание sql запроса для появления новой записи в таблице тьюторов.
 	var query string = `insert into tutors (full_name, email) values
@@ -98,18 +186,22 @@ func (tutorService *TutorService) PostString(fullName string, email string) (int
 	var id int
 
 	// Выполнение функции, которая проводит sql запрос и возвращает таблицу из одной строки.
-	row := tutorService.db.QueryRow(query, fullName, email)
+	row := tutorService.db.QueryRowContext(ctx, query, fullName, email)
 
 	// Получение id созданной записи.
 	err := row.Scan(&id)
 	if err != nil {
-		return 0, err
+		return 0, queryError(ctx, err)
 	}
 
 	return id, nil
 }
 
-func (tutorService *TutorService) PutString(fullName string, email string, id int) (models.Tutor, error) {
+func (tutorService *TutorService) PutString(ctx context.Context, fullName string, email string, id int) (models.Tutor, error) {
+
+	// Ограничение времени выполнения запроса.
+	ctx, finish := beginQuery(ctx, "TutorService.PutString", attribute.Int("tutor.id", id))
+	defer finish()
 
 	//Создание sql запроса для обновления данных конкретного тьютора.
 	var query string = `update tutors 
@@ -121,9 +213,9 @@ func (tutorService *TutorService) PutString(fullName string, email string, id in
 	var tutor models.Tutor
 
 	// Выполнение функции, которая проводит sql запрос и возвращает таблицу из одной строки. Заполнение полей переменной типа models.Tutor.
-	err := tutorService.db.QueryRow(query, fullName, email, id).Scan(&tutor.FullName, &tutor.Email)
+	err := tutorService.db.QueryRowContext(ctx, query, fullName, email, id).Scan(&tutor.FullName, &tutor.Email)
 	if err != nil {
-		return models.Tutor{}, err
+		return models.Tutor{}, queryError(ctx, err)
 	}
 
 	return tutor, nil
@@ -0,0 +1,304 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"knowledge-base/internal/models"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// Статусы вопросов и ответов.
+const (
+	StatusDraft     = "draft"
+	StatusInReview  = "in_review"
+	StatusPublished = "published"
+	StatusArchived  = "archived"
+)
+
+// Роли вызывающего. Учащийся видит только опубликованные вопросы и ответы,
+// тьютор и модератор - все, статус меняют только они.
+const (
+	RoleLearner   = "learner"
+	RoleTutor     = "tutor"
+	RoleModerator = "moderator"
+)
+
+// Ошибки смены статуса.
+var (
+	ErrInvalidStatus    = errors.New("неверная смена статуса")
+	ErrStatusTransition = errors.New("смена статуса недопустима")
+	ErrStatusForbidden  = errors.New("недостаточно прав для смены статуса")
+)
+
+// Правило перехода между статусами.
+type statusTransition struct {
+	// Переход выполняет только модератор.
+	moderator bool
+
+	// Нужен комментарий (причина отклонения).
+	comment bool
+}
+
+// Допустимые переходы: текущий статус -> новый статус.
+// Тьютор отправляет черновик на проверку, модератор публикует или отклоняет его с комментарием,
+// опубликованное архивирует и восстанавливает из архива. Архивное тьютор может вернуть в черновик для переработки.
+var statusTransitions = map[string]map[string]statusTransition{
+	StatusDraft: {
+		StatusInReview: {},
+	},
+	StatusInReview: {
+		StatusPublished: {moderator: true},
+		StatusDraft:     {moderator: true, comment: true},
+	},
+	StatusPublished: {
+		StatusArchived: {moderator: true},
+	},
+	StatusArchived: {
+		StatusPublished: {moderator: true},
+		StatusDraft:     {},
+	},
+}
+
+// Ключ для хранения роли в контексте.
+type roleKey struct{}
+
+// WithRole возвращает контекст с ролью вызывающего.
+func WithRole(ctx context.Context, role string) context.Context {
+	return context.WithValue(ctx, roleKey{}, role)
+}
+
+// RoleFromContext возвращает роль вызывающего. Без роли вызывающий считается учащимся.
+func RoleFromContext(ctx context.Context) string {
+	if role, ok := ctx.Value(roleKey{}).(string); ok && role != "" {
+		return role
+	}
+	return RoleLearner
+}
+
+// Ключ для хранения ID тьютора, который вызывает по личному токену.
+type tutorIDKey struct{}
+
+// WithTutorID возвращает контекст с ID тьютора, которого определил личный токен.
+func WithTutorID(ctx context.Context, tutorID int) context.Context {
+	return context.WithValue(ctx, tutorIDKey{}, tutorID)
+}
+
+// TutorIDFromContext возвращает ID тьютора, который вызывает по личному токену, или 0.
+func TutorIDFromContext(ctx context.Context) int {
+	tutorID, _ := ctx.Value(tutorIDKey{}).(int)
+	return tutorID
+}
+
+// ValidStatus проверяет имя статуса.
+func ValidStatus(status string) bool {
+	_, ok := statusTransitions[status]
+	return ok
+}
+
+// Вызывающему видны только опубликованные вопросы и ответы.
+func publishedOnly(ctx context.Context) bool {
+	return RoleFromContext(ctx) == RoleLearner
+}
+
+// Условие видимости вопроса из таблицы или псевдонима alias. Для тьюторов и модераторов условие всегда истинно.
+func visibleQuestion(ctx context.Context, alias string) string {
+	if !publishedOnly(ctx) {
+		return "true"
+	}
+	return alias + ".status = '" + StatusPublished + "'"
+}
+
+// Условие видимости ответа из таблицы или псевдонима alias: опубликован сам ответ и его вопрос.
+func visibleAnswer(ctx context.Context, alias string) string {
+	if !publishedOnly(ctx) {
+		return "true"
+	}
+	return "(" + alias + ".status = '" + StatusPublished + "' and exists (select 1 from questions vq where vq.id = " + alias + ".question_id and vq.status = '" + StatusPublished + "'))"
+}
+
+// Условие видимости версии вопроса или ответа из таблицы или псевдонима alias.
+// Правка опубликованного сохраняется версией in_review, поэтому учащемуся видны только опубликованные версии.
+func visibleVersion(ctx context.Context, alias string) string {
+	if !publishedOnly(ctx) {
+		return "true"
+	}
+	return alias + ".status = '" + StatusPublished + "'"
+}
+
+// Запрос одной строки: *sql.DB или *sql.Tx.
+type rowQueryer interface {
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// Проверка, что вопрос (table = "questions") или ответ (table = "answers") есть и виден вызывающему.
+// Иначе возвращается sql.ErrNoRows, чтобы неопубликованное для учащегося выглядело несуществующим.
+func checkVisible(ctx context.Context, db rowQueryer, table string, id int) error {
+	visible := visibleQuestion(ctx, "target")
+	if table == "answers" {
+		visible = visibleAnswer(ctx, "target")
+	}
+
+	var exists bool
+	err := db.QueryRowContext(ctx, `select exists (select 1 from `+table+` target where target.id = $1 and `+visible+`)`, id).Scan(&exists)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// Проверка перехода из статуса from в to для роли вызывающего.
+func checkTransition(ctx context.Context, from string, to string, comment string) error {
+	if !ValidStatus(to) {
+		return fmt.Errorf("%w: неизвестный статус %q", ErrInvalidStatus, to)
+	}
+
+	rule, ok := statusTransitions[from][to]
+	if !ok {
+		return fmt.Errorf("%w: %s -> %s", ErrStatusTransition, from, to)
+	}
+
+	role := RoleFromContext(ctx)
+	if role == RoleLearner || (rule.moderator && role != RoleModerator) {
+		return fmt.Errorf("%w: %s -> %s", ErrStatusForbidden, from, to)
+	}
+
+	if rule.comment && strings.TrimSpace(comment) == "" {
+		return fmt.Errorf("%w: для %s -> %s нужен комментарий", ErrInvalidStatus, from, to)
+	}
+	return nil
+}
+
+// Проверка, что тьютор, который меняет статус, существует.
+func checkStatusTutor(ctx context.Context, tx *sql.Tx, tutorID int) error {
+	var exists bool
+	err := tx.QueryRowContext(ctx, `select exists (select 1 from tutors where id = $1)`, tutorID).Scan(&exists)
+	if err != nil {
+		return queryError(ctx, err)
+	}
+	if !exists {
+		return fmt.Errorf("%w: тьютор %d не найден", ErrInvalidStatus, tutorID)
+	}
+	return nil
+}
+
+// ChangeStatus переводит вопрос в новый статус и записывает смену статуса новой версией вопроса.
+// Недопустимый переход возвращает ErrStatusTransition, переход не по роли - ErrStatusForbidden.
+// Если вопроса нет, возвращается sql.ErrNoRows.
+func (questionService *QuestionService) ChangeStatus(ctx context.Context, id int, status string, tutorID int, comment string) (models.Question, error) {
+
+	// Ограничение времени выполнения запроса.
+	ctx, finish := beginQuery(ctx, "QuestionService.ChangeStatus", attribute.Int("question.id", id), attribute.String("question.status", status))
+	defer finish()
+
+	// Статус и версия - в одной транзакции.
+	tx, err := questionService.db.BeginTx(ctx, nil)
+	if err != nil {
+		return models.Question{}, queryError(ctx, err)
+	}
+
+	// Откат, если транзакция не была зафиксирована.
+	defer tx.Rollback()
+
+	// Блокировка вопроса, чтобы одновременные переходы проверялись по актуальному статусу.
+	var current string
+	err = tx.QueryRowContext(ctx, `select status from questions where id = $1 for update`, id).Scan(&current)
+	if err != nil {
+		return models.Question{}, queryError(ctx, err)
+	}
+
+	if err := checkTransition(ctx, current, status, comment); err != nil {
+		return models.Question{}, err
+	}
+	if err := checkStatusTutor(ctx, tx, tutorID); err != nil {
+		return models.Question{}, err
+	}
+
+	var question models.Question
+	err = tx.QueryRowContext(ctx, `update questions set status = $2 where id = $1
+		returning id, question_text, tutor_id, created_at, is_edit, status`, id, status).
+		Scan(&question.ID, &question.QuestionText, &question.TutorID, &question.CreatedAt, &question.IsEdit, &question.Status)
+	if err != nil {
+		return models.Question{}, queryError(ctx, err)
+	}
+
+	// Версия с тем же текстом, новым статусом и комментарием.
+	_, err = tx.ExecContext(ctx, `insert into question_versions (question_id, question_text, tutor_id, version_number, status, status_comment)
+		select $1, $2, $3, coalesce(max(version_number), 0) + 1, $4, $5 from question_versions where question_id = $1`,
+		id, question.QuestionText, tutorID, status, strings.TrimSpace(comment))
+	if err != nil {
+		return models.Question{}, queryError(ctx, err)
+	}
+
+	// Фиксация транзакции.
+	if err := tx.Commit(); err != nil {
+		return models.Question{}, queryError(ctx, err)
+	}
+
+	notifyQuestionsChanged(ctx, id)
+
+	return question, nil
+}
+
+// ChangeStatus переводит ответ в новый статус и записывает смену статуса новой версией ответа.
+// Ошибки - как у QuestionService.ChangeStatus.
+func (answerService *AnswerService) ChangeStatus(ctx context.Context, id int, status string, tutorID int, comment string) (models.Answer, error) {
+
+	// Ограничение времени выполнения запроса.
+	ctx, finish := beginQuery(ctx, "AnswerService.ChangeStatus", attribute.Int("answer.id", id), attribute.String("answer.status", status))
+	defer finish()
+
+	// Статус и версия - в одной транзакции.
+	tx, err := answerService.db.BeginTx(ctx, nil)
+	if err != nil {
+		return models.Answer{}, queryError(ctx, err)
+	}
+
+	// Откат, если транзакция не была зафиксирована.
+	defer tx.Rollback()
+
+	// Блокировка ответа, чтобы одновременные переходы проверялись по актуальному статусу.
+	var current string
+	err = tx.QueryRowContext(ctx, `select status from answers where id = $1 for update`, id).Scan(&current)
+	if err != nil {
+		return models.Answer{}, queryError(ctx, err)
+	}
+
+	if err := checkTransition(ctx, current, status, comment); err != nil {
+		return models.Answer{}, err
+	}
+	if err := checkStatusTutor(ctx, tx, tutorID); err != nil {
+		return models.Answer{}, err
+	}
+
+	var answer models.Answer
+	err = tx.QueryRowContext(ctx, `update answers set status = $2 where id = $1
+		returning id, answer_text, tutor_id, question_id, created_at, is_edit, is_accepted, position, helpful_count, not_helpful_count, upvote_count, score, status`, id, status).
+		Scan(&answer.ID, &answer.AnswersText, &answer.TutorID, &answer.QuestionID, &answer.CreatedAt, &answer.IsEdit, &answer.IsAccepted, &answer.Position, &answer.HelpfulCount, &answer.NotHelpfulCount, &answer.Upvotes, &answer.Score, &answer.Status)
+	if err != nil {
+		return models.Answer{}, queryError(ctx, err)
+	}
+
+	// Версия с тем же текстом, новым статусом и комментарием.
+	_, err = tx.ExecContext(ctx, `insert into answer_versions (answer_id, answer_text, question_id, tutor_id, version_number, status, status_comment)
+		select $1, $2, $3, $4, coalesce(max(version_number), 0) + 1, $5, $6 from answer_versions where answer_id = $1`,
+		id, answer.AnswersText, answer.QuestionID, tutorID, status, strings.TrimSpace(comment))
+	if err != nil {
+		return models.Answer{}, queryError(ctx, err)
+	}
+
+	// Фиксация транзакции.
+	if err := tx.Commit(); err != nil {
+		return models.Answer{}, queryError(ctx, err)
+	}
+
+	notifyAnswerChanged(ctx, id)
+
+	return answer, nil
+}
@@ -0,0 +1,64 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestCheckTransition(t *testing.T) {
+	learner := context.Background()
+	tutor := WithRole(context.Background(), RoleTutor)
+	moderator := WithRole(context.Background(), RoleModerator)
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		from    string
+		to      string
+		comment string
+		wantErr error
+	}{
+		{name: "учащийся не отправляет на проверку", ctx: learner, from: StatusDraft, to: StatusInReview, wantErr: ErrStatusForbidden},
+		{name: "учащийся не публикует", ctx: learner, from: StatusInReview, to: StatusPublished, wantErr: ErrStatusForbidden},
+		{name: "тьютор отправляет на проверку", ctx: tutor, from: StatusDraft, to: StatusInReview},
+		{name: "тьютор не публикует", ctx: tutor, from: StatusInReview, to: StatusPublished, wantErr: ErrStatusForbidden},
+		{name: "модератор публикует", ctx: moderator, from: StatusInReview, to: StatusPublished},
+		{name: "отклонение без комментария", ctx: moderator, from: StatusInReview, to: StatusDraft, wantErr: ErrInvalidStatus},
+		{name: "отклонение с комментарием", ctx: moderator, from: StatusInReview, to: StatusDraft, comment: "нужен пример"},
+		{name: "черновик сразу не публикуется", ctx: moderator, from: StatusDraft, to: StatusPublished, wantErr: ErrStatusTransition},
+		{name: "модератор архивирует", ctx: moderator, from: StatusPublished, to: StatusArchived},
+		{name: "тьютор возвращает из архива в черновик", ctx: tutor, from: StatusArchived, to: StatusDraft},
+		{name: "неизвестный статус", ctx: moderator, from: StatusDraft, to: "deleted", wantErr: ErrInvalidStatus},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkTransition(tt.ctx, tt.from, tt.to, tt.comment)
+			if !errors.Is(err, tt.wantErr) || (tt.wantErr == nil && err != nil) {
+				t.Errorf("checkTransition(%s -> %s) = %v, want %v", tt.from, tt.to, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestVisibility(t *testing.T) {
+	learner := context.Background()
+	moderator := WithRole(context.Background(), RoleModerator)
+
+	if got, want := visibleQuestion(learner, "q"), "q.status = 'published'"; got != want {
+		t.Errorf("visibleQuestion(learner) = %q, want %q", got, want)
+	}
+	if got := visibleQuestion(moderator, "q"); got != "true" {
+		t.Errorf("visibleQuestion(moderator) = %q, want true", got)
+	}
+	if got := visibleAnswer(moderator, "a"); got != "true" {
+		t.Errorf("visibleAnswer(moderator) = %q, want true", got)
+	}
+	if got, want := visibleVersion(learner, "v"), "v.status = 'published'"; got != want {
+		t.Errorf("visibleVersion(learner) = %q, want %q", got, want)
+	}
+	if got := visibleVersion(WithRole(context.Background(), RoleTutor), "v"); got != "true" {
+		t.Errorf("visibleVersion(tutor) = %q, want true", got)
+	}
+}
@@ -0,0 +1,84 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+// Имя сервиса в трейсах.
+const ServiceName = "knowledge-base"
+
+// Варианты экспорта трейсов.
+const (
+	ExporterNone   = "none"
+	ExporterOTLP   = "otlp"
+	ExporterStdout = "stdout"
+)
+
+// Настройки трассировки.
+type Options struct {
+	// Куда отправлять спаны: none, otlp или stdout.
+	Exporter string
+
+	// Адрес OTLP/HTTP коллектора, например http://localhost:4318.
+	OTLPEndpoint string
+
+	// Доля запросов, которые попадают в трейсы (от 0 до 1).
+	SampleRatio float64
+}
+
+// Setup настраивает глобальный TracerProvider и W3C propagator.
+// Возвращает функцию, которая отправляет оставшиеся спаны при остановке приложения.
+func Setup(ctx context.Context, options Options) (func(context.Context) error, error) {
+
+	// Контекст трассировки из заголовков traceparent/tracestate входящих запросов.
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	var exporter sdktrace.SpanExporter
+	var err error
+
+	switch strings.ToLower(options.Exporter) {
+	case "", ExporterNone:
+		// Трассировка выключена: остается TracerProvider по умолчанию, который ничего не записывает.
+		return func(context.Context) error { return nil }, nil
+	case ExporterOTLP:
+		exporter, err = otlptracehttp.New(ctx, otlptracehttp.WithEndpointURL(options.OTLPEndpoint))
+	case ExporterStdout:
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+	default:
+		return nil, fmt.Errorf("неизвестный экспортер трейсов %q", options.Exporter)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("ошибка создания экспортера трейсов: %w", err)
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(
+		semconv.SchemaURL,
+		semconv.ServiceName(ServiceName),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("ошибка создания ресурса трейсов: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(options.SampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+
+	return provider.Shutdown, nil
+}
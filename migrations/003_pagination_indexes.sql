@@ -0,0 +1,27 @@
+-- Индексы для постраничного чтения по ключу (cursor): сортировка по (колонка, id).
+-- Миграция выполняется при каждом запуске, поэтому все команды повторяемы.
+
+-- Курсор по дате создания требует значения во всех строках.
+update public.questions set created_at = now() where created_at is null;
+alter table public.questions alter column created_at set not null;
+
+update public.answers set created_at = now() where created_at is null;
+alter table public.answers alter column created_at set not null;
+
+update public.question_versions set created_at = now() where created_at is null;
+alter table public.question_versions alter column created_at set not null;
+
+update public.answer_versions set created_at = now() where created_at is null;
+alter table public.answer_versions alter column created_at set not null;
+
+create index if not exists questions_created_at_id_idx on public.questions (created_at, id);
+create index if not exists questions_tutor_id_id_idx on public.questions (tutor_id, id);
+
+create index if not exists answers_created_at_id_idx on public.answers (created_at, id);
+create index if not exists answers_tutor_id_id_idx on public.answers (tutor_id, id);
+
+create index if not exists question_versions_question_id_version_idx on public.question_versions (question_id, version_number, id);
+create index if not exists question_versions_question_id_created_at_idx on public.question_versions (question_id, created_at, id);
+
+create index if not exists answer_versions_answer_id_version_idx on public.answer_versions (answer_id, version_number, id);
+create index if not exists answer_versions_answer_id_created_at_idx on public.answer_versions (answer_id, created_at, id);
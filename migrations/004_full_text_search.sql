@@ -0,0 +1,12 @@
+-- Полнотекстовый поиск по вопросам и ответам.
+-- Вектор строится по русской и английской конфигурациям, чтобы находить словоформы обоих языков.
+-- Миграция выполняется при каждом запуске, поэтому все команды повторяемы.
+
+alter table public.questions add column if not exists search_vector tsvector
+    generated always as (to_tsvector('russian'::regconfig, question_text) || to_tsvector('english'::regconfig, question_text)) stored;
+
+alter table public.answers add column if not exists search_vector tsvector
+    generated always as (to_tsvector('russian'::regconfig, answer_text) || to_tsvector('english'::regconfig, answer_text)) stored;
+
+create index if not exists questions_search_vector_idx on public.questions using gin (search_vector);
+create index if not exists answers_search_vector_idx on public.answers using gin (search_vector);
@@ -0,0 +1,8 @@
+-- Нечеткий поиск с опечатками по тегам и тексту вопросов.
+-- Trigram индексы используются операторами % и <%, пороги задаются настройками pg_trgm в сервисе.
+-- Миграция выполняется при каждом запуске, поэтому все команды повторяемы.
+
+create extension if not exists pg_trgm;
+
+create index if not exists tags_tag_trgm_idx on public.tags using gin (lower(tag) gin_trgm_ops);
+create index if not exists questions_question_text_trgm_idx on public.questions using gin (lower(question_text) gin_trgm_ops);
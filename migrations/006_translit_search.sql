@@ -0,0 +1,24 @@
+-- Ключ транслитерации для поиска тегов независимо от алфавита: "докер" и "docker" дают один ключ "doker".
+-- Кириллица переводится в латиницу, латинские буквосочетания упрощаются до того, как их записывают по-русски
+-- (ck, ph, th, x, j, q, c), пробелы и разделители убираются, повторы букв схлопываются.
+-- Миграция выполняется при каждом запуске, поэтому все команды повторяемы.
+
+create or replace function public.translit_key(value text) returns text
+    language sql immutable strict parallel safe
+    as $$
+    select regexp_replace(
+        regexp_replace(
+            translate(
+                replace(replace(replace(replace(replace(replace(replace(replace(replace(
+                    regexp_replace(
+                        replace(replace(replace(replace(replace(replace(lower(trim(value)),
+                            'ck', 'k'), 'ph', 'f'), 'th', 't'), 'x', 'ks'), 'j', 'dzh'), 'q', 'k'),
+                        'c(?!h)', 'k', 'g'),
+                    'щ', 'sch'), 'ш', 'sh'), 'ч', 'ch'), 'ж', 'zh'), 'ц', 'ts'), 'ю', 'u'), 'я', 'ia'), 'ъ', ''), 'ь', ''),
+                'абвгдеёзийклмнопрстуфхыэwy',
+                'abvgdeeziiklmnoprstufhievi'),
+            '[\s_.\-]+', '', 'g'),
+        '(.)\1+', '\1', 'g')
+    $$;
+
+create index if not exists tags_translit_key_idx on public.tags (public.translit_key(tag));
@@ -0,0 +1,68 @@
+-- Словарь синонимов: группы равнозначных терминов ("k8s" и "kubernetes", "бд" и "базы данных").
+-- Синонимы читаются из таблицы при каждом запросе, поэтому изменения через API действуют без перезапуска.
+-- Миграция выполняется при каждом запуске, поэтому все команды повторяемы.
+
+create table if not exists public.synonym_groups (
+    id int generated always as identity primary key,
+    created_at timestamp default now()
+);
+
+create table if not exists public.synonyms (
+    id int generated always as identity primary key,
+    group_id int not null references public.synonym_groups(id) on delete cascade,
+    term varchar(50) not null
+);
+
+-- Термин может входить только в одну группу.
+create unique index if not exists synonyms_term_unique on public.synonyms (lower(trim(term)));
+create index if not exists synonyms_group_id_idx on public.synonyms (group_id);
+create index if not exists synonyms_translit_key_idx on public.synonyms (public.translit_key(term));
+
+-- Расширение tsquery синонимами: каждое вхождение термина из группы заменяется на ИЛИ всех терминов группы.
+-- Многословные термины сравниваются как фразы.
+create or replace function public.expand_synonyms(config regconfig, query tsquery) returns tsquery
+    language plpgsql stable
+    as $$
+declare
+    rule record;
+    result tsquery := query;
+begin
+    for rule in
+        select target, (
+                select string_agg('(' || phraseto_tsquery(config, g.term)::text || ')', ' | ')
+                from public.synonyms g
+                where g.group_id = s.group_id and numnode(phraseto_tsquery(config, g.term)) > 0
+            )::tsquery as substitute
+        from public.synonyms s
+        cross join lateral (select phraseto_tsquery(config, s.term) as target) t
+        where numnode(target) > 0 and query @> target
+    loop
+        result := ts_rewrite(result, rule.target, rule.substitute);
+    end loop;
+    return result;
+end
+$$;
+
+-- Начальный словарь для новой базы.
+do $$
+declare
+    group_terms text[];
+    new_group_id int;
+begin
+    if not exists (select 1 from public.synonym_groups) then
+        for group_terms in
+            select terms from (values
+                (array['kubernetes', 'k8s']),
+                (array['база данных', 'базы данных', 'бд', 'database', 'db']),
+                (array['golang', 'go']),
+                (array['postgresql', 'postgres', 'pg']),
+                (array['javascript', 'js']),
+                (array['ci/cd', 'непрерывная интеграция'])
+            ) as groups (terms)
+        loop
+            insert into public.synonym_groups default values returning id into new_group_id;
+            insert into public.synonyms (group_id, term) select new_group_id, unnest(group_terms);
+        end loop;
+    end if;
+end
+$$;
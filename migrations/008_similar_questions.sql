@@ -0,0 +1,14 @@
+-- Похожие вопросы и поиск дубликатов при создании вопроса.
+-- Сходство - косинус между множествами триграмм (pg_trgm): общих триграмм / sqrt(триграмм первого * триграмм второго).
+-- В отличие от similarity, оно меньше наказывает разницу в длине ("в Go" и "в Golang").
+-- Миграция выполняется при каждом запуске, поэтому все команды повторяемы.
+
+create or replace function public.trgm_cosine(a text, b text) returns double precision
+language sql immutable strict parallel safe as $$
+    select case
+        when cardinality(t.a) = 0 or cardinality(t.b) = 0 then 0
+        else (select count(*) from (select unnest(t.a) intersect select unnest(t.b)) common)::double precision
+            / sqrt(cardinality(t.a)::double precision * cardinality(t.b))
+    end
+    from (select show_trgm(a) as a, show_trgm(b) as b) t
+$$;
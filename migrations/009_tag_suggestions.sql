@@ -0,0 +1,6 @@
+-- Подсказки тегов и автоматическое добавление тегов.
+-- is_auto - тег добавлен автоматически по подсказке, added_by - тьютор, добавивший тег.
+-- Миграция выполняется при каждом запуске, поэтому все команды повторяемы.
+
+alter table public.questions_tags add column if not exists added_by int references public.tutors(id) on delete set null;
+alter table public.questions_tags add column if not exists is_auto boolean not null default false;
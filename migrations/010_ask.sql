@@ -0,0 +1,21 @@
+-- Ответы на свободные вопросы (POST /ask) и список вопросов без ответа.
+-- Запросы, для которых не нашлось достаточно похожего вопроса, собираются в unanswered_demand:
+-- одинаковые открытые запросы объединяются, ask_count показывает, сколько раз их задавали.
+-- Миграция выполняется при каждом запуске, поэтому все команды повторяемы.
+
+create table if not exists public.unanswered_demand (
+    id int generated always as identity primary key,
+    query_text text not null,
+    normalized_text text not null,
+    ask_count int not null default 1,
+    best_confidence double precision not null default 0,
+    status varchar(20) not null default 'open',
+    question_id int references public.questions(id) on delete set null,
+    created_at timestamp default now(),
+    last_asked_at timestamp default now(),
+    constraint unanswered_demand_status_check check (status in ('open', 'resolved', 'dismissed'))
+);
+
+-- Один открытый запрос на каждый текст без учета регистра и пробелов.
+create unique index if not exists unanswered_demand_open_unique on public.unanswered_demand (normalized_text) where status = 'open';
+create index if not exists unanswered_demand_status_idx on public.unanswered_demand (status, ask_count desc, last_asked_at desc);
@@ -0,0 +1,32 @@
+-- Журнал поисковых запросов и переходов к результатам для отчетов по поиску.
+-- client_id - обезличенный ID клиента (хэш с солью), сам адрес клиента не хранится.
+-- Миграция выполняется при каждом запуске, поэтому все команды повторяемы.
+
+create table if not exists public.search_log (
+    id bigint generated always as identity primary key,
+    search_id varchar(32) not null,
+    endpoint varchar(50) not null,
+    query text not null,
+    normalized_query text not null,
+    result_count int,
+    latency_ms double precision not null,
+    client_id varchar(32) not null,
+    created_at timestamp default now(),
+    constraint search_log_search_id_unique unique (search_id)
+);
+
+create index if not exists search_log_created_at_idx on public.search_log (created_at);
+create index if not exists search_log_normalized_query_idx on public.search_log (normalized_query, created_at);
+
+-- Переходы к результатам. Вопрос не связан внешним ключом, чтобы статистика сохранялась после его удаления.
+create table if not exists public.search_clicks (
+    id bigint generated always as identity primary key,
+    search_id varchar(32) not null,
+    question_id int not null,
+    position int,
+    client_id varchar(32) not null,
+    created_at timestamp default now(),
+    constraint search_clicks_unique unique (search_id, question_id)
+);
+
+create index if not exists search_clicks_search_id_idx on public.search_clicks (search_id);
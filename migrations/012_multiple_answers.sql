@@ -0,0 +1,30 @@
+-- Несколько ответов на вопрос: один из них может быть принятым, остальные упорядочены по position.
+-- Миграция выполняется при каждом запуске, поэтому все команды повторяемы.
+
+-- Раньше у вопроса мог быть только один ответ, а текст ответа был уникальным во всей базе.
+alter table public.answers drop constraint if exists question_id_unique;
+alter table public.answers drop constraint if exists answer_text_unique;
+
+-- Принятый ответ. Колонка добавляется без значения по умолчанию, чтобы отличить существующие ответы:
+-- они были единственными ответами своих вопросов и становятся принятыми. При повторном запуске null уже нет.
+alter table public.answers add column if not exists is_accepted bool;
+update public.answers a set is_accepted = not exists (
+    select 1 from public.answers b where b.question_id = a.question_id and b.id < a.id
+) where is_accepted is null;
+alter table public.answers alter column is_accepted set default false;
+alter table public.answers alter column is_accepted set not null;
+
+-- Порядок ответов вопроса. Новые ответы добавляются в конец, существующие нумеруются по id.
+alter table public.answers add column if not exists position int;
+update public.answers a set position = r.rn
+from (select id, row_number() over (partition by question_id order by id) as rn from public.answers) r
+where a.id = r.id and a.position is null;
+alter table public.answers alter column position set default 0;
+alter table public.answers alter column position set not null;
+
+-- У вопроса не больше одного принятого ответа.
+create unique index if not exists answers_accepted_unique on public.answers (question_id) where is_accepted;
+
+-- Ответы вопроса по порядку и версии всех ответов вопроса.
+create index if not exists answers_question_position_idx on public.answers (question_id, position, id);
+create index if not exists answer_versions_question_id_idx on public.answer_versions (question_id);
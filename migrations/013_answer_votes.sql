@@ -0,0 +1,50 @@
+-- Оценки ответов: "был ли ответ полезен" от учащихся и голоса тьюторов за ответы коллег.
+-- Миграция выполняется при каждом запуске, поэтому все команды повторяемы.
+
+-- Оценка учащегося. Учащийся определяется обезличенным ID клиента, одна оценка на ответ (повторная заменяет прежнюю).
+create table if not exists public.answer_feedback (
+    id int generated always as identity primary key,
+    answer_id int not null references public.answers (id) on delete cascade,
+    client_id varchar(32) not null,
+    helpful bool not null,
+    comment text not null default '',
+    created_at timestamp not null default now(),
+    updated_at timestamp not null default now(),
+    constraint answer_feedback_client_unique unique (answer_id, client_id),
+    constraint answer_feedback_comment_length check (char_length(comment) <= 1000)
+);
+
+-- Комментарии для отчета по ответу.
+create index if not exists answer_feedback_answer_updated_idx on public.answer_feedback (answer_id, updated_at desc);
+
+-- Голос тьютора за ответ коллеги, один на ответ.
+create table if not exists public.answer_votes (
+    answer_id int not null references public.answers (id) on delete cascade,
+    tutor_id int not null references public.tutors (id) on delete cascade,
+    created_at timestamp not null default now(),
+    constraint answer_votes_pk primary key (answer_id, tutor_id)
+);
+
+-- Счетчики оценок хранятся в ответе, чтобы читать и сортировать ответы без подсчета.
+-- Они пересчитываются при каждой оценке, а здесь выравниваются с таблицами оценок.
+alter table public.answers add column if not exists helpful_count int not null default 0;
+alter table public.answers add column if not exists not_helpful_count int not null default 0;
+alter table public.answers add column if not exists upvote_count int not null default 0;
+alter table public.answers add column if not exists score int
+    generated always as (upvote_count + helpful_count - not_helpful_count) stored;
+
+update public.answers a set
+    helpful_count = c.helpful,
+    not_helpful_count = c.not_helpful,
+    upvote_count = c.upvotes
+from (
+    select a.id,
+        (select count(*) from public.answer_feedback f where f.answer_id = a.id and f.helpful) as helpful,
+        (select count(*) from public.answer_feedback f where f.answer_id = a.id and not f.helpful) as not_helpful,
+        (select count(*) from public.answer_votes v where v.answer_id = a.id) as upvotes
+    from public.answers a
+) c
+where a.id = c.id
+    and (a.helpful_count, a.not_helpful_count, a.upvote_count) is distinct from (c.helpful, c.not_helpful, c.upvotes);
+
+create index if not exists answers_score_idx on public.answers (score, id);
@@ -0,0 +1,46 @@
+-- Комментарии к вопросам и ответам с ветками ответов, мягким удалением и упоминаниями тьюторов.
+-- Миграция выполняется при каждом запуске, поэтому все команды повторяемы.
+
+create table if not exists public.comments (
+    id int generated always as identity primary key,
+
+    -- Комментарий относится либо к вопросу, либо к ответу.
+    question_id int references public.questions (id) on delete cascade,
+    answer_id int references public.answers (id) on delete cascade,
+
+    -- Комментарий, на который отвечают. Ответ всегда относится к тому же вопросу или ответу.
+    parent_id int references public.comments (id) on delete cascade,
+
+    -- Автор: тьютор или учащийся. Учащийся определяется обезличенным ID клиента и подписывается именем.
+    author_type varchar(10) not null,
+    tutor_id int references public.tutors (id) on delete set null,
+    learner_name varchar(50),
+    client_id varchar(32),
+
+    comment_text text not null,
+    created_at timestamp not null default now(),
+    updated_at timestamp,
+    is_edit bool not null default false,
+
+    -- Мягкое удаление: комментарий остается в ветке, но текст не показывается.
+    is_deleted bool not null default false,
+    deleted_at timestamp,
+    delete_by_tutor int,
+
+    constraint comments_target_check check ((question_id is null) <> (answer_id is null)),
+    constraint comments_author_type_check check (author_type in ('tutor', 'learner')),
+    constraint comments_text_length check (char_length(comment_text) between 1 and 5000)
+);
+
+create index if not exists comments_question_idx on public.comments (question_id, id) where question_id is not null;
+create index if not exists comments_answer_idx on public.comments (answer_id, id) where answer_id is not null;
+create index if not exists comments_parent_idx on public.comments (parent_id);
+
+-- Тьюторы, упомянутые в комментарии через @имя или @email.
+create table if not exists public.comment_mentions (
+    comment_id int not null references public.comments (id) on delete cascade,
+    tutor_id int not null references public.tutors (id) on delete cascade,
+    constraint comment_mentions_pk primary key (comment_id, tutor_id)
+);
+
+create index if not exists comment_mentions_tutor_idx on public.comment_mentions (tutor_id, comment_id);
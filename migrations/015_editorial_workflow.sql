@@ -0,0 +1,30 @@
+-- Редакционный процесс: статус вопросов и ответов (черновик, на проверке, опубликован, в архиве).
+-- Миграция выполняется при каждом запуске, поэтому все команды повторяемы.
+
+-- Вопросы и ответы, созданные до появления статусов, уже были видны всем, поэтому при добавлении колонки
+-- они получают статус published. Новые вопросы и ответы создаются черновиками.
+alter table public.questions add column if not exists status varchar(20) not null default 'published';
+alter table public.questions alter column status set default 'draft';
+alter table public.questions drop constraint if exists questions_status_check;
+alter table public.questions add constraint questions_status_check
+    check (status in ('draft', 'in_review', 'published', 'archived'));
+
+alter table public.answers add column if not exists status varchar(20) not null default 'published';
+alter table public.answers alter column status set default 'draft';
+alter table public.answers drop constraint if exists answers_status_check;
+alter table public.answers add constraint answers_status_check
+    check (status in ('draft', 'in_review', 'published', 'archived'));
+
+-- Статус в истории версий: каждая смена статуса добавляет версию с комментарием проверяющего.
+-- Прежние версии относятся к опубликованным вопросам и ответам.
+alter table public.question_versions add column if not exists status varchar(20) not null default 'published';
+alter table public.question_versions alter column status set default 'draft';
+alter table public.question_versions add column if not exists status_comment text not null default '';
+
+alter table public.answer_versions add column if not exists status varchar(20) not null default 'published';
+alter table public.answer_versions alter column status set default 'draft';
+alter table public.answer_versions add column if not exists status_comment text not null default '';
+
+-- Публичные списки читают только опубликованные строки, очередь проверки - строки in_review.
+create index if not exists questions_status_idx on public.questions (status, id);
+create index if not exists answers_status_idx on public.answers (status, id);